@@ -0,0 +1,52 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+
+	"github.com/mayureshucsb2019/bookstore/service/factory"
+	"github.com/mayureshucsb2019/bookstore/service/recommendation"
+)
+
+// computeRecommendations runs the compute-recommendations subcommand, which replaces the stored
+// recommendations of every book with ones computed from the current orders and catalog. It
+// returns the exit code.
+func computeRecommendations(args []string) int {
+	flags := flag.NewFlagSet("compute-recommendations", flag.ExitOnError)
+	configFile := flags.String("config", "config.json", "path to the configuration file")
+	top := flags.Int("top", 10, "recommendations kept per book")
+	minBuyers := flags.Int("min-buyers", 1, "customers who must have bought two books for them to count as bought together")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: bookstore compute-recommendations [-config config.json] [-top 10] [-min-buyers 1]")
+		flags.PrintDefaults()
+	}
+	_ = flags.Parse(args)
+	if flags.NArg() > 0 || *top <= 0 || *minBuyers <= 0 {
+		flags.Usage()
+		return 2
+	}
+
+	config, err := loadConfig(*configFile)
+	if err != nil {
+		log.Fatalf("Error loading config: %v", err)
+	}
+	dbConn, err := connectDB(config)
+	if err != nil {
+		log.Fatalf("Failed to connect to the database: %v", err)
+	}
+	defer dbConn.Close()
+	job := recommendation.NewJob(factory.GetRepositoryFactory(dbConn).CreateRecommendationRepository())
+	job.TopN = *top
+	job.MinBuyers = *minBuyers
+
+	report, err := job.Run()
+	if err != nil {
+		log.Printf("Failed to compute recommendations: %v", err)
+		return 1
+	}
+	fmt.Printf("Books: %d, %d with recommendations\n", report.Books, report.Recommended)
+	fmt.Printf("Recommendations: %d bought together, %d same author, %d similar tags\n",
+		report.BoughtTogether, report.SameAuthor, report.SimilarTags)
+	return 0
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+
+	"github.com/mayureshucsb2019/bookstore/service/factory"
+	"github.com/mayureshucsb2019/bookstore/service/onix"
+)
+
+// importOnix runs the import-onix subcommand, which loads ONIX 3.0 files into the catalog and
+// prints a report for each. It returns the exit code: 1 if any file or product failed.
+func importOnix(args []string) int {
+	flags := flag.NewFlagSet("import-onix", flag.ExitOnError)
+	configFile := flags.String("config", "config.json", "path to the configuration file")
+	dryRun := flags.Bool("dry-run", false, "validate the files and report what would change without saving")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: bookstore import-onix [-config config.json] [-dry-run] file.xml...")
+		flags.PrintDefaults()
+	}
+	_ = flags.Parse(args)
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return 2
+	}
+
+	config, err := loadConfig(*configFile)
+	if err != nil {
+		log.Fatalf("Error loading config: %v", err)
+	}
+	dbConn, err := connectDB(config)
+	if err != nil {
+		log.Fatalf("Failed to connect to the database: %v", err)
+	}
+	defer dbConn.Close()
+	importer := onix.NewImporter(factory.GetRepositoryFactory(dbConn).CreateBookRepository())
+
+	status := 0
+	for _, path := range flags.Args() {
+		file, err := os.Open(path)
+		if err != nil {
+			log.Printf("Failed to open %s: %v", path, err)
+			status = 1
+			continue
+		}
+		report, err := importer.Import(file, *dryRun)
+		file.Close()
+		printOnixReport(path, report)
+		if err != nil {
+			log.Printf("Failed to import %s: %v", path, err)
+			status = 1
+		}
+		if len(report.Errors) > 0 {
+			status = 1
+		}
+	}
+	return status
+}
+
+func printOnixReport(path string, report *onix.Report) {
+	action := "Imported"
+	if report.DryRun {
+		action = "Dry run of"
+	}
+	fmt.Printf("%s %s: %d products, %d created, %d updated, %d unchanged, %d skipped, %d failed, %d new authors\n",
+		action, path, report.Products, report.Created, report.Updated, report.Unchanged, report.Skipped, len(report.Errors), report.AuthorsCreated)
+	for _, e := range report.Errors {
+		fmt.Printf("  error: %s (ISBN %s): %s\n", e.Reference, e.ISBN, e.Message)
+	}
+	paths := make([]string, 0, len(report.Unmapped))
+	for p := range report.Unmapped {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	for _, p := range paths {
+		fmt.Printf("  unmapped: %s (%d)\n", p, report.Unmapped[p])
+	}
+}
@@ -3,16 +3,35 @@ package main
 import (
 	"encoding/json"
 	"flag"
+	"fmt"
 	"io"
 	"log"
 	"net/http"
 	"os"
+	"time"
 
 	author_service "github.com/mayureshucsb2019/bookstore/service/author/service"
 	book_service "github.com/mayureshucsb2019/bookstore/service/book/service"
+	cart_service "github.com/mayureshucsb2019/bookstore/service/cart/service"
 	"github.com/mayureshucsb2019/bookstore/service/common"
+	customer_db "github.com/mayureshucsb2019/bookstore/service/customer/db"
 	customer_service "github.com/mayureshucsb2019/bookstore/service/customer/service"
+	"github.com/mayureshucsb2019/bookstore/service/event"
+	event_service "github.com/mayureshucsb2019/bookstore/service/event/service"
+	exchange_service "github.com/mayureshucsb2019/bookstore/service/exchange/service"
 	"github.com/mayureshucsb2019/bookstore/service/factory"
+	"github.com/mayureshucsb2019/bookstore/service/mail"
+	order_service "github.com/mayureshucsb2019/bookstore/service/order/service"
+	"github.com/mayureshucsb2019/bookstore/service/payment"
+	"github.com/mayureshucsb2019/bookstore/service/pii"
+	promotion_service "github.com/mayureshucsb2019/bookstore/service/promotion/service"
+	recommendation_service "github.com/mayureshucsb2019/bookstore/service/recommendation/service"
+	review_service "github.com/mayureshucsb2019/bookstore/service/review/service"
+	shipping_service "github.com/mayureshucsb2019/bookstore/service/shipping/service"
+	"github.com/mayureshucsb2019/bookstore/service/tax"
+	"github.com/mayureshucsb2019/bookstore/service/token"
+	webhook_service "github.com/mayureshucsb2019/bookstore/service/webhook/service"
+	wishlist_service "github.com/mayureshucsb2019/bookstore/service/wishlist/service"
 )
 
 type Config struct {
@@ -21,6 +40,64 @@ type Config struct {
 	Host     string `json:"host"`
 	Port     string `json:"port"`
 	DBName   string `json:"dbname"`
+
+	// Optional JSON file of exchange rates loaded into the database at startup
+	ExchangeRatesFile string `json:"exchange_rates_file"`
+
+	// Carts untouched for this many hours are deleted, defaults to 72
+	CartTTLHours int `json:"cart_ttl_hours"`
+
+	// Optional JSON file of tax rates by country and state; orders are untaxed without it
+	TaxRatesFile string `json:"tax_rates_file"`
+
+	// Payment provider charged at checkout, defaults to the local "fake" gateway
+	PaymentProvider string `json:"payment_provider"`
+
+	// Card tokens the fake gateway declines, mapped to the decline reason. Defaults to
+	// payment.DefaultDeclineCards
+	FakeDeclineCards map[string]string `json:"fake_decline_cards"`
+
+	// Responses to POST requests with an Idempotency-Key are replayed for this many hours, defaults to 24
+	IdempotencyTTLHours int `json:"idempotency_ttl_hours"`
+
+	// Where Idempotency-Key responses are kept: "database", the default, shares them between
+	// instances and restarts; "memory" keeps them in this instance only
+	IdempotencyStore string `json:"idempotency_store"`
+
+	// Largest request and response bodies of POST requests with an Idempotency-Key, in KB,
+	// defaults to 1024. Larger keyed requests are rejected; larger responses are not stored
+	IdempotencyMaxBodyKB int `json:"idempotency_max_body_kb"`
+
+	// Write every domain event to the log as it is dispatched from the outbox
+	LogEvents bool `json:"log_events"`
+
+	// Dispatched events are kept in the outbox for this many hours, defaults to 168
+	OutboxRetentionHours int `json:"outbox_retention_hours"`
+
+	// Latest events kept in memory for clients resuming the event stream, defaults to 1000
+	EventStreamBufferSize int `json:"event_stream_buffer_size"`
+
+	// Records saved per transaction by the /bulk imports, defaults to 500. Requests may override it
+	// with batch_size
+	BulkBatchSize int `json:"bulk_batch_size"`
+
+	// Optional JSON file of the keys encrypting customer personal data, see pii.KeyFile. The
+	// BOOKSTORE_PII_KEYS environment variable takes precedence; without either, customers are
+	// stored in plaintext
+	PIIKeysFile string `json:"pii_keys_file"`
+
+	// Delivery of the registration, password reset and email change mails, see mail.Config. By
+	// default they are written to files in ./mail
+	Mail mail.Config `json:"mail"`
+
+	// Base64 key of at least 32 bytes signing the tokens in those mails. The BOOKSTORE_TOKEN_SECRET
+	// environment variable takes precedence; without either a random key is used, so tokens stop
+	// working when the server restarts
+	TokenSecret string `json:"token_secret"`
+
+	// Thresholds of the customer segments, see customer_db.SegmentRules. Defaults to 30 days for
+	// new customers, 180 days without an order for lapsed ones and a lifetime value of 1000 for VIPs
+	CustomerSegments customer_db.SegmentRules `json:"customer_segments"`
 }
 
 // LoadConfig reads the configuration from a JSON file.
@@ -45,24 +122,63 @@ func loadConfig(filePath string) (Config, error) {
 	return config, nil
 }
 
+// connectDB opens the database connection described by the configuration.
+func connectDB(config Config) (*common.DBConnection, error) {
+	return common.GetDBInstance(common.DBConfig{
+		Username: config.Username,
+		Password: config.Password,
+		Host:     config.Host,
+		Port:     config.Port,
+		DBName:   config.DBName,
+	})
+}
+
+// loadPIIKeys reads the keys encrypting customer personal data, or returns nil when none are
+// configured.
+func loadPIIKeys(config Config) (*pii.Keyring, error) {
+	keys, err := pii.LoadKeyring(config.PIIKeysFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load PII keys: %w", err)
+	}
+	return keys, nil
+}
+
+// loadTokenSigner creates the signer of mailed tokens.
+func loadTokenSigner(config Config) (*token.Signer, error) {
+	secret := os.Getenv(token.SecretEnv)
+	if secret == "" {
+		secret = config.TokenSecret
+	}
+	signer, err := token.NewSignerFromSecret(secret)
+	if err != nil || signer != nil {
+		return signer, err
+	}
+	log.Printf("No token secret configured, mailed tokens stop working when the server restarts")
+	return token.NewRandomSigner()
+}
+
 func main() {
+	// Subcommands run instead of the server
+	if len(os.Args) > 1 && os.Args[1] == "import-onix" {
+		os.Exit(importOnix(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "reencrypt-customers" {
+		os.Exit(reencryptCustomers(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "compute-recommendations" {
+		os.Exit(computeRecommendations(os.Args[2:]))
+	}
 
 	// Load configuration from file
 	configFile := flag.String("config", "config.json", "path to the configuration file")
+	flag.Parse()
 	config, err := loadConfig(*configFile)
 	if err != nil {
 		log.Fatalf("Error loading config: %v", err)
 	}
 
 	// Initialize DB connection
-	dbConn, err := common.GetDBInstance(common.DBConfig{
-		Username: config.Username,
-		Password: config.Password,
-		Host:     config.Host,
-		Port:     config.Port,
-		DBName:   config.DBName,
-	})
-
+	dbConn, err := connectDB(config)
 	if err != nil {
 		log.Fatalf("Failed to connect to the database: %v", err)
 	}
@@ -71,23 +187,169 @@ func main() {
 	// Get the repository factory
 	repoFactory := factory.GetRepositoryFactory(dbConn)
 
+	// Create the exchange rate repository and seed it from the rates file if configured
+	exchangeRateRepo := repoFactory.CreateExchangeRateRepository()
+	if config.ExchangeRatesFile != "" {
+		count, err := exchangeRateRepo.LoadExchangeRatesFile(config.ExchangeRatesFile)
+		if err != nil {
+			log.Fatalf("Failed to load exchange rates: %v", err)
+		}
+		log.Printf("Loaded %d exchange rates from %s", count, config.ExchangeRatesFile)
+	}
+	exchangeAPIService := exchange_service.NewDefaultAPIService(exchangeRateRepo)
+	exchangeAPIController := exchange_service.NewDefaultAPIController(exchangeAPIService)
+
 	// Create the book repository with the DB connection
 	bookRepo := repoFactory.CreateBookRepository()
-	bookAPIService := book_service.NewDefaultAPIService(bookRepo)
+	bookAPIService := book_service.NewDefaultAPIService(bookRepo, exchangeRateRepo)
+	bookAPIService.BulkBatchSize = config.BulkBatchSize
+	bookAPIService.Reviews = repoFactory.CreateReviewRepository()
 	bookAPIController := book_service.NewDefaultAPIController(bookAPIService)
 
 	// Create the author repository with the DB connection
 	authorRepo := repoFactory.CreateAuthorRepository()
 	authorAPIService := author_service.NewDefaultAPIService(authorRepo)
+	authorAPIService.BulkBatchSize = config.BulkBatchSize
 	authorAPIController := author_service.NewDefaultAPIController(authorAPIService)
 
 	// Create the author repository with the DB connection
 	customerRepo := repoFactory.CreateCustomerRepository()
-	customerAPIService := customer_service.NewDefaultAPIService(customerRepo)
+	customerRepo.Segments = config.CustomerSegments
+	if customerRepo.Keys, err = loadPIIKeys(config); err != nil {
+		log.Fatal(err)
+	}
+	if customerRepo.Keys == nil {
+		log.Printf("No PII keys configured, customer personal data is stored in plaintext and erasure receipts keep no email hash")
+	}
+	customerAPIService := customer_service.NewDefaultAPIService(customerRepo, repoFactory.CreateOrderRepository(),
+		repoFactory.CreatePaymentRepository(), repoFactory.CreateOutboxRepository())
+	customerAPIService.BulkBatchSize = config.BulkBatchSize
+	customerAPIService.Reviews = repoFactory.CreateReviewRepository()
+	customerAPIService.Wishlists = repoFactory.CreateWishlistRepository()
+	if customerAPIService.Mailer, err = mail.NewMailer(config.Mail); err != nil {
+		log.Fatalf("Failed to create mailer: %v", err)
+	}
+	if customerAPIService.Tokens, err = loadTokenSigner(config); err != nil {
+		log.Fatalf("Failed to load token secret: %v", err)
+	}
 	customerAPIController := customer_service.NewDefaultAPIController(customerAPIService)
 
+	// Create the payment processor that records attempts against orders
+	paymentProvider, err := payment.NewProvider(config.PaymentProvider, config.FakeDeclineCards)
+	if err != nil {
+		log.Fatalf("Failed to create payment provider: %v", err)
+	}
+	paymentProcessor := payment.NewProcessor(paymentProvider, repoFactory.CreatePaymentRepository())
+
+	// Create the order repository with the DB connection
+	orderRepo := repoFactory.CreateOrderRepository()
+	orderAPIService := order_service.NewDefaultAPIService(orderRepo, paymentProcessor)
+	orderAPIController := order_service.NewDefaultAPIController(orderAPIService)
+
+	// Create the promotion repository with the DB connection
+	promotionRepo := repoFactory.CreatePromotionRepository()
+	promotionAPIService := promotion_service.NewDefaultAPIService(promotionRepo)
+	promotionAPIController := promotion_service.NewDefaultAPIController(promotionAPIService)
+
+	// Create the shipping method repository with the DB connection
+	shippingMethodRepo := repoFactory.CreateShippingMethodRepository()
+	shippingAPIService := shipping_service.NewDefaultAPIService(shippingMethodRepo)
+	shippingAPIController := shipping_service.NewDefaultAPIController(shippingAPIService)
+
+	// Create the review repository with the DB connection
+	reviewAPIService := review_service.NewDefaultAPIService(repoFactory.CreateReviewRepository())
+	reviewAPIController := review_service.NewDefaultAPIController(reviewAPIService)
+
+	// Build the tax table applied at checkout
+	taxCalculator, err := tax.NewTableCalculator(nil)
+	if config.TaxRatesFile != "" {
+		taxCalculator, err = tax.LoadTableFile(config.TaxRatesFile)
+	}
+	if err != nil {
+		log.Fatalf("Failed to load tax rates: %v", err)
+	}
+
+	// Create the cart repository and expire abandoned carts in the background
+	cartRepo := repoFactory.CreateCartRepository()
+	cartAPIService := cart_service.NewDefaultAPIService(cartRepo, bookRepo, orderRepo, customerRepo, exchangeRateRepo, promotionRepo,
+		shippingMethodRepo, taxCalculator, paymentProcessor)
+	cartAPIController := cart_service.NewDefaultAPIController(cartAPIService)
+	cartTTL := time.Duration(config.CartTTLHours) * time.Hour
+	if cartTTL <= 0 {
+		cartTTL = 72 * time.Hour
+	}
+	stopCartExpiry := cartAPIService.StartExpiryWorker(time.Hour, cartTTL)
+	defer stopCartExpiry()
+
+	// Deliver domain events from the outbox to the registered sinks
+	eventDispatcher := event.NewDispatcher(repoFactory.CreateOutboxRepository())
+	if config.LogEvents {
+		eventDispatcher.Register(event.LogSink{})
+	}
+
+	// Create the webhook repository, queue events for subscribers and send them in the background
+	webhookRepo := repoFactory.CreateWebhookRepository()
+	webhookAPIService := webhook_service.NewDefaultAPIService(webhookRepo)
+	webhookAPIController := webhook_service.NewDefaultAPIController(webhookAPIService)
+	eventDispatcher.Register(webhook_service.Sink{Repo: webhookRepo})
+	stopWebhookSender := webhook_service.NewSender(webhookRepo).Start(5 * time.Second)
+	defer stopWebhookSender()
+
+	// Create the wishlist repository, fire book alerts on stock and price changes and mail them
+	wishlistRepo := repoFactory.CreateWishlistRepository()
+	wishlistAPIService := wishlist_service.NewDefaultAPIService(wishlistRepo)
+	wishlistAPIController := wishlist_service.NewDefaultAPIController(wishlistAPIService)
+	eventDispatcher.Register(wishlist_service.AlertSink{Repo: wishlistRepo})
+	stopNotifier := wishlist_service.NewNotifier(wishlistRepo, customerAPIService.Mailer).Start(time.Minute)
+	defer stopNotifier()
+
+	// Serve the recommendations stored by the compute-recommendations subcommand
+	recommendationAPIService := recommendation_service.NewDefaultAPIService(repoFactory.CreateRecommendationRepository())
+	recommendationAPIController := recommendation_service.NewDefaultAPIController(recommendationAPIService)
+
+	outboxRetention := time.Duration(config.OutboxRetentionHours) * time.Hour
+	if outboxRetention <= 0 {
+		outboxRetention = 7 * 24 * time.Hour
+	}
+	stopEventDispatcher := eventDispatcher.Start(time.Second, outboxRetention)
+	defer stopEventDispatcher()
+
+	// Follow the outbox to push catalog and inventory changes to event stream clients
+	eventStreamBufferSize := config.EventStreamBufferSize
+	if eventStreamBufferSize <= 0 {
+		eventStreamBufferSize = 1000
+	}
+	eventFeed := event.NewFeed(repoFactory.CreateOutboxRepository(), eventStreamBufferSize)
+	stopEventFeed, err := eventFeed.Start(500 * time.Millisecond)
+	if err != nil {
+		log.Fatalf("Failed to start the event feed: %v", err)
+	}
+	defer stopEventFeed()
+	eventStreamController := event_service.NewStreamController(eventFeed)
+
 	log.Printf("Server started")
-	router := common.NewRouter(bookAPIController, authorAPIController, customerAPIController)
+	router := common.NewRouter(bookAPIController, authorAPIController, customerAPIController, exchangeAPIController,
+		orderAPIController, cartAPIController, promotionAPIController, shippingAPIController, webhookAPIController,
+		reviewAPIController, wishlistAPIController, recommendationAPIController, eventStreamController)
+
+	idempotencyTTL := time.Duration(config.IdempotencyTTLHours) * time.Hour
+	if idempotencyTTL <= 0 {
+		idempotencyTTL = 24 * time.Hour
+	}
+	var idempotencyStore common.IdempotencyStore
+	switch config.IdempotencyStore {
+	case "", "database":
+		idempotencyStore = common.NewDBIdempotencyStore(dbConn)
+	case "memory":
+		idempotencyStore = common.NewMemoryIdempotencyStore()
+	default:
+		log.Fatalf("Unknown idempotency_store %q, expected database or memory", config.IdempotencyStore)
+	}
+	idempotencyMaxBody := int64(config.IdempotencyMaxBodyKB) << 10
+	if idempotencyMaxBody <= 0 {
+		idempotencyMaxBody = 1 << 20
+	}
+	handler := common.Idempotency(router, idempotencyStore, idempotencyTTL, idempotencyMaxBody)
 
-	log.Fatal(http.ListenAndServe(":8080", router))
+	log.Fatal(http.ListenAndServe(":8080", handler))
 }
@@ -0,0 +1,18 @@
+USE bookstore;
+
+-- Store prices and totals as exact decimals with an ISO 4217 currency code.
+-- FLOAT values are rounded to whole cents because every existing row is in USD.
+ALTER TABLE Books
+    MODIFY cost DECIMAL(19,4),
+    ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'USD';
+UPDATE Books SET cost = ROUND(cost, 2) WHERE cost IS NOT NULL;
+
+UPDATE Orders SET total_amount = 0 WHERE total_amount IS NULL;
+ALTER TABLE Orders
+    MODIFY total_amount DECIMAL(19,4) NOT NULL DEFAULT 0,
+    ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'USD';
+UPDATE Orders SET total_amount = ROUND(total_amount, 2);
+
+ALTER TABLE OrderItems
+    ADD COLUMN unit_price DECIMAL(19,4) NOT NULL DEFAULT 0 AFTER quantity,
+    ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'USD' AFTER unit_price;
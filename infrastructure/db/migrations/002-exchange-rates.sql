@@ -0,0 +1,15 @@
+USE bookstore;
+
+-- Exchange rates for displaying and charging prices in other currencies
+CREATE TABLE IF NOT EXISTS ExchangeRates (
+    base_currency CHAR(3) NOT NULL,
+    quote_currency CHAR(3) NOT NULL,
+    rate DECIMAL(20,10) NOT NULL,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (base_currency, quote_currency)
+);
+
+-- Record the rate used when an order was priced in another currency
+ALTER TABLE Orders
+    ADD COLUMN exchange_rate DECIMAL(20,10),
+    ADD COLUMN source_currency CHAR(3);
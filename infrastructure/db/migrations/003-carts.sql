@@ -0,0 +1,28 @@
+USE bookstore;
+
+-- Track how many copies of each book can be ordered. Books added from now on start out of stock,
+-- but existing books get @initial_stock copies so the catalog can still be ordered until real
+-- levels are set through PATCH /books/{isbn}. Change it before running if 100 does not suit
+SET @initial_stock = 100;
+ALTER TABLE Books ADD COLUMN stock INT NOT NULL DEFAULT 0;
+UPDATE Books SET stock = @initial_stock;
+
+-- Create the Carts table. Anonymous carts have no customer and are addressed by their random id
+CREATE TABLE IF NOT EXISTS Carts (
+    id VARCHAR(64) PRIMARY KEY,
+    customer_email VARCHAR(255) UNIQUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (customer_email) REFERENCES Customer(email) ON DELETE CASCADE
+);
+
+-- Create the CartItems table
+CREATE TABLE IF NOT EXISTS CartItems (
+    cart_id VARCHAR(64),
+    isbn VARCHAR(255),
+    quantity INT NOT NULL,
+    added_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (cart_id, isbn),
+    FOREIGN KEY (cart_id) REFERENCES Carts(id) ON DELETE CASCADE,
+    FOREIGN KEY (isbn) REFERENCES Books(isbn) ON DELETE CASCADE
+);
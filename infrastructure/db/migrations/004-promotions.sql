@@ -0,0 +1,39 @@
+USE bookstore;
+
+-- Keep the subtotal and discount total of each order next to the payable total
+ALTER TABLE Orders ADD COLUMN subtotal_amount DECIMAL(19,4) NOT NULL DEFAULT 0 AFTER order_date;
+ALTER TABLE Orders ADD COLUMN discount_amount DECIMAL(19,4) NOT NULL DEFAULT 0 AFTER subtotal_amount;
+UPDATE Orders SET subtotal_amount = total_amount;
+
+-- Create the Promotions table. Validity windows are stored in UTC
+CREATE TABLE IF NOT EXISTS Promotions (
+    code VARCHAR(64) PRIMARY KEY,
+    description VARCHAR(255),
+    type VARCHAR(32) NOT NULL,
+    percent_off DECIMAL(5,2),
+    amount_off DECIMAL(19,4) NOT NULL DEFAULT 0,
+    currency CHAR(3) NOT NULL DEFAULT 'USD',
+    buy_quantity INT NOT NULL DEFAULT 0,
+    get_quantity INT NOT NULL DEFAULT 0,
+    tag VARCHAR(255),
+    starts_at DATETIME,
+    ends_at DATETIME,
+    usage_limit_per_customer INT NOT NULL DEFAULT 0,
+    stackable BOOLEAN NOT NULL DEFAULT FALSE,
+    priority INT NOT NULL DEFAULT 0,
+    auto_apply BOOLEAN NOT NULL DEFAULT FALSE,
+    active BOOLEAN NOT NULL DEFAULT TRUE
+);
+
+-- Create the OrderDiscounts table holding the itemized discount breakdown of each order
+CREATE TABLE IF NOT EXISTS OrderDiscounts (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    order_id INT NOT NULL,
+    promotion_code VARCHAR(64) NOT NULL,
+    description VARCHAR(255),
+    isbn VARCHAR(255),
+    amount DECIMAL(19,4) NOT NULL,
+    currency CHAR(3) NOT NULL,
+    FOREIGN KEY (order_id) REFERENCES Orders(id),
+    INDEX (promotion_code)
+);
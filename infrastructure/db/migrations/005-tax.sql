@@ -0,0 +1,6 @@
+USE bookstore;
+
+-- Keep the tax charged on each order line and the order total so receipts and refunds can be reproduced
+ALTER TABLE Orders ADD COLUMN tax_amount DECIMAL(19,4) NOT NULL DEFAULT 0 AFTER discount_amount;
+ALTER TABLE OrderItems ADD COLUMN tax_rate DECIMAL(7,4) AFTER currency;
+ALTER TABLE OrderItems ADD COLUMN tax_amount DECIMAL(19,4) NOT NULL DEFAULT 0 AFTER tax_rate;
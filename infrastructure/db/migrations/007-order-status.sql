@@ -0,0 +1,20 @@
+USE bookstore;
+
+-- Track the lifecycle status of each order. Existing orders are treated as placed, or as
+-- shipped or delivered when their shipping status says so
+ALTER TABLE Orders ADD COLUMN status ENUM('placed', 'paid', 'shipped', 'delivered', 'cancelled', 'return_requested', 'returned', 'refunded') NOT NULL DEFAULT 'placed' AFTER order_date;
+UPDATE Orders SET status = shipping_status WHERE shipping_status IN ('shipped', 'delivered');
+
+-- Create the OrderStatusHistory table recording every status change of an order
+CREATE TABLE IF NOT EXISTS OrderStatusHistory (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    order_id INT NOT NULL,
+    from_status VARCHAR(32),
+    to_status VARCHAR(32) NOT NULL,
+    reason VARCHAR(255),
+    changed_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (order_id) REFERENCES Orders(id)
+);
+
+INSERT INTO OrderStatusHistory (order_id, from_status, to_status, reason, changed_at)
+SELECT id, NULL, status, 'status at migration', order_date FROM Orders;
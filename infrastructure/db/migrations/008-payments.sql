@@ -0,0 +1,18 @@
+USE bookstore;
+
+-- Create the Payments table recording every call made to the payment provider for an order.
+-- The idempotency key is unique so a retried operation updates its attempt instead of adding one
+CREATE TABLE IF NOT EXISTS Payments (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    order_id INT NOT NULL,
+    provider VARCHAR(32) NOT NULL,
+    operation ENUM('authorize', 'capture', 'void', 'refund') NOT NULL,
+    idempotency_key VARCHAR(64) NOT NULL UNIQUE,
+    transaction_id VARCHAR(128),
+    amount DECIMAL(19, 4) NOT NULL,
+    currency CHAR(3) NOT NULL,
+    status ENUM('succeeded', 'declined', 'failed') NOT NULL,
+    decline_reason VARCHAR(255),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (order_id) REFERENCES Orders(id)
+);
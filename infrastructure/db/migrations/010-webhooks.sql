@@ -0,0 +1,43 @@
+USE bookstore;
+
+-- Create the WebhookSubscriptions table of partner endpoints receiving domain events
+CREATE TABLE IF NOT EXISTS WebhookSubscriptions (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    url VARCHAR(2048) NOT NULL,
+    event_types JSON NOT NULL,
+    secret VARCHAR(255) NOT NULL,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create the WebhookDeliveries table queuing each event for each subscription. The payload is
+-- kept verbatim so redeliveries are signed over the same bytes; times are UTC
+CREATE TABLE IF NOT EXISTS WebhookDeliveries (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    subscription_id INT NOT NULL,
+    event_id BIGINT NOT NULL,
+    event_type VARCHAR(64) NOT NULL,
+    payload MEDIUMTEXT NOT NULL,
+    status ENUM('pending', 'succeeded', 'dead') NOT NULL DEFAULT 'pending',
+    attempts INT NOT NULL DEFAULT 0,
+    next_attempt_at DATETIME(3) NOT NULL,
+    last_status_code INT,
+    last_error VARCHAR(1024),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    delivered_at DATETIME(3),
+    UNIQUE KEY uq_webhook_delivery_event (subscription_id, event_id),
+    INDEX idx_webhook_delivery_due (status, next_attempt_at),
+    FOREIGN KEY (subscription_id) REFERENCES WebhookSubscriptions(id) ON DELETE CASCADE
+);
+
+-- Create the WebhookDeliveryAttempts table logging every request made for a delivery
+CREATE TABLE IF NOT EXISTS WebhookDeliveryAttempts (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    delivery_id BIGINT NOT NULL,
+    attempt INT NOT NULL,
+    status_code INT,
+    error VARCHAR(1024),
+    duration_ms BIGINT NOT NULL,
+    attempted_at DATETIME(3) NOT NULL,
+    FOREIGN KEY (delivery_id) REFERENCES WebhookDeliveries(id) ON DELETE CASCADE
+);
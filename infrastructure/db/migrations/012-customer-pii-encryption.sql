@@ -0,0 +1,17 @@
+USE bookstore;
+
+-- Make room for encrypted personal data and add the envelope and blind index columns. Existing
+-- customers stay in plaintext until the reencrypt-customers command is run
+ALTER TABLE Customer
+    MODIFY phone_number VARCHAR(512),
+    MODIFY dob VARCHAR(512) NOT NULL,
+    MODIFY unit_no VARCHAR(512),
+    MODIFY street_name VARCHAR(512),
+    MODIFY city VARCHAR(512),
+    MODIFY state VARCHAR(512),
+    MODIFY zipcode VARCHAR(512),
+    MODIFY landmark VARCHAR(512),
+    ADD COLUMN pii_key_id VARCHAR(64),
+    ADD COLUMN pii_data_key VARCHAR(128),
+    ADD COLUMN phone_number_bidx CHAR(64),
+    ADD INDEX idx_customer_phone_bidx (phone_number_bidx);
@@ -0,0 +1,37 @@
+USE bookstore;
+
+-- Give customers a surrogate id and make Orders and Carts refer to it instead of the email, so the
+-- email can change. The foreign key names are the ones MySQL generated for the original schema
+-- scripts; check them with SHOW CREATE TABLE if the tables were created differently
+ALTER TABLE Orders DROP FOREIGN KEY Orders_ibfk_1;
+ALTER TABLE Carts DROP FOREIGN KEY Carts_ibfk_1;
+
+ALTER TABLE Customer
+    DROP PRIMARY KEY,
+    ADD COLUMN id BIGINT AUTO_INCREMENT PRIMARY KEY FIRST,
+    MODIFY email VARCHAR(255) NOT NULL,
+    ADD UNIQUE KEY (email);
+
+ALTER TABLE Orders ADD COLUMN customer_id BIGINT AFTER id;
+UPDATE Orders o JOIN Customer c ON c.email = o.customer_email SET o.customer_id = c.id;
+ALTER TABLE Orders
+    DROP COLUMN customer_email,
+    ADD FOREIGN KEY (customer_id) REFERENCES Customer(id);
+
+ALTER TABLE Carts ADD COLUMN customer_id BIGINT UNIQUE AFTER id;
+UPDATE Carts t JOIN Customer c ON c.email = t.customer_email SET t.customer_id = c.id;
+ALTER TABLE Carts
+    DROP COLUMN customer_email,
+    ADD FOREIGN KEY (customer_id) REFERENCES Customer(id) ON DELETE CASCADE;
+
+-- Create the CustomerEmailChanges table of requested and confirmed email changes
+CREATE TABLE IF NOT EXISTS CustomerEmailChanges (
+    token_hash CHAR(64) PRIMARY KEY,
+    customer_id BIGINT NOT NULL,
+    old_email VARCHAR(255) NOT NULL,
+    new_email VARCHAR(255) NOT NULL,
+    requested_at DATETIME NOT NULL,
+    expires_at DATETIME NOT NULL,
+    confirmed_at DATETIME,
+    FOREIGN KEY (customer_id) REFERENCES Customer(id) ON DELETE CASCADE
+);
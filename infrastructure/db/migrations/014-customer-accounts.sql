@@ -0,0 +1,9 @@
+USE bookstore;
+
+-- Registered customers stay Pending until they verify their email. Passwords are stored as
+-- PBKDF2 hashes; times are UTC. Existing customers are left unverified
+ALTER TABLE Customer
+    MODIFY status ENUM('Pending', 'Active', 'Inactive') DEFAULT 'Active',
+    ADD COLUMN email_verified_at DATETIME AFTER languages,
+    ADD COLUMN password_hash VARCHAR(255) AFTER email_verified_at,
+    ADD COLUMN password_changed_at DATETIME AFTER password_hash;
@@ -0,0 +1,52 @@
+USE bookstore;
+
+-- Move the inline address of customers into an address book, where it becomes their default
+-- shipping and billing address labelled Home. Encrypted addresses keep their ciphertext and reuse
+-- the customer's wrapped data key, which stays valid because field values are only bound to their
+-- field name
+CREATE TABLE IF NOT EXISTS CustomerAddresses (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    customer_id BIGINT NOT NULL,
+    label VARCHAR(64) NOT NULL,
+    unit_no VARCHAR(512),
+    street_name VARCHAR(512),
+    city VARCHAR(512),
+    state VARCHAR(512),
+    country VARCHAR(255),
+    zipcode VARCHAR(512),
+    landmark VARCHAR(512),
+    is_default_shipping BOOLEAN NOT NULL DEFAULT FALSE,
+    is_default_billing BOOLEAN NOT NULL DEFAULT FALSE,
+    pii_key_id VARCHAR(64),
+    pii_data_key VARCHAR(128),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    default_shipping_of BIGINT AS (IF(is_default_shipping, customer_id, NULL)),
+    default_billing_of BIGINT AS (IF(is_default_billing, customer_id, NULL)),
+    UNIQUE KEY (default_shipping_of),
+    UNIQUE KEY (default_billing_of),
+    FOREIGN KEY (customer_id) REFERENCES Customer(id) ON DELETE CASCADE
+);
+
+INSERT INTO CustomerAddresses (customer_id, label, unit_no, street_name, city, state, country, zipcode, landmark,
+    is_default_shipping, is_default_billing, pii_key_id, pii_data_key)
+SELECT id, 'Home', unit_no, street_name, city, state, country, zipcode, landmark, TRUE, TRUE,
+    pii_key_id, pii_data_key
+FROM Customer
+WHERE NULLIF(CONCAT_WS('', unit_no, street_name, city, state, country, zipcode, landmark), '') IS NOT NULL;
+
+ALTER TABLE Customer
+    DROP COLUMN unit_no,
+    DROP COLUMN street_name,
+    DROP COLUMN city,
+    DROP COLUMN state,
+    DROP COLUMN country,
+    DROP COLUMN zipcode,
+    DROP COLUMN landmark;
+
+-- Orders refer to the addresses they were placed with. Existing orders only keep their snapshot
+ALTER TABLE Orders
+    ADD COLUMN shipping_address_id BIGINT AFTER shipping_method,
+    ADD COLUMN billing_address_id BIGINT AFTER shipping_address_id,
+    ADD FOREIGN KEY (shipping_address_id) REFERENCES CustomerAddresses(id) ON DELETE SET NULL,
+    ADD FOREIGN KEY (billing_address_id) REFERENCES CustomerAddresses(id) ON DELETE SET NULL;
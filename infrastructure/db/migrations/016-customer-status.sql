@@ -0,0 +1,16 @@
+USE bookstore;
+
+-- Customers can be suspended and closed by staff, who give a reason for each change
+ALTER TABLE Customer MODIFY COLUMN status ENUM('Pending', 'Active', 'Inactive', 'Suspended', 'Closed') DEFAULT 'Active';
+
+-- Create the CustomerStatusHistory table recording why customers were suspended, reactivated or
+-- closed. The reasons are written by staff, so they go when the customer is erased
+CREATE TABLE IF NOT EXISTS CustomerStatusHistory (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    customer_id BIGINT NOT NULL,
+    from_status VARCHAR(32) NOT NULL,
+    to_status VARCHAR(32) NOT NULL,
+    reason VARCHAR(255) NOT NULL,
+    changed_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (customer_id) REFERENCES Customer(id) ON DELETE CASCADE
+);
@@ -0,0 +1,18 @@
+USE bookstore;
+
+-- Create the IdempotencyKeys table holding the first response to each POST sent with an
+-- Idempotency-Key, so retries are answered from it by whichever server instance they reach. Keys
+-- are stored hashed; a row is reserved (completed = FALSE) while the first request runs and is
+-- deleted once expires_at, in UTC, has passed
+CREATE TABLE IF NOT EXISTS IdempotencyKeys (
+    key_hash CHAR(64) PRIMARY KEY,
+    fingerprint CHAR(64) NOT NULL,
+    completed BOOLEAN NOT NULL DEFAULT FALSE,
+    code INT,
+    content_type VARCHAR(255),
+    location VARCHAR(2048),
+    body MEDIUMBLOB,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    expires_at DATETIME(3) NOT NULL,
+    INDEX idx_idempotency_keys_expires (expires_at)
+);
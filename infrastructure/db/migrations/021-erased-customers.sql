@@ -0,0 +1,11 @@
+USE bookstore;
+
+-- The placeholder customers keeping the orders of erased customers are Erased, which leaves them
+-- out of customer listings and segments
+ALTER TABLE Customer MODIFY COLUMN status ENUM('Pending', 'Active', 'Inactive', 'Suspended', 'Closed', 'Erased') DEFAULT 'Active';
+
+UPDATE Customer SET status = 'Erased' WHERE email IN (SELECT pseudonym FROM CustomerErasures);
+
+-- Receipts now keep a hash of the email keyed with the PII blind-index key. The plain SHA-256
+-- hashes kept before could be matched against any list of emails, so they are dropped
+UPDATE CustomerErasures SET email_hash = '';
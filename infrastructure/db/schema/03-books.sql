@@ -9,5 +9,7 @@ CREATE TABLE IF NOT EXISTS Books (
     date_of_publish DATE NOT NULL,
     publishing_house JSON,
     number_of_pages INT,
-    cost FLOAT
+    cost DECIMAL(19,4),
+    currency CHAR(3) NOT NULL DEFAULT 'USD',
+    stock INT NOT NULL DEFAULT 0
 );
@@ -0,0 +1,55 @@
+USE bookstore;
+
+-- Create the Customer table. Personal data columns hold ciphertext when PII keys are configured:
+-- pii_key_id names the key wrapping the record's data key in pii_data_key, and phone_number_bidx
+-- is a keyed hash of the phone number for lookups. Other tables refer to customers by id, so the
+-- email can change. Addresses are kept in CustomerAddresses
+CREATE TABLE IF NOT EXISTS Customer (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    email VARCHAR(255) NOT NULL UNIQUE,
+    first_name VARCHAR(255) NOT NULL,
+    middle_name VARCHAR(255),
+    last_name VARCHAR(255) NOT NULL,
+    phone_number VARCHAR(512),
+    dob VARCHAR(512) NOT NULL,
+    registration_date TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    last_login TIMESTAMP,
+    status ENUM('Pending', 'Active', 'Inactive', 'Suspended', 'Closed', 'Erased') DEFAULT 'Active',
+    notes TEXT,
+    languages JSON,
+    email_verified_at DATETIME,
+    password_hash VARCHAR(255),
+    password_changed_at DATETIME,
+    pii_key_id VARCHAR(64),
+    pii_data_key VARCHAR(128),
+    phone_number_bidx CHAR(64),
+    INDEX idx_customer_phone_bidx (phone_number_bidx)
+);
+
+-- Create the CustomerAddresses table, the address book of a customer. A customer has at most one
+-- default shipping and one default billing address, enforced through the generated columns. The
+-- address columns are encrypted like those of Customer, each address under its own data key;
+-- country stays in plaintext
+CREATE TABLE IF NOT EXISTS CustomerAddresses (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    customer_id BIGINT NOT NULL,
+    label VARCHAR(64) NOT NULL,
+    unit_no VARCHAR(512),
+    street_name VARCHAR(512),
+    city VARCHAR(512),
+    state VARCHAR(512),
+    country VARCHAR(255),
+    zipcode VARCHAR(512),
+    landmark VARCHAR(512),
+    is_default_shipping BOOLEAN NOT NULL DEFAULT FALSE,
+    is_default_billing BOOLEAN NOT NULL DEFAULT FALSE,
+    pii_key_id VARCHAR(64),
+    pii_data_key VARCHAR(128),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    default_shipping_of BIGINT AS (IF(is_default_shipping, customer_id, NULL)),
+    default_billing_of BIGINT AS (IF(is_default_billing, customer_id, NULL)),
+    UNIQUE KEY (default_shipping_of),
+    UNIQUE KEY (default_billing_of),
+    FOREIGN KEY (customer_id) REFERENCES Customer(id) ON DELETE CASCADE
+);
@@ -0,0 +1,32 @@
+USE bookstore;
+
+-- Create the Orders table. The ship_ columns are a snapshot of the shipping address the order was
+-- placed with, so it outlives changes to the address book
+CREATE TABLE IF NOT EXISTS Orders (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    customer_id BIGINT,
+    order_date TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    status ENUM('placed', 'paid', 'shipped', 'delivered', 'cancelled', 'return_requested', 'returned', 'refunded') NOT NULL DEFAULT 'placed',
+    subtotal_amount DECIMAL(19,4) NOT NULL DEFAULT 0,
+    discount_amount DECIMAL(19,4) NOT NULL DEFAULT 0,
+    tax_amount DECIMAL(19,4) NOT NULL DEFAULT 0,
+    shipping_amount DECIMAL(19,4) NOT NULL DEFAULT 0,
+    total_amount DECIMAL(19,4) NOT NULL DEFAULT 0,
+    currency CHAR(3) NOT NULL DEFAULT 'USD',
+    exchange_rate DECIMAL(20,10),
+    source_currency CHAR(3),
+    shipping_method VARCHAR(64),
+    shipping_address_id BIGINT,
+    billing_address_id BIGINT,
+    ship_unit_no VARCHAR(255),
+    ship_street_name VARCHAR(255),
+    ship_city VARCHAR(255),
+    ship_state VARCHAR(255),
+    ship_country VARCHAR(255),
+    ship_zipcode VARCHAR(20),
+    ship_landmark VARCHAR(255),
+    shipping_status ENUM('pending', 'shipped', 'delivered') NOT NULL DEFAULT 'pending',
+    FOREIGN KEY (customer_id) REFERENCES Customer(id),
+    FOREIGN KEY (shipping_address_id) REFERENCES CustomerAddresses(id) ON DELETE SET NULL,
+    FOREIGN KEY (billing_address_id) REFERENCES CustomerAddresses(id) ON DELETE SET NULL
+);
\ No newline at end of file
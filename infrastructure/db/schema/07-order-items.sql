@@ -5,6 +5,10 @@ CREATE TABLE IF NOT EXISTS OrderItems (
     order_id INT,
     isbn VARCHAR(255),
     quantity INT NOT NULL,
+    unit_price DECIMAL(19,4) NOT NULL DEFAULT 0,
+    currency CHAR(3) NOT NULL DEFAULT 'USD',
+    tax_rate DECIMAL(7,4),
+    tax_amount DECIMAL(19,4) NOT NULL DEFAULT 0,
     PRIMARY KEY (order_id, isbn),
     FOREIGN KEY (order_id) REFERENCES Orders(id),
     FOREIGN KEY (isbn) REFERENCES Books(isbn)
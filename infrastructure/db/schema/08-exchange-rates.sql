@@ -0,0 +1,10 @@
+USE bookstore;
+
+-- Create the ExchangeRates table
+CREATE TABLE IF NOT EXISTS ExchangeRates (
+    base_currency CHAR(3) NOT NULL,
+    quote_currency CHAR(3) NOT NULL,
+    rate DECIMAL(20,10) NOT NULL,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (base_currency, quote_currency)
+);
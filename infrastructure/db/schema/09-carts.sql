@@ -0,0 +1,21 @@
+USE bookstore;
+
+-- Create the Carts table. Anonymous carts have no customer and are addressed by their random id
+CREATE TABLE IF NOT EXISTS Carts (
+    id VARCHAR(64) PRIMARY KEY,
+    customer_id BIGINT UNIQUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (customer_id) REFERENCES Customer(id) ON DELETE CASCADE
+);
+
+-- Create the CartItems table
+CREATE TABLE IF NOT EXISTS CartItems (
+    cart_id VARCHAR(64),
+    isbn VARCHAR(255),
+    quantity INT NOT NULL,
+    added_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (cart_id, isbn),
+    FOREIGN KEY (cart_id) REFERENCES Carts(id) ON DELETE CASCADE,
+    FOREIGN KEY (isbn) REFERENCES Books(isbn) ON DELETE CASCADE
+);
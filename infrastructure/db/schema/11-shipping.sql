@@ -0,0 +1,25 @@
+USE bookstore;
+
+-- Create the ShippingMethods table. Rates grow with the page count of the order as a proxy for weight
+CREATE TABLE IF NOT EXISTS ShippingMethods (
+    code VARCHAR(64) PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    carrier VARCHAR(255),
+    base_rate DECIMAL(19,4) NOT NULL DEFAULT 0,
+    rate_per_unit DECIMAL(19,4) NOT NULL DEFAULT 0,
+    currency CHAR(3) NOT NULL DEFAULT 'USD',
+    pages_per_unit INT NOT NULL DEFAULT 0,
+    estimated_days INT NOT NULL DEFAULT 0,
+    active BOOLEAN NOT NULL DEFAULT TRUE
+);
+
+-- Create the Shipments table tracking the parcels handed to carriers
+CREATE TABLE IF NOT EXISTS Shipments (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    order_id INT NOT NULL,
+    carrier VARCHAR(255) NOT NULL,
+    tracking_number VARCHAR(255) NOT NULL,
+    shipped_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    delivered_at TIMESTAMP NULL,
+    FOREIGN KEY (order_id) REFERENCES Orders(id)
+);
@@ -0,0 +1,12 @@
+USE bookstore;
+
+-- Create the OrderStatusHistory table recording every status change of an order
+CREATE TABLE IF NOT EXISTS OrderStatusHistory (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    order_id INT NOT NULL,
+    from_status VARCHAR(32),
+    to_status VARCHAR(32) NOT NULL,
+    reason VARCHAR(255),
+    changed_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (order_id) REFERENCES Orders(id)
+);
@@ -0,0 +1,28 @@
+USE bookstore;
+
+-- Create the Outbox table holding domain events written in the same transaction as the change
+-- they describe. The dispatcher delivers them to the registered sinks and retries failures
+-- with backoff; times are UTC
+CREATE TABLE IF NOT EXISTS Outbox (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    event_type VARCHAR(64) NOT NULL,
+    entity_type VARCHAR(32) NOT NULL,
+    entity_id VARCHAR(255) NOT NULL,
+    payload JSON NOT NULL,
+    occurred_at DATETIME(3) NOT NULL,
+    attempts INT NOT NULL DEFAULT 0,
+    next_attempt_at DATETIME(3) NOT NULL DEFAULT CURRENT_TIMESTAMP(3),
+    last_error VARCHAR(1024),
+    dispatched_at DATETIME(3),
+    INDEX idx_outbox_pending (dispatched_at, next_attempt_at)
+);
+
+-- Create the OutboxDeliveries table recording which sinks received an event, so retries only
+-- go to the sinks that failed
+CREATE TABLE IF NOT EXISTS OutboxDeliveries (
+    event_id BIGINT NOT NULL,
+    sink VARCHAR(64) NOT NULL,
+    delivered_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (event_id, sink),
+    FOREIGN KEY (event_id) REFERENCES Outbox(id) ON DELETE CASCADE
+);
@@ -0,0 +1,15 @@
+USE bookstore;
+
+-- Create the CustomerErasures table of receipts for erased customers. It holds no personal data:
+-- the email is kept as a hash of its lower case form keyed with the PII blind-index key, empty
+-- without PII keys, and the retained orders refer to the pseudonym
+CREATE TABLE IF NOT EXISTS CustomerErasures (
+    id CHAR(32) PRIMARY KEY,
+    email_hash CHAR(64) NOT NULL,
+    pseudonym VARCHAR(255) NOT NULL,
+    reason VARCHAR(255),
+    orders_retained INT NOT NULL DEFAULT 0,
+    events_redacted INT NOT NULL DEFAULT 0,
+    erased_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_customer_erasure_email (email_hash)
+);
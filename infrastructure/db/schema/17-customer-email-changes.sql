@@ -0,0 +1,15 @@
+USE bookstore;
+
+-- Create the CustomerEmailChanges table of requested and confirmed email changes. Only the SHA-256
+-- of the token sent to the new email is stored; times are UTC. Confirmed rows keep the previous
+-- email so events recorded under it can be found
+CREATE TABLE IF NOT EXISTS CustomerEmailChanges (
+    token_hash CHAR(64) PRIMARY KEY,
+    customer_id BIGINT NOT NULL,
+    old_email VARCHAR(255) NOT NULL,
+    new_email VARCHAR(255) NOT NULL,
+    requested_at DATETIME NOT NULL,
+    expires_at DATETIME NOT NULL,
+    confirmed_at DATETIME,
+    FOREIGN KEY (customer_id) REFERENCES Customer(id) ON DELETE CASCADE
+);
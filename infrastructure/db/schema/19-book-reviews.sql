@@ -0,0 +1,22 @@
+USE bookstore;
+
+-- Create the BookReviews table, at most one review per customer and book. Reviews are shown and
+-- counted in the rating of the book once approved by a moderator; editing a review sends it back
+-- to moderation
+CREATE TABLE IF NOT EXISTS BookReviews (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    isbn VARCHAR(255) NOT NULL,
+    customer_id BIGINT NOT NULL,
+    rating TINYINT NOT NULL,
+    title VARCHAR(255),
+    text TEXT,
+    status ENUM('pending', 'approved', 'rejected') NOT NULL DEFAULT 'pending',
+    moderation_note VARCHAR(255),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    UNIQUE KEY (isbn, customer_id),
+    INDEX idx_book_reviews_status (status, isbn),
+    FOREIGN KEY (isbn) REFERENCES Books(isbn) ON DELETE CASCADE,
+    FOREIGN KEY (customer_id) REFERENCES Customer(id) ON DELETE CASCADE,
+    CHECK (rating BETWEEN 1 AND 5)
+);
@@ -0,0 +1,66 @@
+USE bookstore;
+
+-- Create the Wishlists table of named lists of books saved by customers. A list can be shared
+-- read-only through its share_token, which is NULL while the list is private
+CREATE TABLE IF NOT EXISTS Wishlists (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    customer_id BIGINT NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    share_token CHAR(43),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    UNIQUE KEY (customer_id, name),
+    UNIQUE KEY (share_token),
+    FOREIGN KEY (customer_id) REFERENCES Customer(id) ON DELETE CASCADE
+);
+
+-- Create the WishlistItems table of the books on each wishlist
+CREATE TABLE IF NOT EXISTS WishlistItems (
+    wishlist_id BIGINT NOT NULL,
+    isbn VARCHAR(255) NOT NULL,
+    added_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (wishlist_id, isbn),
+    FOREIGN KEY (wishlist_id) REFERENCES Wishlists(id) ON DELETE CASCADE,
+    FOREIGN KEY (isbn) REFERENCES Books(isbn) ON DELETE CASCADE
+);
+
+-- Create the BookAlerts table of customers waiting for a book to be back in stock or to drop to a
+-- price. An alert fires once, when the stock or price crosses its threshold, and is then inactive
+-- until the customer sets it again
+CREATE TABLE IF NOT EXISTS BookAlerts (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    customer_id BIGINT NOT NULL,
+    isbn VARCHAR(255) NOT NULL,
+    alert_type ENUM('back_in_stock', 'price_drop') NOT NULL,
+    min_stock INT,
+    target_price DECIMAL(19,4),
+    currency CHAR(3),
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    notified_at TIMESTAMP NULL,
+    UNIQUE KEY (customer_id, isbn, alert_type),
+    INDEX idx_book_alerts_isbn (isbn, alert_type, active),
+    FOREIGN KEY (customer_id) REFERENCES Customer(id) ON DELETE CASCADE,
+    FOREIGN KEY (isbn) REFERENCES Books(isbn) ON DELETE CASCADE
+);
+
+-- Create the BookNotifications table queuing the mails of fired alerts. The event that fired an
+-- alert is kept so an event delivered twice queues one notification. A server instance sending a
+-- notification claims it by moving next_attempt_at, in UTC, past the time it needs; NULL is due now
+CREATE TABLE IF NOT EXISTS BookNotifications (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    alert_id BIGINT NOT NULL,
+    event_id BIGINT NOT NULL,
+    stock INT,
+    price DECIMAL(19,4),
+    currency CHAR(3),
+    status ENUM('pending', 'sent', 'failed') NOT NULL DEFAULT 'pending',
+    attempts INT NOT NULL DEFAULT 0,
+    next_attempt_at DATETIME(3),
+    last_error VARCHAR(1024),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    sent_at TIMESTAMP NULL,
+    UNIQUE KEY (alert_id, event_id),
+    INDEX idx_book_notifications_status (status, id),
+    FOREIGN KEY (alert_id) REFERENCES BookAlerts(id) ON DELETE CASCADE
+);
@@ -0,0 +1,17 @@
+USE bookstore;
+
+-- Create the BookRecommendations table of the books recommended with each book, best first. It is
+-- rebuilt as a whole by the compute-recommendations job: neighbours are the books bought by the
+-- same customers, topped up with books sharing an author and then tags
+CREATE TABLE IF NOT EXISTS BookRecommendations (
+    isbn VARCHAR(255) NOT NULL,
+    recommended_isbn VARCHAR(255) NOT NULL,
+    position INT NOT NULL,
+    score DOUBLE NOT NULL,
+    reason ENUM('bought_together', 'same_author', 'similar_tags') NOT NULL,
+    computed_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (isbn, recommended_isbn),
+    INDEX idx_book_recommendations_position (isbn, position),
+    FOREIGN KEY (isbn) REFERENCES Books(isbn) ON DELETE CASCADE,
+    FOREIGN KEY (recommended_isbn) REFERENCES Books(isbn) ON DELETE CASCADE
+);
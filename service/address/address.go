@@ -0,0 +1,188 @@
+// Package address validates postal addresses and brings them into a canonical form, so addresses
+// of customers and authors can be compared, taxed and shipped to consistently.
+package address
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/mayureshucsb2019/bookstore/service/common"
+)
+
+// Codes of the field errors reported by Normalize
+const (
+	CodeRequired      = "required"
+	CodeInvalidFormat = "invalid_format"
+	CodeUnknownValue  = "unknown_value"
+)
+
+// Address is a postal address as entered by a user.
+type Address struct {
+	Unit       string
+	StreetName string
+	City       string
+	State      string
+	Country    string // ISO 3166-1 alpha-2 code once normalized
+	Zipcode    string
+	Landmark   string
+}
+
+// IsZero reports whether none of the fields of the address is set.
+func (a Address) IsZero() bool {
+	return a == Address{}
+}
+
+// Normalize validates an address and returns it in canonical form:
+//   - whitespace is collapsed, and names typed all in upper or lower case are title-cased
+//   - the country becomes its ISO 3166-1 alpha-2 code; common names of the countries with
+//     rules are accepted too
+//   - street types and unit designators are abbreviated, e.g. "Main Street" becomes "Main St"
+//   - for countries with rules (see rules), the state becomes its postal code, the postal code
+//     is checked and formatted, and a street address needs a city, state and postal code
+//
+// An empty address is valid. Invalid fields are reported together in a *common.ValidationError,
+// named by their JSON names after prefix, e.g. "address.".
+func Normalize(a Address, prefix string) (Address, error) {
+	a = Address{
+		Unit:       normalizeUnit(clean(a.Unit)),
+		StreetName: normalizeStreet(titleCase(clean(a.StreetName))),
+		City:       titleCase(clean(a.City)),
+		State:      clean(a.State),
+		Country:    clean(a.Country),
+		Zipcode:    clean(a.Zipcode),
+		Landmark:   titleCase(clean(a.Landmark)),
+	}
+	if a.IsZero() {
+		return a, nil
+	}
+
+	var errs []common.FieldError
+	report := func(field string, code string, message string) {
+		errs = append(errs, common.FieldError{Field: prefix + field, Code: code, Message: message})
+	}
+
+	country, ok := CountryCode(a.Country)
+	switch {
+	case a.Country == "":
+		report("country", CodeRequired, "is required with an address")
+	case !ok:
+		report("country", CodeUnknownValue, "must be an ISO 3166-1 alpha-2 country code, e.g. US")
+	default:
+		a.Country = country
+	}
+
+	if rule, ok := rules[a.Country]; ok {
+		if a.State != "" {
+			if code, ok := rule.subdivision(a.State); ok {
+				a.State = code
+			} else {
+				report("state", CodeUnknownValue, "must be a "+rule.subdivisionName+" of "+rule.name+", e.g. "+rule.subdivisionExample)
+			}
+		}
+		if a.Zipcode != "" {
+			if zipcode, ok := rule.postalCode(a.Zipcode); ok {
+				a.Zipcode = zipcode
+			} else {
+				report("zipcode", CodeInvalidFormat, "must be "+rule.postalCodeFormat)
+			}
+		}
+		// A street address is one that can be mailed to, which needs all of these
+		if a.StreetName != "" {
+			if a.City == "" {
+				report("city", CodeRequired, "is required with a street address in "+rule.name)
+			}
+			if a.State == "" {
+				report("state", CodeRequired, "is required with a street address in "+rule.name)
+			}
+			if a.Zipcode == "" {
+				report("zipcode", CodeRequired, "is required with a street address in "+rule.name)
+			}
+		}
+	} else {
+		a.Zipcode = strings.ToUpper(a.Zipcode)
+	}
+
+	if len(errs) > 0 {
+		return a, &common.ValidationError{Errors: errs}
+	}
+	return a, nil
+}
+
+// clean trims a value and collapses runs of whitespace into single spaces.
+func clean(value string) string {
+	return strings.Join(strings.Fields(value), " ")
+}
+
+// key reduces a name to what lookups compare: upper case without dots, so "N.Y." matches "NY".
+func key(value string) string {
+	return strings.ToUpper(strings.ReplaceAll(value, ".", ""))
+}
+
+// titleCase capitalizes the words of a value typed all in upper or all in lower case. Values in
+// mixed case are taken as intended, so names like "McAllen" are kept.
+func titleCase(value string) string {
+	if value != strings.ToUpper(value) && value != strings.ToLower(value) {
+		return value
+	}
+	runes := []rune(strings.ToLower(value))
+	for i, r := range runes {
+		// Words start after a space, hyphen, apostrophe or slash; "42nd" stays as it is
+		if i == 0 || strings.ContainsRune(" -'/", runes[i-1]) {
+			runes[i] = unicode.ToUpper(r)
+		}
+	}
+	return string(runes)
+}
+
+// streetTypes maps the spellings of street types to their abbreviation.
+var streetTypes = map[string]string{
+	"STREET": "St", "STR": "St", "ST": "St",
+	"AVENUE": "Ave", "AVE": "Ave", "AV": "Ave",
+	"ROAD": "Rd", "RD": "Rd",
+	"BOULEVARD": "Blvd", "BLVD": "Blvd",
+	"DRIVE": "Dr", "DR": "Dr",
+	"LANE": "Ln", "LN": "Ln",
+	"COURT": "Ct", "CT": "Ct",
+	"PLACE": "Pl", "PL": "Pl",
+	"TERRACE": "Ter", "TER": "Ter",
+	"HIGHWAY": "Hwy", "HWY": "Hwy",
+	"PARKWAY": "Pkwy", "PKWY": "Pkwy",
+	"CIRCLE": "Cir", "CIR": "Cir",
+	"SQUARE": "Sq", "SQ": "Sq",
+}
+
+// normalizeStreet abbreviates the street type at the end of a street name. Only the last word is
+// looked at, so "St John's Road" keeps its saint.
+func normalizeStreet(street string) string {
+	words := strings.Fields(street)
+	if len(words) < 2 {
+		return street
+	}
+	if abbreviation, ok := streetTypes[key(words[len(words)-1])]; ok {
+		words[len(words)-1] = abbreviation
+	}
+	return strings.Join(words, " ")
+}
+
+// unitDesignators maps the spellings of unit designators to their abbreviation.
+var unitDesignators = map[string]string{
+	"APARTMENT": "Apt", "APT": "Apt",
+	"SUITE": "Ste", "STE": "Ste",
+	"UNIT": "Unit", "FLOOR": "Fl", "FL": "Fl",
+	"ROOM": "Rm", "RM": "Rm",
+	"BUILDING": "Bldg", "BLDG": "Bldg",
+}
+
+// normalizeUnit abbreviates the designator a unit starts with and upper-cases the rest, so
+// "apartment 4b" becomes "Apt 4B".
+func normalizeUnit(unit string) string {
+	words := strings.Fields(unit)
+	if len(words) < 2 {
+		return strings.ToUpper(unit)
+	}
+	designator, ok := unitDesignators[key(words[0])]
+	if !ok {
+		return unit
+	}
+	return designator + " " + strings.ToUpper(strings.Join(words[1:], " "))
+}
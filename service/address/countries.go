@@ -0,0 +1,156 @@
+package address
+
+import (
+	"regexp"
+	"strings"
+)
+
+// countryCodes are the ISO 3166-1 alpha-2 codes of the countries.
+var countryCodes = strings.Fields(`
+	AD AE AF AG AI AL AM AO AQ AR AS AT AU AW AX AZ BA BB BD BE BF BG BH BI BJ BL BM BN BO BQ BR BS BT
+	BV BW BY BZ CA CC CD CF CG CH CI CK CL CM CN CO CR CU CV CW CX CY CZ DE DJ DK DM DO DZ EC EE EG EH
+	ER ES ET FI FJ FK FM FO FR GA GB GD GE GF GG GH GI GL GM GN GP GQ GR GS GT GU GW GY HK HM HN HR HT
+	HU ID IE IL IM IN IO IQ IR IS IT JE JM JO JP KE KG KH KI KM KN KP KR KW KY KZ LA LB LC LI LK LR LS
+	LT LU LV LY MA MC MD ME MF MG MH MK ML MM MN MO MP MQ MR MS MT MU MV MW MX MY MZ NA NC NE NF NG NI
+	NL NO NP NR NU NZ OM PA PE PF PG PH PK PL PM PN PR PS PT PW PY QA RE RO RS RU RW SA SB SC SD SE SG
+	SH SI SJ SK SL SM SN SO SR SS ST SV SX SY SZ TC TD TF TG TH TJ TK TL TM TN TO TR TT TV TW TZ UA UG
+	UM US UY UZ VA VC VE VG VI VN VU WF WS YE YT ZA ZM ZW`)
+
+// countryAliases maps other names in common use to country codes, keyed by key.
+var countryAliases = map[string]string{
+	"USA": "US", "UNITED STATES": "US", "UNITED STATES OF AMERICA": "US",
+	"CAN": "CA", "CANADA": "CA",
+	"IND": "IN", "INDIA": "IN", "BHARAT": "IN",
+	"UK": "GB", "GBR": "GB", "UNITED KINGDOM": "GB", "GREAT BRITAIN": "GB",
+}
+
+// CountryCode returns the ISO 3166-1 alpha-2 code of a country given by code or by one of
+// countryAliases, such as USA or United Kingdom.
+func CountryCode(country string) (string, bool) {
+	k := key(country)
+	if code, ok := countryAliases[k]; ok {
+		return code, true
+	}
+	for _, code := range countryCodes {
+		if code == k {
+			return code, true
+		}
+	}
+	return "", false
+}
+
+// rule holds the address conventions of a country.
+type rule struct {
+	name               string
+	subdivisionName    string            // What the country calls its states
+	subdivisionExample string            // Shown in errors
+	subdivisions       map[string]string // Postal abbreviation to name
+	aliases            map[string]string // Former or informal abbreviations to postal abbreviation
+	postalCodeFormat   string            // Shown in errors
+	postalPattern      *regexp.Regexp    // Matched against the postal code without spaces and hyphens
+	formatPostalCode   func(compact string) string
+}
+
+// subdivision returns the postal abbreviation of a state given by abbreviation or name.
+func (r *rule) subdivision(state string) (string, bool) {
+	k := key(state)
+	if _, ok := r.subdivisions[k]; ok {
+		return k, true
+	}
+	if code, ok := r.aliases[k]; ok {
+		return code, true
+	}
+	for code, name := range r.subdivisions {
+		if key(name) == k {
+			return code, true
+		}
+	}
+	return "", false
+}
+
+// postalCode checks a postal code and returns it in its canonical format.
+func (r *rule) postalCode(zipcode string) (string, bool) {
+	compact := strings.ToUpper(strings.NewReplacer(" ", "", "-", "").Replace(zipcode))
+	if !r.postalPattern.MatchString(compact) {
+		return "", false
+	}
+	return r.formatPostalCode(compact), true
+}
+
+// rules are the countries whose states and postal codes are checked.
+var rules = map[string]*rule{
+	"US": {
+		name:               "the United States",
+		subdivisionName:    "state or territory",
+		subdivisionExample: "NY",
+		subdivisions: map[string]string{
+			"AL": "Alabama", "AK": "Alaska", "AZ": "Arizona", "AR": "Arkansas", "CA": "California",
+			"CO": "Colorado", "CT": "Connecticut", "DE": "Delaware", "DC": "District of Columbia",
+			"FL": "Florida", "GA": "Georgia", "HI": "Hawaii", "ID": "Idaho", "IL": "Illinois",
+			"IN": "Indiana", "IA": "Iowa", "KS": "Kansas", "KY": "Kentucky", "LA": "Louisiana",
+			"ME": "Maine", "MD": "Maryland", "MA": "Massachusetts", "MI": "Michigan", "MN": "Minnesota",
+			"MS": "Mississippi", "MO": "Missouri", "MT": "Montana", "NE": "Nebraska", "NV": "Nevada",
+			"NH": "New Hampshire", "NJ": "New Jersey", "NM": "New Mexico", "NY": "New York",
+			"NC": "North Carolina", "ND": "North Dakota", "OH": "Ohio", "OK": "Oklahoma", "OR": "Oregon",
+			"PA": "Pennsylvania", "RI": "Rhode Island", "SC": "South Carolina", "SD": "South Dakota",
+			"TN": "Tennessee", "TX": "Texas", "UT": "Utah", "VT": "Vermont", "VA": "Virginia",
+			"WA": "Washington", "WV": "West Virginia", "WI": "Wisconsin", "WY": "Wyoming",
+			"AS": "American Samoa", "GU": "Guam", "MP": "Northern Mariana Islands", "PR": "Puerto Rico",
+			"VI": "U.S. Virgin Islands", "UM": "U.S. Minor Outlying Islands",
+			"AA": "Armed Forces Americas", "AE": "Armed Forces Europe", "AP": "Armed Forces Pacific",
+		},
+		aliases:          map[string]string{"WASHINGTON DC": "DC", "VIRGIN ISLANDS": "VI"},
+		postalCodeFormat: "a 5-digit ZIP code or ZIP+4, e.g. 10001 or 10001-1234",
+		postalPattern:    regexp.MustCompile(`^([0-9]{5}|[0-9]{9})$`),
+		formatPostalCode: func(compact string) string {
+			if len(compact) == 9 {
+				return compact[:5] + "-" + compact[5:]
+			}
+			return compact
+		},
+	},
+	"CA": {
+		name:               "Canada",
+		subdivisionName:    "province or territory",
+		subdivisionExample: "ON",
+		subdivisions: map[string]string{
+			"AB": "Alberta", "BC": "British Columbia", "MB": "Manitoba", "NB": "New Brunswick",
+			"NL": "Newfoundland and Labrador", "NS": "Nova Scotia", "NT": "Northwest Territories",
+			"NU": "Nunavut", "ON": "Ontario", "PE": "Prince Edward Island", "QC": "Quebec",
+			"SK": "Saskatchewan", "YT": "Yukon",
+		},
+		aliases:          map[string]string{"QUÉBEC": "QC", "PQ": "QC", "NEWFOUNDLAND": "NL", "NF": "NL", "YUKON TERRITORY": "YT", "PEI": "PE"},
+		postalCodeFormat: "a postal code like K1A 0B1",
+		// D, F, I, O, Q and U are never used, and W and Z do not start a postal code
+		postalPattern: regexp.MustCompile(`^[ABCEGHJ-NPRSTVXY][0-9][ABCEGHJ-NPRSTV-Z][0-9][ABCEGHJ-NPRSTV-Z][0-9]$`),
+		formatPostalCode: func(compact string) string {
+			return compact[:3] + " " + compact[3:]
+		},
+	},
+	"IN": {
+		name:               "India",
+		subdivisionName:    "state or union territory",
+		subdivisionExample: "MH",
+		subdivisions: map[string]string{
+			"AN": "Andaman and Nicobar Islands", "AP": "Andhra Pradesh", "AR": "Arunachal Pradesh",
+			"AS": "Assam", "BR": "Bihar", "CH": "Chandigarh", "CG": "Chhattisgarh",
+			"DH": "Dadra and Nagar Haveli and Daman and Diu", "DL": "Delhi", "GA": "Goa", "GJ": "Gujarat",
+			"HR": "Haryana", "HP": "Himachal Pradesh", "JK": "Jammu and Kashmir", "JH": "Jharkhand",
+			"KA": "Karnataka", "KL": "Kerala", "LA": "Ladakh", "LD": "Lakshadweep", "MP": "Madhya Pradesh",
+			"MH": "Maharashtra", "MN": "Manipur", "ML": "Meghalaya", "MZ": "Mizoram", "NL": "Nagaland",
+			"OD": "Odisha", "PY": "Puducherry", "PB": "Punjab", "RJ": "Rajasthan", "SK": "Sikkim",
+			"TN": "Tamil Nadu", "TS": "Telangana", "TR": "Tripura", "UP": "Uttar Pradesh",
+			"UK": "Uttarakhand", "WB": "West Bengal",
+		},
+		// Codes ISO 3166-2 used before 2023, and names in common use
+		aliases: map[string]string{
+			"CT": "CG", "TG": "TS", "UT": "UK", "OR": "OD", "DD": "DH", "DN": "DH",
+			"ORISSA": "OD", "PONDICHERRY": "PY", "NEW DELHI": "DL", "NCT OF DELHI": "DL", "UTTARANCHAL": "UK",
+		},
+		postalCodeFormat: "a 6-digit PIN code, e.g. 110001",
+		postalPattern:    regexp.MustCompile(`^[1-9][0-9]{5}$`),
+		formatPostalCode: func(compact string) string {
+			return compact
+		},
+	},
+}
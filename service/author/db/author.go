@@ -6,6 +6,7 @@ import (
 	"fmt"
 
 	_ "github.com/go-sql-driver/mysql"
+	event_db "github.com/mayureshucsb2019/bookstore/service/event/db"
 )
 
 // Author represents the structure of an Author record in the database.
@@ -42,8 +43,35 @@ type AuthorRepository struct {
 	DB *sql.DB
 }
 
-// CreateAuthor inserts a new Author into the database.
+// CreateAuthor inserts a new Author into the database and publishes an AuthorCreated event.
 func (r *AuthorRepository) CreateAuthor(author *Author) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := createAuthor(tx, author); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// EnsureAuthor creates author inside tx unless an author with its ID exists, which is left as it
+// is. It reports whether the author was created.
+func EnsureAuthor(tx *sql.Tx, author *Author) (bool, error) {
+	exists, err := lockAuthor(tx, author.ID)
+	if err != nil || exists {
+		return false, err
+	}
+	if err := createAuthor(tx, author); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// createAuthor inserts an Author and records its AuthorCreated event inside tx.
+func createAuthor(tx *sql.Tx, author *Author) error {
 	// Prepare the SQL query for inserting a new Author
 	query := `
 		INSERT INTO Authors (
@@ -61,7 +89,7 @@ func (r *AuthorRepository) CreateAuthor(author *Author) error {
 	}
 
 	// Execute the query with the provided author data
-	_, err = r.DB.Exec(query,
+	_, err = tx.Exec(query,
 		author.ID,
 		author.FirstName,
 		author.MiddleName,
@@ -76,8 +104,11 @@ func (r *AuthorRepository) CreateAuthor(author *Author) error {
 		author.Landmark,
 		languagesJSON,
 	)
+	if err != nil {
+		return err
+	}
 
-	return err
+	return event_db.Record(tx, event_db.AuthorCreated, event_db.EntityAuthor, author.ID, authorEventPayload(author))
 }
 
 // GetAuthorByID retrieves an Author by its ID from the database.
@@ -129,8 +160,71 @@ func (r *AuthorRepository) GetAuthorByID(id string) (*Author, error) {
 	return &author, nil
 }
 
-// UpdateAuthor updates an existing Author record in the database.
+// UpdateAuthor updates an existing Author record in the database and publishes an AuthorUpdated event.
 func (r *AuthorRepository) UpdateAuthor(author *Author) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	rowsAffected, err := updateAuthor(tx, author)
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("no changes were made to author with id %s", author.ID)
+	}
+	return tx.Commit()
+}
+
+// SaveAuthors creates or replaces every Author in a single transaction, publishing the same events
+// as CreateAuthor and UpdateAuthor, and reports for each Author whether it was created.
+func (r *AuthorRepository) SaveAuthors(authors []Author) ([]bool, error) {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	created := make([]bool, len(authors))
+	for i := range authors {
+		exists, err := lockAuthor(tx, authors[i].ID)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			_, err = updateAuthor(tx, &authors[i])
+		} else {
+			err = createAuthor(tx, &authors[i])
+			created[i] = true
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to save author %s: %w", authors[i].ID, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// lockAuthor reports whether an Author exists and locks it until tx ends.
+func lockAuthor(tx *sql.Tx, id string) (bool, error) {
+	var exists int
+	err := tx.QueryRow(`SELECT 1 FROM Authors WHERE id = ? FOR UPDATE`, id).Scan(&exists)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to look up author %s: %w", id, err)
+	}
+	return true, nil
+}
+
+// updateAuthor updates an Author and records its AuthorUpdated event inside tx. It returns the
+// number of rows changed; the event is only recorded when there was one.
+func updateAuthor(tx *sql.Tx, author *Author) (int64, error) {
 	// Prepare the SQL query for updating an Author record
 	query := `
 		UPDATE Authors
@@ -154,11 +248,11 @@ func (r *AuthorRepository) UpdateAuthor(author *Author) error {
 	// Marshal the Languages slice to JSON
 	languagesJSON, err := json.Marshal(author.Languages)
 	if err != nil {
-		return fmt.Errorf("failed to marshal languages: %w", err)
+		return 0, fmt.Errorf("failed to marshal languages: %w", err)
 	}
 
 	// Execute the query
-	result, err := r.DB.Exec(
+	result, err := tx.Exec(
 		query,
 		author.FirstName,
 		author.MiddleName,
@@ -176,29 +270,34 @@ func (r *AuthorRepository) UpdateAuthor(author *Author) error {
 	)
 
 	if err != nil {
-		return fmt.Errorf("failed to update author: %w", err)
+		return 0, fmt.Errorf("failed to update author: %w", err)
 	}
 
 	// Check if the update affected any rows
 	rowsAffected, err := result.RowsAffected()
 	if err != nil {
-		return fmt.Errorf("failed to check rows affected: %w", err)
+		return 0, fmt.Errorf("failed to check rows affected: %w", err)
 	}
-
 	if rowsAffected == 0 {
-		return fmt.Errorf("no changes were made to author with id %s", author.ID)
+		return 0, nil
 	}
 
-	return nil
+	return rowsAffected, event_db.Record(tx, event_db.AuthorUpdated, event_db.EntityAuthor, author.ID, authorEventPayload(author))
 }
 
-// DeleteAuthor removes an Author from the database by its ID.
+// DeleteAuthor removes an Author from the database by its ID and publishes an AuthorDeleted event.
 func (r *AuthorRepository) DeleteAuthor(id string) error {
 	// Prepare the SQL query for deleting an Author record
 	query := `DELETE FROM Authors WHERE id = ?`
 
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
 	// Execute the query
-	result, err := r.DB.Exec(query, id)
+	result, err := tx.Exec(query, id)
 	if err != nil {
 		return fmt.Errorf("failed to delete author: %w", err)
 	}
@@ -213,24 +312,39 @@ func (r *AuthorRepository) DeleteAuthor(id string) error {
 		return fmt.Errorf("no author found with id %s", id)
 	}
 
-	return nil
+	if err := event_db.Record(tx, event_db.AuthorDeleted, event_db.EntityAuthor, id, map[string]string{"id": id}); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
 
 // GetAllAuthors retrieves all Authors from the database.
 func (r *AuthorRepository) GetAllAuthors() ([]Author, error) {
+	// Declare a slice to hold the authors
+	var authors []Author
+	err := r.ForEachAuthor(func(author *Author) error {
+		authors = append(authors, *author)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return authors, nil
+}
+
+// ForEachAuthor calls fn for every Author in ID order while reading them from the database. It
+// stops at the first error fn returns.
+func (r *AuthorRepository) ForEachAuthor(fn func(author *Author) error) error {
 	// Prepare the SQL query for selecting all Author records
-	query := `SELECT * FROM Authors`
+	query := `SELECT * FROM Authors ORDER BY id`
 
 	// Execute the query
 	rows, err := r.DB.Query(query)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query authors: %w", err)
+		return fmt.Errorf("failed to query authors: %w", err)
 	}
 	defer rows.Close()
 
-	// Declare a slice to hold the authors
-	var authors []Author
-
 	// Iterate over the rows
 	for rows.Next() {
 		var author Author
@@ -252,24 +366,35 @@ func (r *AuthorRepository) GetAllAuthors() ([]Author, error) {
 			&author.Landmark,
 			&languagesJSON,
 		); err != nil {
-			return nil, fmt.Errorf("failed to scan author: %w", err)
+			return fmt.Errorf("failed to scan author: %w", err)
 		}
 
 		// Unmarshal the languages JSON byte slice into a []string slice
 		if len(languagesJSON) > 0 {
 			if err := json.Unmarshal(languagesJSON, &author.Languages); err != nil {
-				return nil, fmt.Errorf("failed to unmarshal languages: %w", err)
+				return fmt.Errorf("failed to unmarshal languages: %w", err)
 			}
 		}
 
-		// Add the author to the slice
-		authors = append(authors, author)
+		if err := fn(&author); err != nil {
+			return err
+		}
 	}
 
 	// Check for errors during iteration
 	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating over rows: %w", err)
+		return fmt.Errorf("error iterating over rows: %w", err)
 	}
+	return nil
+}
 
-	return authors, nil
+// authorEventPayload is the representation of an author carried by author events.
+func authorEventPayload(author *Author) map[string]interface{} {
+	return map[string]interface{}{
+		"id":          author.ID,
+		"first_name":  author.FirstName,
+		"middle_name": author.MiddleName.String,
+		"last_name":   author.LastName,
+		"languages":   author.Languages,
+	}
 }
@@ -0,0 +1,56 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// GetBookAuthorIDs retrieves the IDs of the authors linked to a book in the AuthorBook table.
+func GetBookAuthorIDs(tx *sql.Tx, isbn string) ([]string, error) {
+	rows, err := tx.Query(`SELECT author_id FROM AuthorBook WHERE book_isbn = ? ORDER BY author_id`, isbn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query authors of book %s: %w", isbn, err)
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan author id: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// SetBookAuthors replaces the authors linked to a book inside tx and reports whether they changed.
+func SetBookAuthors(tx *sql.Tx, isbn string, authorIDs []string) (bool, error) {
+	current, err := GetBookAuthorIDs(tx, isbn)
+	if err != nil {
+		return false, err
+	}
+	wanted := map[string]bool{}
+	for _, id := range authorIDs {
+		wanted[id] = true
+	}
+	if len(wanted) == len(current) {
+		same := true
+		for _, id := range current {
+			same = same && wanted[id]
+		}
+		if same {
+			return false, nil
+		}
+	}
+
+	if _, err := tx.Exec(`DELETE FROM AuthorBook WHERE book_isbn = ?`, isbn); err != nil {
+		return false, fmt.Errorf("failed to unlink authors of book %s: %w", isbn, err)
+	}
+	for id := range wanted {
+		if _, err := tx.Exec(`INSERT INTO AuthorBook (author_id, book_isbn) VALUES (?, ?)`, id, isbn); err != nil {
+			return false, fmt.Errorf("failed to link author %s to book %s: %w", id, isbn, err)
+		}
+	}
+	return true, nil
+}
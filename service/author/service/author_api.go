@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"io"
 	"net/http"
 
 	"github.com/mayureshucsb2019/bookstore/service/author/models"
@@ -17,6 +18,8 @@ type DefaultAPIRouter interface {
 	AuthorsIdGet(http.ResponseWriter, *http.Request)
 	AuthorsIdPatch(http.ResponseWriter, *http.Request)
 	AuthorsPost(http.ResponseWriter, *http.Request)
+	BulkAuthorsGet(http.ResponseWriter, *http.Request)
+	BulkAuthorsPost(http.ResponseWriter, *http.Request)
 }
 
 // DefaultAPIServicer defines the api actions for the DefaultAPI service
@@ -29,4 +32,6 @@ type DefaultAPIServicer interface {
 	AuthorsIdGet(context.Context, string) (common.ImplResponse, error)
 	AuthorsIdPatch(context.Context, string, models.Author) (common.ImplResponse, error)
 	AuthorsPost(context.Context, models.Author) (common.ImplResponse, error)
+	BulkAuthorsGet(context.Context) (common.ImplResponse, error)
+	BulkAuthorsPost(context.Context, io.Reader, int32) (common.ImplResponse, error)
 }
@@ -68,6 +68,16 @@ func (c *DefaultAPIController) Routes() common.Routes {
 			Pattern:     "/authors",
 			HandlerFunc: c.AuthorsPost,
 		},
+		"BulkAuthorsGet": common.Route{
+			Method:      strings.ToUpper("Get"),
+			Pattern:     "/bulk/authors",
+			HandlerFunc: c.BulkAuthorsGet,
+		},
+		"BulkAuthorsPost": common.Route{
+			Method:      strings.ToUpper("Post"),
+			Pattern:     "/bulk/authors",
+			HandlerFunc: c.BulkAuthorsPost,
+		},
 	}
 }
 
@@ -215,3 +225,47 @@ func (c *DefaultAPIController) AuthorsPost(w http.ResponseWriter, r *http.Reques
 	// If no error, encode the body and the result code
 	_ = common.EncodeJSONResponse(result.Body, &result.Code, w)
 }
+
+// BulkAuthorsGet - Export every author as NDJSON
+func (c *DefaultAPIController) BulkAuthorsGet(w http.ResponseWriter, r *http.Request) {
+	result, err := c.service.BulkAuthorsGet(r.Context())
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	_ = common.EncodeJSONResponse(result.Body, &result.Code, w)
+}
+
+// BulkAuthorsPost - Create or replace authors from NDJSON
+func (c *DefaultAPIController) BulkAuthorsPost(w http.ResponseWriter, r *http.Request) {
+	query, err := common.ParseQuery(r.URL.RawQuery)
+	if err != nil {
+		c.errorHandler(w, r, &common.ParsingError{Err: err}, nil)
+		return
+	}
+	var batchSizeParam int32
+	if query.Has("batch_size") {
+		param, err := common.ParseNumericParameter[int32](
+			query.Get("batch_size"),
+			common.WithParse[int32](common.ParseInt32),
+			common.WithMinimum[int32](1),
+			common.WithMaximum[int32](common.MaxBulkBatchSize),
+		)
+		if err != nil {
+			c.errorHandler(w, r, &common.ParsingError{Param: "batch_size", Err: err}, nil)
+			return
+		}
+
+		batchSizeParam = param
+	}
+	result, err := c.service.BulkAuthorsPost(r.Context(), r.Body, batchSizeParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	_ = common.EncodeJSONResponse(result.Body, &result.Code, w)
+}
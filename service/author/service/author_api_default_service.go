@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"net/http"
 
+	"github.com/mayureshucsb2019/bookstore/service/address"
 	"github.com/mayureshucsb2019/bookstore/service/author/db"
 	"github.com/mayureshucsb2019/bookstore/service/author/models"
 	"github.com/mayureshucsb2019/bookstore/service/common"
@@ -17,6 +18,9 @@ import (
 // Include any external packages or services that will be required by this service.
 type DefaultAPIService struct {
 	Repo *db.AuthorRepository // Add a field to hold the repository
+
+	// Authors saved per transaction by bulk imports, common.DefaultBulkBatchSize when not set
+	BulkBatchSize int
 }
 
 // NewDefaultAPIService creates a default API service with the given repository.
@@ -75,10 +79,14 @@ func (s *DefaultAPIService) AuthorsIdPatch(ctx context.Context, id string, autho
 	if author.Id != id {
 		return common.Response(http.StatusBadRequest, nil), errors.New("id in the path does not match id in the body")
 	}
+	var err error
+	if author.Address, err = normalizeAddress(author.Address); err != nil {
+		return common.Response(http.StatusUnprocessableEntity, nil), err
+	}
 
 	// Call the repository method to update the book
 	dbAuthor := convertApiToDBAuthor(author)
-	err := s.Repo.UpdateAuthor(&dbAuthor)
+	err = s.Repo.UpdateAuthor(&dbAuthor)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return common.Response(http.StatusNotFound, nil), errors.New("book not found")
@@ -93,9 +101,13 @@ func (s *DefaultAPIService) AuthorsIdPatch(ctx context.Context, id string, autho
 func (s *DefaultAPIService) AuthorsPost(ctx context.Context, author models.Author) (common.ImplResponse, error) {
 	// TODO: Uncomment the next line to return response Response(404, {}) or use other options such as http.Ok ...
 	// return Response(404, nil),nil
+	var err error
+	if author.Address, err = normalizeAddress(author.Address); err != nil {
+		return common.Response(http.StatusUnprocessableEntity, nil), err
+	}
 	dbAuthor := convertApiToDBAuthor(author)
 
-	err := s.Repo.CreateAuthor(&dbAuthor)
+	err = s.Repo.CreateAuthor(&dbAuthor)
 	if err != nil {
 		return common.Response(http.StatusInternalServerError, nil), fmt.Errorf("failed to add author: %w", err)
 	}
@@ -103,6 +115,13 @@ func (s *DefaultAPIService) AuthorsPost(ctx context.Context, author models.Autho
 	return common.Response(http.StatusCreated, nil), nil
 }
 
+// normalizeAddress validates the address of an author and brings it into canonical form, see
+// address.Normalize.
+func normalizeAddress(authorAddress models.AuthorAddress) (models.AuthorAddress, error) {
+	normalized, err := address.Normalize(address.Address(authorAddress), "address.")
+	return models.AuthorAddress(normalized), err
+}
+
 // ConvertToDBAuthor converts an API model Author to a database model Author.
 func convertApiToDBAuthor(author models.Author) db.Author {
 	return db.Author{
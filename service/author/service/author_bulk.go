@@ -0,0 +1,60 @@
+package service
+
+import (
+	"context"
+	"io"
+	"net/http"
+
+	"github.com/mayureshucsb2019/bookstore/service/author/db"
+	"github.com/mayureshucsb2019/bookstore/service/author/models"
+	"github.com/mayureshucsb2019/bookstore/service/common"
+)
+
+// BulkAuthorsGet - Export every author as NDJSON
+func (s *DefaultAPIService) BulkAuthorsGet(ctx context.Context) (common.ImplResponse, error) {
+	export := func(encode func(v interface{}) error) error {
+		return s.Repo.ForEachAuthor(func(author *db.Author) error {
+			return encode(convertDBToAPIResponse(*author))
+		})
+	}
+	return common.Response(http.StatusOK, common.NDJSONStream(export)), nil
+}
+
+// BulkAuthorsPost - Create or replace authors from NDJSON
+func (s *DefaultAPIService) BulkAuthorsPost(ctx context.Context, body io.Reader, batchSize int32) (common.ImplResponse, error) {
+	if batchSize == 0 {
+		batchSize = int32(s.BulkBatchSize)
+	}
+	bulk := common.BulkImport[db.Author]{
+		Decode:    decodeBulkAuthor,
+		Save:      s.Repo.SaveAuthors,
+		BatchSize: int(batchSize),
+	}
+	results, err := common.SpoolStream(common.NDJSONContentType, func(w io.Writer) error {
+		_, err := bulk.Run(body, w)
+		return err
+	})
+	if err != nil {
+		return common.Response(http.StatusInternalServerError, nil), err
+	}
+	return common.Response(http.StatusOK, results), nil
+}
+
+// decodeBulkAuthor reads and validates one author of a bulk import.
+func decodeBulkAuthor(line []byte) (db.Author, string, error) {
+	var author models.Author
+	if err := common.DecodeBulkRecord(line, &author); err != nil {
+		return db.Author{}, "", err
+	}
+	if err := models.AssertAuthorRequired(author); err != nil {
+		return db.Author{}, author.Id, err
+	}
+	if err := models.AssertAuthorConstraints(author); err != nil {
+		return db.Author{}, author.Id, err
+	}
+	var err error
+	if author.Address, err = normalizeAddress(author.Address); err != nil {
+		return db.Author{}, author.Id, err
+	}
+	return convertApiToDBAuthor(author), author.Id, nil
+}
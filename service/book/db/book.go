@@ -5,8 +5,11 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
 
 	_ "github.com/go-sql-driver/mysql"
+	"github.com/mayureshucsb2019/bookstore/service/common"
+	event_db "github.com/mayureshucsb2019/bookstore/service/event/db"
 )
 
 // Book struct represents the structure of a book record in the database.
@@ -18,7 +21,9 @@ type Book struct {
 	DateOfPublish   string
 	PublishingHouse string
 	NumberOfPages   int
-	Cost            float64
+	Cost            common.Money
+	Stock           int
+	KeepStock       bool // Stock was not given: an existing book keeps its stock, a new one has none
 }
 
 // BookRepository provides access to the book storage.
@@ -26,79 +31,318 @@ type BookRepository struct {
 	DB *sql.DB
 }
 
-// CreateBook inserts a new book into the database.
+// CreateBook inserts a new book into the database and publishes a BookCreated event.
 func (r *BookRepository) CreateBook(book *Book) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := createBook(tx, book); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// createBook inserts a book and records its BookCreated event inside tx.
+func createBook(tx *sql.Tx, book *Book) error {
 	tagsJSON, err := json.Marshal(book.Tags)
 	if err != nil {
 		return err
 	}
-	query := `INSERT INTO Books (isbn, name, tags, author_name, date_of_publish, publishing_house, number_of_pages, cost) 
-              VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
-	_, err = r.DB.Exec(query, book.ISBN, book.Name, tagsJSON, book.AuthorName, book.DateOfPublish, book.PublishingHouse, book.NumberOfPages, book.Cost)
-	return err
+
+	query := `INSERT INTO Books (isbn, name, tags, author_name, date_of_publish, publishing_house, number_of_pages, cost, currency, stock) 
+              VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	_, err = tx.Exec(query, book.ISBN, book.Name, tagsJSON, book.AuthorName, book.DateOfPublish, book.PublishingHouse, book.NumberOfPages, book.Cost.Decimal(), book.Cost.Currency, book.Stock)
+	if err != nil {
+		return err
+	}
+	return event_db.Record(tx, event_db.BookCreated, event_db.EntityBook, book.ISBN, bookEventPayload(book))
 }
 
 // GetBookByISBN retrieves a book from the database by its ISBN.
 func (r *BookRepository) GetBookByISBN(isbn string) (*Book, error) {
 	query := `SELECT * FROM Books WHERE isbn = ?`
-	row := r.DB.QueryRow(query, isbn)
+	book, err := scanBook(r.DB.QueryRow(query, isbn))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil // No book found
+	}
+	return book, err
+}
 
+// scanBook reads a full Books row.
+func scanBook(row *sql.Row) (*Book, error) {
 	var book Book
 	var tags string
+	var cost sql.NullString
+	var currency string
 
-	err := row.Scan(&book.ISBN, &book.Name, &tags, &book.AuthorName, &book.DateOfPublish, &book.PublishingHouse, &book.NumberOfPages, &book.Cost)
+	err := row.Scan(&book.ISBN, &book.Name, &tags, &book.AuthorName, &book.DateOfPublish, &book.PublishingHouse, &book.NumberOfPages, &cost, &currency, &book.Stock)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return nil, nil // No book found
-		}
 		return nil, err
 	}
 
 	// Convert tags from string to slice
 	book.Tags = parseTags(tags)
+	if book.Cost, err = parseCost(cost, currency); err != nil {
+		return nil, err
+	}
 
 	return &book, nil
 }
 
-// UpdateBook updates an existing book record in the database.
+// UpdateBook updates an existing book record in the database and publishes a BookUpdated event,
+// plus BookPriceChanged and BookStockChanged events when the cost or stock changed.
 func (r *BookRepository) UpdateBook(book *Book) error {
-	tagsJSON, err := json.Marshal(book.Tags)
+	tx, err := r.DB.Begin()
 	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := updateBook(tx, book); err != nil {
 		return err
 	}
-	query := `UPDATE Books SET name=?, tags=?, author_name=?, date_of_publish=?, publishing_house=?, number_of_pages=?, cost=? WHERE isbn=?`
-	_, err = r.DB.Exec(query, book.Name, tagsJSON, book.AuthorName, book.DateOfPublish, book.PublishingHouse, book.NumberOfPages, book.Cost, book.ISBN)
-	return err
+	return tx.Commit()
+}
+
+// ImportBooks creates or replaces every book in a single transaction, publishing the same events
+// as CreateBook and UpdateBook, and returns how many books were created and updated.
+func (r *BookRepository) ImportBooks(books []Book) (created int, updated int, err error) {
+	saved, err := r.SaveBooks(books)
+	if err != nil {
+		return 0, 0, err
+	}
+	for _, isNew := range saved {
+		if isNew {
+			created++
+		} else {
+			updated++
+		}
+	}
+	return created, updated, nil
+}
+
+// SaveBooks creates or replaces every book in a single transaction, publishing the same events as
+// CreateBook and UpdateBook, and reports for each book whether it was created.
+func (r *BookRepository) SaveBooks(books []Book) ([]bool, error) {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	created := make([]bool, len(books))
+	for i := range books {
+		if created[i], err = saveBook(tx, &books[i]); err != nil {
+			return nil, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// saveBook updates a book inside tx, or creates it when it does not exist, and reports whether it
+// was created.
+func saveBook(tx *sql.Tx, book *Book) (bool, error) {
+	found, err := updateBook(tx, book)
+	if err != nil {
+		return false, fmt.Errorf("failed to update book %s: %w", book.ISBN, err)
+	}
+	if found {
+		return false, nil
+	}
+	if err := createBook(tx, book); err != nil {
+		return false, fmt.Errorf("failed to create book %s: %w", book.ISBN, err)
+	}
+	return true, nil
+}
+
+// GetBookForUpdate retrieves a book inside tx and locks it until tx ends. It returns nil if
+// there is no such book.
+func GetBookForUpdate(tx *sql.Tx, isbn string) (*Book, error) {
+	book, err := scanBook(tx.QueryRow(`SELECT * FROM Books WHERE isbn = ? FOR UPDATE`, isbn))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return book, err
+}
+
+// SaveBookMetadata creates book inside tx, or updates it when it exists. The stock of an existing
+// book is kept, since metadata feeds do not own it, and nothing is written when no other field
+// changed. It reports whether the book was created and whether anything changed.
+func SaveBookMetadata(tx *sql.Tx, book *Book) (created bool, changed bool, err error) {
+	existing, err := GetBookForUpdate(tx, book.ISBN)
+	if err != nil {
+		return false, false, err
+	}
+	if existing == nil {
+		if err := createBook(tx, book); err != nil {
+			return false, false, err
+		}
+		return true, true, nil
+	}
+
+	book.Stock = existing.Stock
+	if sameBook(existing, book) {
+		return false, false, nil
+	}
+	if _, err := updateBook(tx, book); err != nil {
+		return false, false, err
+	}
+	return false, true, nil
 }
 
-// DeleteBook removes a book from the database by its ISBN.
+// sameBook reports whether two books hold the same data.
+func sameBook(a *Book, b *Book) bool {
+	if len(a.Tags) != len(b.Tags) {
+		return false
+	}
+	for i := range a.Tags {
+		if a.Tags[i] != b.Tags[i] {
+			return false
+		}
+	}
+	return a.ISBN == b.ISBN && a.Name == b.Name && a.AuthorName == b.AuthorName && a.DateOfPublish == b.DateOfPublish &&
+		a.PublishingHouse == b.PublishingHouse && a.NumberOfPages == b.NumberOfPages && a.Cost == b.Cost && a.Stock == b.Stock
+}
+
+// updateBook updates a book and records its events inside tx. It reports whether the book exists.
+func updateBook(tx *sql.Tx, book *Book) (bool, error) {
+	tagsJSON, err := json.Marshal(book.Tags)
+	if err != nil {
+		return false, err
+	}
+
+	var oldCost sql.NullString
+	var oldCurrency string
+	var oldStock int
+	err = tx.QueryRow(`SELECT cost, currency, stock FROM Books WHERE isbn = ? FOR UPDATE`, book.ISBN).Scan(&oldCost, &oldCurrency, &oldStock)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if book.KeepStock {
+		book.Stock = oldStock
+	}
+
+	query := `UPDATE Books SET name=?, tags=?, author_name=?, date_of_publish=?, publishing_house=?, number_of_pages=?, cost=?, currency=?, stock=? WHERE isbn=?`
+	_, err = tx.Exec(query, book.Name, tagsJSON, book.AuthorName, book.DateOfPublish, book.PublishingHouse, book.NumberOfPages, book.Cost.Decimal(), book.Cost.Currency, book.Stock, book.ISBN)
+	if err != nil {
+		return true, err
+	}
+
+	if err := event_db.Record(tx, event_db.BookUpdated, event_db.EntityBook, book.ISBN, bookEventPayload(book)); err != nil {
+		return true, err
+	}
+	previous, err := parseCost(oldCost, oldCurrency)
+	if err != nil {
+		return true, err
+	}
+	if previous != book.Cost {
+		payload := event_db.PriceChanged{ISBN: book.ISBN, OldCost: previous, NewCost: book.Cost}
+		if err := event_db.Record(tx, event_db.BookPriceChanged, event_db.EntityBook, book.ISBN, payload); err != nil {
+			return true, err
+		}
+	}
+	if oldStock != book.Stock {
+		if err := event_db.RecordStockChange(tx, book.ISBN, book.Stock, book.Stock-oldStock, "stock updated"); err != nil {
+			return true, err
+		}
+	}
+	return true, nil
+}
+
+// DeleteBook removes a book from the database by its ISBN and publishes a BookDeleted event.
 func (r *BookRepository) DeleteBook(isbn string) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
 	query := `DELETE FROM Books WHERE isbn = ?`
-	_, err := r.DB.Exec(query, isbn)
-	return err
+	result, err := tx.Exec(query, isbn)
+	if err != nil {
+		return err
+	}
+	if deleted, err := result.RowsAffected(); err == nil && deleted > 0 {
+		if err := event_db.Record(tx, event_db.BookDeleted, event_db.EntityBook, isbn, map[string]string{"isbn": isbn}); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
 }
 
 // GetAllBooks retrieves all books from the database.
 func (r *BookRepository) GetAllBooks() ([]Book, error) {
-	rows, err := r.DB.Query("SELECT * FROM Books")
+	var books []Book
+	err := r.ForEachBook(func(book *Book) error {
+		books = append(books, *book)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return books, nil
+}
+
+// ForEachBook calls fn for every book in ISBN order while reading them from the database, so the
+// whole catalog never has to be held in memory. It stops at the first error fn returns.
+func (r *BookRepository) ForEachBook(fn func(book *Book) error) error {
+	rows, err := r.DB.Query("SELECT * FROM Books ORDER BY isbn")
 	if err != nil {
-		return nil, fmt.Errorf("failed to query books: %w", err)
+		return fmt.Errorf("failed to query books: %w", err)
 	}
 	defer rows.Close()
 
-	var books []Book
 	var tags string
+	var cost sql.NullString
+	var currency string
 	for rows.Next() {
 		var book Book
-		if err := rows.Scan(&book.ISBN, &book.Name, &tags, &book.AuthorName, &book.DateOfPublish, &book.PublishingHouse, &book.NumberOfPages, &book.Cost); err != nil {
-			return nil, fmt.Errorf("failed to scan book: %w", err)
+		if err := rows.Scan(&book.ISBN, &book.Name, &tags, &book.AuthorName, &book.DateOfPublish, &book.PublishingHouse, &book.NumberOfPages, &cost, &currency, &book.Stock); err != nil {
+			return fmt.Errorf("failed to scan book: %w", err)
 		}
 		// Convert tags from string to slice
 		book.Tags = parseTags(tags)
-		books = append(books, book)
+		if book.Cost, err = parseCost(cost, currency); err != nil {
+			return fmt.Errorf("failed to parse cost of book %s: %w", book.ISBN, err)
+		}
+		if err := fn(&book); err != nil {
+			return err
+		}
 	}
 
-	return books, nil
+	return rows.Err()
+}
+
+// bookEventPayload is the representation of a book carried by book events.
+func bookEventPayload(book *Book) map[string]interface{} {
+	return map[string]interface{}{
+		"isbn":             book.ISBN,
+		"name":             book.Name,
+		"tags":             book.Tags,
+		"author_name":      book.AuthorName,
+		"date_of_publish":  book.DateOfPublish,
+		"publishing_house": book.PublishingHouse,
+		"number_of_pages":  book.NumberOfPages,
+		"cost":             book.Cost,
+		"stock":            book.Stock,
+	}
+}
+
+// Helper function to convert the DECIMAL cost column into Money. A NULL cost is treated as zero.
+func parseCost(cost sql.NullString, currency string) (common.Money, error) {
+	if !cost.Valid {
+		return common.NewMoney(0, currency), nil
+	}
+	return common.ParseMoney(cost.String, currency)
 }
 
 // Helper function to parse tags from a string to a slice.
@@ -110,3 +354,38 @@ func parseTags(tagsStr string) []string {
 	}
 	return tags
 }
+
+// GetExistingISBNs returns which of isbns are already in the catalog.
+func (r *BookRepository) GetExistingISBNs(isbns []string) (map[string]bool, error) {
+	existing := map[string]bool{}
+	const batchSize = 500
+	for start := 0; start < len(isbns); start += batchSize {
+		end := start + batchSize
+		if end > len(isbns) {
+			end = len(isbns)
+		}
+		batch := isbns[start:end]
+		args := make([]interface{}, len(batch))
+		for i, isbn := range batch {
+			args[i] = isbn
+		}
+		rows, err := r.DB.Query(`SELECT isbn FROM Books WHERE isbn IN (?`+strings.Repeat(", ?", len(batch)-1)+`)`, args...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query books: %w", err)
+		}
+		for rows.Next() {
+			var isbn string
+			if err := rows.Scan(&isbn); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("failed to scan book: %w", err)
+			}
+			existing[isbn] = true
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return existing, nil
+}
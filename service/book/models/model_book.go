@@ -1,6 +1,10 @@
 package models
 
-import "github.com/mayureshucsb2019/bookstore/service/common"
+import (
+	"errors"
+
+	"github.com/mayureshucsb2019/bookstore/service/common"
+)
 
 type Book struct {
 	Isbn string `json:"isbn"`
@@ -17,7 +21,10 @@ type Book struct {
 
 	NumberOfPages int32 `json:"number_of_pages,omitempty"`
 
-	Cost float32 `json:"cost,omitempty"`
+	Cost common.Money `json:"cost"`
+
+	// Stock is left unchanged by updates that omit it
+	Stock *int32 `json:"stock,omitempty"`
 }
 
 // AssertBookRequired checks if the required fields are not zero-ed
@@ -39,5 +46,14 @@ func AssertBookRequired(obj Book) error {
 
 // AssertBookConstraints checks if the values respects the defined constraints
 func AssertBookConstraints(obj Book) error {
+	if obj.Cost.Currency != "" && !common.IsValidCurrency(obj.Cost.Currency) {
+		return &common.ParsingError{Param: "cost", Err: common.ErrUnknownCurrency}
+	}
+	if obj.Stock != nil && *obj.Stock < 0 {
+		return &common.ParsingError{Param: "stock", Err: errors.New("stock cannot be negative")}
+	}
+	if obj.Cost.Amount < 0 {
+		return &common.ParsingError{Param: "cost", Err: errors.New("cost cannot be negative")}
+	}
 	return nil
 }
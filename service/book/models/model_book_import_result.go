@@ -0,0 +1,41 @@
+package models
+
+type BookImportResult struct {
+
+	// True when the file was only validated.
+	DryRun bool `json:"dry_run"`
+
+	// Number of data rows in the file.
+	Rows int32 `json:"rows"`
+
+	// Books that were, or on a dry run would be, added.
+	Created int32 `json:"created"`
+
+	// Existing books that were, or on a dry run would be, replaced.
+	Updated int32 `json:"updated"`
+
+	// Rows that failed validation. Nothing is imported while there are any.
+	Errors []BookImportError `json:"errors"`
+}
+
+type BookImportError struct {
+
+	// Line of the row in the file, the header being line 1.
+	Line int32 `json:"line"`
+
+	Isbn string `json:"isbn,omitempty"`
+
+	Field string `json:"field,omitempty"`
+
+	Message string `json:"message"`
+}
+
+// AssertBookImportResultRequired checks if the required fields are not zero-ed
+func AssertBookImportResultRequired(obj BookImportResult) error {
+	return nil
+}
+
+// AssertBookImportResultConstraints checks if the values respects the defined constraints
+func AssertBookImportResultConstraints(obj BookImportResult) error {
+	return nil
+}
@@ -0,0 +1,47 @@
+package models
+
+type OnixImportResult struct {
+
+	// True when the products were only validated and rolled back.
+	DryRun bool `json:"dry_run"`
+
+	// Number of products in the message.
+	Products int32 `json:"products"`
+
+	Created int32 `json:"created"`
+
+	// Books whose metadata or authors changed.
+	Updated int32 `json:"updated"`
+
+	// Books that were already up to date.
+	Unchanged int32 `json:"unchanged"`
+
+	// Deletion notices, which are not applied.
+	Skipped int32 `json:"skipped"`
+
+	AuthorsCreated int32 `json:"authors_created"`
+
+	// Products that could not be imported.
+	Errors []OnixImportError `json:"errors"`
+
+	// Number of occurrences of every element that was not mapped, by path within Product.
+	Unmapped map[string]int32 `json:"unmapped"`
+}
+
+type OnixImportError struct {
+	RecordReference string `json:"record_reference,omitempty"`
+
+	Isbn string `json:"isbn,omitempty"`
+
+	Message string `json:"message"`
+}
+
+// AssertOnixImportResultRequired checks if the required fields are not zero-ed
+func AssertOnixImportResultRequired(obj OnixImportResult) error {
+	return nil
+}
+
+// AssertOnixImportResultConstraints checks if the values respects the defined constraints
+func AssertOnixImportResultConstraints(obj OnixImportResult) error {
+	return nil
+}
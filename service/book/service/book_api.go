@@ -2,7 +2,9 @@ package service
 
 import (
 	"context"
+	"io"
 	"net/http"
+	"os"
 
 	"github.com/mayureshucsb2019/bookstore/service/book/models"
 	"github.com/mayureshucsb2019/bookstore/service/common"
@@ -12,11 +14,16 @@ import (
 // The DefaultAPIRouter implementation should parse necessary information from the http request,
 // pass the data to a DefaultAPIServicer to perform the required actions, then write the service results to the http response.
 type DefaultAPIRouter interface {
+	BooksExportGet(http.ResponseWriter, *http.Request)
 	BooksGet(http.ResponseWriter, *http.Request)
+	BooksImportOnixPost(http.ResponseWriter, *http.Request)
+	BooksImportPost(http.ResponseWriter, *http.Request)
 	BooksIsbnDelete(http.ResponseWriter, *http.Request)
 	BooksIsbnGet(http.ResponseWriter, *http.Request)
 	BooksIsbnPatch(http.ResponseWriter, *http.Request)
 	BooksPost(http.ResponseWriter, *http.Request)
+	BulkBooksGet(http.ResponseWriter, *http.Request)
+	BulkBooksPost(http.ResponseWriter, *http.Request)
 }
 
 // DefaultAPIServicer defines the api actions for the DefaultAPI service
@@ -24,9 +31,14 @@ type DefaultAPIRouter interface {
 // while the service implementation can be ignored with the .openapi-generator-ignore file
 // and updated with the logic required for the API.
 type DefaultAPIServicer interface {
-	BooksGet(context.Context, int32, int32) (common.ImplResponse, error)
+	BooksExportGet(context.Context, string) (common.ImplResponse, error)
+	BooksGet(context.Context, int32, int32, string) (common.ImplResponse, error)
+	BooksImportOnixPost(context.Context, *os.File, bool) (common.ImplResponse, error)
+	BooksImportPost(context.Context, *os.File, map[string]string, bool) (common.ImplResponse, error)
 	BooksIsbnDelete(context.Context, string) (common.ImplResponse, error)
-	BooksIsbnGet(context.Context, string) (common.ImplResponse, error)
+	BooksIsbnGet(context.Context, string, string) (common.ImplResponse, error)
 	BooksIsbnPatch(context.Context, string, models.Book) (common.ImplResponse, error)
 	BooksPost(context.Context, models.Book) (common.ImplResponse, error)
+	BulkBooksGet(context.Context) (common.ImplResponse, error)
+	BulkBooksPost(context.Context, io.Reader, int32) (common.ImplResponse, error)
 }
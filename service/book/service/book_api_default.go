@@ -3,6 +3,7 @@ package service
 import (
 	"encoding/json"
 	"net/http"
+	"os"
 	"strings"
 
 	"github.com/gorilla/mux"
@@ -43,11 +44,26 @@ func NewDefaultAPIController(s DefaultAPIServicer, opts ...DefaultAPIOption) *De
 // Routes returns all the api routes for the DefaultAPIController
 func (c *DefaultAPIController) Routes() common.Routes {
 	return common.Routes{
+		"BooksExportGet": common.Route{
+			Method:      strings.ToUpper("Get"),
+			Pattern:     "/books/export",
+			HandlerFunc: c.BooksExportGet,
+		},
 		"BooksGet": common.Route{
 			Method:      strings.ToUpper("Get"),
 			Pattern:     "/books",
 			HandlerFunc: c.BooksGet,
 		},
+		"BooksImportOnixPost": common.Route{
+			Method:      strings.ToUpper("Post"),
+			Pattern:     "/books/import/onix",
+			HandlerFunc: c.BooksImportOnixPost,
+		},
+		"BooksImportPost": common.Route{
+			Method:      strings.ToUpper("Post"),
+			Pattern:     "/books/import",
+			HandlerFunc: c.BooksImportPost,
+		},
 		"BooksIsbnDelete": common.Route{
 			Method:      strings.ToUpper("Delete"),
 			Pattern:     "/books/{isbn}",
@@ -68,9 +84,40 @@ func (c *DefaultAPIController) Routes() common.Routes {
 			Pattern:     "/books",
 			HandlerFunc: c.BooksPost,
 		},
+		"BulkBooksGet": common.Route{
+			Method:      strings.ToUpper("Get"),
+			Pattern:     "/bulk/books",
+			HandlerFunc: c.BulkBooksGet,
+		},
+		"BulkBooksPost": common.Route{
+			Method:      strings.ToUpper("Post"),
+			Pattern:     "/bulk/books",
+			HandlerFunc: c.BulkBooksPost,
+		},
 	}
 }
 
+// BooksExportGet - Export the whole catalog
+func (c *DefaultAPIController) BooksExportGet(w http.ResponseWriter, r *http.Request) {
+	query, err := common.ParseQuery(r.URL.RawQuery)
+	if err != nil {
+		c.errorHandler(w, r, &common.ParsingError{Err: err}, nil)
+		return
+	}
+	formatParam := "csv"
+	if query.Has("format") {
+		formatParam = query.Get("format")
+	}
+	result, err := c.service.BooksExportGet(r.Context(), formatParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	_ = common.EncodeJSONResponse(result.Body, &result.Code, w)
+}
+
 // BooksGet - Get a paginated list of books
 func (c *DefaultAPIController) BooksGet(w http.ResponseWriter, r *http.Request) {
 	query, err := common.ParseQuery(r.URL.RawQuery)
@@ -110,7 +157,65 @@ func (c *DefaultAPIController) BooksGet(w http.ResponseWriter, r *http.Request)
 		var param int32 = 25
 		pageSizeParam = param
 	}
-	result, err := c.service.BooksGet(r.Context(), pageNumberParam, pageSizeParam)
+	currencyParam, err := common.ResolveCurrency(r)
+	if err != nil {
+		c.errorHandler(w, r, err, nil)
+		return
+	}
+	result, err := c.service.BooksGet(r.Context(), pageNumberParam, pageSizeParam, currencyParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	_ = common.EncodeJSONResponse(result.Body, &result.Code, w)
+}
+
+// BooksImportOnixPost - Create or update books from an ONIX 3.0 message
+func (c *DefaultAPIController) BooksImportOnixPost(w http.ResponseWriter, r *http.Request) {
+	fileParam, err := common.ReadFormFileToTempFile(r, "file")
+	if err != nil {
+		c.errorHandler(w, r, &common.ParsingError{Param: "file", Err: err}, nil)
+		return
+	}
+	defer os.Remove(fileParam.Name())
+	dryRunParam, err := common.ParseBoolParameter(r.FormValue("dry_run"), common.WithParse[bool](common.ParseBool))
+	if err != nil {
+		c.errorHandler(w, r, &common.ParsingError{Param: "dry_run", Err: err}, nil)
+		return
+	}
+	result, err := c.service.BooksImportOnixPost(r.Context(), fileParam, dryRunParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	_ = common.EncodeJSONResponse(result.Body, &result.Code, w)
+}
+
+// BooksImportPost - Create or replace books from a CSV file
+func (c *DefaultAPIController) BooksImportPost(w http.ResponseWriter, r *http.Request) {
+	fileParam, err := common.ReadFormFileToTempFile(r, "file")
+	if err != nil {
+		c.errorHandler(w, r, &common.ParsingError{Param: "file", Err: err}, nil)
+		return
+	}
+	defer os.Remove(fileParam.Name())
+	var mappingParam map[string]string
+	if mapping := r.FormValue("mapping"); mapping != "" {
+		if err := json.Unmarshal([]byte(mapping), &mappingParam); err != nil {
+			c.errorHandler(w, r, &common.ParsingError{Param: "mapping", Err: err}, nil)
+			return
+		}
+	}
+	dryRunParam, err := common.ParseBoolParameter(r.FormValue("dry_run"), common.WithParse[bool](common.ParseBool))
+	if err != nil {
+		c.errorHandler(w, r, &common.ParsingError{Param: "dry_run", Err: err}, nil)
+		return
+	}
+	result, err := c.service.BooksImportPost(r.Context(), fileParam, mappingParam, dryRunParam)
 	// If an error occurred, encode the error with the status code
 	if err != nil {
 		c.errorHandler(w, r, err, &result)
@@ -146,7 +251,12 @@ func (c *DefaultAPIController) BooksIsbnGet(w http.ResponseWriter, r *http.Reque
 		c.errorHandler(w, r, &common.RequiredError{Field: "isbn"}, nil)
 		return
 	}
-	result, err := c.service.BooksIsbnGet(r.Context(), isbnParam)
+	currencyParam, err := common.ResolveCurrency(r)
+	if err != nil {
+		c.errorHandler(w, r, err, nil)
+		return
+	}
+	result, err := c.service.BooksIsbnGet(r.Context(), isbnParam, currencyParam)
 	// If an error occurred, encode the error with the status code
 	if err != nil {
 		c.errorHandler(w, r, err, &result)
@@ -215,3 +325,47 @@ func (c *DefaultAPIController) BooksPost(w http.ResponseWriter, r *http.Request)
 	// If no error, encode the body and the result code
 	_ = common.EncodeJSONResponse(result.Body, &result.Code, w)
 }
+
+// BulkBooksGet - Export every book as NDJSON
+func (c *DefaultAPIController) BulkBooksGet(w http.ResponseWriter, r *http.Request) {
+	result, err := c.service.BulkBooksGet(r.Context())
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	_ = common.EncodeJSONResponse(result.Body, &result.Code, w)
+}
+
+// BulkBooksPost - Create or replace books from NDJSON
+func (c *DefaultAPIController) BulkBooksPost(w http.ResponseWriter, r *http.Request) {
+	query, err := common.ParseQuery(r.URL.RawQuery)
+	if err != nil {
+		c.errorHandler(w, r, &common.ParsingError{Err: err}, nil)
+		return
+	}
+	var batchSizeParam int32
+	if query.Has("batch_size") {
+		param, err := common.ParseNumericParameter[int32](
+			query.Get("batch_size"),
+			common.WithParse[int32](common.ParseInt32),
+			common.WithMinimum[int32](1),
+			common.WithMaximum[int32](common.MaxBulkBatchSize),
+		)
+		if err != nil {
+			c.errorHandler(w, r, &common.ParsingError{Param: "batch_size", Err: err}, nil)
+			return
+		}
+
+		batchSizeParam = param
+	}
+	result, err := c.service.BulkBooksPost(r.Context(), r.Body, batchSizeParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	_ = common.EncodeJSONResponse(result.Body, &result.Code, w)
+}
@@ -11,32 +11,57 @@ import (
 	"github.com/mayureshucsb2019/bookstore/service/book/db"
 	"github.com/mayureshucsb2019/bookstore/service/book/models"
 	"github.com/mayureshucsb2019/bookstore/service/common"
+	exchange_db "github.com/mayureshucsb2019/bookstore/service/exchange/db"
+	"github.com/mayureshucsb2019/bookstore/service/onix"
+	review_db "github.com/mayureshucsb2019/bookstore/service/review/db"
 )
 
 // DefaultAPIService is a service that implements the logic for the DefaultAPI API.
 // This service interacts with the repository layer for data access.
 type DefaultAPIService struct {
-	Repo *db.BookRepository // Add a field to hold the repository
+	Repo  *db.BookRepository // Add a field to hold the repository
+	Rates *exchange_db.ExchangeRateRepository
+	Onix  *onix.Importer
+	// Rates the books from their approved reviews; books are shown without a rating when not set
+	Reviews *review_db.ReviewRepository
+
+	// Books saved per transaction by bulk imports, common.DefaultBulkBatchSize when not set
+	BulkBatchSize int
 }
 
-// NewDefaultAPIService creates a default API service with the given repository.
-func NewDefaultAPIService(repo *db.BookRepository) *DefaultAPIService {
+// NewDefaultAPIService creates a default API service with the given repositories.
+func NewDefaultAPIService(repo *db.BookRepository, rates *exchange_db.ExchangeRateRepository) *DefaultAPIService {
 	return &DefaultAPIService{
-		Repo: repo,
+		Repo:  repo,
+		Rates: rates,
+		Onix:  onix.NewImporter(repo),
 	}
 }
 
 // BooksGet - Get a paginated list of books
-func (s *DefaultAPIService) BooksGet(ctx context.Context, pageNumber int32, pageSize int32) (common.ImplResponse, error) {
+func (s *DefaultAPIService) BooksGet(ctx context.Context, pageNumber int32, pageSize int32, currency string) (common.ImplResponse, error) {
 	// TODO: Uncomment the next line to return response Response(404, {}) or use other options such as http.Ok ...
 	// return Response(404, nil),nil
 	books, err := s.Repo.GetAllBooks() // Use the repository to get the books
 	if err != nil {
 		return common.Response(http.StatusInternalServerError, nil), err
 	}
+	rates, err := s.rateTable(currency)
+	if err != nil {
+		return common.Response(http.StatusInternalServerError, nil), err
+	}
+	ratings, err := s.ratings(nil)
+	if err != nil {
+		return common.Response(http.StatusInternalServerError, nil), err
+	}
 	var booksJSON []map[string]interface{}
 	for _, book := range books {
-		booksJSON = append(booksJSON, convertBookToAPIFormat(book))
+		bookJSON, err := convertBookToCurrency(book, rates, currency)
+		if err != nil {
+			return common.Response(http.StatusUnprocessableEntity, nil), err
+		}
+		addRating(bookJSON, ratings)
+		booksJSON = append(booksJSON, bookJSON)
 	}
 
 	return common.Response(http.StatusOK, booksJSON), nil
@@ -55,15 +80,31 @@ func (s *DefaultAPIService) BooksIsbnDelete(ctx context.Context, isbn string) (c
 }
 
 // BooksIsbnGet - Get a specific book by ISBN
-func (s *DefaultAPIService) BooksIsbnGet(ctx context.Context, isbn string) (common.ImplResponse, error) {
+func (s *DefaultAPIService) BooksIsbnGet(ctx context.Context, isbn string, currency string) (common.ImplResponse, error) {
 	// TODO: Uncomment the next line to return response Response(404, {}) or use other options such as http.Ok ...
 	// return Response(404, nil),nil
 	book, err := s.Repo.GetBookByISBN(isbn) // Use the repository to get the books
 	if err != nil {
 		return common.Response(http.StatusInternalServerError, nil), err
 	}
+	if book == nil {
+		return common.Response(http.StatusNotFound, nil), fmt.Errorf("book %s not found", isbn)
+	}
+	rates, err := s.rateTable(currency)
+	if err != nil {
+		return common.Response(http.StatusInternalServerError, nil), err
+	}
+	bookJSON, err := convertBookToCurrency(*book, rates, currency)
+	if err != nil {
+		return common.Response(http.StatusUnprocessableEntity, nil), err
+	}
+	ratings, err := s.ratings([]string{book.ISBN})
+	if err != nil {
+		return common.Response(http.StatusInternalServerError, nil), err
+	}
+	addRating(bookJSON, ratings)
 
-	return common.Response(http.StatusOK, convertBookToAPIFormat(*book)), nil
+	return common.Response(http.StatusOK, bookJSON), nil
 }
 
 // BooksIsbnPatch - Update a book by ISBN
@@ -102,8 +143,62 @@ func (s *DefaultAPIService) BooksPost(ctx context.Context, book models.Book) (co
 	return common.Response(http.StatusCreated, nil), nil
 }
 
+// rateTable loads the exchange rates when the client asked for a display currency
+func (s *DefaultAPIService) rateTable(currency string) (*common.RateTable, error) {
+	if currency == "" {
+		return nil, nil
+	}
+	return s.Rates.GetRateTable()
+}
+
+// ratings returns the ratings of books by ISBN, of every book without ISBNs. It is nil when the
+// service has no review repository.
+func (s *DefaultAPIService) ratings(isbns []string) (map[string]review_db.Rating, error) {
+	if s.Reviews == nil {
+		return nil, nil
+	}
+	return s.Reviews.GetRatings(isbns)
+}
+
+// addRating adds the rating of a book to its response. Books without approved reviews are rated
+// 0 from 0 reviews.
+func addRating(bookJSON map[string]interface{}, ratings map[string]review_db.Rating) {
+	if ratings == nil {
+		return
+	}
+	rating := ratings[bookJSON["isbn"].(string)]
+	bookJSON["rating"] = map[string]interface{}{
+		"average": rating.Average,
+		"count":   rating.Count,
+	}
+}
+
+// convertBookToCurrency converts the book to API format with its cost in the requested currency.
+// The stored price is kept as list_price together with the exchange rate that was applied.
+func convertBookToCurrency(book db.Book, rates *common.RateTable, currency string) (map[string]interface{}, error) {
+	if currency == "" || currency == book.Cost.Currency {
+		return convertBookToAPIFormat(book), nil
+	}
+
+	listPrice := book.Cost
+	converted, rate, err := rates.Convert(listPrice, currency)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert price of book %s: %w", book.ISBN, err)
+	}
+	book.Cost = converted
+
+	bookJSON := convertBookToAPIFormat(book)
+	bookJSON["list_price"] = listPrice
+	bookJSON["exchange_rate"] = common.FormatRate(rate)
+	return bookJSON, nil
+}
+
 // Convert Book to db.Book
 func convertToDBBook(book models.Book) db.Book {
+	cost := book.Cost
+	if cost.Currency == "" {
+		cost.Currency = common.DefaultCurrency
+	}
 	dbBook := db.Book{
 		ISBN:            book.Isbn,
 		Name:            book.Name,
@@ -112,7 +207,11 @@ func convertToDBBook(book models.Book) db.Book {
 		DateOfPublish:   book.DateOfPublish,
 		PublishingHouse: book.PublishingHouse,
 		NumberOfPages:   int(book.NumberOfPages), // Convert int32 to int
-		Cost:            float64(book.Cost),      // Convert float32 to float64
+		Cost:            cost,
+		KeepStock:       book.Stock == nil,
+	}
+	if book.Stock != nil {
+		dbBook.Stock = int(*book.Stock)
 	}
 	return dbBook
 }
@@ -134,5 +233,6 @@ func convertBookToAPIFormat(book db.Book) map[string]interface{} {
 		"publishing_house": book.PublishingHouse,
 		"number_of_pages":  book.NumberOfPages,
 		"cost":             book.Cost,
+		"stock":            book.Stock,
 	}
 }
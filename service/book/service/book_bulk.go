@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+	"io"
+	"net/http"
+
+	"github.com/mayureshucsb2019/bookstore/service/book/db"
+	"github.com/mayureshucsb2019/bookstore/service/book/models"
+	"github.com/mayureshucsb2019/bookstore/service/common"
+)
+
+// BulkBooksGet - Export every book as NDJSON
+func (s *DefaultAPIService) BulkBooksGet(ctx context.Context) (common.ImplResponse, error) {
+	export := func(encode func(v interface{}) error) error {
+		return s.Repo.ForEachBook(func(book *db.Book) error {
+			return encode(convertToAPIBook(*book))
+		})
+	}
+	return common.Response(http.StatusOK, common.NDJSONStream(export)), nil
+}
+
+// BulkBooksPost - Create or replace books from NDJSON
+func (s *DefaultAPIService) BulkBooksPost(ctx context.Context, body io.Reader, batchSize int32) (common.ImplResponse, error) {
+	if batchSize == 0 {
+		batchSize = int32(s.BulkBatchSize)
+	}
+	bulk := common.BulkImport[db.Book]{
+		Decode:    decodeBulkBook,
+		Save:      s.Repo.SaveBooks,
+		BatchSize: int(batchSize),
+	}
+	results, err := common.SpoolStream(common.NDJSONContentType, func(w io.Writer) error {
+		_, err := bulk.Run(body, w)
+		return err
+	})
+	if err != nil {
+		return common.Response(http.StatusInternalServerError, nil), err
+	}
+	return common.Response(http.StatusOK, results), nil
+}
+
+// decodeBulkBook reads and validates one book of a bulk import.
+func decodeBulkBook(line []byte) (db.Book, string, error) {
+	var book models.Book
+	if err := common.DecodeBulkRecord(line, &book); err != nil {
+		return db.Book{}, "", err
+	}
+	if err := models.AssertBookRequired(book); err != nil {
+		return db.Book{}, book.Isbn, err
+	}
+	if err := models.AssertBookConstraints(book); err != nil {
+		return db.Book{}, book.Isbn, err
+	}
+	if err := validatePublishDate(book.DateOfPublish); err != nil {
+		return db.Book{}, book.Isbn, &common.ParsingError{Param: "date_of_publish", Err: err}
+	}
+	return convertToDBBook(book), book.Isbn, nil
+}
+
+// convertToAPIBook converts a stored book to the API model, keeping the stored date and price so
+// an export can be imported again unchanged.
+func convertToAPIBook(book db.Book) models.Book {
+	stock := int32(book.Stock)
+	return models.Book{
+		Isbn:            book.ISBN,
+		Name:            book.Name,
+		Tags:            book.Tags,
+		AuthorName:      book.AuthorName,
+		DateOfPublish:   book.DateOfPublish,
+		PublishingHouse: book.PublishingHouse,
+		NumberOfPages:   int32(book.NumberOfPages),
+		Cost:            book.Cost,
+		Stock:           &stock,
+	}
+}
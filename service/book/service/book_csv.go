@@ -0,0 +1,274 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mayureshucsb2019/bookstore/service/book/db"
+	"github.com/mayureshucsb2019/bookstore/service/book/models"
+	"github.com/mayureshucsb2019/bookstore/service/common"
+)
+
+// BookColumns are the catalog fields in CSV files, in export order. Unless mapped otherwise an
+// import reads each field from the column with the same header.
+var BookColumns = []string{
+	"isbn", "name", "tags", "author_name", "date_of_publish", "publishing_house", "number_of_pages", "cost", "currency", "stock",
+}
+
+// tagSeparator separates the tags of a book within a CSV cell.
+const tagSeparator = ";"
+
+// BooksExportGet - Export the whole catalog
+func (s *DefaultAPIService) BooksExportGet(ctx context.Context, format string) (common.ImplResponse, error) {
+	if format != "csv" {
+		return common.Response(http.StatusBadRequest, nil), &common.ParsingError{Param: "format", Err: fmt.Errorf("unsupported export format %q", format)}
+	}
+
+	export := func(w io.Writer) error {
+		writer := csv.NewWriter(w)
+		if err := writer.Write(BookColumns); err != nil {
+			return err
+		}
+		err := s.Repo.ForEachBook(func(book *db.Book) error {
+			return writer.Write([]string{
+				book.ISBN,
+				book.Name,
+				strings.Join(book.Tags, tagSeparator),
+				book.AuthorName,
+				book.DateOfPublish,
+				book.PublishingHouse,
+				strconv.Itoa(book.NumberOfPages),
+				book.Cost.Decimal(),
+				book.Cost.Currency,
+				strconv.Itoa(book.Stock),
+			})
+		})
+		if err != nil {
+			return err
+		}
+		writer.Flush()
+		return writer.Error()
+	}
+	return common.Response(http.StatusOK, common.Stream{ContentType: "text/csv; charset=utf-8", Filename: "books.csv", Write: export}), nil
+}
+
+// BooksImportPost - Create or replace books from a CSV file
+func (s *DefaultAPIService) BooksImportPost(ctx context.Context, file *os.File, mapping map[string]string, dryRun bool) (common.ImplResponse, error) {
+	// The upload helper closes the temporary file once it is written, so open it again
+	f, err := os.Open(file.Name())
+	if err != nil {
+		return common.Response(http.StatusInternalServerError, nil), err
+	}
+	defer f.Close()
+
+	books, result, err := parseBooksCSV(f, mapping)
+	if err != nil {
+		return common.Response(http.StatusBadRequest, nil), err
+	}
+	result.DryRun = dryRun
+	if len(result.Errors) > 0 {
+		return common.Response(http.StatusUnprocessableEntity, result), nil
+	}
+
+	if dryRun {
+		isbns := make([]string, len(books))
+		for i, book := range books {
+			isbns[i] = book.ISBN
+		}
+		existing, err := s.Repo.GetExistingISBNs(isbns)
+		if err != nil {
+			return common.Response(http.StatusInternalServerError, nil), err
+		}
+		result.Updated = int32(len(existing))
+		result.Created = int32(len(books) - len(existing))
+		return common.Response(http.StatusOK, result), nil
+	}
+
+	created, updated, err := s.Repo.ImportBooks(books)
+	if err != nil {
+		return common.Response(http.StatusInternalServerError, nil), fmt.Errorf("failed to import books: %w", err)
+	}
+	result.Created, result.Updated = int32(created), int32(updated)
+	return common.Response(http.StatusOK, result), nil
+}
+
+// parseBooksCSV reads the books of a CSV file whose first row is the header. mapping names the
+// column of a field when it differs from the field name. Rows that do not hold a valid book are
+// reported in the result; an error is only returned when the file or the mapping is unusable.
+func parseBooksCSV(r io.Reader, mapping map[string]string) ([]db.Book, models.BookImportResult, error) {
+	result := models.BookImportResult{Errors: []models.BookImportError{}}
+
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			err = errors.New("the file is empty")
+		}
+		return nil, result, &common.ParsingError{Param: "file", Err: err}
+	}
+	columns, err := resolveColumns(header, mapping)
+	if err != nil {
+		return nil, result, err
+	}
+
+	var books []db.Book
+	lines := map[string]int32{}
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, result, &common.ParsingError{Param: "file", Err: err}
+		}
+		line, _ := reader.FieldPos(0)
+		result.Rows++
+
+		cell := func(field string) string {
+			if i, ok := columns[field]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		book, field, err := parseBookRow(cell)
+		if err != nil {
+			result.Errors = append(result.Errors, models.BookImportError{Line: int32(line), Isbn: book.Isbn, Field: field, Message: err.Error()})
+			continue
+		}
+		if first, ok := lines[book.Isbn]; ok {
+			message := fmt.Sprintf("duplicate of the book on line %d", first)
+			result.Errors = append(result.Errors, models.BookImportError{Line: int32(line), Isbn: book.Isbn, Field: "isbn", Message: message})
+			continue
+		}
+		lines[book.Isbn] = int32(line)
+		books = append(books, convertToDBBook(book))
+	}
+	return books, result, nil
+}
+
+// resolveColumns maps every field that has a column in header to the column index.
+func resolveColumns(header []string, mapping map[string]string) (map[string]int, error) {
+	indexes := map[string]int{}
+	for i, name := range header {
+		if i == 0 {
+			// Spreadsheet programs like to start UTF-8 files with a byte order mark
+			name = strings.TrimPrefix(name, "\ufeff")
+		}
+		indexes[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+
+	for field := range mapping {
+		if !isBookColumn(field) {
+			return nil, &common.ParsingError{Param: "mapping", Err: fmt.Errorf("unknown field %q, expected one of %s", field, strings.Join(BookColumns, ", "))}
+		}
+	}
+
+	columns := map[string]int{}
+	for _, field := range BookColumns {
+		name, mapped := mapping[field]
+		if !mapped {
+			name = field
+		}
+		i, ok := indexes[strings.ToLower(strings.TrimSpace(name))]
+		if !ok {
+			if mapped {
+				return nil, &common.ParsingError{Param: "mapping", Err: fmt.Errorf("column %q for %s is not in the header", name, field)}
+			}
+			continue
+		}
+		columns[field] = i
+	}
+	return columns, nil
+}
+
+// parseBookRow builds a book from the cells of a row and validates it like the JSON API does.
+// On failure it returns the field at fault with the error.
+func parseBookRow(cell func(field string) string) (models.Book, string, error) {
+	book := models.Book{
+		Isbn:            cell("isbn"),
+		Name:            cell("name"),
+		AuthorName:      cell("author_name"),
+		DateOfPublish:   cell("date_of_publish"),
+		PublishingHouse: cell("publishing_house"),
+	}
+	for _, tag := range strings.Split(cell("tags"), tagSeparator) {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			book.Tags = append(book.Tags, tag)
+		}
+	}
+	// Books without a stock keep the one they have
+	var stock int32
+	for field, target := range map[string]*int32{"number_of_pages": &book.NumberOfPages, "stock": &stock} {
+		if value := cell(field); value != "" {
+			n, err := strconv.ParseInt(value, 10, 32)
+			if err != nil {
+				return book, field, fmt.Errorf("%q is not a whole number", value)
+			}
+			*target = int32(n)
+			if field == "stock" {
+				book.Stock = &stock
+			}
+		}
+	}
+	currency := strings.ToUpper(cell("currency"))
+	if currency == "" {
+		currency = common.DefaultCurrency
+	}
+	if !common.IsValidCurrency(currency) {
+		return book, "currency", common.ErrUnknownCurrency
+	}
+	book.Cost = common.NewMoney(0, currency)
+	if value := cell("cost"); value != "" {
+		cost, err := common.ParseMoney(value, currency)
+		if err != nil {
+			return book, "cost", err
+		}
+		book.Cost = cost
+	}
+
+	if err := models.AssertBookRequired(book); err != nil {
+		var requiredErr *common.RequiredError
+		if errors.As(err, &requiredErr) {
+			return book, requiredErr.Field, errors.New("required field is empty")
+		}
+		return book, "", err
+	}
+	if err := models.AssertBookConstraints(book); err != nil {
+		var parsingErr *common.ParsingError
+		if errors.As(err, &parsingErr) {
+			return book, parsingErr.Param, parsingErr.Err
+		}
+		return book, "", err
+	}
+	if err := validatePublishDate(book.DateOfPublish); err != nil {
+		return book, "date_of_publish", err
+	}
+	return book, "", nil
+}
+
+func isBookColumn(field string) bool {
+	for _, column := range BookColumns {
+		if column == field {
+			return true
+		}
+	}
+	return false
+}
+
+// validatePublishDate checks that a publication date is in the YYYY-MM-DD form the catalog stores.
+func validatePublishDate(date string) error {
+	if _, err := time.Parse("2006-01-02", date); err != nil {
+		return fmt.Errorf("%q is not a YYYY-MM-DD date", date)
+	}
+	return nil
+}
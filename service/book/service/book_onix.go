@@ -0,0 +1,56 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+
+	"github.com/mayureshucsb2019/bookstore/service/book/models"
+	"github.com/mayureshucsb2019/bookstore/service/common"
+	"github.com/mayureshucsb2019/bookstore/service/onix"
+)
+
+// BooksImportOnixPost - Create or update books from an ONIX 3.0 message
+func (s *DefaultAPIService) BooksImportOnixPost(ctx context.Context, file *os.File, dryRun bool) (common.ImplResponse, error) {
+	// The upload helper closes the temporary file once it is written, so open it again
+	f, err := os.Open(file.Name())
+	if err != nil {
+		return common.Response(http.StatusInternalServerError, nil), err
+	}
+	defer f.Close()
+
+	report, err := s.Onix.Import(f, dryRun)
+	if errors.Is(err, onix.ErrUnsupportedFormat) || (err != nil && report.Products == 0) {
+		return common.Response(http.StatusBadRequest, nil), &common.ParsingError{Param: "file", Err: err}
+	}
+	result := convertOnixReport(report)
+	if err != nil {
+		// The products before the point where the message broke off were imported
+		result.Errors = append(result.Errors, models.OnixImportError{Message: err.Error()})
+		return common.Response(http.StatusUnprocessableEntity, result), nil
+	}
+	return common.Response(http.StatusOK, result), nil
+}
+
+// convertOnixReport converts an import report to API format
+func convertOnixReport(report *onix.Report) models.OnixImportResult {
+	result := models.OnixImportResult{
+		DryRun:         report.DryRun,
+		Products:       int32(report.Products),
+		Created:        int32(report.Created),
+		Updated:        int32(report.Updated),
+		Unchanged:      int32(report.Unchanged),
+		Skipped:        int32(report.Skipped),
+		AuthorsCreated: int32(report.AuthorsCreated),
+		Errors:         []models.OnixImportError{},
+		Unmapped:       map[string]int32{},
+	}
+	for _, e := range report.Errors {
+		result.Errors = append(result.Errors, models.OnixImportError{RecordReference: e.Reference, Isbn: e.ISBN, Message: e.Message})
+	}
+	for path, count := range report.Unmapped {
+		result.Unmapped[path] = int32(count)
+	}
+	return result
+}
@@ -0,0 +1,240 @@
+package db
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	_ "github.com/go-sql-driver/mysql"
+	"github.com/mayureshucsb2019/bookstore/service/common"
+)
+
+// ErrCartNotFound is returned when no cart exists with the requested id
+var ErrCartNotFound = errors.New("cart not found")
+
+// Cart represents the structure of a Carts record in the database. Anonymous carts
+// have no customer and are addressed only by their random id. Carts refer to their customer by
+// id; CustomerEmail is the customer's current email.
+type Cart struct {
+	ID            string
+	CustomerEmail string
+	CreatedAt     string
+	UpdatedAt     string
+	Items         []CartItem
+}
+
+// CartItem represents the structure of a CartItems record in the database.
+type CartItem struct {
+	CartID   string
+	ISBN     string
+	Quantity int
+}
+
+// CartRepository provides access to the Carts and CartItems storage.
+type CartRepository struct {
+	DB *sql.DB
+}
+
+// NewCartID generates a random, unguessable cart id that doubles as the anonymous cart token.
+func NewCartID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate cart id: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// CreateCart inserts a new, empty cart. The cart id is generated when not set.
+func (r *CartRepository) CreateCart(cart *Cart) error {
+	if cart.ID == "" {
+		id, err := NewCartID()
+		if err != nil {
+			return err
+		}
+		cart.ID = id
+	}
+
+	_, err := r.DB.Exec(`INSERT INTO Carts (id, customer_id) VALUES (?, (SELECT id FROM Customer WHERE email = ?))`,
+		cart.ID, common.NullStringOrNil(cart.CustomerEmail))
+	if err != nil {
+		return fmt.Errorf("failed to insert cart: %w", err)
+	}
+	return nil
+}
+
+// GetCartByID retrieves a cart and its items by the cart id.
+func (r *CartRepository) GetCartByID(id string) (*Cart, error) {
+	query := `SELECT ` + cartColumns + ` FROM Carts WHERE id = ?`
+	return r.getCart(query, id)
+}
+
+// GetCartByCustomer retrieves the cart owned by a customer. It returns nil if the customer has no cart.
+func (r *CartRepository) GetCartByCustomer(email string) (*Cart, error) {
+	query := `SELECT ` + cartColumns + ` FROM Carts WHERE customer_id = (SELECT id FROM Customer WHERE email = ?)`
+	cart, err := r.getCart(query, email)
+	if errors.Is(err, ErrCartNotFound) {
+		return nil, nil
+	}
+	return cart, err
+}
+
+// cartColumns are the Carts columns read by getCart, with the email of the customer in place of their id.
+const cartColumns = `id, (SELECT email FROM Customer WHERE Customer.id = Carts.customer_id), created_at, updated_at`
+
+func (r *CartRepository) getCart(query string, arg interface{}) (*Cart, error) {
+	var cart Cart
+	var customerEmail sql.NullString
+
+	err := r.DB.QueryRow(query, arg).Scan(&cart.ID, &customerEmail, &cart.CreatedAt, &cart.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("%w: %v", ErrCartNotFound, arg)
+		}
+		return nil, fmt.Errorf("failed to get cart: %w", err)
+	}
+	cart.CustomerEmail = common.StringOrEmpty(customerEmail)
+
+	rows, err := r.DB.Query(`SELECT cart_id, isbn, quantity FROM CartItems WHERE cart_id = ? ORDER BY added_at`, cart.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query cart items: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var item CartItem
+		if err := rows.Scan(&item.CartID, &item.ISBN, &item.Quantity); err != nil {
+			return nil, fmt.Errorf("failed to scan cart item: %w", err)
+		}
+		cart.Items = append(cart.Items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+
+	return &cart, nil
+}
+
+// AddCartItem adds quantity copies of a book to the cart, on top of any copies already in it.
+func (r *CartRepository) AddCartItem(cartID string, isbn string, quantity int) error {
+	query := `
+		INSERT INTO CartItems (cart_id, isbn, quantity) VALUES (?, ?, ?)
+		ON DUPLICATE KEY UPDATE quantity = quantity + VALUES(quantity)
+	`
+	if _, err := r.DB.Exec(query, cartID, isbn, quantity); err != nil {
+		return fmt.Errorf("failed to add cart item: %w", err)
+	}
+	return r.touch(r.DB, cartID)
+}
+
+// SetCartItem sets the quantity of a book in the cart. A quantity of zero removes the book.
+func (r *CartRepository) SetCartItem(cartID string, isbn string, quantity int) error {
+	if quantity <= 0 {
+		return r.RemoveCartItem(cartID, isbn)
+	}
+
+	query := `
+		INSERT INTO CartItems (cart_id, isbn, quantity) VALUES (?, ?, ?)
+		ON DUPLICATE KEY UPDATE quantity = VALUES(quantity)
+	`
+	if _, err := r.DB.Exec(query, cartID, isbn, quantity); err != nil {
+		return fmt.Errorf("failed to set cart item: %w", err)
+	}
+	return r.touch(r.DB, cartID)
+}
+
+// RemoveCartItem removes a book from the cart.
+func (r *CartRepository) RemoveCartItem(cartID string, isbn string) error {
+	if _, err := r.DB.Exec(`DELETE FROM CartItems WHERE cart_id = ? AND isbn = ?`, cartID, isbn); err != nil {
+		return fmt.Errorf("failed to remove cart item: %w", err)
+	}
+	return r.touch(r.DB, cartID)
+}
+
+// ClearCart removes every item from the cart but keeps the cart itself.
+func (r *CartRepository) ClearCart(cartID string) error {
+	if _, err := r.DB.Exec(`DELETE FROM CartItems WHERE cart_id = ?`, cartID); err != nil {
+		return fmt.Errorf("failed to clear cart: %w", err)
+	}
+	return r.touch(r.DB, cartID)
+}
+
+// DeleteCart removes a cart and its items.
+func (r *CartRepository) DeleteCart(id string) error {
+	result, err := r.DB.Exec(`DELETE FROM Carts WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete cart: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("%w: %s", ErrCartNotFound, id)
+	}
+	return nil
+}
+
+// AssignCustomer makes an anonymous cart the customer's cart.
+func (r *CartRepository) AssignCustomer(cartID string, email string) error {
+	_, err := r.DB.Exec(`UPDATE Carts SET customer_id = (SELECT id FROM Customer WHERE email = ?), updated_at = CURRENT_TIMESTAMP
+		WHERE id = ?`, email, cartID)
+	if err != nil {
+		return fmt.Errorf("failed to assign cart to customer: %w", err)
+	}
+	return nil
+}
+
+// MergeCarts moves every item of the source cart into the target cart, adding quantities
+// for books present in both, and deletes the source cart in the same transaction.
+func (r *CartRepository) MergeCarts(sourceID string, targetID string) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO CartItems (cart_id, isbn, quantity)
+		SELECT ?, isbn, quantity FROM CartItems WHERE cart_id = ?
+		ON DUPLICATE KEY UPDATE quantity = CartItems.quantity + VALUES(quantity)
+	`
+	if _, err := tx.Exec(query, targetID, sourceID); err != nil {
+		return fmt.Errorf("failed to merge cart items: %w", err)
+	}
+	if _, err := tx.Exec(`DELETE FROM Carts WHERE id = ?`, sourceID); err != nil {
+		return fmt.Errorf("failed to delete merged cart: %w", err)
+	}
+	if err := r.touch(tx, targetID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// DeleteExpiredCarts removes carts that have not been updated within ttl and returns the
+// number of carts removed. The cutoff is computed by the database to avoid clock and
+// time zone differences with the application.
+func (r *CartRepository) DeleteExpiredCarts(ttl time.Duration) (int64, error) {
+	result, err := r.DB.Exec(`DELETE FROM Carts WHERE updated_at < NOW() - INTERVAL ? SECOND`, int64(ttl.Seconds()))
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired carts: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// execer is implemented by both *sql.DB and *sql.Tx.
+type execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+// touch marks the cart as active so it is not expired.
+func (r *CartRepository) touch(ex execer, cartID string) error {
+	if _, err := ex.Exec(`UPDATE Carts SET updated_at = CURRENT_TIMESTAMP WHERE id = ?`, cartID); err != nil {
+		return fmt.Errorf("failed to update cart: %w", err)
+	}
+	return nil
+}
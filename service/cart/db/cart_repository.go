@@ -0,0 +1,19 @@
+package db
+
+import (
+	"sync"
+
+	"github.com/mayureshucsb2019/bookstore/service/common"
+)
+
+var cartRepoInstance *CartRepository
+var cartRepoOnce sync.Once
+
+func NewCartRepository(db *common.DBConnection) *CartRepository {
+	cartRepoOnce.Do(func() {
+		cartRepoInstance = &CartRepository{
+			DB: db.DB,
+		}
+	})
+	return cartRepoInstance
+}
@@ -0,0 +1,60 @@
+package models
+
+import "github.com/mayureshucsb2019/bookstore/service/common"
+
+type Cart struct {
+	Id string `json:"id"`
+
+	CustomerEmail string `json:"customer_email,omitempty"`
+
+	// Currency the cart is priced in.
+	Currency string `json:"currency"`
+
+	Items []CartItem `json:"items"`
+
+	Subtotal common.Money `json:"subtotal"`
+
+	// Itemized discounts from auto-applied promotions and the coupon codes supplied.
+	Discounts []CartDiscount `json:"discounts,omitempty"`
+
+	DiscountTotal common.Money `json:"discount_total"`
+
+	// Tax for the customer's address. Anonymous carts are quoted without tax.
+	TaxTotal common.Money `json:"tax_total"`
+
+	ShippingMethod string `json:"shipping_method,omitempty"`
+
+	// Cost of the chosen shipping method, zero until one is chosen.
+	ShippingTotal common.Money `json:"shipping_total"`
+
+	// Amount payable, the subtotal less discounts plus tax and shipping.
+	Total common.Money `json:"total"`
+
+	// Coupon codes that were supplied but could not be applied.
+	RejectedCoupons []CouponRejection `json:"rejected_coupons,omitempty"`
+
+	// Problems found while re-validating prices and stock; checkout is refused while any remain.
+	Warnings []string `json:"warnings,omitempty"`
+
+	UpdatedAt string `json:"updated_at,omitempty"`
+}
+
+// AssertCartRequired checks if the required fields are not zero-ed
+func AssertCartRequired(obj Cart) error {
+	for _, el := range obj.Items {
+		if err := AssertCartItemRequired(el); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AssertCartConstraints checks if the values respects the defined constraints
+func AssertCartConstraints(obj Cart) error {
+	for _, el := range obj.Items {
+		if err := AssertCartItemConstraints(el); err != nil {
+			return err
+		}
+	}
+	return nil
+}
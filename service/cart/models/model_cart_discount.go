@@ -0,0 +1,25 @@
+package models
+
+import "github.com/mayureshucsb2019/bookstore/service/common"
+
+type CartDiscount struct {
+	// Promotion code that grants the discount.
+	Code string `json:"code"`
+
+	Description string `json:"description,omitempty"`
+
+	// Book the discount applies to.
+	Isbn string `json:"isbn"`
+
+	Amount common.Money `json:"amount"`
+}
+
+// AssertCartDiscountRequired checks if the required fields are not zero-ed
+func AssertCartDiscountRequired(obj CartDiscount) error {
+	return nil
+}
+
+// AssertCartDiscountConstraints checks if the values respects the defined constraints
+func AssertCartDiscountConstraints(obj CartDiscount) error {
+	return nil
+}
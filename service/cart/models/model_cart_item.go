@@ -0,0 +1,51 @@
+package models
+
+import (
+	"errors"
+
+	"github.com/mayureshucsb2019/bookstore/service/common"
+)
+
+type CartItem struct {
+	Isbn string `json:"isbn"`
+
+	Name string `json:"name,omitempty"`
+
+	Quantity int32 `json:"quantity"`
+
+	// Current catalog price, converted to the cart currency.
+	UnitPrice common.Money `json:"unit_price"`
+
+	LineTotal common.Money `json:"line_total"`
+
+	// Percentage of tax charged on the line after discounts.
+	TaxRate string `json:"tax_rate,omitempty"`
+
+	Tax common.Money `json:"tax"`
+
+	AvailableStock int32 `json:"available_stock"`
+
+	InStock bool `json:"in_stock"`
+}
+
+// AssertCartItemRequired checks if the required fields are not zero-ed
+func AssertCartItemRequired(obj CartItem) error {
+	elements := map[string]interface{}{
+		"isbn": obj.Isbn,
+	}
+	for name, el := range elements {
+		if isZero := common.IsZeroValue(el); isZero {
+			return &common.RequiredError{Field: name}
+		}
+	}
+
+	return nil
+}
+
+// AssertCartItemConstraints checks if the values respects the defined constraints
+func AssertCartItemConstraints(obj CartItem) error {
+	if obj.Quantity < 0 {
+		return &common.ParsingError{Param: "quantity", Err: errors.New("quantity cannot be negative")}
+	}
+	return nil
+}
@@ -0,0 +1,27 @@
+package models
+
+import "github.com/mayureshucsb2019/bookstore/service/common"
+
+type CartMergeRequest struct {
+	// Id of the anonymous cart to merge into the customer's cart.
+	CartId string `json:"cart_id"`
+}
+
+// AssertCartMergeRequestRequired checks if the required fields are not zero-ed
+func AssertCartMergeRequestRequired(obj CartMergeRequest) error {
+	elements := map[string]interface{}{
+		"cart_id": obj.CartId,
+	}
+	for name, el := range elements {
+		if isZero := common.IsZeroValue(el); isZero {
+			return &common.RequiredError{Field: name}
+		}
+	}
+
+	return nil
+}
+
+// AssertCartMergeRequestConstraints checks if the values respects the defined constraints
+func AssertCartMergeRequestConstraints(obj CartMergeRequest) error {
+	return nil
+}
@@ -0,0 +1,39 @@
+package models
+
+import (
+	"errors"
+
+	"github.com/mayureshucsb2019/bookstore/service/common"
+)
+
+type CheckoutRequest struct {
+	// Coupon codes the customer entered. Auto-applied promotions do not need to be listed.
+	CouponCodes []string `json:"coupon_codes,omitempty"`
+
+	// Code of the shipping method, required at checkout once shipping methods are configured.
+	ShippingMethod string `json:"shipping_method,omitempty"`
+
+	// Address book entry to ship to and charge tax for; the customer's default shipping address when omitted.
+	ShippingAddressId int64 `json:"shipping_address_id,omitempty"`
+
+	// Address book entry to bill; the customer's default billing address when omitted.
+	BillingAddressId int64 `json:"billing_address_id,omitempty"`
+
+	// Token identifying the customer's payment method at the payment provider, required at checkout.
+	PaymentToken string `json:"payment_token,omitempty"`
+}
+
+// AssertCheckoutRequestRequired checks if the required fields are not zero-ed
+func AssertCheckoutRequestRequired(obj CheckoutRequest) error {
+	return nil
+}
+
+// AssertCheckoutRequestConstraints checks if the values respects the defined constraints
+func AssertCheckoutRequestConstraints(obj CheckoutRequest) error {
+	for _, code := range obj.CouponCodes {
+		if code == "" {
+			return &common.ParsingError{Param: "coupon_codes", Err: errors.New("coupon codes cannot be empty")}
+		}
+	}
+	return nil
+}
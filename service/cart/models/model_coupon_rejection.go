@@ -0,0 +1,18 @@
+package models
+
+type CouponRejection struct {
+	Code string `json:"code"`
+
+	// Why the coupon was not applied.
+	Reason string `json:"reason"`
+}
+
+// AssertCouponRejectionRequired checks if the required fields are not zero-ed
+func AssertCouponRejectionRequired(obj CouponRejection) error {
+	return nil
+}
+
+// AssertCouponRejectionConstraints checks if the values respects the defined constraints
+func AssertCouponRejectionConstraints(obj CouponRejection) error {
+	return nil
+}
@@ -0,0 +1,42 @@
+package service
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/mayureshucsb2019/bookstore/service/cart/models"
+	"github.com/mayureshucsb2019/bookstore/service/common"
+)
+
+// DefaultAPIRouter defines the required methods for binding the api requests to a responses for the DefaultAPI
+// The DefaultAPIRouter implementation should parse necessary information from the http request,
+// pass the data to a DefaultAPIServicer to perform the required actions, then write the service results to the http response.
+type DefaultAPIRouter interface {
+	CartsCartIdCheckoutPost(http.ResponseWriter, *http.Request)
+	CartsCartIdDelete(http.ResponseWriter, *http.Request)
+	CartsCartIdGet(http.ResponseWriter, *http.Request)
+	CartsCartIdItemsIsbnDelete(http.ResponseWriter, *http.Request)
+	CartsCartIdItemsIsbnPut(http.ResponseWriter, *http.Request)
+	CartsCartIdItemsPost(http.ResponseWriter, *http.Request)
+	CartsCartIdQuotePost(http.ResponseWriter, *http.Request)
+	CartsPost(http.ResponseWriter, *http.Request)
+	CustomersEmailCartGet(http.ResponseWriter, *http.Request)
+	CustomersEmailCartMergePost(http.ResponseWriter, *http.Request)
+}
+
+// DefaultAPIServicer defines the api actions for the DefaultAPI service
+// This interface intended to stay up to date with the openapi yaml used to generate it,
+// while the service implementation can be ignored with the .openapi-generator-ignore file
+// and updated with the logic required for the API.
+type DefaultAPIServicer interface {
+	CartsCartIdCheckoutPost(context.Context, string, models.CheckoutRequest, string) (common.ImplResponse, error)
+	CartsCartIdDelete(context.Context, string) (common.ImplResponse, error)
+	CartsCartIdGet(context.Context, string, string) (common.ImplResponse, error)
+	CartsCartIdItemsIsbnDelete(context.Context, string, string, string) (common.ImplResponse, error)
+	CartsCartIdItemsIsbnPut(context.Context, string, string, models.CartItem, string) (common.ImplResponse, error)
+	CartsCartIdItemsPost(context.Context, string, models.CartItem, string) (common.ImplResponse, error)
+	CartsCartIdQuotePost(context.Context, string, models.CheckoutRequest, string) (common.ImplResponse, error)
+	CartsPost(context.Context, string) (common.ImplResponse, error)
+	CustomersEmailCartGet(context.Context, string, string) (common.ImplResponse, error)
+	CustomersEmailCartMergePost(context.Context, string, models.CartMergeRequest, string) (common.ImplResponse, error)
+}
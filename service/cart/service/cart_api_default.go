@@ -0,0 +1,404 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/mayureshucsb2019/bookstore/service/cart/models"
+	"github.com/mayureshucsb2019/bookstore/service/common"
+)
+
+// DefaultAPIController binds http requests to an api service and writes the service results to the http response
+type DefaultAPIController struct {
+	service      DefaultAPIServicer
+	errorHandler common.ErrorHandler
+}
+
+// DefaultAPIOption for how the controller is set up.
+type DefaultAPIOption func(*DefaultAPIController)
+
+// WithDefaultAPIErrorHandler inject ErrorHandler into controller
+func WithDefaultAPIErrorHandler(h common.ErrorHandler) DefaultAPIOption {
+	return func(c *DefaultAPIController) {
+		c.errorHandler = h
+	}
+}
+
+// NewDefaultAPIController creates a default api controller
+func NewDefaultAPIController(s DefaultAPIServicer, opts ...DefaultAPIOption) *DefaultAPIController {
+	controller := &DefaultAPIController{
+		service:      s,
+		errorHandler: common.DefaultErrorHandler,
+	}
+
+	for _, opt := range opts {
+		opt(controller)
+	}
+
+	return controller
+}
+
+// Routes returns all the api routes for the DefaultAPIController
+func (c *DefaultAPIController) Routes() common.Routes {
+	return common.Routes{
+		"CartsCartIdCheckoutPost": common.Route{
+			Method:      strings.ToUpper("Post"),
+			Pattern:     "/carts/{cartId}/checkout",
+			HandlerFunc: c.CartsCartIdCheckoutPost,
+		},
+		"CartsCartIdDelete": common.Route{
+			Method:      strings.ToUpper("Delete"),
+			Pattern:     "/carts/{cartId}",
+			HandlerFunc: c.CartsCartIdDelete,
+		},
+		"CartsCartIdGet": common.Route{
+			Method:      strings.ToUpper("Get"),
+			Pattern:     "/carts/{cartId}",
+			HandlerFunc: c.CartsCartIdGet,
+		},
+		"CartsCartIdItemsIsbnDelete": common.Route{
+			Method:      strings.ToUpper("Delete"),
+			Pattern:     "/carts/{cartId}/items/{isbn}",
+			HandlerFunc: c.CartsCartIdItemsIsbnDelete,
+		},
+		"CartsCartIdItemsIsbnPut": common.Route{
+			Method:      strings.ToUpper("Put"),
+			Pattern:     "/carts/{cartId}/items/{isbn}",
+			HandlerFunc: c.CartsCartIdItemsIsbnPut,
+		},
+		"CartsCartIdItemsPost": common.Route{
+			Method:      strings.ToUpper("Post"),
+			Pattern:     "/carts/{cartId}/items",
+			HandlerFunc: c.CartsCartIdItemsPost,
+		},
+		"CartsCartIdQuotePost": common.Route{
+			Method:      strings.ToUpper("Post"),
+			Pattern:     "/carts/{cartId}/quote",
+			HandlerFunc: c.CartsCartIdQuotePost,
+		},
+		"CartsPost": common.Route{
+			Method:      strings.ToUpper("Post"),
+			Pattern:     "/carts",
+			HandlerFunc: c.CartsPost,
+		},
+		"CustomersEmailCartGet": common.Route{
+			Method:      strings.ToUpper("Get"),
+			Pattern:     "/customers/{email}/cart",
+			HandlerFunc: c.CustomersEmailCartGet,
+		},
+		"CustomersEmailCartMergePost": common.Route{
+			Method:      strings.ToUpper("Post"),
+			Pattern:     "/customers/{email}/cart/merge",
+			HandlerFunc: c.CustomersEmailCartMergePost,
+		},
+	}
+}
+
+// CartsCartIdCheckoutPost - Convert a cart into an order
+func (c *DefaultAPIController) CartsCartIdCheckoutPost(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	cartIdParam := params["cartId"]
+	if cartIdParam == "" {
+		c.errorHandler(w, r, &common.RequiredError{Field: "cartId"}, nil)
+		return
+	}
+	checkoutRequestParam, ok := c.decodeCheckoutRequest(w, r)
+	if !ok {
+		return
+	}
+	currencyParam, err := common.ResolveCurrency(r)
+	if err != nil {
+		c.errorHandler(w, r, err, nil)
+		return
+	}
+	result, err := c.service.CartsCartIdCheckoutPost(r.Context(), cartIdParam, checkoutRequestParam, currencyParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	_ = common.EncodeJSONResponse(result.Body, &result.Code, w)
+}
+
+// CartsCartIdDelete - Delete a cart
+func (c *DefaultAPIController) CartsCartIdDelete(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	cartIdParam := params["cartId"]
+	if cartIdParam == "" {
+		c.errorHandler(w, r, &common.RequiredError{Field: "cartId"}, nil)
+		return
+	}
+	result, err := c.service.CartsCartIdDelete(r.Context(), cartIdParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	_ = common.EncodeJSONResponse(result.Body, &result.Code, w)
+}
+
+// CartsCartIdGet - Get a cart with current prices and stock
+func (c *DefaultAPIController) CartsCartIdGet(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	cartIdParam := params["cartId"]
+	if cartIdParam == "" {
+		c.errorHandler(w, r, &common.RequiredError{Field: "cartId"}, nil)
+		return
+	}
+	currencyParam, err := common.ResolveCurrency(r)
+	if err != nil {
+		c.errorHandler(w, r, err, nil)
+		return
+	}
+	result, err := c.service.CartsCartIdGet(r.Context(), cartIdParam, currencyParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	_ = common.EncodeJSONResponse(result.Body, &result.Code, w)
+}
+
+// CartsCartIdItemsIsbnDelete - Remove a book from a cart
+func (c *DefaultAPIController) CartsCartIdItemsIsbnDelete(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	cartIdParam := params["cartId"]
+	if cartIdParam == "" {
+		c.errorHandler(w, r, &common.RequiredError{Field: "cartId"}, nil)
+		return
+	}
+	isbnParam := params["isbn"]
+	if isbnParam == "" {
+		c.errorHandler(w, r, &common.RequiredError{Field: "isbn"}, nil)
+		return
+	}
+	currencyParam, err := common.ResolveCurrency(r)
+	if err != nil {
+		c.errorHandler(w, r, err, nil)
+		return
+	}
+	result, err := c.service.CartsCartIdItemsIsbnDelete(r.Context(), cartIdParam, isbnParam, currencyParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	_ = common.EncodeJSONResponse(result.Body, &result.Code, w)
+}
+
+// CartsCartIdItemsIsbnPut - Set the quantity of a book in a cart
+func (c *DefaultAPIController) CartsCartIdItemsIsbnPut(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	cartIdParam := params["cartId"]
+	if cartIdParam == "" {
+		c.errorHandler(w, r, &common.RequiredError{Field: "cartId"}, nil)
+		return
+	}
+	isbnParam := params["isbn"]
+	if isbnParam == "" {
+		c.errorHandler(w, r, &common.RequiredError{Field: "isbn"}, nil)
+		return
+	}
+	cartItemParam := models.CartItem{}
+	d := json.NewDecoder(r.Body)
+	d.DisallowUnknownFields()
+	if err := d.Decode(&cartItemParam); err != nil {
+		c.errorHandler(w, r, &common.ParsingError{Err: err}, nil)
+		return
+	}
+	if cartItemParam.Isbn == "" {
+		cartItemParam.Isbn = isbnParam
+	}
+	if err := models.AssertCartItemRequired(cartItemParam); err != nil {
+		c.errorHandler(w, r, err, nil)
+		return
+	}
+	if err := models.AssertCartItemConstraints(cartItemParam); err != nil {
+		c.errorHandler(w, r, err, nil)
+		return
+	}
+	currencyParam, err := common.ResolveCurrency(r)
+	if err != nil {
+		c.errorHandler(w, r, err, nil)
+		return
+	}
+	result, err := c.service.CartsCartIdItemsIsbnPut(r.Context(), cartIdParam, isbnParam, cartItemParam, currencyParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	_ = common.EncodeJSONResponse(result.Body, &result.Code, w)
+}
+
+// CartsCartIdItemsPost - Add a book to a cart
+func (c *DefaultAPIController) CartsCartIdItemsPost(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	cartIdParam := params["cartId"]
+	if cartIdParam == "" {
+		c.errorHandler(w, r, &common.RequiredError{Field: "cartId"}, nil)
+		return
+	}
+	cartItemParam := models.CartItem{}
+	d := json.NewDecoder(r.Body)
+	d.DisallowUnknownFields()
+	if err := d.Decode(&cartItemParam); err != nil {
+		c.errorHandler(w, r, &common.ParsingError{Err: err}, nil)
+		return
+	}
+	if err := models.AssertCartItemRequired(cartItemParam); err != nil {
+		c.errorHandler(w, r, err, nil)
+		return
+	}
+	if err := models.AssertCartItemConstraints(cartItemParam); err != nil {
+		c.errorHandler(w, r, err, nil)
+		return
+	}
+	currencyParam, err := common.ResolveCurrency(r)
+	if err != nil {
+		c.errorHandler(w, r, err, nil)
+		return
+	}
+	result, err := c.service.CartsCartIdItemsPost(r.Context(), cartIdParam, cartItemParam, currencyParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	_ = common.EncodeJSONResponse(result.Body, &result.Code, w)
+}
+
+// CartsCartIdQuotePost - Price a cart with promotions and coupon codes applied
+func (c *DefaultAPIController) CartsCartIdQuotePost(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	cartIdParam := params["cartId"]
+	if cartIdParam == "" {
+		c.errorHandler(w, r, &common.RequiredError{Field: "cartId"}, nil)
+		return
+	}
+	checkoutRequestParam, ok := c.decodeCheckoutRequest(w, r)
+	if !ok {
+		return
+	}
+	currencyParam, err := common.ResolveCurrency(r)
+	if err != nil {
+		c.errorHandler(w, r, err, nil)
+		return
+	}
+	result, err := c.service.CartsCartIdQuotePost(r.Context(), cartIdParam, checkoutRequestParam, currencyParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	_ = common.EncodeJSONResponse(result.Body, &result.Code, w)
+}
+
+// CartsPost - Create an anonymous cart
+func (c *DefaultAPIController) CartsPost(w http.ResponseWriter, r *http.Request) {
+	currencyParam, err := common.ResolveCurrency(r)
+	if err != nil {
+		c.errorHandler(w, r, err, nil)
+		return
+	}
+	result, err := c.service.CartsPost(r.Context(), currencyParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	_ = common.EncodeJSONResponse(result.Body, &result.Code, w)
+}
+
+// CustomersEmailCartGet - Get the customer's cart, creating it if needed
+func (c *DefaultAPIController) CustomersEmailCartGet(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	emailParam := params["email"]
+	if emailParam == "" {
+		c.errorHandler(w, r, &common.RequiredError{Field: "email"}, nil)
+		return
+	}
+	currencyParam, err := common.ResolveCurrency(r)
+	if err != nil {
+		c.errorHandler(w, r, err, nil)
+		return
+	}
+	result, err := c.service.CustomersEmailCartGet(r.Context(), emailParam, currencyParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	_ = common.EncodeJSONResponse(result.Body, &result.Code, w)
+}
+
+// CustomersEmailCartMergePost - Merge an anonymous cart into the customer's cart on login
+func (c *DefaultAPIController) CustomersEmailCartMergePost(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	emailParam := params["email"]
+	if emailParam == "" {
+		c.errorHandler(w, r, &common.RequiredError{Field: "email"}, nil)
+		return
+	}
+	cartMergeRequestParam := models.CartMergeRequest{}
+	d := json.NewDecoder(r.Body)
+	d.DisallowUnknownFields()
+	if err := d.Decode(&cartMergeRequestParam); err != nil {
+		c.errorHandler(w, r, &common.ParsingError{Err: err}, nil)
+		return
+	}
+	if err := models.AssertCartMergeRequestRequired(cartMergeRequestParam); err != nil {
+		c.errorHandler(w, r, err, nil)
+		return
+	}
+	if err := models.AssertCartMergeRequestConstraints(cartMergeRequestParam); err != nil {
+		c.errorHandler(w, r, err, nil)
+		return
+	}
+	currencyParam, err := common.ResolveCurrency(r)
+	if err != nil {
+		c.errorHandler(w, r, err, nil)
+		return
+	}
+	result, err := c.service.CustomersEmailCartMergePost(r.Context(), emailParam, cartMergeRequestParam, currencyParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	_ = common.EncodeJSONResponse(result.Body, &result.Code, w)
+}
+
+// decodeCheckoutRequest reads the optional checkout body. An empty body means no coupon codes.
+func (c *DefaultAPIController) decodeCheckoutRequest(w http.ResponseWriter, r *http.Request) (models.CheckoutRequest, bool) {
+	checkoutRequestParam := models.CheckoutRequest{}
+	d := json.NewDecoder(r.Body)
+	d.DisallowUnknownFields()
+	if err := d.Decode(&checkoutRequestParam); err != nil && !errors.Is(err, io.EOF) {
+		c.errorHandler(w, r, &common.ParsingError{Err: err}, nil)
+		return checkoutRequestParam, false
+	}
+	if err := models.AssertCheckoutRequestRequired(checkoutRequestParam); err != nil {
+		c.errorHandler(w, r, err, nil)
+		return checkoutRequestParam, false
+	}
+	if err := models.AssertCheckoutRequestConstraints(checkoutRequestParam); err != nil {
+		c.errorHandler(w, r, err, nil)
+		return checkoutRequestParam, false
+	}
+	return checkoutRequestParam, true
+}
@@ -0,0 +1,713 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	book_db "github.com/mayureshucsb2019/bookstore/service/book/db"
+	"github.com/mayureshucsb2019/bookstore/service/cart/db"
+	"github.com/mayureshucsb2019/bookstore/service/cart/models"
+	"github.com/mayureshucsb2019/bookstore/service/common"
+	customer_db "github.com/mayureshucsb2019/bookstore/service/customer/db"
+	exchange_db "github.com/mayureshucsb2019/bookstore/service/exchange/db"
+	order_db "github.com/mayureshucsb2019/bookstore/service/order/db"
+	order_service "github.com/mayureshucsb2019/bookstore/service/order/service"
+	"github.com/mayureshucsb2019/bookstore/service/payment"
+	promotion_db "github.com/mayureshucsb2019/bookstore/service/promotion/db"
+	promotion_service "github.com/mayureshucsb2019/bookstore/service/promotion/service"
+	shipping_db "github.com/mayureshucsb2019/bookstore/service/shipping/db"
+	"github.com/mayureshucsb2019/bookstore/service/tax"
+)
+
+// DefaultAPIService is a service that implements the logic for the DefaultAPIServicer
+// This service manages customer and anonymous carts and converts them into orders at checkout.
+type DefaultAPIService struct {
+	Repo       *db.CartRepository
+	Books      *book_db.BookRepository
+	Orders     *order_db.OrderRepository
+	Customers  *customer_db.CustomerRepository
+	Rates      *exchange_db.ExchangeRateRepository
+	Promotions *promotion_db.PromotionRepository
+	Shipping   *shipping_db.ShippingMethodRepository
+	Tax        tax.Calculator
+	Payments   *payment.Processor
+}
+
+// NewDefaultAPIService creates a default API service with the given repositories.
+func NewDefaultAPIService(repo *db.CartRepository, books *book_db.BookRepository, orders *order_db.OrderRepository,
+	customers *customer_db.CustomerRepository, rates *exchange_db.ExchangeRateRepository,
+	promotions *promotion_db.PromotionRepository, shipping *shipping_db.ShippingMethodRepository,
+	taxCalculator tax.Calculator, payments *payment.Processor) *DefaultAPIService {
+	return &DefaultAPIService{
+		Repo:       repo,
+		Books:      books,
+		Orders:     orders,
+		Customers:  customers,
+		Rates:      rates,
+		Promotions: promotions,
+		Shipping:   shipping,
+		Tax:        taxCalculator,
+		Payments:   payments,
+	}
+}
+
+// StartExpiryWorker deletes carts that have been inactive for longer than ttl, checking every interval.
+// The returned function stops the worker.
+func (s *DefaultAPIService) StartExpiryWorker(interval time.Duration, ttl time.Duration) func() {
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				removed, err := s.Repo.DeleteExpiredCarts(ttl)
+				if err != nil {
+					log.Printf("Failed to expire abandoned carts: %v", err)
+					continue
+				}
+				if removed > 0 {
+					log.Printf("Expired %d abandoned carts", removed)
+				}
+			}
+		}
+	}()
+	return func() { close(done) }
+}
+
+// CartsCartIdCheckoutPost - Convert a cart into an order
+func (s *DefaultAPIService) CartsCartIdCheckoutPost(ctx context.Context, cartId string, request models.CheckoutRequest, currency string) (common.ImplResponse, error) {
+	cart, err := s.Repo.GetCartByID(cartId)
+	if err != nil {
+		return cartErrorResponse(err)
+	}
+	if cart.CustomerEmail == "" {
+		return common.Response(http.StatusConflict, nil), errors.New("anonymous carts must be merged into a customer cart before checkout")
+	}
+	if len(cart.Items) == 0 {
+		return common.Response(http.StatusUnprocessableEntity, nil), errors.New("cart is empty")
+	}
+	if request.PaymentToken == "" {
+		return common.Response(http.StatusUnprocessableEntity, nil), &common.RequiredError{Field: "payment_token"}
+	}
+
+	quote, err := s.quoteCart(ctx, cart, currency, request)
+	if err != nil {
+		return quoteErrorResponse(err)
+	}
+	if len(quote.cart.Warnings) > 0 {
+		return common.Response(http.StatusConflict, nil), fmt.Errorf("cart cannot be checked out: %s", strings.Join(quote.cart.Warnings, "; "))
+	}
+	if len(quote.cart.RejectedCoupons) > 0 {
+		var reasons []string
+		for _, rejection := range quote.cart.RejectedCoupons {
+			reasons = append(reasons, fmt.Sprintf("%s: %s", rejection.Code, rejection.Reason))
+		}
+		return common.Response(http.StatusUnprocessableEntity, nil), fmt.Errorf("coupon codes cannot be applied: %s", strings.Join(reasons, "; "))
+	}
+	if request.ShippingMethod == "" {
+		methods, err := s.Shipping.GetAllShippingMethods(true)
+		if err != nil {
+			return common.Response(http.StatusInternalServerError, nil), err
+		}
+		if len(methods) > 0 {
+			return common.Response(http.StatusUnprocessableEntity, nil), &common.RequiredError{Field: "shipping_method"}
+		}
+	}
+
+	var address order_db.ShippingAddress
+	if quote.shippingAddress != nil {
+		address = order_db.ShippingAddress{
+			UnitNo:     common.StringOrEmpty(quote.shippingAddress.UnitNo),
+			StreetName: common.StringOrEmpty(quote.shippingAddress.StreetName),
+			City:       common.StringOrEmpty(quote.shippingAddress.City),
+			State:      common.StringOrEmpty(quote.shippingAddress.State),
+			Country:    common.StringOrEmpty(quote.shippingAddress.Country),
+			Zipcode:    common.StringOrEmpty(quote.shippingAddress.Zipcode),
+			Landmark:   common.StringOrEmpty(quote.shippingAddress.Landmark),
+		}
+	}
+	if quote.shippingMethod != "" && (address.StreetName == "" || address.City == "" || address.Country == "") {
+		return common.Response(http.StatusUnprocessableEntity, nil), errors.New("shipping address needs a street name, city and country before the order can be shipped")
+	}
+
+	order := order_db.Order{
+		CustomerEmail:   cart.CustomerEmail,
+		TotalAmount:     common.Money{Currency: quote.cart.Currency},
+		ShippingAmount:  quote.cart.ShippingTotal,
+		ExchangeRate:    quote.exchangeRate,
+		SourceCurrency:  quote.sourceCurrency,
+		ShippingMethod:  quote.shippingMethod,
+		ShippingAddress: address,
+		Items:           quote.items,
+		Discounts:       quote.discounts,
+	}
+	if quote.shippingAddress != nil {
+		order.ShippingAddressID = quote.shippingAddress.ID
+	}
+	if quote.billingAddress != nil {
+		order.BillingAddressID = quote.billingAddress.ID
+	}
+	if err := s.Orders.CreateOrder(&order); err != nil {
+		var stockErr *order_db.InsufficientStockError
+		if errors.As(err, &stockErr) {
+			return common.Response(http.StatusConflict, nil), err
+		}
+		if errors.Is(err, common.ErrCurrencyMismatch) {
+			return common.Response(http.StatusUnprocessableEntity, nil), err
+		}
+		if errors.Is(err, order_db.ErrCustomerBlocked) {
+			return common.Response(http.StatusForbidden, nil), err
+		}
+		if errors.Is(err, order_db.ErrPromotionUsageExceeded) {
+			return common.Response(http.StatusConflict, nil), err
+		}
+		return common.Response(http.StatusInternalServerError, nil), err
+	}
+
+	if resp, err := s.chargeOrder(ctx, &order, request.PaymentToken); err != nil {
+		return resp, err
+	}
+
+	if err := s.Repo.ClearCart(cart.ID); err != nil {
+		log.Printf("Order %d placed but cart %s could not be cleared: %v", order.ID, cart.ID, err)
+	}
+
+	placed, err := s.Orders.GetOrderByID(order.ID)
+	if err != nil {
+		return common.Response(http.StatusInternalServerError, nil), err
+	}
+
+	return common.Response(http.StatusCreated, order_service.ConvertDBToAPIResponse(*placed)), nil
+}
+
+// CartsCartIdDelete - Delete a cart
+func (s *DefaultAPIService) CartsCartIdDelete(ctx context.Context, cartId string) (common.ImplResponse, error) {
+	if err := s.Repo.DeleteCart(cartId); err != nil {
+		return cartErrorResponse(err)
+	}
+
+	return common.Response(http.StatusNoContent, nil), nil
+}
+
+// CartsCartIdGet - Get a cart with current prices and stock
+func (s *DefaultAPIService) CartsCartIdGet(ctx context.Context, cartId string, currency string) (common.ImplResponse, error) {
+	return s.cartResponse(ctx, http.StatusOK, cartId, currency)
+}
+
+// CartsCartIdItemsIsbnDelete - Remove a book from a cart
+func (s *DefaultAPIService) CartsCartIdItemsIsbnDelete(ctx context.Context, cartId string, isbn string, currency string) (common.ImplResponse, error) {
+	if _, err := s.Repo.GetCartByID(cartId); err != nil {
+		return cartErrorResponse(err)
+	}
+	if err := s.Repo.RemoveCartItem(cartId, isbn); err != nil {
+		return common.Response(http.StatusInternalServerError, nil), err
+	}
+
+	return s.cartResponse(ctx, http.StatusOK, cartId, currency)
+}
+
+// CartsCartIdItemsIsbnPut - Set the quantity of a book in a cart
+func (s *DefaultAPIService) CartsCartIdItemsIsbnPut(ctx context.Context, cartId string, isbn string, item models.CartItem, currency string) (common.ImplResponse, error) {
+	if item.Isbn != isbn {
+		return common.Response(http.StatusBadRequest, nil), errors.New("isbn in the path does not match isbn in the body")
+	}
+	if resp, err := s.checkCartAndBook(cartId, isbn); err != nil {
+		return resp, err
+	}
+	if err := s.Repo.SetCartItem(cartId, isbn, int(item.Quantity)); err != nil {
+		return common.Response(http.StatusInternalServerError, nil), err
+	}
+
+	return s.cartResponse(ctx, http.StatusOK, cartId, currency)
+}
+
+// CartsCartIdItemsPost - Add a book to a cart
+func (s *DefaultAPIService) CartsCartIdItemsPost(ctx context.Context, cartId string, item models.CartItem, currency string) (common.ImplResponse, error) {
+	if item.Quantity == 0 {
+		item.Quantity = 1
+	}
+	if resp, err := s.checkCartAndBook(cartId, item.Isbn); err != nil {
+		return resp, err
+	}
+	if err := s.Repo.AddCartItem(cartId, item.Isbn, int(item.Quantity)); err != nil {
+		return common.Response(http.StatusInternalServerError, nil), err
+	}
+
+	return s.cartResponse(ctx, http.StatusOK, cartId, currency)
+}
+
+// CartsCartIdQuotePost - Price a cart with promotions and coupon codes applied
+func (s *DefaultAPIService) CartsCartIdQuotePost(ctx context.Context, cartId string, request models.CheckoutRequest, currency string) (common.ImplResponse, error) {
+	cart, err := s.Repo.GetCartByID(cartId)
+	if err != nil {
+		return cartErrorResponse(err)
+	}
+	quote, err := s.quoteCart(ctx, cart, currency, request)
+	if err != nil {
+		return quoteErrorResponse(err)
+	}
+
+	return common.Response(http.StatusOK, quote.cart), nil
+}
+
+// CartsPost - Create an anonymous cart
+func (s *DefaultAPIService) CartsPost(ctx context.Context, currency string) (common.ImplResponse, error) {
+	var cart db.Cart
+	if err := s.Repo.CreateCart(&cart); err != nil {
+		return common.Response(http.StatusInternalServerError, nil), err
+	}
+
+	return s.cartResponse(ctx, http.StatusCreated, cart.ID, currency)
+}
+
+// CustomersEmailCartGet - Get the customer's cart, creating it if needed
+func (s *DefaultAPIService) CustomersEmailCartGet(ctx context.Context, email string, currency string) (common.ImplResponse, error) {
+	if _, err := s.Customers.GetCustomerByID(email); err != nil {
+		return common.Response(http.StatusNotFound, nil), err
+	}
+
+	cart, err := s.customerCart(email)
+	if err != nil {
+		return common.Response(http.StatusInternalServerError, nil), err
+	}
+
+	return s.cartResponse(ctx, http.StatusOK, cart.ID, currency)
+}
+
+// CustomersEmailCartMergePost - Merge an anonymous cart into the customer's cart on login
+func (s *DefaultAPIService) CustomersEmailCartMergePost(ctx context.Context, email string, request models.CartMergeRequest, currency string) (common.ImplResponse, error) {
+	if _, err := s.Customers.GetCustomerByID(email); err != nil {
+		return common.Response(http.StatusNotFound, nil), err
+	}
+
+	source, err := s.Repo.GetCartByID(request.CartId)
+	if err != nil {
+		return cartErrorResponse(err)
+	}
+	if source.CustomerEmail != "" && source.CustomerEmail != email {
+		return common.Response(http.StatusConflict, nil), errors.New("cart belongs to another customer")
+	}
+
+	target, err := s.Repo.GetCartByCustomer(email)
+	if err != nil {
+		return common.Response(http.StatusInternalServerError, nil), err
+	}
+	switch {
+	case target == nil:
+		// The customer has no cart yet, so the anonymous cart simply becomes theirs
+		if err := s.Repo.AssignCustomer(source.ID, email); err != nil {
+			return common.Response(http.StatusInternalServerError, nil), err
+		}
+		return s.cartResponse(ctx, http.StatusOK, source.ID, currency)
+	case target.ID != source.ID:
+		if err := s.Repo.MergeCarts(source.ID, target.ID); err != nil {
+			return common.Response(http.StatusInternalServerError, nil), err
+		}
+	}
+
+	return s.cartResponse(ctx, http.StatusOK, target.ID, currency)
+}
+
+// customerCart returns the customer's cart, creating an empty one if they have none.
+func (s *DefaultAPIService) customerCart(email string) (*db.Cart, error) {
+	cart, err := s.Repo.GetCartByCustomer(email)
+	if err != nil || cart != nil {
+		return cart, err
+	}
+
+	cart = &db.Cart{CustomerEmail: email}
+	if err := s.Repo.CreateCart(cart); err != nil {
+		return nil, err
+	}
+	return cart, nil
+}
+
+// checkCartAndBook verifies that both the cart and the book exist before the cart is modified.
+func (s *DefaultAPIService) checkCartAndBook(cartId string, isbn string) (common.ImplResponse, error) {
+	if _, err := s.Repo.GetCartByID(cartId); err != nil {
+		return cartErrorResponse(err)
+	}
+	book, err := s.Books.GetBookByISBN(isbn)
+	if err != nil {
+		return common.Response(http.StatusInternalServerError, nil), err
+	}
+	if book == nil {
+		return common.Response(http.StatusNotFound, nil), fmt.Errorf("book %s not found", isbn)
+	}
+	return common.ImplResponse{}, nil
+}
+
+// cartResponse loads the cart, re-validates it and returns it with the given status code.
+func (s *DefaultAPIService) cartResponse(ctx context.Context, code int, cartId string, currency string) (common.ImplResponse, error) {
+	cart, err := s.Repo.GetCartByID(cartId)
+	if err != nil {
+		return cartErrorResponse(err)
+	}
+	quote, err := s.quoteCart(ctx, cart, currency, models.CheckoutRequest{})
+	if err != nil {
+		return quoteErrorResponse(err)
+	}
+
+	return common.Response(code, quote.cart), nil
+}
+
+// cartQuote is a cart priced against the live catalog, ready to be turned into an order.
+type cartQuote struct {
+	cart           models.Cart
+	items          []order_db.OrderItem
+	lines          []promotion_service.Line
+	discounts      []order_db.OrderDiscount
+	pages          int // Total page count, the weight proxy for shipping rates
+	shippingMethod string
+	exchangeRate   string
+	sourceCurrency string
+	// Addresses from the customer's address book, nil for anonymous carts and customers without one
+	shippingAddress *customer_db.Address
+	billingAddress  *customer_db.Address
+}
+
+// quoteCart prices the cart and applies promotions, tax and the chosen shipping method.
+func (s *DefaultAPIService) quoteCart(ctx context.Context, cart *db.Cart, currency string, request models.CheckoutRequest) (*cartQuote, error) {
+	quote, err := s.priceCart(cart, currency)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.applyPromotions(quote, request.CouponCodes); err != nil {
+		return nil, err
+	}
+	if err := s.resolveAddresses(quote, request.ShippingAddressId, request.BillingAddressId); err != nil {
+		return nil, err
+	}
+	if err := s.applyTax(ctx, quote); err != nil {
+		return nil, err
+	}
+	if err := s.applyShipping(quote, request.ShippingMethod); err != nil {
+		return nil, err
+	}
+	return quote, nil
+}
+
+// priceCart re-reads the price and stock of every book in the cart and converts prices into
+// the requested currency. Problems that would prevent checkout are reported as warnings.
+func (s *DefaultAPIService) priceCart(cart *db.Cart, currency string) (*cartQuote, error) {
+	if currency == "" {
+		currency = common.DefaultCurrency
+	}
+	quote := &cartQuote{
+		cart: models.Cart{
+			Id:            cart.ID,
+			CustomerEmail: cart.CustomerEmail,
+			Currency:      currency,
+			Items:         []models.CartItem{},
+			UpdatedAt:     cart.UpdatedAt,
+		},
+	}
+
+	var rates *common.RateTable
+	sourceCurrencies := map[string]string{}
+	for _, item := range cart.Items {
+		book, err := s.Books.GetBookByISBN(item.ISBN)
+		if err != nil {
+			return nil, err
+		}
+		if book == nil {
+			quote.cart.Warnings = append(quote.cart.Warnings, fmt.Sprintf("book %s is no longer available", item.ISBN))
+			continue
+		}
+
+		unitPrice := book.Cost
+		if unitPrice.Currency != currency {
+			if rates == nil {
+				if rates, err = s.Rates.GetRateTable(); err != nil {
+					return nil, err
+				}
+			}
+			converted, rate, err := rates.Convert(unitPrice, currency)
+			if err != nil {
+				quote.cart.Warnings = append(quote.cart.Warnings, fmt.Sprintf("book %s cannot be priced in %s: %v", item.ISBN, currency, err))
+				continue
+			}
+			unitPrice = converted
+			sourceCurrencies[book.Cost.Currency] = common.FormatRate(rate)
+		}
+		if book.Stock < item.Quantity {
+			quote.cart.Warnings = append(quote.cart.Warnings, fmt.Sprintf("only %d copies of book %s are available", book.Stock, item.ISBN))
+		}
+
+		orderItem := order_db.OrderItem{ISBN: item.ISBN, Quantity: item.Quantity, UnitPrice: unitPrice}
+		quote.items = append(quote.items, orderItem)
+		quote.pages += book.NumberOfPages * item.Quantity
+		quote.lines = append(quote.lines, promotion_service.Line{ISBN: item.ISBN, Tags: book.Tags, Quantity: item.Quantity, UnitPrice: unitPrice})
+		quote.cart.Items = append(quote.cart.Items, models.CartItem{
+			Isbn:           item.ISBN,
+			Name:           book.Name,
+			Quantity:       int32(item.Quantity),
+			UnitPrice:      unitPrice,
+			LineTotal:      orderItem.LineTotal(),
+			AvailableStock: int32(book.Stock),
+			InStock:        book.Stock >= item.Quantity,
+		})
+	}
+
+	// An order records a single exchange rate, so it can only be converted from one catalog currency
+	if len(sourceCurrencies) > 1 {
+		quote.cart.Warnings = append(quote.cart.Warnings, "cart contains books priced in more than one currency")
+	}
+	for source, rate := range sourceCurrencies {
+		quote.sourceCurrency, quote.exchangeRate = source, rate
+	}
+
+	subtotal, err := order_db.ComputeTotal(currency, quote.items)
+	if err != nil {
+		return nil, err
+	}
+	quote.cart.Subtotal = subtotal
+	quote.cart.DiscountTotal = common.Money{Currency: currency}
+	quote.cart.TaxTotal = common.Money{Currency: currency}
+	quote.cart.ShippingTotal = common.Money{Currency: currency}
+	quote.cart.Total = subtotal
+
+	return quote, nil
+}
+
+// applyPromotions evaluates the auto-applied promotions and the given coupon codes against the
+// priced cart and records the itemized discounts and the discounted total on the quote.
+func (s *DefaultAPIService) applyPromotions(quote *cartQuote, codes []string) error {
+	var requested []string
+	seen := map[string]bool{}
+	for _, code := range codes {
+		if !seen[code] {
+			seen[code] = true
+			requested = append(requested, code)
+		}
+	}
+
+	promotions, err := s.Promotions.GetApplicablePromotions(requested)
+	if err != nil {
+		return err
+	}
+
+	usage := map[string]int{}
+	found := map[string]bool{}
+	for _, promotion := range promotions {
+		found[promotion.Code] = true
+		if promotion.UsageLimitPerCustomer > 0 && quote.cart.CustomerEmail != "" {
+			if usage[promotion.Code], err = s.Promotions.CountCustomerUsage(promotion.Code, quote.cart.CustomerEmail); err != nil {
+				return err
+			}
+		}
+	}
+
+	evaluation, err := promotion_service.Evaluate(promotions, quote.lines, usage, requested, quote.cart.Currency, time.Now())
+	if err != nil {
+		return err
+	}
+
+	for _, code := range requested {
+		if !found[code] {
+			quote.cart.RejectedCoupons = append(quote.cart.RejectedCoupons, models.CouponRejection{Code: code, Reason: "unknown coupon code"})
+		}
+	}
+	for _, rejection := range evaluation.Rejected {
+		quote.cart.RejectedCoupons = append(quote.cart.RejectedCoupons, models.CouponRejection{Code: rejection.Code, Reason: rejection.Reason})
+	}
+
+	quote.discounts = nil
+	quote.cart.Discounts = nil
+	for _, discount := range evaluation.Discounts {
+		quote.discounts = append(quote.discounts, order_db.OrderDiscount{
+			PromotionCode: discount.Code,
+			Description:   discount.Description,
+			ISBN:          discount.Isbn,
+			Amount:        discount.Amount,
+		})
+		quote.cart.Discounts = append(quote.cart.Discounts, models.CartDiscount{
+			Code:        discount.Code,
+			Description: discount.Description,
+			Isbn:        discount.Isbn,
+			Amount:      discount.Amount,
+		})
+	}
+
+	total, err := quote.cart.Subtotal.Sub(evaluation.Total)
+	if err != nil {
+		return err
+	}
+	quote.cart.DiscountTotal = evaluation.Total
+	quote.cart.Total = total
+	return nil
+}
+
+// resolveAddresses picks the shipping and billing addresses of the quote from the customer's
+// address book: the requested ones, or else the customer's defaults. An id the customer has no
+// address with fails with customer_db.ErrAddressNotFound.
+func (s *DefaultAPIService) resolveAddresses(quote *cartQuote, shippingID int64, billingID int64) error {
+	if quote.cart.CustomerEmail == "" {
+		return nil
+	}
+	addresses, err := s.Customers.GetAddresses(quote.cart.CustomerEmail)
+	if err != nil {
+		return err
+	}
+	pick := func(id int64, isDefault func(address *customer_db.Address) bool) (*customer_db.Address, error) {
+		for i := range addresses {
+			if (id != 0 && addresses[i].ID == id) || (id == 0 && isDefault(&addresses[i])) {
+				return &addresses[i], nil
+			}
+		}
+		if id != 0 {
+			return nil, fmt.Errorf("%w: %d", customer_db.ErrAddressNotFound, id)
+		}
+		return nil, nil
+	}
+	if quote.shippingAddress, err = pick(shippingID, func(a *customer_db.Address) bool { return a.DefaultShipping }); err != nil {
+		return err
+	}
+	if quote.billingAddress, err = pick(billingID, func(a *customer_db.Address) bool { return a.DefaultBilling }); err != nil {
+		return err
+	}
+	return nil
+}
+
+// applyTax charges tax on each line after discounts according to the shipping address and adds
+// it to the quote total. Anonymous carts have no address yet and are quoted without tax.
+func (s *DefaultAPIService) applyTax(ctx context.Context, quote *cartQuote) error {
+	if s.Tax == nil || quote.cart.CustomerEmail == "" || len(quote.items) == 0 {
+		return nil
+	}
+	var address tax.Address
+	if quote.shippingAddress != nil {
+		address = tax.Address{
+			Country: common.StringOrEmpty(quote.shippingAddress.Country),
+			State:   common.StringOrEmpty(quote.shippingAddress.State),
+			Zipcode: common.StringOrEmpty(quote.shippingAddress.Zipcode),
+		}
+	}
+
+	discounted := map[string]int64{}
+	for _, discount := range quote.discounts {
+		discounted[discount.ISBN] += discount.Amount.Amount
+	}
+	lines := make([]tax.Line, 0, len(quote.items))
+	for i, item := range quote.items {
+		amount := item.LineTotal()
+		amount.Amount -= discounted[item.ISBN]
+		lines = append(lines, tax.Line{ISBN: item.ISBN, Tags: quote.lines[i].Tags, Amount: amount})
+	}
+
+	taxes, err := s.Tax.Calculate(ctx, address, lines)
+	if err != nil {
+		return err
+	}
+	if len(taxes) != len(lines) {
+		return fmt.Errorf("tax calculator returned %d lines for %d order lines", len(taxes), len(lines))
+	}
+
+	taxTotal := common.Money{Currency: quote.cart.Currency}
+	for i, lineTax := range taxes {
+		quote.items[i].TaxRate, quote.items[i].Tax = lineTax.Rate, lineTax.Amount
+		quote.cart.Items[i].TaxRate, quote.cart.Items[i].Tax = lineTax.Rate, lineTax.Amount
+		if taxTotal, err = taxTotal.Add(lineTax.Amount); err != nil {
+			return err
+		}
+	}
+	total, err := quote.cart.Total.Add(taxTotal)
+	if err != nil {
+		return err
+	}
+	quote.cart.TaxTotal, quote.cart.Total = taxTotal, total
+	return nil
+}
+
+// applyShipping adds the cost of the shipping method to the quote, converted into the cart currency.
+func (s *DefaultAPIService) applyShipping(quote *cartQuote, code string) error {
+	if code == "" {
+		return nil
+	}
+	method, err := s.Shipping.GetShippingMethodByCode(code)
+	if err != nil {
+		return err
+	}
+	if !method.Active {
+		return fmt.Errorf("%w: %s is not available", shipping_db.ErrShippingMethodNotFound, code)
+	}
+
+	rate := method.Rate(quote.pages)
+	if rate.Currency != quote.cart.Currency {
+		rates, err := s.Rates.GetRateTable()
+		if err != nil {
+			return err
+		}
+		if rate, _, err = rates.Convert(rate, quote.cart.Currency); err != nil {
+			return err
+		}
+	}
+
+	total, err := quote.cart.Total.Add(rate)
+	if err != nil {
+		return err
+	}
+	quote.shippingMethod = method.Code
+	quote.cart.ShippingMethod, quote.cart.ShippingTotal, quote.cart.Total = method.Code, rate, total
+	return nil
+}
+
+// chargeOrder captures payment for a placed order and marks it paid, returning the error response
+// when it could not. When the payment fails, or the order cannot be marked paid and the capture is
+// refunded, the order is cancelled, which puts its stock back, and the cart is left as it was so
+// the customer can retry.
+func (s *DefaultAPIService) chargeOrder(ctx context.Context, order *order_db.Order, paymentToken string) (common.ImplResponse, error) {
+	var recordErr error
+	markPaid := func() error {
+		recordErr = s.Orders.TransitionOrder(order.ID, order_db.StatusPaid, "payment captured")
+		return recordErr
+	}
+	var err error
+	if order.TotalAmount.Amount == 0 {
+		err = markPaid()
+	} else {
+		err = s.Payments.ChargeAndRecord(ctx, order.ID, order.TotalAmount, paymentToken, markPaid)
+	}
+	if err == nil {
+		return common.Response(http.StatusCreated, nil), nil
+	}
+
+	// An order the customer is still charged for stays placed, so the capture can be found and refunded
+	if errors.Is(err, payment.ErrChargeNotReversed) {
+		log.Printf("Order %d could not be marked paid and its payment could not be refunded: %v", order.ID, err)
+	} else if cancelErr := s.Orders.TransitionOrder(order.ID, order_db.StatusCancelled, "payment failed"); cancelErr != nil {
+		log.Printf("Payment for order %d failed but the order could not be cancelled: %v", order.ID, cancelErr)
+	}
+	switch {
+	case errors.Is(err, payment.ErrDeclined):
+		return common.Response(http.StatusPaymentRequired, nil), err
+	case recordErr != nil:
+		return common.Response(http.StatusInternalServerError, nil), err
+	}
+	return common.Response(http.StatusBadGateway, nil), err
+}
+
+// quoteErrorResponse maps errors from quoting a cart to an unprocessable or internal error response.
+func quoteErrorResponse(err error) (common.ImplResponse, error) {
+	if errors.Is(err, shipping_db.ErrShippingMethodNotFound) || errors.Is(err, common.ErrRateNotFound) ||
+		errors.Is(err, customer_db.ErrAddressNotFound) {
+		return common.Response(http.StatusUnprocessableEntity, nil), err
+	}
+	return common.Response(http.StatusInternalServerError, nil), err
+}
+
+// cartErrorResponse maps repository errors to a not found or internal error response.
+func cartErrorResponse(err error) (common.ImplResponse, error) {
+	if errors.Is(err, db.ErrCartNotFound) {
+		return common.Response(http.StatusNotFound, nil), err
+	}
+	return common.Response(http.StatusInternalServerError, nil), err
+}
@@ -0,0 +1,163 @@
+package common
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+)
+
+// ErrRateNotFound is returned when no exchange rate is configured between two currencies
+var ErrRateNotFound = errors.New("exchange rate not found")
+
+// RateScale is the number of decimal places exchange rates are stored and reported with.
+const RateScale = 10
+
+// RateTable holds exchange rates keyed by base and quote currency. One unit of the base
+// currency buys Rate units of the quote currency.
+type RateTable struct {
+	rates map[[2]string]*big.Rat
+}
+
+// NewRateTable creates an empty rate table.
+func NewRateTable() *RateTable {
+	return &RateTable{rates: map[[2]string]*big.Rat{}}
+}
+
+// ParseRate parses a positive decimal exchange rate such as "0.9231".
+func ParseRate(rate string) (*big.Rat, error) {
+	r, ok := new(big.Rat).SetString(strings.TrimSpace(rate))
+	if !ok || r.Sign() <= 0 {
+		return nil, fmt.Errorf("invalid exchange rate %q", rate)
+	}
+	return r, nil
+}
+
+// FormatRate formats an exchange rate as a decimal string with RateScale places.
+func FormatRate(rate *big.Rat) string {
+	return rate.FloatString(RateScale)
+}
+
+// Set adds or replaces the rate from base to quote.
+func (t *RateTable) Set(base, quote string, rate *big.Rat) {
+	t.rates[[2]string{base, quote}] = rate
+}
+
+// Lookup finds the rate from one currency to another. A direct rate is preferred, then the
+// inverse of the opposite rate, then a cross rate through DefaultCurrency.
+func (t *RateTable) Lookup(from, to string) (*big.Rat, error) {
+	if from == to {
+		return big.NewRat(1, 1), nil
+	}
+	if rate, ok := t.pair(from, to); ok {
+		return rate, nil
+	}
+	if from != DefaultCurrency && to != DefaultCurrency {
+		toBase, okFrom := t.pair(from, DefaultCurrency)
+		fromBase, okTo := t.pair(DefaultCurrency, to)
+		if okFrom && okTo {
+			return new(big.Rat).Mul(toBase, fromBase), nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s to %s", ErrRateNotFound, from, to)
+}
+
+// pair returns the direct rate or the inverse of the opposite rate.
+func (t *RateTable) pair(from, to string) (*big.Rat, bool) {
+	if rate, ok := t.rates[[2]string{from, to}]; ok {
+		return rate, true
+	}
+	if rate, ok := t.rates[[2]string{to, from}]; ok {
+		return new(big.Rat).Inv(rate), true
+	}
+	return nil, false
+}
+
+// Convert converts the amount into the target currency and returns the rate that was applied.
+func (t *RateTable) Convert(amount Money, to string) (Money, *big.Rat, error) {
+	rate, err := t.Lookup(amount.Currency, to)
+	if err != nil {
+		return Money{}, nil, err
+	}
+	converted, err := ConvertMoney(amount, rate, to)
+	if err != nil {
+		return Money{}, nil, err
+	}
+	return converted, rate, nil
+}
+
+// ConvertMoney multiplies the amount by rate and rounds the result to the minor unit of the
+// target currency. Rounding is half to even (banker's rounding) so repeated conversions do
+// not drift upwards, and it is applied exactly once on the final amount.
+func ConvertMoney(amount Money, rate *big.Rat, to string) (Money, error) {
+	fromExp, err := CurrencyExponent(amount.Currency)
+	if err != nil {
+		return Money{}, err
+	}
+	toExp, err := CurrencyExponent(to)
+	if err != nil {
+		return Money{}, err
+	}
+
+	value := new(big.Rat).SetInt64(amount.Amount)
+	value.Mul(value, rate)
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(absInt(toExp-fromExp))), nil)
+	if toExp >= fromExp {
+		value.Mul(value, new(big.Rat).SetInt(scale))
+	} else {
+		value.Quo(value, new(big.Rat).SetInt(scale))
+	}
+
+	rounded := roundHalfEven(value)
+	if !rounded.IsInt64() {
+		return Money{}, fmt.Errorf("converted amount overflows %s", to)
+	}
+	return Money{Amount: rounded.Int64(), Currency: to}, nil
+}
+
+// roundHalfEven rounds a rational number to the nearest integer, ties going to the even neighbour.
+func roundHalfEven(value *big.Rat) *big.Int {
+	quo, rem := new(big.Int).QuoRem(value.Num(), value.Denom(), new(big.Int))
+	if rem.Sign() == 0 {
+		return quo
+	}
+
+	twiceRem := new(big.Int).Mul(new(big.Int).Abs(rem), big.NewInt(2))
+	cmp := twiceRem.Cmp(value.Denom())
+	if cmp > 0 || (cmp == 0 && quo.Bit(0) == 1) {
+		if value.Sign() < 0 {
+			quo.Sub(quo, big.NewInt(1))
+		} else {
+			quo.Add(quo, big.NewInt(1))
+		}
+	}
+	return quo
+}
+
+func absInt(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+// ResolveCurrency returns the display currency requested by the client. The `currency` query
+// parameter takes precedence over the Accept-Currency header; an empty string means the
+// stored currency should be used unchanged.
+func ResolveCurrency(r *http.Request) (string, error) {
+	currency := r.URL.Query().Get("currency")
+	param := "currency"
+	if currency == "" {
+		currency = r.Header.Get("Accept-Currency")
+		param = "Accept-Currency"
+	}
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	if currency == "" {
+		return "", nil
+	}
+	if !IsValidCurrency(currency) {
+		return "", &ParsingError{Param: param, Err: ErrUnknownCurrency}
+	}
+	return currency, nil
+}
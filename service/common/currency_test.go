@@ -0,0 +1,164 @@
+package common
+
+import (
+	"errors"
+	"math/big"
+	"net/http/httptest"
+	"testing"
+)
+
+func mustRate(t *testing.T, rate string) *big.Rat {
+	t.Helper()
+	r, err := ParseRate(rate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func TestConvertMoney(t *testing.T) {
+	tests := []struct {
+		amount Money
+		rate   string
+		to     string
+		want   Money
+	}{
+		// Ties go to the even neighbour, in both directions from zero
+		{Money{5, "USD"}, "0.5", "USD", Money{2, "USD"}},
+		{Money{3, "USD"}, "0.5", "USD", Money{2, "USD"}},
+		{Money{7, "USD"}, "0.5", "USD", Money{4, "USD"}},
+		{Money{-5, "USD"}, "0.5", "USD", Money{-2, "USD"}},
+		{Money{-7, "USD"}, "0.5", "USD", Money{-4, "USD"}},
+		{Money{11, "USD"}, "0.5", "USD", Money{6, "USD"}},
+		// Only exact ties are affected
+		{Money{1001, "USD"}, "0.4995", "USD", Money{500, "USD"}},
+		{Money{1003, "USD"}, "0.4995", "USD", Money{501, "USD"}},
+		{Money{-1003, "USD"}, "0.4995", "USD", Money{-501, "USD"}},
+		// Zero decimals
+		{Money{1299, "USD"}, "150", "JPY", Money{1948, "JPY"}},
+		{Money{1, "USD"}, "150", "JPY", Money{2, "JPY"}},
+		{Money{3, "USD"}, "150", "JPY", Money{4, "JPY"}},
+		{Money{-1299, "USD"}, "150", "JPY", Money{-1948, "JPY"}},
+		{Money{1500, "JPY"}, "0.0066666667", "USD", Money{1000, "USD"}},
+		{Money{1, "JPY"}, "0.005", "USD", Money{0, "USD"}},
+		{Money{3, "JPY"}, "0.005", "USD", Money{2, "USD"}},
+		// Three decimals
+		{Money{20, "USD"}, "0.3025", "KWD", Money{60, "KWD"}},
+		{Money{60, "USD"}, "0.3025", "KWD", Money{182, "KWD"}},
+		{Money{-60, "USD"}, "0.3025", "KWD", Money{-182, "KWD"}},
+		{Money{1005, "KWD"}, "3.25", "USD", Money{327, "USD"}},
+		{Money{1, "KWD"}, "5", "USD", Money{0, "USD"}},
+		{Money{3, "KWD"}, "5", "USD", Money{2, "USD"}},
+		{Money{1, "KWD"}, "500", "JPY", Money{0, "JPY"}},
+		{Money{3, "KWD"}, "500", "JPY", Money{2, "JPY"}},
+		{Money{1500, "JPY"}, "0.002", "KWD", Money{3000, "KWD"}},
+		{Money{0, "KWD"}, "3.25", "USD", Money{0, "USD"}},
+	}
+	for _, test := range tests {
+		got, err := ConvertMoney(test.amount, mustRate(t, test.rate), test.to)
+		if err != nil || got != test.want {
+			t.Errorf("ConvertMoney(%v, %s, %s) = %v, %v, want %v", test.amount, test.rate, test.to, got, err, test.want)
+		}
+	}
+}
+
+func TestConvertMoneyInvalid(t *testing.T) {
+	tests := []struct {
+		amount Money
+		to     string
+	}{
+		{Money{100, "XYZ"}, "USD"},
+		{Money{100, "USD"}, "XYZ"},
+		{Money{100, "USD"}, "usd"},
+		{Money{100, ""}, "USD"},
+	}
+	for _, test := range tests {
+		if _, err := ConvertMoney(test.amount, big.NewRat(1, 1), test.to); !errors.Is(err, ErrUnknownCurrency) {
+			t.Errorf("ConvertMoney(%v, 1, %q) = %v, want ErrUnknownCurrency", test.amount, test.to, err)
+		}
+	}
+
+	if _, err := ConvertMoney(Money{1 << 62, "USD"}, big.NewRat(4, 1), "USD"); err == nil {
+		t.Error("ConvertMoney() of an amount overflowing int64 succeeded")
+	}
+}
+
+func TestParseRate(t *testing.T) {
+	if rate := mustRate(t, " 0.9231 "); FormatRate(rate) != "0.9231000000" {
+		t.Errorf("FormatRate(ParseRate(0.9231)) = %s", FormatRate(rate))
+	}
+	for _, rate := range []string{"", "0", "-1.5", "abc", "1.2.3"} {
+		if _, err := ParseRate(rate); err == nil {
+			t.Errorf("ParseRate(%q) succeeded", rate)
+		}
+	}
+}
+
+func TestRateTableLookup(t *testing.T) {
+	table := NewRateTable()
+	table.Set("USD", "EUR", mustRate(t, "0.8"))
+	table.Set("USD", "JPY", mustRate(t, "150"))
+	table.Set("KWD", "USD", mustRate(t, "3.25"))
+
+	tests := []struct {
+		from string
+		to   string
+		want *big.Rat
+	}{
+		{"EUR", "EUR", big.NewRat(1, 1)},
+		{"USD", "EUR", big.NewRat(4, 5)},
+		{"EUR", "USD", big.NewRat(5, 4)},              // Inverse
+		{"JPY", "EUR", big.NewRat(2, 375)},            // Cross rate through USD
+		{"KWD", "JPY", big.NewRat(975, 2)},            // Direct to USD, then USD to JPY
+		{"EUR", "KWD", new(big.Rat).SetFrac64(5, 13)}, // Both inverted
+	}
+	for _, test := range tests {
+		got, err := table.Lookup(test.from, test.to)
+		if err != nil || got.Cmp(test.want) != 0 {
+			t.Errorf("Lookup(%s, %s) = %v, %v, want %v", test.from, test.to, got, err, test.want)
+		}
+	}
+
+	if _, err := table.Lookup("USD", "GBP"); !errors.Is(err, ErrRateNotFound) {
+		t.Errorf("Lookup() without a rate = %v, want ErrRateNotFound", err)
+	}
+
+	converted, rate, err := table.Convert(NewMoney(1500, "JPY"), "EUR")
+	if err != nil || converted != NewMoney(800, "EUR") || rate.Cmp(big.NewRat(2, 375)) != 0 {
+		t.Errorf("Convert(15 JPY, EUR) = %v, %v, %v, want 8.00 EUR", converted, rate, err)
+	}
+}
+
+func TestResolveCurrency(t *testing.T) {
+	tests := []struct {
+		query  string
+		header string
+		want   string
+	}{
+		{"", "", ""},
+		{"?currency=jpy", "", "JPY"},
+		{"", " kwd ", "KWD"},
+		{"?currency=EUR", "GBP", "EUR"},
+	}
+	for _, test := range tests {
+		r := httptest.NewRequest("GET", "/books"+test.query, nil)
+		if test.header != "" {
+			r.Header.Set("Accept-Currency", test.header)
+		}
+		if got, err := ResolveCurrency(r); err != nil || got != test.want {
+			t.Errorf("ResolveCurrency(%q, %q) = %q, %v, want %q", test.query, test.header, got, err, test.want)
+		}
+	}
+
+	for _, test := range []struct{ query, header, param string }{
+		{"?currency=XYZ", "", "currency"},
+		{"", "dollars", "Accept-Currency"},
+	} {
+		r := httptest.NewRequest("GET", "/books"+test.query, nil)
+		r.Header.Set("Accept-Currency", test.header)
+		var parsingErr *ParsingError
+		if _, err := ResolveCurrency(r); !errors.As(err, &parsingErr) || parsingErr.Param != test.param {
+			t.Errorf("ResolveCurrency(%q, %q) = %v, want a parsing error for %s", test.query, test.header, err, test.param)
+		}
+	}
+}
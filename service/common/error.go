@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"strings"
 )
 
 var (
@@ -38,6 +39,27 @@ func (e *RequiredError) Error() string {
 	return fmt.Sprintf("required field '%s' is zero value.", e.Field)
 }
 
+// FieldError describes why the value of a request field is invalid.
+type FieldError struct {
+	Field   string `json:"field"` // JSON name of the field, with the names of enclosing objects, e.g. address.zipcode
+	Code    string `json:"code"`  // Machine-readable reason, e.g. required or invalid_format
+	Message string `json:"message"`
+}
+
+// ValidationError reports every invalid field of a request at once. DefaultErrorHandler responds
+// to it with StatusUnprocessableEntity and the list of field errors.
+type ValidationError struct {
+	Errors []FieldError `json:"errors"`
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, 0, len(e.Errors))
+	for _, fieldErr := range e.Errors {
+		messages = append(messages, fieldErr.Field+": "+fieldErr.Message)
+	}
+	return strings.Join(messages, "; ")
+}
+
 // ErrorHandler defines the required method for handling error. You may implement it and inject this into a controller if
 // you would like errors to be handled differently from the DefaultErrorHandler
 type ErrorHandler func(w http.ResponseWriter, r *http.Request, err error, result *ImplResponse)
@@ -59,6 +81,13 @@ func DefaultErrorHandler(w http.ResponseWriter, _ *http.Request, err error, resu
 		return
 	}
 
+	var validationErr *ValidationError
+	if ok := errors.As(err, &validationErr); ok {
+		// Handle invalid field values, listing each one
+		_ = EncodeJSONResponse(validationErr, func(i int) *int { return &i }(http.StatusUnprocessableEntity), w)
+		return
+	}
+
 	// Handle all other errors
 	_ = EncodeJSONResponse(err.Error(), &result.Code, w)
 }
@@ -0,0 +1,283 @@
+package common
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// IdempotencyKeyHeader is the request header clients set to make a POST safe to retry.
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// IdempotentReplayedHeader is set on responses replayed from an earlier request with the same key.
+const IdempotentReplayedHeader = "Idempotent-Replayed"
+
+// ClientIDHeader identifies a client sending an Idempotency-Key without credentials. Clients pick
+// a random id once, e.g. a UUID, and send it with every request.
+const ClientIDHeader = "X-Client-Id"
+
+const maxIdempotencyKeyLength = 255
+
+// IdempotentResponse is the response stored for an idempotency key.
+type IdempotentResponse struct {
+	// Fingerprint identifies the request that claimed the key: its method, URI and body
+	Fingerprint string
+	// Completed is false while the first request is still being handled
+	Completed   bool
+	Code        int
+	ContentType string
+	Location    string
+	Body        []byte
+}
+
+// IdempotencyStore keeps the first response for each idempotency key until its TTL expires.
+type IdempotencyStore interface {
+	// Reserve claims a key for the request with the given fingerprint. It returns the entry
+	// already holding the key, or nil when the caller now owns it.
+	Reserve(key string, fingerprint string, ttl time.Duration) (*IdempotentResponse, error)
+	// Complete stores the response of the request that reserved the key.
+	Complete(key string, response IdempotentResponse) error
+	// Release forgets a reserved key so the request can be retried.
+	Release(key string) error
+}
+
+// Idempotency wraps a router so POST requests carrying an Idempotency-Key header are handled at
+// most once per key and caller within ttl. Callers are told apart by their credentials, or by the
+// X-Client-Id header when they have none; a key sent with neither is rejected with 400. A retry
+// with the same method, URI and body gets the stored status and body back without reaching the
+// handler; reusing the key for a different request is rejected with 422, and a retry arriving
+// while the first request is still running with 409. Server errors are not stored, so a request
+// that failed with a 5xx can be retried.
+//
+// Keyed request bodies are read into memory to fingerprint them, so those over maxBodyBytes are
+// rejected with 413; large uploads such as the /bulk imports are sent without a key. Responses
+// over maxBodyBytes are passed through but not stored, which leaves the key free for a retry.
+func Idempotency(inner http.Handler, store IdempotencyStore, ttl time.Duration, maxBodyBytes int64) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(IdempotencyKeyHeader)
+		if r.Method != http.MethodPost || key == "" {
+			inner.ServeHTTP(w, r)
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			_ = EncodeJSONResponse("Idempotency-Key must be at most 255 characters", intPtr(http.StatusBadRequest), w)
+			return
+		}
+
+		// Keys are scoped to the caller so one caller cannot be replayed another's response. They
+		// are not scoped to the client address, which changes when a phone switches networks
+		// between retries and is shared by clients behind a proxy
+		caller := idempotencyCaller(r)
+		if caller == "" {
+			_ = EncodeJSONResponse("Idempotency-Key needs credentials or an "+ClientIDHeader+" header", intPtr(http.StatusBadRequest), w)
+			return
+		}
+
+		body, err := io.ReadAll(io.LimitReader(r.Body, maxBodyBytes+1))
+		if err != nil {
+			_ = EncodeJSONResponse("failed to read request body: "+err.Error(), intPtr(http.StatusBadRequest), w)
+			return
+		}
+		if int64(len(body)) > maxBodyBytes {
+			_ = EncodeJSONResponse(fmt.Sprintf("requests with an Idempotency-Key must be at most %d bytes", maxBodyBytes),
+				intPtr(http.StatusRequestEntityTooLarge), w)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		scopedKey := caller + " " + key
+		fingerprint := requestFingerprint(r, body)
+		stored, err := store.Reserve(scopedKey, fingerprint, ttl)
+		if err != nil {
+			_ = EncodeJSONResponse(err.Error(), intPtr(http.StatusInternalServerError), w)
+			return
+		}
+		if stored != nil {
+			switch {
+			case stored.Fingerprint != fingerprint:
+				_ = EncodeJSONResponse("Idempotency-Key was already used for a different request", intPtr(http.StatusUnprocessableEntity), w)
+			case !stored.Completed:
+				_ = EncodeJSONResponse("a request with this Idempotency-Key is still being processed", intPtr(http.StatusConflict), w)
+			default:
+				replayResponse(w, stored)
+			}
+			return
+		}
+
+		// The reservation is released unless a response is stored, including when the handler panics
+		defer func() {
+			if stored == nil {
+				if err := store.Release(scopedKey); err != nil {
+					log.Printf("Failed to release Idempotency-Key %q: %v", key, err)
+				}
+			}
+		}()
+
+		recorder := &responseRecorder{ResponseWriter: w, code: http.StatusOK, limit: maxBodyBytes}
+		inner.ServeHTTP(recorder, r)
+		if recorder.code >= http.StatusInternalServerError || recorder.truncated {
+			return
+		}
+
+		stored = &IdempotentResponse{
+			Fingerprint: fingerprint,
+			Completed:   true,
+			Code:        recorder.code,
+			ContentType: recorder.Header().Get("Content-Type"),
+			Location:    recorder.Header().Get("Location"),
+			Body:        recorder.body.Bytes(),
+		}
+		if err := store.Complete(scopedKey, *stored); err != nil {
+			log.Printf("Failed to store the response for Idempotency-Key %q: %v", key, err)
+		}
+	})
+}
+
+// idempotencyCaller identifies who sent a request by its credentials, or by its client id when
+// it has none. It returns "" when the request has neither.
+func idempotencyCaller(r *http.Request) string {
+	if auth := r.Header.Get("Authorization"); auth != "" {
+		sum := sha256.Sum256([]byte(auth))
+		return "auth:" + hex.EncodeToString(sum[:])
+	}
+	if client := r.Header.Get(ClientIDHeader); client != "" {
+		sum := sha256.Sum256([]byte(client))
+		return "client:" + hex.EncodeToString(sum[:])
+	}
+	return ""
+}
+
+func requestFingerprint(r *http.Request, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(r.Method + " " + r.URL.RequestURI() + "\n"))
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func replayResponse(w http.ResponseWriter, stored *IdempotentResponse) {
+	if stored.ContentType != "" {
+		w.Header().Set("Content-Type", stored.ContentType)
+	}
+	if stored.Location != "" {
+		w.Header().Set("Location", stored.Location)
+	}
+	w.Header().Set(IdempotentReplayedHeader, "true")
+	w.WriteHeader(stored.Code)
+	_, _ = w.Write(stored.Body)
+}
+
+func intPtr(i int) *int {
+	return &i
+}
+
+// responseRecorder passes a response through while keeping a copy of its status and of its body,
+// up to limit bytes.
+type responseRecorder struct {
+	http.ResponseWriter
+	code        int
+	wroteHeader bool
+	body        bytes.Buffer
+	limit       int64
+	truncated   bool // The body went over limit and was not kept
+}
+
+func (rec *responseRecorder) WriteHeader(code int) {
+	if !rec.wroteHeader {
+		rec.code, rec.wroteHeader = code, true
+	}
+	rec.ResponseWriter.WriteHeader(code)
+}
+
+func (rec *responseRecorder) Write(data []byte) (int, error) {
+	rec.wroteHeader = true
+	if !rec.truncated {
+		if int64(rec.body.Len()+len(data)) > rec.limit {
+			rec.truncated = true
+			rec.body = bytes.Buffer{}
+		} else {
+			rec.body.Write(data)
+		}
+	}
+	return rec.ResponseWriter.Write(data)
+}
+
+// Flush sends what was written so far, so streamed responses are not held back.
+func (rec *responseRecorder) Flush() {
+	if flusher, ok := rec.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// MemoryIdempotencyStore is an IdempotencyStore held in process memory. Entries are lost on
+// restart and are not shared between instances.
+type MemoryIdempotencyStore struct {
+	mu        sync.Mutex
+	entries   map[string]*memoryIdempotencyEntry
+	lastSweep time.Time
+}
+
+type memoryIdempotencyEntry struct {
+	response  IdempotentResponse
+	expiresAt time.Time
+}
+
+// NewMemoryIdempotencyStore creates an empty in-memory store.
+func NewMemoryIdempotencyStore() *MemoryIdempotencyStore {
+	return &MemoryIdempotencyStore{
+		entries:   map[string]*memoryIdempotencyEntry{},
+		lastSweep: time.Now(),
+	}
+}
+
+// Reserve claims a key unless an unexpired entry already holds it.
+func (s *MemoryIdempotencyStore) Reserve(key string, fingerprint string, ttl time.Duration) (*IdempotentResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	// Expired entries are swept at most once per TTL so memory stays bounded by recent traffic
+	if now.Sub(s.lastSweep) >= ttl {
+		for k, entry := range s.entries {
+			if !now.Before(entry.expiresAt) {
+				delete(s.entries, k)
+			}
+		}
+		s.lastSweep = now
+	}
+
+	if entry, ok := s.entries[key]; ok && now.Before(entry.expiresAt) {
+		stored := entry.response
+		return &stored, nil
+	}
+	s.entries[key] = &memoryIdempotencyEntry{
+		response:  IdempotentResponse{Fingerprint: fingerprint},
+		expiresAt: now.Add(ttl),
+	}
+	return nil, nil
+}
+
+// Complete stores the response for a reserved key, keeping its original expiry.
+func (s *MemoryIdempotencyStore) Complete(key string, response IdempotentResponse) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if entry, ok := s.entries[key]; ok {
+		entry.response = response
+	}
+	return nil
+}
+
+// Release forgets a key.
+func (s *MemoryIdempotencyStore) Release(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.entries, key)
+	return nil
+}
@@ -0,0 +1,108 @@
+package common
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// DBIdempotencyStore is an IdempotencyStore kept in the IdempotencyKeys table, so retries are
+// recognised by every server instance and across restarts.
+type DBIdempotencyStore struct {
+	DB *sql.DB
+
+	mu        sync.Mutex
+	lastSweep time.Time
+}
+
+// NewDBIdempotencyStore creates a store over the IdempotencyKeys table.
+func NewDBIdempotencyStore(db *DBConnection) *DBIdempotencyStore {
+	return &DBIdempotencyStore{DB: db.DB, lastSweep: time.Now()}
+}
+
+// Reserve claims a key unless an unexpired entry already holds it. Expiry is decided by the
+// database clock, so instances with drifting clocks agree on it.
+func (s *DBIdempotencyStore) Reserve(key string, fingerprint string, ttl time.Duration) (*IdempotentResponse, error) {
+	s.sweep(ttl)
+
+	keyHash := hashIdempotencyKey(key)
+	// Two tries: an entry can expire or be released between the insert and the read
+	for i := 0; i < 2; i++ {
+		if _, err := s.DB.Exec(`DELETE FROM IdempotencyKeys WHERE key_hash = ? AND expires_at <= UTC_TIMESTAMP(3)`, keyHash); err != nil {
+			return nil, fmt.Errorf("failed to expire idempotency key: %w", err)
+		}
+		result, err := s.DB.Exec(`INSERT IGNORE INTO IdempotencyKeys (key_hash, fingerprint, expires_at)
+			VALUES (?, ?, UTC_TIMESTAMP(3) + INTERVAL ? MICROSECOND)`, keyHash, fingerprint, ttl.Microseconds())
+		if err != nil {
+			return nil, fmt.Errorf("failed to reserve idempotency key: %w", err)
+		}
+		if reserved, err := result.RowsAffected(); err != nil {
+			return nil, err
+		} else if reserved > 0 {
+			return nil, nil
+		}
+
+		var stored IdempotentResponse
+		var code sql.NullInt64
+		var contentType, location sql.NullString
+		err = s.DB.QueryRow(`SELECT fingerprint, completed, code, content_type, location, body FROM IdempotencyKeys
+			WHERE key_hash = ? AND expires_at > UTC_TIMESTAMP(3)`, keyHash).
+			Scan(&stored.Fingerprint, &stored.Completed, &code, &contentType, &location, &stored.Body)
+		if err == sql.ErrNoRows {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read idempotency key: %w", err)
+		}
+		stored.Code = int(code.Int64)
+		stored.ContentType = contentType.String
+		stored.Location = location.String
+		return &stored, nil
+	}
+	return nil, fmt.Errorf("failed to reserve idempotency key: it keeps changing")
+}
+
+// Complete stores the response for a reserved key, keeping its original expiry.
+func (s *DBIdempotencyStore) Complete(key string, response IdempotentResponse) error {
+	_, err := s.DB.Exec(`UPDATE IdempotencyKeys SET completed = TRUE, code = ?, content_type = ?, location = ?, body = ?
+		WHERE key_hash = ? AND fingerprint = ?`,
+		response.Code, response.ContentType, response.Location, response.Body, hashIdempotencyKey(key), response.Fingerprint)
+	if err != nil {
+		return fmt.Errorf("failed to store idempotent response: %w", err)
+	}
+	return nil
+}
+
+// Release forgets a key that has no response stored yet.
+func (s *DBIdempotencyStore) Release(key string) error {
+	if _, err := s.DB.Exec(`DELETE FROM IdempotencyKeys WHERE key_hash = ? AND completed = FALSE`, hashIdempotencyKey(key)); err != nil {
+		return fmt.Errorf("failed to release idempotency key: %w", err)
+	}
+	return nil
+}
+
+// sweep deletes expired entries at most once per TTL, so the table stays bounded by recent traffic.
+func (s *DBIdempotencyStore) sweep(ttl time.Duration) {
+	s.mu.Lock()
+	due := time.Since(s.lastSweep) >= ttl
+	if due {
+		s.lastSweep = time.Now()
+	}
+	s.mu.Unlock()
+
+	if due {
+		if _, err := s.DB.Exec(`DELETE FROM IdempotencyKeys WHERE expires_at <= UTC_TIMESTAMP(3)`); err != nil {
+			log.Printf("Failed to delete expired idempotency keys: %v", err)
+		}
+	}
+}
+
+// hashIdempotencyKey returns the stored form of a key, which has a fixed length whatever the key.
+func hashIdempotencyKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,212 @@
+package common
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// DefaultCurrency is used when a price is supplied without a currency code.
+const DefaultCurrency = "USD"
+
+var (
+	// ErrCurrencyMismatch is returned when arithmetic is attempted on amounts in different currencies
+	ErrCurrencyMismatch = errors.New("currency mismatch")
+	// ErrUnknownCurrency is returned when a currency code is not a supported ISO 4217 code
+	ErrUnknownCurrency = errors.New("unknown currency")
+)
+
+// currencyExponents maps supported ISO 4217 codes to the number of digits in their minor unit.
+var currencyExponents = map[string]int{
+	"AED": 2, "AUD": 2, "BHD": 3, "BRL": 2, "CAD": 2, "CHF": 2, "CNY": 2,
+	"CZK": 2, "DKK": 2, "EUR": 2, "GBP": 2, "HKD": 2, "HUF": 2, "IDR": 2,
+	"ILS": 2, "INR": 2, "JOD": 3, "JPY": 0, "KRW": 0, "KWD": 3, "MXN": 2,
+	"MYR": 2, "NOK": 2, "NZD": 2, "OMR": 3, "PHP": 2, "PLN": 2, "SAR": 2,
+	"SEK": 2, "SGD": 2, "THB": 2, "TRY": 2, "TWD": 2, "USD": 2, "VND": 0,
+	"ZAR": 2,
+}
+
+// Money is an exact monetary amount stored as an integer number of minor units
+// (e.g. cents) together with its ISO 4217 currency code.
+type Money struct {
+	Amount   int64
+	Currency string
+}
+
+// IsValidCurrency reports whether code is a supported ISO 4217 currency code.
+func IsValidCurrency(code string) bool {
+	_, ok := currencyExponents[code]
+	return ok
+}
+
+// CurrencyExponent returns the number of minor unit digits for the currency.
+func CurrencyExponent(code string) (int, error) {
+	exp, ok := currencyExponents[code]
+	if !ok {
+		return 0, fmt.Errorf("%w: %q", ErrUnknownCurrency, code)
+	}
+	return exp, nil
+}
+
+// NewMoney returns an amount of minor units in the given currency.
+func NewMoney(amount int64, currency string) Money {
+	return Money{Amount: amount, Currency: currency}
+}
+
+// ParseMoney parses a decimal string such as "12.99" into Money without going through floating point.
+func ParseMoney(amount string, currency string) (Money, error) {
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	exp, err := CurrencyExponent(currency)
+	if err != nil {
+		return Money{}, err
+	}
+
+	amount = strings.TrimSpace(amount)
+	negative := strings.HasPrefix(amount, "-")
+	amount = strings.TrimPrefix(strings.TrimPrefix(amount, "-"), "+")
+
+	whole, frac := amount, ""
+	if i := strings.IndexByte(amount, '.'); i >= 0 {
+		whole, frac = amount[:i], amount[i+1:]
+	}
+	if whole == "" && frac == "" {
+		return Money{}, fmt.Errorf("invalid amount %q", amount)
+	}
+	// Trailing zeros beyond the minor unit are allowed, e.g. DECIMAL(19,4) columns
+	if len(frac) > exp {
+		if strings.Trim(frac[exp:], "0") != "" {
+			return Money{}, fmt.Errorf("amount %q has more precision than %s allows", amount, currency)
+		}
+		frac = frac[:exp]
+	}
+	frac += strings.Repeat("0", exp-len(frac))
+
+	digits := whole + frac
+	if digits == "" {
+		digits = "0"
+	}
+	for _, c := range digits {
+		if c < '0' || c > '9' {
+			return Money{}, fmt.Errorf("invalid amount %q", amount)
+		}
+	}
+	minor, err := strconv.ParseInt(digits, 10, 64)
+	if err != nil {
+		return Money{}, fmt.Errorf("invalid amount %q: %w", amount, err)
+	}
+	if negative {
+		minor = -minor
+	}
+
+	return Money{Amount: minor, Currency: currency}, nil
+}
+
+// Decimal formats the amount as a decimal string in major units, e.g. "12.99".
+func (m Money) Decimal() string {
+	exp := currencyExponents[m.Currency]
+	amount := m.Amount
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+	digits := strconv.FormatInt(amount, 10)
+	if exp == 0 {
+		return sign + digits
+	}
+	if len(digits) <= exp {
+		digits = strings.Repeat("0", exp-len(digits)+1) + digits
+	}
+	return sign + digits[:len(digits)-exp] + "." + digits[len(digits)-exp:]
+}
+
+// String formats the amount with its currency code, e.g. "12.99 USD".
+func (m Money) String() string {
+	return m.Decimal() + " " + m.Currency
+}
+
+// IsZero reports whether the amount is zero.
+func (m Money) IsZero() bool {
+	return m.Amount == 0
+}
+
+// Add returns the sum of two amounts. Amounts in different currencies cannot be added.
+func (m Money) Add(other Money) (Money, error) {
+	if m.Currency != other.Currency {
+		return Money{}, fmt.Errorf("%w: cannot add %s to %s", ErrCurrencyMismatch, other.Currency, m.Currency)
+	}
+	return Money{Amount: m.Amount + other.Amount, Currency: m.Currency}, nil
+}
+
+// Sub returns the difference of two amounts. Amounts in different currencies cannot be subtracted.
+func (m Money) Sub(other Money) (Money, error) {
+	if m.Currency != other.Currency {
+		return Money{}, fmt.Errorf("%w: cannot subtract %s from %s", ErrCurrencyMismatch, other.Currency, m.Currency)
+	}
+	return Money{Amount: m.Amount - other.Amount, Currency: m.Currency}, nil
+}
+
+// Mul returns the amount multiplied by a whole quantity.
+func (m Money) Mul(quantity int64) Money {
+	return Money{Amount: m.Amount * quantity, Currency: m.Currency}
+}
+
+// SumMoney adds up amounts that must all share the given currency.
+func SumMoney(currency string, values ...Money) (Money, error) {
+	total := Money{Currency: currency}
+	for _, v := range values {
+		var err error
+		if total, err = total.Add(v); err != nil {
+			return Money{}, err
+		}
+	}
+	return total, nil
+}
+
+type moneyJSON struct {
+	Amount   json.RawMessage `json:"amount"`
+	Currency string          `json:"currency"`
+}
+
+// MarshalJSON encodes the amount as a decimal string to avoid floating point rounding in clients.
+func (m Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Amount   string `json:"amount"`
+		Currency string `json:"currency"`
+	}{Amount: m.Decimal(), Currency: m.Currency})
+}
+
+// UnmarshalJSON accepts the amount either as a decimal string or as a JSON number literal.
+// The literal is parsed exactly and never converted through float64.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(bytes.TrimSpace(data), []byte("null")) {
+		return nil
+	}
+	var raw moneyJSON
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	if raw.Currency == "" {
+		raw.Currency = DefaultCurrency
+	}
+
+	amount := string(bytes.TrimSpace(raw.Amount))
+	if strings.HasPrefix(amount, `"`) {
+		if err := json.Unmarshal(raw.Amount, &amount); err != nil {
+			return err
+		}
+	}
+	if amount == "" {
+		amount = "0"
+	}
+
+	parsed, err := ParseMoney(amount, raw.Currency)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
@@ -0,0 +1,141 @@
+package common
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestParseMoney(t *testing.T) {
+	tests := []struct {
+		amount   string
+		currency string
+		want     Money
+	}{
+		{"12.99", "USD", Money{1299, "USD"}},
+		{"12.9", "usd", Money{1290, "USD"}},
+		{" 12 ", " eur ", Money{1200, "EUR"}},
+		{".5", "USD", Money{50, "USD"}},
+		{"7.", "USD", Money{700, "USD"}},
+		{"+3.10", "USD", Money{310, "USD"}},
+		{"-3.10", "USD", Money{-310, "USD"}},
+		{"-0.01", "USD", Money{-1, "USD"}},
+		{"12.9900", "USD", Money{1299, "USD"}}, // DECIMAL(19,4)
+		{"1500", "JPY", Money{1500, "JPY"}},
+		{"1500.0000", "JPY", Money{1500, "JPY"}},
+		{"-1500", "JPY", Money{-1500, "JPY"}},
+		{"1.234", "KWD", Money{1234, "KWD"}},
+		{"1.2", "KWD", Money{1200, "KWD"}},
+		{"-0.005", "KWD", Money{-5, "KWD"}},
+		{"0", "KWD", Money{0, "KWD"}},
+	}
+	for _, test := range tests {
+		got, err := ParseMoney(test.amount, test.currency)
+		if err != nil || got != test.want {
+			t.Errorf("ParseMoney(%q, %q) = %v, %v, want %v", test.amount, test.currency, got, err, test.want)
+		}
+	}
+}
+
+func TestParseMoneyInvalid(t *testing.T) {
+	tests := []struct {
+		amount   string
+		currency string
+	}{
+		{"12.999", "USD"},
+		{"1500.5", "JPY"},
+		{"1.2345", "KWD"},
+		{"", "USD"},
+		{".", "USD"},
+		{"-", "USD"},
+		{"1.2.3", "USD"},
+		{"1,299", "USD"},
+		{"1e3", "USD"},
+		{"--1", "USD"},
+		{"99999999999999999999", "USD"},
+	}
+	for _, test := range tests {
+		if got, err := ParseMoney(test.amount, test.currency); err == nil {
+			t.Errorf("ParseMoney(%q, %q) = %v, want an error", test.amount, test.currency, got)
+		}
+	}
+
+	for _, currency := range []string{"", "US", "USDX", "XXX", "ABC"} {
+		if _, err := ParseMoney("1.00", currency); !errors.Is(err, ErrUnknownCurrency) {
+			t.Errorf("ParseMoney() in %q = %v, want ErrUnknownCurrency", currency, err)
+		}
+	}
+}
+
+func TestDecimal(t *testing.T) {
+	tests := []struct {
+		money Money
+		want  string
+	}{
+		{Money{1299, "USD"}, "12.99"},
+		{Money{5, "USD"}, "0.05"},
+		{Money{0, "USD"}, "0.00"},
+		{Money{-5, "USD"}, "-0.05"},
+		{Money{-1299, "USD"}, "-12.99"},
+		{Money{1500, "JPY"}, "1500"},
+		{Money{-1500, "JPY"}, "-1500"},
+		{Money{0, "JPY"}, "0"},
+		{Money{1234, "KWD"}, "1.234"},
+		{Money{5, "KWD"}, "0.005"},
+		{Money{-50, "KWD"}, "-0.050"},
+	}
+	for _, test := range tests {
+		if got := test.money.Decimal(); got != test.want {
+			t.Errorf("%#v.Decimal() = %q, want %q", test.money, got, test.want)
+		}
+		parsed, err := ParseMoney(test.want, test.money.Currency)
+		if err != nil || parsed != test.money {
+			t.Errorf("ParseMoney(%q) = %v, %v, want %v", test.want, parsed, err, test.money)
+		}
+	}
+}
+
+func TestMoneyArithmetic(t *testing.T) {
+	sum, err := SumMoney("KWD", NewMoney(1234, "KWD"), NewMoney(-234, "KWD"), NewMoney(5, "KWD").Mul(3))
+	if err != nil || sum != NewMoney(1015, "KWD") {
+		t.Errorf("SumMoney() = %v, %v, want 1.015 KWD", sum, err)
+	}
+	if _, err := NewMoney(100, "USD").Add(NewMoney(100, "JPY")); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Errorf("Add() across currencies = %v, want ErrCurrencyMismatch", err)
+	}
+	if _, err := NewMoney(100, "USD").Sub(NewMoney(100, "EUR")); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Errorf("Sub() across currencies = %v, want ErrCurrencyMismatch", err)
+	}
+}
+
+func TestMoneyJSON(t *testing.T) {
+	tests := []struct {
+		json string
+		want Money
+	}{
+		{`{"amount":"12.99","currency":"USD"}`, Money{1299, "USD"}},
+		{`{"amount":12.99,"currency":"USD"}`, Money{1299, "USD"}},
+		{`{"amount":0.1}`, Money{10, DefaultCurrency}},
+		{`{"amount":1500,"currency":"JPY"}`, Money{1500, "JPY"}},
+		{`{"amount":"-1.005","currency":"KWD"}`, Money{-1005, "KWD"}},
+		{`{"currency":"EUR"}`, Money{0, "EUR"}},
+	}
+	for _, test := range tests {
+		var got Money
+		if err := json.Unmarshal([]byte(test.json), &got); err != nil || got != test.want {
+			t.Errorf("Unmarshal(%s) = %v, %v, want %v", test.json, got, err, test.want)
+		}
+	}
+
+	for _, invalid := range []string{`{"amount":"12.999","currency":"USD"}`, `{"amount":"1","currency":"XYZ"}`} {
+		var got Money
+		if err := json.Unmarshal([]byte(invalid), &got); err == nil {
+			t.Errorf("Unmarshal(%s) = %v, want an error", invalid, got)
+		}
+	}
+
+	encoded, err := json.Marshal(NewMoney(-5, "KWD"))
+	if want := `{"amount":"-0.005","currency":"KWD"}`; err != nil || string(encoded) != want {
+		t.Errorf("Marshal() = %s, %v, want %s", encoded, err, want)
+	}
+}
@@ -0,0 +1,218 @@
+package common
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// NDJSONContentType is the media type of newline delimited JSON, one value per line.
+const NDJSONContentType = "application/x-ndjson"
+
+// Limits of bulk imports
+const (
+	DefaultBulkBatchSize = 500
+	MaxBulkBatchSize     = 10000
+	MaxBulkLineSize      = 1 << 20 // Bytes in one record
+)
+
+// Statuses of the lines of a bulk import
+const (
+	BulkCreated = "created"
+	BulkUpdated = "updated"
+	BulkFailed  = "error"
+)
+
+// BulkResult is the outcome of one line of a bulk import.
+type BulkResult struct {
+	Line   int    `json:"line"`
+	ID     string `json:"id,omitempty"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// BulkSummary counts the outcomes of a bulk import. It is the last line of the results.
+type BulkSummary struct {
+	Records int `json:"records"`
+	Created int `json:"created"`
+	Updated int `json:"updated"`
+	Failed  int `json:"failed"`
+}
+
+// BulkImport imports NDJSON records of type T.
+type BulkImport[T any] struct {
+	// Decode parses and validates the record on a line and returns it with its identifier
+	Decode func(line []byte) (T, string, error)
+	// Save writes records in a single transaction and reports for each whether it was created
+	// rather than updated
+	Save func(records []T) ([]bool, error)
+	// Records saved per transaction, DefaultBulkBatchSize when not set
+	BatchSize int
+}
+
+// DecodeBulkRecord decodes the record on one line of a bulk import into v, rejecting unknown
+// fields and anything after the record.
+func DecodeBulkRecord(line []byte, v interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(line))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		return &ParsingError{Err: err}
+	}
+	if decoder.More() {
+		return &ParsingError{Err: errors.New("unexpected data after the record")}
+	}
+	return nil
+}
+
+// Run reads records from r one line at a time and saves them in batches. A batch that fails is
+// saved again one record at a time, so the failure is reported on the line that caused it and the
+// rest still go in. One BulkResult per record is written to w, in line order, followed by
+// {"summary": BulkSummary}. Blank lines are skipped. Only a failure to write w is returned.
+func (b BulkImport[T]) Run(r io.Reader, w io.Writer) (BulkSummary, error) {
+	var summary BulkSummary
+	encoder := json.NewEncoder(w)
+	batchSize := b.BatchSize
+	if batchSize <= 0 {
+		batchSize = DefaultBulkBatchSize
+	}
+
+	var batch []T
+	var results []BulkResult // Results since the last batch; records refer to batch by position
+	var positions []int
+	flush := func() error {
+		if len(batch) > 0 {
+			b.save(batch, results, positions)
+		}
+		for _, result := range results {
+			switch result.Status {
+			case BulkCreated:
+				summary.Created++
+			case BulkUpdated:
+				summary.Updated++
+			default:
+				summary.Failed++
+			}
+			if err := encoder.Encode(result); err != nil {
+				return err
+			}
+		}
+		batch, results, positions = batch[:0], results[:0], positions[:0]
+		return nil
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64<<10), MaxBulkLineSize)
+	line := 0
+	for scanner.Scan() {
+		line++
+		data := bytes.TrimSpace(scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+		summary.Records++
+		record, id, err := b.Decode(data)
+		if err != nil {
+			results = append(results, BulkResult{Line: line, ID: id, Status: BulkFailed, Error: err.Error()})
+			continue
+		}
+		positions = append(positions, len(results))
+		results = append(results, BulkResult{Line: line, ID: id})
+		batch = append(batch, record)
+		if len(batch) >= batchSize {
+			if err := flush(); err != nil {
+				return summary, err
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		// The rest of the input cannot be read, so report it against the line after the last one
+		message := err.Error()
+		if errors.Is(err, bufio.ErrTooLong) {
+			message = fmt.Sprintf("line is longer than %d bytes, the rest of the input was not read", MaxBulkLineSize)
+		}
+		summary.Records++
+		results = append(results, BulkResult{Line: line + 1, Status: BulkFailed, Error: message})
+	}
+	if err := flush(); err != nil {
+		return summary, err
+	}
+	return summary, encoder.Encode(map[string]BulkSummary{"summary": summary})
+}
+
+// save writes a batch and fills in the results of its records.
+func (b BulkImport[T]) save(batch []T, results []BulkResult, positions []int) {
+	created, err := b.Save(batch)
+	if err == nil {
+		for i, position := range positions {
+			results[position].Status = bulkStatus(created[i])
+		}
+		return
+	}
+	for i, position := range positions {
+		created, err := b.Save(batch[i : i+1])
+		if err != nil {
+			results[position].Status = BulkFailed
+			results[position].Error = err.Error()
+			continue
+		}
+		results[position].Status = bulkStatus(created[0])
+	}
+}
+
+func bulkStatus(created bool) string {
+	if created {
+		return BulkCreated
+	}
+	return BulkUpdated
+}
+
+// NDJSONStream returns a response body that writes the values passed to encode, one per line.
+func NDJSONStream(write func(encode func(v interface{}) error) error) Stream {
+	return Stream{
+		ContentType: NDJSONContentType,
+		Write: func(w io.Writer) error {
+			buffered := bufio.NewWriter(w)
+			if err := write(json.NewEncoder(buffered).Encode); err != nil {
+				return err
+			}
+			return buffered.Flush()
+		},
+	}
+}
+
+// SpoolStream runs write against a temporary file and returns a response body that sends the
+// file, removing it afterwards. HTTP/1.x handlers must read the whole request before they start
+// the response, so results produced while reading a large upload are kept on disk, not in memory.
+func SpoolStream(contentType string, write func(w io.Writer) error) (Stream, error) {
+	file, err := os.CreateTemp("", "bookstore-spool-*")
+	if err != nil {
+		return Stream{}, err
+	}
+	buffered := bufio.NewWriter(file)
+	err = write(buffered)
+	if err == nil {
+		err = buffered.Flush()
+	}
+	if err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return Stream{}, err
+	}
+
+	return Stream{
+		ContentType: contentType,
+		Write: func(w io.Writer) error {
+			defer os.Remove(file.Name())
+			defer file.Close()
+			if _, err := file.Seek(0, io.SeekStart); err != nil {
+				return err
+			}
+			_, err := io.Copy(w, file)
+			return err
+		},
+	}, nil
+}
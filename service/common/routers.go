@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"net/url"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -34,20 +35,37 @@ const errMsgRequiredMissing = "required parameter is missing"
 const errMsgMinValueConstraint = "provided parameter is not respecting minimum value constraint"
 const errMsgMaxValueConstraint = "provided parameter is not respecting maximum value constraint"
 
-// NewRouter creates a new router for any number of api routers
+// NewRouter creates a new router for any number of api routers. Routes with fewer path variables
+// are registered first, so a fixed path such as /books/export wins over /books/{isbn}.
 func NewRouter(routers ...Router) *mux.Router {
-	router := mux.NewRouter().StrictSlash(true)
+	type namedRoute struct {
+		name string
+		Route
+	}
+	var routes []namedRoute
 	for _, api := range routers {
 		for name, route := range api.Routes() {
-			var handler http.Handler = route.HandlerFunc
-			handler = Logger(handler, name)
-
-			router.
-				Methods(route.Method).
-				Path(route.Pattern).
-				Name(name).
-				Handler(handler)
+			routes = append(routes, namedRoute{name, route})
+		}
+	}
+	sort.Slice(routes, func(i, j int) bool {
+		vi, vj := strings.Count(routes[i].Pattern, "{"), strings.Count(routes[j].Pattern, "{")
+		if vi != vj {
+			return vi < vj
 		}
+		return routes[i].name < routes[j].name
+	})
+
+	router := mux.NewRouter().StrictSlash(true)
+	for _, route := range routes {
+		var handler http.Handler = route.HandlerFunc
+		handler = Logger(handler, route.name)
+
+		router.
+			Methods(route.Method).
+			Path(route.Pattern).
+			Name(route.name).
+			Handler(handler)
 	}
 
 	return router
@@ -57,6 +75,10 @@ func NewRouter(routers ...Router) *mux.Router {
 func EncodeJSONResponse(i interface{}, status *int, w http.ResponseWriter) error {
 	wHeader := w.Header()
 
+	if stream, ok := i.(Stream); ok {
+		return stream.writeTo(w, status)
+	}
+
 	f, ok := i.(*os.File)
 	if ok {
 		data, err := io.ReadAll(f)
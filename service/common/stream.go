@@ -0,0 +1,34 @@
+package common
+
+import (
+	"io"
+	"log"
+	"net/http"
+)
+
+// Stream is a response body that is written straight to the connection rather than encoded in
+// memory, for exports too large to hold at once. EncodeJSONResponse sends it with its content
+// type and calls Write once the headers are out.
+type Stream struct {
+	ContentType string
+	Filename    string // Sent as an attachment when set
+	Write       func(w io.Writer) error
+}
+
+func (s Stream) writeTo(w http.ResponseWriter, status *int) error {
+	w.Header().Set("Content-Type", s.ContentType)
+	if s.Filename != "" {
+		w.Header().Set("Content-Disposition", "attachment; filename="+s.Filename)
+	}
+	if status != nil {
+		w.WriteHeader(*status)
+	} else {
+		w.WriteHeader(http.StatusOK)
+	}
+	// The status is already sent, so a failure part way can only cut the body short
+	if err := s.Write(w); err != nil {
+		log.Printf("Failed to stream %s response: %v", s.ContentType, err)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package db
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+
+	event_db "github.com/mayureshucsb2019/bookstore/service/event/db"
+)
+
+// Customer statuses. Suspended and Closed are set by staff with a reason, see TransitionCustomer.
+const (
+	StatusPending   = "Pending" // Registered, email not verified yet
+	StatusActive    = "Active"
+	StatusInactive  = "Inactive"
+	StatusSuspended = "Suspended" // Blocked from placing orders until reactivated
+	StatusClosed    = "Closed"    // Final; the customer keeps their history but cannot place orders
+	StatusErased    = "Erased"    // Placeholder keeping the orders of an erased customer, see EraseCustomer
+)
+
+// ErrPasswordChanged is returned when a password reset was issued for a password that has been
+// changed since, including by an earlier use of the same reset.
+var ErrPasswordChanged = errors.New("password changed since the reset was requested")
+
+// VerifyEmail records that a customer proved they own their email, activating a Pending customer,
+// and publishes a CustomerUpdated event. It fails with ErrCustomerNotFound when the customer no
+// longer has that email.
+func (r *CustomerRepository) VerifyEmail(customerID int64, email string) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := lockCustomerEmail(tx, customerID, email); err != nil {
+		return err
+	}
+	if err := markEmailVerified(tx, customerID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// markEmailVerified sets the email of a customer verified and activates them if they were Pending,
+// recording a CustomerUpdated event inside tx.
+func markEmailVerified(tx *sql.Tx, customerID int64) error {
+	_, err := tx.Exec(`
+		UPDATE Customer
+		SET email_verified_at = COALESCE(email_verified_at, UTC_TIMESTAMP()),
+			status = IF(status = ?, ?, status)
+		WHERE id = ?`, StatusPending, StatusActive, customerID)
+	if err != nil {
+		return fmt.Errorf("failed to verify email: %w", err)
+	}
+
+	var customer Customer
+	err = tx.QueryRow(`SELECT email, first_name, last_name, status FROM Customer WHERE id = ?`, customerID).Scan(
+		&customer.Email, &customer.FirstName, &customer.LastName, &customer.Status)
+	if err != nil {
+		return fmt.Errorf("failed to read customer: %w", err)
+	}
+	customer.ID = customerID
+	payload := customerEventPayload(&customer)
+	delete(payload, "languages") // Not read, and not changed by verification
+	return event_db.Record(tx, event_db.CustomerUpdated, event_db.EntityCustomer, customer.Email, payload)
+}
+
+// PasswordFingerprint returns a short hash of the stored password of a customer that changes
+// whenever the password does. Password reset tokens carry it, so they stop working once used.
+func (r *CustomerRepository) PasswordFingerprint(customerID int64) (string, error) {
+	var passwordHash sql.NullString
+	err := r.DB.QueryRow(`SELECT password_hash FROM Customer WHERE id = ?`, customerID).Scan(&passwordHash)
+	if err == sql.ErrNoRows {
+		return "", fmt.Errorf("%w: %d", ErrCustomerNotFound, customerID)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read password: %w", err)
+	}
+	return passwordFingerprint(customerID, passwordHash.String), nil
+}
+
+// ResetPassword stores a new password hash for a customer whose password still has fingerprint.
+// Receiving the reset mail proves the customer owns their email, so it is verified as well.
+func (r *CustomerRepository) ResetPassword(customerID int64, fingerprint string, passwordHash string) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var current sql.NullString
+	err = tx.QueryRow(`SELECT password_hash FROM Customer WHERE id = ? FOR UPDATE`, customerID).Scan(&current)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("%w: %d", ErrCustomerNotFound, customerID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read password: %w", err)
+	}
+	if passwordFingerprint(customerID, current.String) != fingerprint {
+		return ErrPasswordChanged
+	}
+
+	_, err = tx.Exec(`UPDATE Customer SET password_hash = ?, password_changed_at = UTC_TIMESTAMP() WHERE id = ?`, passwordHash, customerID)
+	if err != nil {
+		return fmt.Errorf("failed to store password: %w", err)
+	}
+	if err := markEmailVerified(tx, customerID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// lockCustomerEmail locks a customer until tx ends, failing with ErrCustomerNotFound unless they
+// still have email.
+func lockCustomerEmail(tx *sql.Tx, customerID int64, email string) error {
+	var current string
+	err := tx.QueryRow(`SELECT email FROM Customer WHERE id = ? FOR UPDATE`, customerID).Scan(&current)
+	if err == sql.ErrNoRows || (err == nil && current != email) {
+		return fmt.Errorf("%w: %s", ErrCustomerNotFound, email)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to look up customer: %w", err)
+	}
+	return nil
+}
+
+func passwordFingerprint(customerID int64, passwordHash string) string {
+	sum := sha256.Sum256([]byte(strconv.FormatInt(customerID, 10) + ":" + passwordHash))
+	return hex.EncodeToString(sum[:8])
+}
@@ -0,0 +1,28 @@
+package db
+
+import "testing"
+
+// Password reset tokens carry the fingerprint of the password they were issued for, so a token
+// stops working once the password changes, including through its own use.
+func TestPasswordFingerprint(t *testing.T) {
+	const before = "pbkdf2-sha256$600000$c2FsdA$Eg+2z/z4syxD5yJSVsT4N6hlSMkszDVICAWYfLcL4Xs"
+	const after = "pbkdf2-sha256$600000$cGVwcGVy$rn8zrk6ff5Ksfvn3Ut8JvfQCm6LRwZFYcOB0Gb0AaLI"
+
+	fingerprint := passwordFingerprint(42, before)
+	if len(fingerprint) != 16 {
+		t.Errorf("fingerprint %q has %d characters, want 16", fingerprint, len(fingerprint))
+	}
+	if passwordFingerprint(42, before) != fingerprint {
+		t.Error("fingerprint of the same password changed")
+	}
+	if passwordFingerprint(42, after) == fingerprint {
+		t.Error("fingerprint did not change with the password")
+	}
+	if passwordFingerprint(43, before) == fingerprint {
+		t.Error("fingerprint of another customer with the same hash matched")
+	}
+	// Customers without a password can reset one, once
+	if passwordFingerprint(42, "") == passwordFingerprint(42, before) {
+		t.Error("fingerprint without a password matched the one after setting it")
+	}
+}
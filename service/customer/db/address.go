@@ -0,0 +1,366 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// ErrAddressNotFound is returned when a customer has no address with the requested id
+var ErrAddressNotFound = errors.New("address not found")
+
+// DefaultAddressLabel labels the address book entry created from the address given with a customer.
+const DefaultAddressLabel = "Home"
+
+// PostalAddress is a postal address. Country is stored in plaintext, the other fields are
+// encrypted like the personal data of customers.
+type PostalAddress struct {
+	UnitNo     sql.NullString `json:"unit_no" db:"unit_no"` // Nullable string
+	StreetName sql.NullString `json:"street_name" db:"street_name"`
+	City       sql.NullString `json:"city" db:"city"`
+	State      sql.NullString `json:"state" db:"state"`
+	Country    sql.NullString `json:"country" db:"country"`
+	Zipcode    sql.NullString `json:"zipcode" db:"zipcode"`
+	Landmark   sql.NullString `json:"landmark" db:"landmark"`
+}
+
+// IsZero reports whether none of the fields of the address is set.
+func (a PostalAddress) IsZero() bool {
+	for _, field := range []sql.NullString{a.UnitNo, a.StreetName, a.City, a.State, a.Country, a.Zipcode, a.Landmark} {
+		if field.String != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// Address represents the structure of a CustomerAddresses record, an entry in the address book of
+// a customer. A customer has at most one default shipping and one default billing address.
+type Address struct {
+	ID         int64  `json:"id" db:"id"`
+	CustomerID int64  `json:"customer_id" db:"customer_id"`
+	Label      string `json:"label" db:"label"` // Home, Office, ...
+	PostalAddress
+	DefaultShipping bool   `json:"is_default_shipping" db:"is_default_shipping"`
+	DefaultBilling  bool   `json:"is_default_billing" db:"is_default_billing"`
+	CreatedAt       string `json:"created_at" db:"created_at"` // Timestamp as string
+	UpdatedAt       string `json:"updated_at" db:"updated_at"` // Timestamp as string
+}
+
+// addressColumns are the columns read into a storedAddress, in scan order.
+const addressColumns = `a.id, a.customer_id, a.label, a.unit_no, a.street_name, a.city, a.state, a.country, a.zipcode,
+	a.landmark, a.is_default_shipping, a.is_default_billing, a.created_at, a.updated_at, a.pii_key_id, a.pii_data_key`
+
+// storedAddress is an Address as written to the database, see storedCustomer.
+type storedAddress struct {
+	Address
+	KeyID   sql.NullString
+	DataKey sql.NullString
+}
+
+// GetAddresses retrieves the address book of a customer, oldest address first.
+func (r *CustomerRepository) GetAddresses(email string) ([]Address, error) {
+	customerID, err := customerIDByEmail(r.DB, email)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := r.DB.Query(`SELECT `+addressColumns+` FROM CustomerAddresses a WHERE a.customer_id = ? ORDER BY a.id`, customerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query addresses: %w", err)
+	}
+	defer rows.Close()
+
+	addresses := []Address{}
+	for rows.Next() {
+		address, err := r.scanAddress(rows)
+		if err != nil {
+			return nil, err
+		}
+		addresses = append(addresses, *address)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error occurred during rows iteration: %w", err)
+	}
+	return addresses, nil
+}
+
+// GetAddress retrieves an address from the address book of a customer.
+func (r *CustomerRepository) GetAddress(email string, id int64) (*Address, error) {
+	address, err := r.scanAddress(r.DB.QueryRow(`
+		SELECT `+addressColumns+` FROM CustomerAddresses a JOIN Customer c ON c.id = a.customer_id
+		WHERE c.email = ? AND a.id = ?`, email, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: %d", ErrAddressNotFound, id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return address, nil
+}
+
+// CreateAddress adds an address to the address book of a customer. The first address of a
+// customer becomes their default shipping and billing address; an address made a default takes
+// over from the previous one.
+func (r *CustomerRepository) CreateAddress(email string, address *Address) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	customerID, err := lockCustomerByEmail(tx, email)
+	if err != nil {
+		return err
+	}
+	var count int
+	if err := tx.QueryRow(`SELECT COUNT(*) FROM CustomerAddresses WHERE customer_id = ?`, customerID).Scan(&count); err != nil {
+		return fmt.Errorf("failed to count addresses: %w", err)
+	}
+	if count == 0 {
+		address.DefaultShipping, address.DefaultBilling = true, true
+	}
+
+	stored, err := r.sealAddress(address)
+	if err != nil {
+		return err
+	}
+	if err := clearDefaultAddresses(tx, customerID, 0, address.DefaultShipping, address.DefaultBilling); err != nil {
+		return err
+	}
+	if err := insertAddress(tx, customerID, stored); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	address.ID, address.CustomerID = stored.ID, customerID
+	return nil
+}
+
+// UpdateAddress replaces an address in the address book of a customer. Making it a default
+// takes over from the previous default; clearing a default flag leaves the customer without one.
+func (r *CustomerRepository) UpdateAddress(email string, address *Address) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	customerID, err := lockCustomerByEmail(tx, email)
+	if err != nil {
+		return err
+	}
+	if _, err := lockAddress(tx, customerID, address.ID); err != nil {
+		return err
+	}
+
+	stored, err := r.sealAddress(address)
+	if err != nil {
+		return err
+	}
+	if err := clearDefaultAddresses(tx, customerID, address.ID, address.DefaultShipping, address.DefaultBilling); err != nil {
+		return err
+	}
+	_, err = tx.Exec(`
+		UPDATE CustomerAddresses
+		SET label = ?, unit_no = ?, street_name = ?, city = ?, state = ?, country = ?, zipcode = ?, landmark = ?,
+			is_default_shipping = ?, is_default_billing = ?, pii_key_id = ?, pii_data_key = ?
+		WHERE id = ?`,
+		stored.Label, stored.UnitNo, stored.StreetName, stored.City, stored.State, stored.Country, stored.Zipcode,
+		stored.Landmark, stored.DefaultShipping, stored.DefaultBilling, stored.KeyID, stored.DataKey, stored.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update address: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	address.CustomerID = customerID
+	return nil
+}
+
+// DeleteAddress removes an address from the address book of a customer. When it was a default,
+// the oldest remaining address takes its place, so checkout keeps working. Orders placed with the
+// address keep their copy of it.
+func (r *CustomerRepository) DeleteAddress(email string, id int64) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	customerID, err := lockCustomerByEmail(tx, email)
+	if err != nil {
+		return err
+	}
+	defaults, err := lockAddress(tx, customerID, id)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM CustomerAddresses WHERE id = ?`, id); err != nil {
+		return fmt.Errorf("failed to delete address: %w", err)
+	}
+	for column, wasDefault := range defaults {
+		if !wasDefault {
+			continue
+		}
+		_, err := tx.Exec(`UPDATE CustomerAddresses SET `+column+` = TRUE WHERE customer_id = ? ORDER BY id LIMIT 1`, customerID)
+		if err != nil {
+			return fmt.Errorf("failed to promote default address: %w", err)
+		}
+	}
+	return tx.Commit()
+}
+
+// saveDefaultAddress stores the address given with a customer as their default shipping address
+// inside tx, replacing the current one. A customer without one gets a new address book entry,
+// which also becomes their default billing address unless they have one.
+func saveDefaultAddress(tx *sql.Tx, customerID int64, address *storedAddress) error {
+	var id int64
+	err := tx.QueryRow(`SELECT id FROM CustomerAddresses WHERE customer_id = ? AND is_default_shipping FOR UPDATE`, customerID).Scan(&id)
+	if err == sql.ErrNoRows {
+		var billing int
+		err := tx.QueryRow(`SELECT COUNT(*) FROM CustomerAddresses WHERE customer_id = ? AND is_default_billing`, customerID).Scan(&billing)
+		if err != nil {
+			return fmt.Errorf("failed to read default billing address: %w", err)
+		}
+		address.Label = DefaultAddressLabel
+		address.DefaultShipping, address.DefaultBilling = true, billing == 0
+		return insertAddress(tx, customerID, address)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read default shipping address: %w", err)
+	}
+
+	_, err = tx.Exec(`
+		UPDATE CustomerAddresses
+		SET unit_no = ?, street_name = ?, city = ?, state = ?, country = ?, zipcode = ?, landmark = ?,
+			pii_key_id = ?, pii_data_key = ?
+		WHERE id = ?`,
+		address.UnitNo, address.StreetName, address.City, address.State, address.Country, address.Zipcode,
+		address.Landmark, address.KeyID, address.DataKey, id)
+	if err != nil {
+		return fmt.Errorf("failed to update default shipping address: %w", err)
+	}
+	address.ID = id
+	return nil
+}
+
+// insertAddress inserts an address of a customer inside tx and sets its ID.
+func insertAddress(tx *sql.Tx, customerID int64, address *storedAddress) error {
+	result, err := tx.Exec(`
+		INSERT INTO CustomerAddresses (
+			customer_id, label, unit_no, street_name, city, state, country, zipcode, landmark,
+			is_default_shipping, is_default_billing, pii_key_id, pii_data_key
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		customerID, address.Label, address.UnitNo, address.StreetName, address.City, address.State, address.Country,
+		address.Zipcode, address.Landmark, address.DefaultShipping, address.DefaultBilling, address.KeyID, address.DataKey)
+	if err != nil {
+		return fmt.Errorf("failed to insert address: %w", err)
+	}
+	if address.ID, err = result.LastInsertId(); err != nil {
+		return fmt.Errorf("failed to read address id: %w", err)
+	}
+	address.CustomerID = customerID
+	return nil
+}
+
+// clearDefaultAddresses unsets the default flags of the addresses of a customer other than
+// keepID, for the flags that are about to be set on it. The flags must be cleared first, since
+// only one address can hold each.
+func clearDefaultAddresses(tx *sql.Tx, customerID int64, keepID int64, shipping bool, billing bool) error {
+	if shipping {
+		_, err := tx.Exec(`UPDATE CustomerAddresses SET is_default_shipping = FALSE WHERE customer_id = ? AND id <> ?`, customerID, keepID)
+		if err != nil {
+			return fmt.Errorf("failed to clear default shipping address: %w", err)
+		}
+	}
+	if billing {
+		_, err := tx.Exec(`UPDATE CustomerAddresses SET is_default_billing = FALSE WHERE customer_id = ? AND id <> ?`, customerID, keepID)
+		if err != nil {
+			return fmt.Errorf("failed to clear default billing address: %w", err)
+		}
+	}
+	return nil
+}
+
+// lockAddress locks an address of a customer until tx ends, failing with ErrAddressNotFound when
+// the customer has no address with that id. It returns whether the address is the default, keyed
+// by the is_default_shipping and is_default_billing columns.
+func lockAddress(tx *sql.Tx, customerID int64, id int64) (map[string]bool, error) {
+	var shipping, billing bool
+	err := tx.QueryRow(`SELECT is_default_shipping, is_default_billing FROM CustomerAddresses WHERE id = ? AND customer_id = ? FOR UPDATE`,
+		id, customerID).Scan(&shipping, &billing)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("%w: %d", ErrAddressNotFound, id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up address: %w", err)
+	}
+	return map[string]bool{"is_default_shipping": shipping, "is_default_billing": billing}, nil
+}
+
+// lockCustomerByEmail locks a customer until tx ends and returns their id.
+func lockCustomerByEmail(tx *sql.Tx, email string) (int64, error) {
+	var id int64
+	err := tx.QueryRow(`SELECT id FROM Customer WHERE email = ? FOR UPDATE`, email).Scan(&id)
+	if err == sql.ErrNoRows {
+		return 0, fmt.Errorf("%w: %s", ErrCustomerNotFound, email)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to look up customer: %w", err)
+	}
+	return id, nil
+}
+
+// customerIDByEmail returns the id of the customer with email.
+func customerIDByEmail(q *sql.DB, email string) (int64, error) {
+	var id int64
+	err := q.QueryRow(`SELECT id FROM Customer WHERE email = ?`, email).Scan(&id)
+	if err == sql.ErrNoRows {
+		return 0, fmt.Errorf("%w: %s", ErrCustomerNotFound, email)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to look up customer: %w", err)
+	}
+	return id, nil
+}
+
+// scanAddress reads a row of addressColumns and decrypts it.
+func (r *CustomerRepository) scanAddress(row rowScanner) (*Address, error) {
+	stored, err := scanStoredAddress(row)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.openAddress(stored); err != nil {
+		return nil, err
+	}
+	return &stored.Address, nil
+}
+
+// scanStoredAddress reads a row of addressColumns as it is stored. sql.ErrNoRows is wrapped, not
+// replaced.
+func scanStoredAddress(row rowScanner) (*storedAddress, error) {
+	var address storedAddress
+	err := row.Scan(
+		&address.ID,
+		&address.CustomerID,
+		&address.Label,
+		&address.UnitNo,
+		&address.StreetName,
+		&address.City,
+		&address.State,
+		&address.Country,
+		&address.Zipcode,
+		&address.Landmark,
+		&address.DefaultShipping,
+		&address.DefaultBilling,
+		&address.CreatedAt,
+		&address.UpdatedAt,
+		&address.KeyID,
+		&address.DataKey,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve address: %w", err)
+	}
+	return &address, nil
+}
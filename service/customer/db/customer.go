@@ -3,32 +3,39 @@ package db
 import (
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
 
 	_ "github.com/go-sql-driver/mysql"
+	event_db "github.com/mayureshucsb2019/bookstore/service/event/db"
+	"github.com/mayureshucsb2019/bookstore/service/pii"
 )
 
-// Customer represents the structure of a Customer record in the database.
+// ErrCustomerNotFound is returned when no customer exists with the requested email
+var ErrCustomerNotFound = errors.New("customer not found")
+
+// Customer represents the structure of a Customer record in the database. Orders and carts refer
+// to customers by ID, so the email can change. The address of a customer is their default shipping
+// address, kept in their address book.
 type Customer struct {
+	ID               int64          `json:"id" db:"id"`
 	Email            string         `json:"email" db:"email"`
 	FirstName        string         `json:"first_name" db:"first_name"`
 	MiddleName       sql.NullString `json:"middle_name" db:"middle_name"` // Nullable string
 	LastName         string         `json:"last_name" db:"last_name"`
 	PhoneNumber      sql.NullString `json:"phone_number" db:"phone_number"`
-	Dob              string         `json:"dob" db:"dob"`         // Date in string format
-	UnitNo           sql.NullString `json:"unit_no" db:"unit_no"` // Nullable string
-	StreetName       sql.NullString `json:"street_name" db:"street_name"`
-	City             sql.NullString `json:"city" db:"city"`
-	State            sql.NullString `json:"state" db:"state"`
-	Country          sql.NullString `json:"country" db:"country"`
-	Zipcode          sql.NullString `json:"zipcode" db:"zipcode"`
-	Landmark         sql.NullString `json:"landmark" db:"landmark"`
+	Dob              string         `json:"dob" db:"dob"`                             // Date in string format
 	RegistrationDate string         `json:"registration_date" db:"registration_date"` // Timestamp as string
 	LastLogin        sql.NullString `json:"last_login" db:"last_login"`               // Timestamp as string
 	Status           string         `json:"status" db:"status"`                       // ENUM value
 	Notes            sql.NullString `json:"notes" db:"notes"`
-	Languages        []string       `json:"languages" db:"languages"` // JSON array of strings
+	Languages        []string       `json:"languages" db:"languages"`                 // JSON array of strings
+	EmailVerifiedAt  sql.NullString `json:"email_verified_at" db:"email_verified_at"` // UTC, set by VerifyEmail
+	Segments         []string       `json:"segments"`                                 // Only set by FindCustomers
+
+	// Default shipping address from the address book, AddressID is 0 when there is none
+	AddressID int64 `json:"address_id"`
+	PostalAddress
 }
 
 // Scan method to handle the JSON decoding for the Languages field
@@ -43,113 +50,246 @@ func (a *Customer) Scan(src interface{}) error {
 	}
 }
 
+// customerColumns are the columns of customerTables read into a storedCustomer, in scan order.
+const customerColumns = `c.id, c.email, c.first_name, c.middle_name, c.last_name, c.phone_number, c.dob,
+	c.registration_date, c.last_login, c.status, c.notes, c.languages, c.email_verified_at, c.pii_key_id, c.pii_data_key,
+	a.id, a.label, a.unit_no, a.street_name, a.city, a.state, a.country, a.zipcode, a.landmark, a.pii_key_id, a.pii_data_key`
+
+// customerTables joins customers with their default shipping address.
+const customerTables = `Customer c LEFT JOIN CustomerAddresses a ON a.customer_id = c.id AND a.is_default_shipping`
+
+// notErased is the condition over customerTables leaving out the placeholders of erased customers,
+// which only exist so their orders keep a customer.
+const notErased = `(c.status IS NULL OR c.status <> '` + StatusErased + `')`
+
 // CustomerRepository provides access to the Customer storage.
 type CustomerRepository struct {
 	DB *sql.DB
+	// Keys encrypts the personal data of customers at rest, see piiFields. Without it customers
+	// are written in plaintext, and reading an encrypted customer fails.
+	Keys *pii.Keyring
+	// Segments are the thresholds of customer segments, DefaultSegmentRules when not set
+	Segments SegmentRules
 }
 
-// CreateCustomer inserts a new Customer into the database.
+// CreateCustomer inserts a new Customer into the database and publishes a CustomerCreated event.
 func (r *CustomerRepository) CreateCustomer(customer *Customer) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	stored, err := r.seal(customer)
+	if err != nil {
+		return err
+	}
+	if err := createCustomer(tx, stored); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	customer.ID = stored.ID
+	return nil
+}
+
+// createCustomer inserts a Customer with its address and records its CustomerCreated event inside tx.
+func createCustomer(tx *sql.Tx, customer *storedCustomer) error {
 	languagesJSON, err := json.Marshal(customer.Languages)
 	if err != nil {
-		log.Fatalf("Failed to marshal languages to JSON: %v", err)
+		return fmt.Errorf("failed to marshal languages: %w", err)
 	}
 	// Prepare the SQL insert statement
 	query := `
 		INSERT INTO Customer (
 			email, first_name, middle_name, last_name, phone_number, dob,
-			unit_no, street_name, city, state, country, zipcode, landmark,
-			last_login, status, notes, languages
+			last_login, status, notes, languages, pii_key_id, pii_data_key, phone_number_bidx
 		) VALUES (
-			?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
+			?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
 		)
 	`
 
 	// Execute the SQL statement
-	_, err = r.DB.Exec(query,
+	result, err := tx.Exec(query,
 		customer.Email,
 		customer.FirstName,
 		customer.MiddleName,
 		customer.LastName,
 		customer.PhoneNumber,
 		customer.Dob,
-		customer.UnitNo,
-		customer.StreetName,
-		customer.City,
-		customer.State,
-		customer.Country,
-		customer.Zipcode,
-		customer.Landmark,
 		customer.LastLogin,
 		customer.Status,
 		customer.Notes,
 		languagesJSON,
+		customer.KeyID,
+		customer.DataKey,
+		customer.PhoneIndex,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to insert customer: %w", err)
 	}
+	if customer.ID, err = result.LastInsertId(); err != nil {
+		return fmt.Errorf("failed to read customer id: %w", err)
+	}
+	if customer.Address != nil {
+		if err := saveDefaultAddress(tx, customer.ID, customer.Address); err != nil {
+			return err
+		}
+		customer.AddressID = customer.Address.ID
+	}
 
-	return nil
+	return event_db.Record(tx, event_db.CustomerCreated, event_db.EntityCustomer, customer.Email, customerEventPayload(&customer.Customer))
 }
 
 // GetCustomerByID retrieves a Customer from the database by its email.
 func (r *CustomerRepository) GetCustomerByID(email string) (*Customer, error) {
 	// Prepare the SQL select statement
-	query := `SELECT * FROM Customer WHERE email = ?`
+	query := `SELECT ` + customerColumns + ` FROM ` + customerTables + ` WHERE c.email = ?`
+
+	customer, err := r.scanCustomer(r.DB.QueryRow(query, email))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			// No customer found with the given email
+			return nil, fmt.Errorf("%w: %s", ErrCustomerNotFound, email)
+		}
+		return nil, err
+	}
+	return customer, nil
+}
 
-	// Declare a variable to hold the Customer data
-	var customer Customer
+// rowScanner is implemented by *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanCustomer reads a row of customerColumns and decrypts it.
+func (r *CustomerRepository) scanCustomer(row rowScanner) (*Customer, error) {
+	stored, err := scanStoredCustomer(row)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.open(stored); err != nil {
+		return nil, err
+	}
+	return &stored.Customer, nil
+}
+
+// scanStoredCustomer reads a row of customerColumns as it is stored. sql.ErrNoRows is wrapped, not
+// replaced.
+func scanStoredCustomer(row rowScanner) (*storedCustomer, error) {
+	var customer storedCustomer
 
 	// Temporary variable to hold the JSON data
 	var languagesJSON []byte
-
-	// Execute the query and scan the result into the customer variable
-	row := r.DB.QueryRow(query, email)
+	// The default shipping address, all NULL when the customer has none
+	var addressID sql.NullInt64
+	var address storedAddress
+	var label sql.NullString
 
 	err := row.Scan(
+		&customer.ID,
 		&customer.Email,
 		&customer.FirstName,
 		&customer.MiddleName,
 		&customer.LastName,
 		&customer.PhoneNumber,
 		&customer.Dob,
-		&customer.UnitNo,
-		&customer.StreetName,
-		&customer.City,
-		&customer.State,
-		&customer.Country,
-		&customer.Zipcode,
-		&customer.Landmark,
 		&customer.RegistrationDate,
 		&customer.LastLogin,
 		&customer.Status,
 		&customer.Notes,
 		&languagesJSON,
+		&customer.EmailVerifiedAt,
+		&customer.KeyID,
+		&customer.DataKey,
+		&addressID,
+		&label,
+		&address.UnitNo,
+		&address.StreetName,
+		&address.City,
+		&address.State,
+		&address.Country,
+		&address.Zipcode,
+		&address.Landmark,
+		&address.KeyID,
+		&address.DataKey,
 	)
-
 	if err != nil {
-		if err == sql.ErrNoRows {
-			// No customer found with the given email
-			return nil, fmt.Errorf("customer with id %s not found", email)
-		}
-		// Other errors
 		return nil, fmt.Errorf("failed to retrieve customer: %w", err)
 	}
+	if addressID.Valid {
+		address.ID, address.CustomerID, address.Label, address.DefaultShipping = addressID.Int64, customer.ID, label.String, true
+		customer.Address = &address
+	}
 
 	// Unmarshal the JSON data into a []string slice
-	var languages []string
 	if len(languagesJSON) > 0 { // Check if languagesJSON is not empty
-		if err := json.Unmarshal(languagesJSON, &languages); err != nil {
+		if err := json.Unmarshal(languagesJSON, &customer.Languages); err != nil {
 			return nil, fmt.Errorf("failed to unmarshal languages: %w", err)
 		}
 	}
-	customer.Languages = languages
-
 	return &customer, nil
 }
 
-// UpdateCustomer updates an existing Customer record in the database.
+// UpdateCustomer updates an existing Customer record in the database and publishes a
+// CustomerUpdated event, plus a CustomerDeactivated event when the status changed to Inactive.
+// A customer given without an address keeps their address book as it is, and one given without a
+// status keeps their status. Customers are suspended, reactivated and closed by TransitionCustomer
+// instead, which fails with ErrInvalidStatusTransition here.
 func (r *CustomerRepository) UpdateCustomer(customer *Customer) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	stored, err := r.seal(customer)
+	if err != nil {
+		return err
+	}
+	if _, err := updateCustomer(tx, stored); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// SaveCustomers creates or replaces every Customer in a single transaction, publishing the same
+// events as CreateCustomer and UpdateCustomer, and reports for each Customer whether it was created.
+func (r *CustomerRepository) SaveCustomers(customers []Customer) ([]bool, error) {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	created := make([]bool, len(customers))
+	for i := range customers {
+		stored, err := r.seal(&customers[i])
+		if err != nil {
+			return nil, fmt.Errorf("failed to save customer %s: %w", customers[i].Email, err)
+		}
+		found, err := updateCustomer(tx, stored)
+		if err != nil {
+			return nil, fmt.Errorf("failed to save customer %s: %w", customers[i].Email, err)
+		}
+		if found {
+			continue
+		}
+		if err := createCustomer(tx, stored); err != nil {
+			return nil, fmt.Errorf("failed to save customer %s: %w", customers[i].Email, err)
+		}
+		created[i] = true
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// updateCustomer updates a Customer and records its events inside tx. It reports whether the
+// Customer exists. An empty RegistrationDate keeps the stored one.
+func updateCustomer(tx *sql.Tx, customer *storedCustomer) (bool, error) {
 	// Prepare the SQL update statement
 	query := `
 		UPDATE Customer
@@ -159,56 +299,93 @@ func (r *CustomerRepository) UpdateCustomer(customer *Customer) error {
 			last_name = ?, 
 			phone_number = ?, 
 			dob = ?, 
-			unit_no = ?, 
-			street_name = ?, 
-			city = ?, 
-			state = ?, 
-			country = ?, 
-			zipcode = ?, 
-			landmark = ?, 
-			registration_date = ?, 
+			registration_date = COALESCE(NULLIF(?, ''), registration_date), 
 			last_login = ?, 
 			status = ?, 
 			notes = ?, 
-			languages = ?
+			languages = ?,
+			pii_key_id = ?,
+			pii_data_key = ?,
+			phone_number_bidx = ?
 		WHERE email = ?
 	`
 
+	languagesJSON, err := json.Marshal(customer.Languages)
+	if err != nil {
+		return false, fmt.Errorf("failed to marshal languages: %w", err)
+	}
+
+	var previousStatus sql.NullString
+	err = tx.QueryRow(`SELECT id, status FROM Customer WHERE email = ? FOR UPDATE`, customer.Email).Scan(&customer.ID, &previousStatus)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to read customer status: %w", err)
+	}
+	if customer.Status == "" {
+		customer.Status = previousStatus.String
+	}
+	if previousStatus.String == StatusErased && customer.Status != StatusErased {
+		return true, fmt.Errorf("%w: customer %s is the placeholder of an erased customer", ErrInvalidStatusTransition, customer.Email)
+	}
+	if customer.Status != previousStatus.String && (isStaffStatus(customer.Status) || isStaffStatus(previousStatus.String)) {
+		return true, fmt.Errorf("%w: customer %s is %s and cannot become %s through an update, only by staff giving a reason",
+			ErrInvalidStatusTransition, customer.Email, previousStatus.String, customer.Status)
+	}
+
 	// Execute the SQL statement
-	_, err := r.DB.Exec(query,
+	_, err = tx.Exec(query,
 		customer.FirstName,
 		customer.MiddleName,
 		customer.LastName,
 		customer.PhoneNumber,
 		customer.Dob,
-		customer.UnitNo,
-		customer.StreetName,
-		customer.City,
-		customer.State,
-		customer.Country,
-		customer.Zipcode,
-		customer.Landmark,
 		customer.RegistrationDate,
 		customer.LastLogin,
 		customer.Status,
 		customer.Notes,
-		customer.Languages,
+		languagesJSON,
+		customer.KeyID,
+		customer.DataKey,
+		customer.PhoneIndex,
 		customer.Email, // Email is used as the unique identifier
 	)
 	if err != nil {
-		return fmt.Errorf("failed to update customer: %w", err)
+		return true, fmt.Errorf("failed to update customer: %w", err)
+	}
+	if customer.Address != nil {
+		if err := saveDefaultAddress(tx, customer.ID, customer.Address); err != nil {
+			return true, err
+		}
+		customer.AddressID = customer.Address.ID
 	}
 
-	return nil
+	if err := event_db.Record(tx, event_db.CustomerUpdated, event_db.EntityCustomer, customer.Email, customerEventPayload(&customer.Customer)); err != nil {
+		return true, err
+	}
+	if customer.Status == StatusInactive && previousStatus.String != StatusInactive {
+		payload := map[string]string{"email": customer.Email}
+		if err := event_db.Record(tx, event_db.CustomerDeactivated, event_db.EntityCustomer, customer.Email, payload); err != nil {
+			return true, err
+		}
+	}
+	return true, nil
 }
 
-// DeleteCustomer removes a Customer from the database by their email.
+// DeleteCustomer removes a Customer from the database by their email and publishes a CustomerDeleted event.
 func (r *CustomerRepository) DeleteCustomer(email string) error {
 	// Prepare the SQL delete statement
 	query := `DELETE FROM Customer WHERE email = ?`
 
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
 	// Execute the SQL statement
-	result, err := r.DB.Exec(query, email)
+	result, err := tx.Exec(query, email)
 	if err != nil {
 		return fmt.Errorf("failed to delete customer: %w", err)
 	}
@@ -223,68 +400,66 @@ func (r *CustomerRepository) DeleteCustomer(email string) error {
 		return nil
 	}
 
-	return nil
+	if err := event_db.Record(tx, event_db.CustomerDeleted, event_db.EntityCustomer, email, map[string]string{"email": email}); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
 
 // GetAllCustomers retrieves all Customers from the database.
 func (r *CustomerRepository) GetAllCustomers() ([]Customer, error) {
+	// Slice to hold all customers
+	var customers []Customer
+	err := r.ForEachCustomer(func(customer *Customer) error {
+		customers = append(customers, *customer)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return customers, nil
+}
+
+// ForEachCustomer calls fn for every Customer in email order while reading them from the database,
+// leaving out the placeholders of erased customers. It stops at the first error fn returns.
+func (r *CustomerRepository) ForEachCustomer(fn func(customer *Customer) error) error {
 	// Prepare the SQL select statement
-	query := `SELECT * FROM Customer`
+	query := `SELECT ` + customerColumns + ` FROM ` + customerTables + ` WHERE ` + notErased + ` ORDER BY c.email`
 
 	// Execute the query
 	rows, err := r.DB.Query(query)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query customers: %w", err)
+		return fmt.Errorf("failed to query customers: %w", err)
 	}
 	defer rows.Close()
 
-	// Slice to hold all customers
-	var customers []Customer
-
 	// Iterate over the rows
 	for rows.Next() {
-		var customer Customer
-		// Temporary variable to hold the JSON data
-		var languagesJSON []byte
-
-		err := rows.Scan(
-			&customer.Email,
-			&customer.FirstName,
-			&customer.MiddleName,
-			&customer.LastName,
-			&customer.PhoneNumber,
-			&customer.Dob,
-			&customer.UnitNo,
-			&customer.StreetName,
-			&customer.City,
-			&customer.State,
-			&customer.Country,
-			&customer.Zipcode,
-			&customer.Landmark,
-			&customer.RegistrationDate,
-			&customer.LastLogin,
-			&customer.Status,
-			&customer.Notes,
-			&languagesJSON,
-		)
+		customer, err := r.scanCustomer(rows)
 		if err != nil {
-			return nil, fmt.Errorf("failed to scan customer: %w", err)
+			return err
 		}
-
-		// Unmarshal the languages JSON byte slice into a []string slice
-		if len(languagesJSON) > 0 {
-			if err := json.Unmarshal(languagesJSON, &customer.Languages); err != nil {
-				return nil, fmt.Errorf("failed to unmarshal languages: %w", err)
-			}
+		if err := fn(customer); err != nil {
+			return err
 		}
-
-		customers = append(customers, customer)
 	}
 
 	// Check for errors from iterating over rows
 	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("error occurred during rows iteration: %w", err)
+		return fmt.Errorf("error occurred during rows iteration: %w", err)
 	}
+	return nil
+}
 
-	return customers, nil
+// customerEventPayload is the representation of a customer carried by customer events.
+// Contact details and addresses are left out so they do not spread to downstream systems.
+func customerEventPayload(customer *Customer) map[string]interface{} {
+	return map[string]interface{}{
+		"id":         customer.ID,
+		"email":      customer.Email,
+		"first_name": customer.FirstName,
+		"last_name":  customer.LastName,
+		"status":     customer.Status,
+		"languages":  customer.Languages,
+	}
 }
@@ -0,0 +1,204 @@
+package db
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	event_db "github.com/mayureshucsb2019/bookstore/service/event/db"
+)
+
+var (
+	// ErrEmailTaken is returned when another customer already has the requested email
+	ErrEmailTaken = errors.New("email is already in use")
+	// ErrEmailChangeNotFound is returned for a token that was never issued, was superseded or was
+	// already confirmed
+	ErrEmailChangeNotFound = errors.New("email change not found")
+	// ErrEmailChangeExpired is returned for a token confirmed after EmailChangeTTL
+	ErrEmailChangeExpired = errors.New("email change has expired")
+)
+
+// EmailChangeTTL is how long the token of an email change can be confirmed.
+const EmailChangeTTL = 24 * time.Hour
+
+// EmailChange represents the structure of a CustomerEmailChanges record. Only the hash of the
+// token is stored, so the table cannot be used to confirm changes. Confirmed changes are kept, so
+// the events recorded under the previous email can still be found when the customer is erased.
+type EmailChange struct {
+	CustomerID  int64
+	OldEmail    string
+	NewEmail    string
+	RequestedAt string // UTC
+	ExpiresAt   string // UTC
+}
+
+// RequestEmailChange starts changing the email of a customer to newEmail and returns the token
+// that confirms it, to be sent to newEmail. A new request supersedes the pending one.
+func (r *CustomerRepository) RequestEmailChange(email string, newEmail string) (string, *EmailChange, error) {
+	token, err := newEmailChangeToken()
+	if err != nil {
+		return "", nil, err
+	}
+	now := time.Now().UTC()
+	change := &EmailChange{
+		OldEmail:    email,
+		NewEmail:    newEmail,
+		RequestedAt: now.Format("2006-01-02 15:04:05"),
+		ExpiresAt:   now.Add(EmailChangeTTL).Format("2006-01-02 15:04:05"),
+	}
+
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRow(`SELECT id FROM Customer WHERE email = ? FOR UPDATE`, email).Scan(&change.CustomerID)
+	if err == sql.ErrNoRows {
+		return "", nil, fmt.Errorf("%w: %s", ErrCustomerNotFound, email)
+	}
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to look up customer: %w", err)
+	}
+	if err := checkEmailAvailable(tx, newEmail); err != nil {
+		return "", nil, err
+	}
+
+	if _, err := tx.Exec(`DELETE FROM CustomerEmailChanges WHERE customer_id = ? AND confirmed_at IS NULL`, change.CustomerID); err != nil {
+		return "", nil, fmt.Errorf("failed to supersede email changes: %w", err)
+	}
+	_, err = tx.Exec(`
+		INSERT INTO CustomerEmailChanges (token_hash, customer_id, old_email, new_email, requested_at, expires_at)
+		VALUES (?, ?, ?, ?, ?, ?)`,
+		hashEmailChangeToken(token), change.CustomerID, change.OldEmail, change.NewEmail, change.RequestedAt, change.ExpiresAt)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to record email change: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return "", nil, err
+	}
+	return token, change, nil
+}
+
+// ConfirmEmailChange applies the email change a token was issued for and publishes a
+// CustomerEmailChanged event. Orders, carts and the rest of the customer's history refer to the
+// customer by id, so they follow the new email.
+func (r *CustomerRepository) ConfirmEmailChange(token string) (*EmailChange, error) {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var change EmailChange
+	var expired bool
+	tokenHash := hashEmailChangeToken(token)
+	err = tx.QueryRow(`
+		SELECT customer_id, old_email, new_email, requested_at, expires_at, expires_at <= UTC_TIMESTAMP()
+		FROM CustomerEmailChanges WHERE token_hash = ? AND confirmed_at IS NULL FOR UPDATE`, tokenHash).Scan(
+		&change.CustomerID, &change.OldEmail, &change.NewEmail, &change.RequestedAt, &change.ExpiresAt, &expired)
+	if err == sql.ErrNoRows {
+		return nil, ErrEmailChangeNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up email change: %w", err)
+	}
+	if expired {
+		return nil, ErrEmailChangeExpired
+	}
+
+	var current string
+	err = tx.QueryRow(`SELECT email FROM Customer WHERE id = ? FOR UPDATE`, change.CustomerID).Scan(&current)
+	if err == sql.ErrNoRows {
+		return nil, ErrEmailChangeNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up customer: %w", err)
+	}
+	if err := checkEmailAvailable(tx, change.NewEmail); err != nil {
+		return nil, err
+	}
+
+	// The token was delivered to the new email, so it is verified
+	_, err = tx.Exec(`UPDATE Customer SET email = ?, email_verified_at = UTC_TIMESTAMP(), status = IF(status = ?, ?, status)
+		WHERE id = ?`, change.NewEmail, StatusPending, StatusActive, change.CustomerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to change email: %w", err)
+	}
+	// The email may have changed since the request, so the previous email is the one replaced now
+	change.OldEmail = current
+	_, err = tx.Exec(`UPDATE CustomerEmailChanges SET old_email = ?, confirmed_at = UTC_TIMESTAMP() WHERE token_hash = ?`,
+		change.OldEmail, tokenHash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to confirm email change: %w", err)
+	}
+
+	payload := map[string]interface{}{"id": change.CustomerID, "email": change.NewEmail, "previous_email": change.OldEmail}
+	if err := event_db.Record(tx, event_db.CustomerEmailChanged, event_db.EntityCustomer, change.NewEmail, payload); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return &change, nil
+}
+
+// PreviousEmails returns the emails a customer had before their confirmed email changes.
+func (r *CustomerRepository) PreviousEmails(customerID int64) ([]string, error) {
+	return previousEmails(r.DB, customerID)
+}
+
+// queryer is implemented by *sql.DB and *sql.Tx.
+type queryer interface {
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+}
+
+func previousEmails(q queryer, customerID int64) ([]string, error) {
+	rows, err := q.Query(`SELECT old_email FROM CustomerEmailChanges WHERE customer_id = ? AND confirmed_at IS NOT NULL`, customerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query email changes: %w", err)
+	}
+	defer rows.Close()
+
+	var emails []string
+	for rows.Next() {
+		var email string
+		if err := rows.Scan(&email); err != nil {
+			return nil, fmt.Errorf("failed to scan email change: %w", err)
+		}
+		emails = append(emails, email)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+	return emails, nil
+}
+
+func checkEmailAvailable(tx *sql.Tx, email string) error {
+	var exists int
+	err := tx.QueryRow(`SELECT 1 FROM Customer WHERE email = ?`, email).Scan(&exists)
+	if err == nil {
+		return fmt.Errorf("%w: %s", ErrEmailTaken, email)
+	}
+	if err != sql.ErrNoRows {
+		return fmt.Errorf("failed to look up customer: %w", err)
+	}
+	return nil
+}
+
+func newEmailChangeToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate email change token: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func hashEmailChangeToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,246 @@
+package db
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	event_db "github.com/mayureshucsb2019/bookstore/service/event/db"
+	webhook_db "github.com/mayureshucsb2019/bookstore/service/webhook/db"
+)
+
+var (
+	// ErrErasureNotFound is returned when no erasure receipt exists with the requested id
+	ErrErasureNotFound = errors.New("erasure receipt not found")
+	// ErrErasureBlocked is returned when a customer still has orders that need their address
+	ErrErasureBlocked = errors.New("customer has open orders")
+)
+
+// openOrderStatuses are the order statuses that still need the shipping address, so a customer
+// cannot be erased until their orders leave them.
+var openOrderStatuses = []string{"placed", "paid", "shipped", "return_requested"}
+
+// Erasure represents the structure of a CustomerErasures record, the receipt kept when the
+// personal data of a customer is erased. It holds no personal data itself: the email is only
+// kept as a hash keyed with the blind-index key of the PII keys, so a later request can be
+// matched against it but the email cannot be guessed back from a list of addresses. Without PII
+// keys no hash is kept.
+type Erasure struct {
+	ID             string
+	EmailHash      string
+	Pseudonym      string // Email the retained orders now refer to
+	Reason         string
+	OrdersRetained int
+	EventsRedacted int
+	ErasedAt       string
+}
+
+// HashEmail returns the hash erasure receipts keep of an email address, or "" when no PII keys
+// are configured.
+func (r *CustomerRepository) HashEmail(email string) string {
+	if r.Keys == nil {
+		return ""
+	}
+	return r.Keys.BlindIndex("erased_email", strings.ToLower(strings.TrimSpace(email)))
+}
+
+// EraseCustomer removes the personal data of a customer and records an erasure receipt. Orders
+// are kept for accounting: they are moved to an anonymous placeholder customer and lose the
+// street-level part of their shipping address, keeping what taxes were charged on. Cart contents
+// are deleted, and the customer's events in the outbox and in webhook deliveries are redacted.
+// A CustomerErased event carrying the pseudonym and the email hash lets downstream systems erase
+// their copies. The placeholder is Erased, which leaves it out of customer listings and segments.
+func (r *CustomerRepository) EraseCustomer(email string, reason string) (*Erasure, error) {
+	id, err := newErasureID()
+	if err != nil {
+		return nil, err
+	}
+	erasure := &Erasure{
+		ID:        id,
+		EmailHash: r.HashEmail(email),
+		Pseudonym: "erased-" + id + "@erased.invalid",
+		Reason:    reason,
+		ErasedAt:  time.Now().UTC().Format("2006-01-02 15:04:05"),
+	}
+
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var customerID int64
+	err = tx.QueryRow(`SELECT id FROM Customer WHERE email = ? FOR UPDATE`, email).Scan(&customerID)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("%w: %s", ErrCustomerNotFound, email)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up customer: %w", err)
+	}
+
+	orderIDs, err := lockCustomerOrders(tx, customerID)
+	if err != nil {
+		return nil, err
+	}
+	erasure.OrdersRetained = len(orderIDs)
+	// Events recorded before an email change refer to the previous email
+	emails, err := previousEmails(tx, customerID)
+	if err != nil {
+		return nil, err
+	}
+	emails = append(emails, email)
+
+	// The placeholder keeps only the registration date, so the orders still have a customer row
+	result, err := tx.Exec(`
+		INSERT INTO Customer (email, first_name, last_name, dob, registration_date, status)
+		SELECT ?, 'Erased', 'Customer', '', registration_date, ? FROM Customer WHERE id = ?`,
+		erasure.Pseudonym, StatusErased, customerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create placeholder customer: %w", err)
+	}
+	placeholderID, err := result.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read placeholder customer id: %w", err)
+	}
+	_, err = tx.Exec(`
+		UPDATE Orders SET customer_id = ?, ship_unit_no = NULL, ship_street_name = NULL, ship_landmark = NULL
+		WHERE customer_id = ?`, placeholderID, customerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to anonymize orders: %w", err)
+	}
+	// Deleting the customer deletes the cart, the address book and the email changes with it
+	if _, err := tx.Exec(`DELETE FROM Customer WHERE id = ?`, customerID); err != nil {
+		return nil, fmt.Errorf("failed to delete customer: %w", err)
+	}
+
+	if erasure.EventsRedacted, err = redactCustomerEvents(tx, emails, erasure.Pseudonym, orderIDs); err != nil {
+		return nil, err
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO CustomerErasures (id, email_hash, pseudonym, reason, orders_retained, events_redacted, erased_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		erasure.ID, erasure.EmailHash, erasure.Pseudonym, erasure.Reason, erasure.OrdersRetained, erasure.EventsRedacted, erasure.ErasedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record erasure: %w", err)
+	}
+	payload := map[string]string{"email_hash": erasure.EmailHash, "pseudonym": erasure.Pseudonym, "erasure_id": erasure.ID}
+	if err := event_db.Record(tx, event_db.CustomerErased, event_db.EntityCustomer, erasure.Pseudonym, payload); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return erasure, nil
+}
+
+// lockCustomerOrders returns the ids of the orders of a customer and locks them until tx ends.
+// It fails with ErrErasureBlocked while any of them is still open.
+func lockCustomerOrders(tx *sql.Tx, customerID int64) ([]string, error) {
+	rows, err := tx.Query(`SELECT id, status FROM Orders WHERE customer_id = ? ORDER BY id FOR UPDATE`, customerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query orders: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []string
+	var open []string
+	for rows.Next() {
+		var id int64
+		var status string
+		if err := rows.Scan(&id, &status); err != nil {
+			return nil, fmt.Errorf("failed to scan order: %w", err)
+		}
+		ids = append(ids, strconv.FormatInt(id, 10))
+		for _, s := range openOrderStatuses {
+			if status == s {
+				open = append(open, strconv.FormatInt(id, 10))
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+	if len(open) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrErasureBlocked, strings.Join(open, ", "))
+	}
+	return ids, nil
+}
+
+// redactCustomerEvents rewrites the outbox events of a customer and their orders, and the webhook
+// deliveries made of them, to refer to the pseudonym. Customer events are found under every email
+// the customer had. It returns how many events were rewritten.
+func redactCustomerEvents(tx *sql.Tx, emails []string, pseudonym string, orderIDs []string) (int, error) {
+	customerEvents, err := event_db.RedactEvents(tx, event_db.EntityCustomer, emails, func(event *event_db.Event) error {
+		event.EntityID = pseudonym
+		event.Payload = json.RawMessage(`{"email":` + strconv.Quote(pseudonym) + `,"erased":true}`)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	orderEvents, err := event_db.RedactEvents(tx, event_db.EntityOrder, orderIDs, func(event *event_db.Event) error {
+		var payload map[string]interface{}
+		if err := json.Unmarshal(event.Payload, &payload); err != nil {
+			return fmt.Errorf("failed to decode outbox event %d: %w", event.ID, err)
+		}
+		if _, ok := payload["customer_email"]; !ok {
+			return nil
+		}
+		payload["customer_email"] = pseudonym
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+		event.Payload = data
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	events := append(customerEvents, orderEvents...)
+	for _, event := range events {
+		body, err := json.Marshal(event)
+		if err != nil {
+			return 0, fmt.Errorf("failed to encode outbox event %d: %w", event.ID, err)
+		}
+		if err := webhook_db.RedactDeliveries(tx, event.ID, body); err != nil {
+			return 0, err
+		}
+	}
+	return len(events), nil
+}
+
+// GetErasure retrieves an erasure receipt by its id.
+func (r *CustomerRepository) GetErasure(id string) (*Erasure, error) {
+	var erasure Erasure
+	var reason sql.NullString
+	err := r.DB.QueryRow(`
+		SELECT id, email_hash, pseudonym, reason, orders_retained, events_redacted, erased_at
+		FROM CustomerErasures WHERE id = ?`, id).Scan(
+		&erasure.ID, &erasure.EmailHash, &erasure.Pseudonym, &reason, &erasure.OrdersRetained, &erasure.EventsRedacted, &erasure.ErasedAt)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("%w: %s", ErrErasureNotFound, id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get erasure receipt: %w", err)
+	}
+	erasure.Reason = reason.String
+	return &erasure, nil
+}
+
+func newErasureID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate erasure id: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,333 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/mayureshucsb2019/bookstore/service/pii"
+)
+
+// piiField is a Customer field stored encrypted, by the name authenticated with its value.
+type piiField struct {
+	name  string
+	value *string
+}
+
+// piiFields returns the personal data of a customer that is encrypted at rest. Their address is
+// stored with the address book, see addressPIIFields.
+func piiFields(customer *Customer) []piiField {
+	return []piiField{
+		{"phone_number", &customer.PhoneNumber.String},
+		{"dob", &customer.Dob},
+	}
+}
+
+// addressPIIFields returns the fields of an address that are encrypted at rest. Country stays in
+// plaintext because tax and reporting group customers by it.
+func addressPIIFields(address *PostalAddress) []piiField {
+	return []piiField{
+		{"unit_no", &address.UnitNo.String},
+		{"street_name", &address.StreetName.String},
+		{"city", &address.City.String},
+		{"state", &address.State.String},
+		{"zipcode", &address.Zipcode.String},
+		{"landmark", &address.Landmark.String},
+	}
+}
+
+// storedCustomer is a Customer as written to the database: with its personal data encrypted, the
+// wrapped data key, and the blind index of its phone number. All three are NULL when the customer
+// is stored in plaintext. Address is the default shipping address, nil when there is none.
+type storedCustomer struct {
+	Customer
+	KeyID      sql.NullString
+	DataKey    sql.NullString
+	PhoneIndex sql.NullString
+	Address    *storedAddress
+}
+
+// seal returns the stored form of a customer, encrypted under a new data key, and of their
+// address, under another one. Without a keyring the customer is stored as it is.
+func (r *CustomerRepository) seal(customer *Customer) (*storedCustomer, error) {
+	stored := &storedCustomer{Customer: *customer}
+	if !customer.PostalAddress.IsZero() {
+		address, err := r.sealAddress(&Address{ID: customer.AddressID, CustomerID: customer.ID, PostalAddress: customer.PostalAddress})
+		if err != nil {
+			return nil, err
+		}
+		stored.Address = address
+	}
+	if r.Keys == nil {
+		return stored, nil
+	}
+
+	envelope, err := r.encryptFields(piiFields(&stored.Customer))
+	if err != nil {
+		return nil, err
+	}
+	if phone := pii.NormalizePhone(customer.PhoneNumber.String); phone != "" {
+		stored.PhoneIndex = sql.NullString{String: r.Keys.BlindIndex("phone_number", phone), Valid: true}
+	}
+	stored.KeyID = sql.NullString{String: envelope.KeyID, Valid: true}
+	stored.DataKey = sql.NullString{String: envelope.WrappedKey, Valid: true}
+	return stored, nil
+}
+
+// open decrypts the personal data of a stored customer and their address in place, and copies the
+// address into the customer.
+func (r *CustomerRepository) open(stored *storedCustomer) error {
+	if stored.Address != nil {
+		if err := r.openAddress(stored.Address); err != nil {
+			return fmt.Errorf("customer %s: %w", stored.Email, err)
+		}
+		stored.AddressID, stored.PostalAddress = stored.Address.ID, stored.Address.PostalAddress
+	}
+	if !stored.KeyID.Valid {
+		return nil
+	}
+	if r.Keys == nil {
+		return fmt.Errorf("customer %s is encrypted but no PII keys are configured", stored.Email)
+	}
+	envelope := pii.Envelope{KeyID: stored.KeyID.String, WrappedKey: stored.DataKey.String}
+	if err := r.decryptFields(envelope, piiFields(&stored.Customer)); err != nil {
+		return fmt.Errorf("customer %s: %w", stored.Email, err)
+	}
+	return nil
+}
+
+// sealAddress returns the stored form of an address, encrypted under a new data key. Without a
+// keyring the address is stored as it is.
+func (r *CustomerRepository) sealAddress(address *Address) (*storedAddress, error) {
+	stored := &storedAddress{Address: *address}
+	if r.Keys == nil {
+		return stored, nil
+	}
+	envelope, err := r.encryptFields(addressPIIFields(&stored.PostalAddress))
+	if err != nil {
+		return nil, err
+	}
+	stored.KeyID = sql.NullString{String: envelope.KeyID, Valid: true}
+	stored.DataKey = sql.NullString{String: envelope.WrappedKey, Valid: true}
+	return stored, nil
+}
+
+// openAddress decrypts a stored address in place.
+func (r *CustomerRepository) openAddress(stored *storedAddress) error {
+	if !stored.KeyID.Valid {
+		return nil
+	}
+	if r.Keys == nil {
+		return fmt.Errorf("address %d is encrypted but no PII keys are configured", stored.ID)
+	}
+	envelope := pii.Envelope{KeyID: stored.KeyID.String, WrappedKey: stored.DataKey.String}
+	if err := r.decryptFields(envelope, addressPIIFields(&stored.PostalAddress)); err != nil {
+		return fmt.Errorf("address %d: %w", stored.ID, err)
+	}
+	return nil
+}
+
+// encryptFields encrypts fields in place under a new data key and returns its envelope.
+func (r *CustomerRepository) encryptFields(fields []piiField) (pii.Envelope, error) {
+	key, envelope, err := r.Keys.NewRecordKey()
+	if err != nil {
+		return pii.Envelope{}, err
+	}
+	for _, field := range fields {
+		if *field.value, err = key.Encrypt(field.name, *field.value); err != nil {
+			return pii.Envelope{}, fmt.Errorf("failed to encrypt %s: %w", field.name, err)
+		}
+	}
+	return envelope, nil
+}
+
+// decryptFields decrypts fields in place with the data key in envelope.
+func (r *CustomerRepository) decryptFields(envelope pii.Envelope, fields []piiField) error {
+	key, err := r.Keys.OpenRecordKey(envelope)
+	if err != nil {
+		return err
+	}
+	for _, field := range fields {
+		if *field.value, err = key.Decrypt(field.name, *field.value); err != nil {
+			return fmt.Errorf("failed to decrypt %s: %w", field.name, err)
+		}
+	}
+	return nil
+}
+
+// ReencryptReport counts the customers and addresses processed by ReencryptCustomers.
+type ReencryptReport struct {
+	Encrypted          int // Stored in plaintext until now
+	Rewrapped          int // Data key moved to the current key
+	AddressesEncrypted int
+	AddressesRewrapped int
+}
+
+// ReencryptCustomers brings every customer and address under the current key: records stored in
+// plaintext are encrypted, and the data keys of records wrapped under an older key are rewrapped,
+// which leaves their fields untouched. Records are processed batchSize at a time, each batch in
+// its own transaction, so it can run against a live database and be resumed after a failure. A
+// key can be removed from the keyring once this has run after it stopped being current.
+func (r *CustomerRepository) ReencryptCustomers(batchSize int) (ReencryptReport, error) {
+	var report ReencryptReport
+	if r.Keys == nil {
+		return report, errors.New("no PII keys are configured")
+	}
+	last := ""
+	for {
+		done, next, err := r.reencryptBatch(last, batchSize, &report)
+		if err != nil {
+			return report, err
+		}
+		if done {
+			break
+		}
+		last = next
+	}
+	var lastAddress int64
+	for {
+		done, next, err := r.reencryptAddressBatch(lastAddress, batchSize, &report)
+		if err != nil {
+			return report, err
+		}
+		if done {
+			return report, nil
+		}
+		lastAddress = next
+	}
+}
+
+// reencryptBatch processes the batch of customers after the email last and returns the last email
+// of the batch, or done when there were none left.
+func (r *CustomerRepository) reencryptBatch(last string, batchSize int, report *ReencryptReport) (bool, string, error) {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return false, "", fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query(`SELECT `+customerColumns+` FROM `+customerTables+`
+		WHERE c.email > ? AND (c.pii_key_id IS NULL OR c.pii_key_id <> ?)
+		ORDER BY c.email LIMIT ? FOR UPDATE OF c`, last, r.Keys.CurrentKeyID(), batchSize)
+	if err != nil {
+		return false, "", fmt.Errorf("failed to query customers: %w", err)
+	}
+	var batch []storedCustomer
+	for rows.Next() {
+		stored, err := scanStoredCustomer(rows)
+		if err != nil {
+			rows.Close()
+			return false, "", err
+		}
+		batch = append(batch, *stored)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return false, "", fmt.Errorf("error occurred during rows iteration: %w", err)
+	}
+	if len(batch) == 0 {
+		return true, "", nil
+	}
+
+	for _, stored := range batch {
+		if stored.KeyID.Valid {
+			envelope, err := r.Keys.Rewrap(pii.Envelope{KeyID: stored.KeyID.String, WrappedKey: stored.DataKey.String})
+			if err != nil {
+				return false, "", fmt.Errorf("customer %s: %w", stored.Email, err)
+			}
+			_, err = tx.Exec(`UPDATE Customer SET pii_key_id = ?, pii_data_key = ? WHERE email = ?`,
+				envelope.KeyID, envelope.WrappedKey, stored.Email)
+			if err != nil {
+				return false, "", fmt.Errorf("failed to rewrap customer %s: %w", stored.Email, err)
+			}
+			report.Rewrapped++
+			continue
+		}
+
+		// The address was not opened, so only the customer's own fields are sealed
+		sealed, err := r.seal(&stored.Customer)
+		if err != nil {
+			return false, "", fmt.Errorf("customer %s: %w", stored.Email, err)
+		}
+		_, err = tx.Exec(`
+			UPDATE Customer SET phone_number = ?, dob = ?, pii_key_id = ?, pii_data_key = ?, phone_number_bidx = ?
+			WHERE email = ?`,
+			sealed.PhoneNumber, sealed.Dob, sealed.KeyID, sealed.DataKey, sealed.PhoneIndex, stored.Email)
+		if err != nil {
+			return false, "", fmt.Errorf("failed to encrypt customer %s: %w", stored.Email, err)
+		}
+		report.Encrypted++
+	}
+	if err := tx.Commit(); err != nil {
+		return false, "", err
+	}
+	return false, batch[len(batch)-1].Email, nil
+}
+
+// reencryptAddressBatch processes the batch of addresses after the id last and returns the last
+// id of the batch, or done when there were none left.
+func (r *CustomerRepository) reencryptAddressBatch(last int64, batchSize int, report *ReencryptReport) (bool, int64, error) {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return false, 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query(`SELECT `+addressColumns+` FROM CustomerAddresses a
+		WHERE a.id > ? AND (a.pii_key_id IS NULL OR a.pii_key_id <> ?)
+		ORDER BY a.id LIMIT ? FOR UPDATE`, last, r.Keys.CurrentKeyID(), batchSize)
+	if err != nil {
+		return false, 0, fmt.Errorf("failed to query addresses: %w", err)
+	}
+	var batch []storedAddress
+	for rows.Next() {
+		stored, err := scanStoredAddress(rows)
+		if err != nil {
+			rows.Close()
+			return false, 0, err
+		}
+		batch = append(batch, *stored)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return false, 0, fmt.Errorf("error occurred during rows iteration: %w", err)
+	}
+	if len(batch) == 0 {
+		return true, 0, nil
+	}
+
+	for _, stored := range batch {
+		if stored.KeyID.Valid {
+			envelope, err := r.Keys.Rewrap(pii.Envelope{KeyID: stored.KeyID.String, WrappedKey: stored.DataKey.String})
+			if err != nil {
+				return false, 0, fmt.Errorf("address %d: %w", stored.ID, err)
+			}
+			_, err = tx.Exec(`UPDATE CustomerAddresses SET pii_key_id = ?, pii_data_key = ? WHERE id = ?`,
+				envelope.KeyID, envelope.WrappedKey, stored.ID)
+			if err != nil {
+				return false, 0, fmt.Errorf("failed to rewrap address %d: %w", stored.ID, err)
+			}
+			report.AddressesRewrapped++
+			continue
+		}
+
+		sealed, err := r.sealAddress(&stored.Address)
+		if err != nil {
+			return false, 0, fmt.Errorf("address %d: %w", stored.ID, err)
+		}
+		_, err = tx.Exec(`
+			UPDATE CustomerAddresses
+			SET unit_no = ?, street_name = ?, city = ?, state = ?, zipcode = ?, landmark = ?, pii_key_id = ?, pii_data_key = ?
+			WHERE id = ?`,
+			sealed.UnitNo, sealed.StreetName, sealed.City, sealed.State, sealed.Zipcode, sealed.Landmark,
+			sealed.KeyID, sealed.DataKey, stored.ID)
+		if err != nil {
+			return false, 0, fmt.Errorf("failed to encrypt address %d: %w", stored.ID, err)
+		}
+		report.AddressesEncrypted++
+	}
+	if err := tx.Commit(); err != nil {
+		return false, 0, err
+	}
+	return false, batch[len(batch)-1].ID, nil
+}
@@ -0,0 +1,195 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/mayureshucsb2019/bookstore/service/pii"
+)
+
+// Customer segments, computed from registration dates and orders. A customer can be in several
+// segments, or in none.
+const (
+	SegmentNew    = "new"    // Registered recently
+	SegmentRepeat = "repeat" // Placed more than one order
+	SegmentLapsed = "lapsed" // Ordered before, but not recently
+	SegmentVIP    = "vip"    // Ordered for a high lifetime value
+)
+
+// AllSegments lists every segment, in the order they are reported.
+var AllSegments = []string{SegmentNew, SegmentRepeat, SegmentLapsed, SegmentVIP}
+
+// SegmentRules are the thresholds of the segments. Zero fields take the value of
+// DefaultSegmentRules.
+type SegmentRules struct {
+	NewDays          int     `json:"new_days"`           // Customers registered within this many days are new
+	LapsedDays       int     `json:"lapsed_days"`        // Customers whose last order is older than this many days are lapsed
+	VIPLifetimeValue float64 `json:"vip_lifetime_value"` // In common.DefaultCurrency
+}
+
+// DefaultSegmentRules are the segment thresholds used unless configured otherwise.
+var DefaultSegmentRules = SegmentRules{NewDays: 30, LapsedDays: 180, VIPLifetimeValue: 1000}
+
+// customerOrderStats joins customerTables with a summary of the orders of each customer. Orders
+// cancelled, returned or refunded do not count, and totals are converted back to the currency of
+// the catalog prices they were converted from.
+const customerOrderStats = ` LEFT JOIN (
+		SELECT customer_id, COUNT(*) AS order_count, MAX(order_date) AS last_order_date,
+			SUM(total_amount / COALESCE(exchange_rate, 1)) AS lifetime_value
+		FROM Orders
+		WHERE customer_id IS NOT NULL AND status NOT IN ('cancelled', 'returned', 'refunded')
+		GROUP BY customer_id
+	) s ON s.customer_id = c.id`
+
+// segmentCondition returns the SQL condition over customerTables and customerOrderStats that a
+// customer is in a segment, with its arguments.
+func (r *CustomerRepository) segmentCondition(segment string) (string, []interface{}) {
+	rules := r.Segments
+	if rules.NewDays == 0 {
+		rules.NewDays = DefaultSegmentRules.NewDays
+	}
+	if rules.LapsedDays == 0 {
+		rules.LapsedDays = DefaultSegmentRules.LapsedDays
+	}
+	if rules.VIPLifetimeValue == 0 {
+		rules.VIPLifetimeValue = DefaultSegmentRules.VIPLifetimeValue
+	}
+
+	switch segment {
+	case SegmentNew:
+		return `COALESCE(c.registration_date >= NOW() - INTERVAL ? DAY, FALSE)`, []interface{}{rules.NewDays}
+	case SegmentRepeat:
+		return `COALESCE(s.order_count >= 2, FALSE)`, nil
+	case SegmentLapsed:
+		return `COALESCE(s.last_order_date < NOW() - INTERVAL ? DAY, FALSE)`, []interface{}{rules.LapsedDays}
+	case SegmentVIP:
+		return `COALESCE(s.lifetime_value >= ?, FALSE)`, []interface{}{rules.VIPLifetimeValue}
+	}
+	return `FALSE`, nil
+}
+
+// segmentColumns returns a column per segment of AllSegments, true when the customer is in it.
+func (r *CustomerRepository) segmentColumns() (string, []interface{}) {
+	var columns []string
+	var args []interface{}
+	for _, segment := range AllSegments {
+		condition, conditionArgs := r.segmentCondition(segment)
+		columns = append(columns, condition)
+		args = append(args, conditionArgs...)
+	}
+	return strings.Join(columns, ", "), args
+}
+
+// segmentScanner scans a row of customerColumns followed by segmentColumns.
+type segmentScanner struct {
+	row       rowScanner
+	inSegment []bool
+}
+
+func (s *segmentScanner) Scan(dest ...interface{}) error {
+	s.inSegment = make([]bool, len(AllSegments))
+	for i := range s.inSegment {
+		dest = append(dest, &s.inSegment[i])
+	}
+	return s.row.Scan(dest...)
+}
+
+// segments returns the segments of the row last scanned.
+func (s *segmentScanner) segments() []string {
+	var segments []string
+	for i, in := range s.inSegment {
+		if in {
+			segments = append(segments, AllSegments[i])
+		}
+	}
+	return segments
+}
+
+// CustomerFilter selects the customers returned by FindCustomers. Zero fields match every
+// customer.
+type CustomerFilter struct {
+	Phone          string // Matched through its blind index when PII keys are configured
+	Status         string
+	Segment        string // One of AllSegments
+	RegisteredFrom string // Date, inclusive
+	RegisteredTo   string // Date, inclusive
+	Country        string // Of the default shipping address
+}
+
+// FindCustomers retrieves the Customers matching a filter with their segments, in email order.
+// Placeholders of erased customers are left out.
+func (r *CustomerRepository) FindCustomers(filter CustomerFilter) ([]Customer, error) {
+	columns, args := r.segmentColumns()
+	conditions := []string{notErased}
+	if filter.Phone != "" {
+		if r.Keys != nil {
+			// Customers stored before the keys were configured still have a plaintext phone number
+			conditions = append(conditions, `(c.phone_number_bidx = ? OR (c.pii_key_id IS NULL AND c.phone_number = ?))`)
+			args = append(args, r.Keys.BlindIndex("phone_number", pii.NormalizePhone(filter.Phone)), filter.Phone)
+		} else {
+			conditions = append(conditions, `c.phone_number = ?`)
+			args = append(args, filter.Phone)
+		}
+	}
+	if filter.Status != "" {
+		conditions = append(conditions, `c.status = ?`)
+		args = append(args, filter.Status)
+	}
+	if filter.Segment != "" {
+		condition, conditionArgs := r.segmentCondition(filter.Segment)
+		conditions = append(conditions, condition)
+		args = append(args, conditionArgs...)
+	}
+	if filter.RegisteredFrom != "" {
+		conditions = append(conditions, `c.registration_date >= ?`)
+		args = append(args, filter.RegisteredFrom)
+	}
+	if filter.RegisteredTo != "" {
+		conditions = append(conditions, `c.registration_date < ? + INTERVAL 1 DAY`)
+		args = append(args, filter.RegisteredTo)
+	}
+	if filter.Country != "" {
+		conditions = append(conditions, `a.country = ?`)
+		args = append(args, filter.Country)
+	}
+
+	query := `SELECT ` + customerColumns + `, ` + columns + ` FROM ` + customerTables + customerOrderStats +
+		` WHERE ` + strings.Join(conditions, ` AND `) + ` ORDER BY c.email`
+
+	rows, err := r.DB.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query customers: %w", err)
+	}
+	defer rows.Close()
+
+	var customers []Customer
+	scanner := &segmentScanner{row: rows}
+	for rows.Next() {
+		customer, err := r.scanCustomer(scanner)
+		if err != nil {
+			return nil, err
+		}
+		customer.Segments = scanner.segments()
+		customers = append(customers, *customer)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error occurred during rows iteration: %w", err)
+	}
+	return customers, nil
+}
+
+// GetCustomerSegments returns the segments a customer is in.
+func (r *CustomerRepository) GetCustomerSegments(customerID int64) ([]string, error) {
+	columns, args := r.segmentColumns()
+	query := `SELECT ` + columns + ` FROM Customer c` + customerOrderStats + ` WHERE c.id = ?`
+	scanner := &segmentScanner{row: r.DB.QueryRow(query, append(args, customerID)...)}
+	err := scanner.Scan()
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("%w: %d", ErrCustomerNotFound, customerID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read customer segments: %w", err)
+	}
+	return scanner.segments(), nil
+}
@@ -0,0 +1,119 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	event_db "github.com/mayureshucsb2019/bookstore/service/event/db"
+)
+
+// ErrInvalidStatusTransition is returned when a customer cannot move from their current status to
+// the requested one.
+var ErrInvalidStatusTransition = errors.New("invalid customer status transition")
+
+// customerTransitions lists the statuses staff can move each status to. Reactivating a customer
+// whose email is not verified makes them Pending rather than Active.
+var customerTransitions = map[string][]string{
+	StatusPending:   {StatusSuspended, StatusClosed},
+	StatusActive:    {StatusSuspended, StatusClosed},
+	StatusInactive:  {StatusActive, StatusSuspended, StatusClosed},
+	StatusSuspended: {StatusActive, StatusClosed},
+}
+
+// CanTransition reports whether staff may move a customer from one status to another.
+func CanTransition(from, to string) bool {
+	for _, next := range customerTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// isStaffStatus reports whether a status is only set through TransitionCustomer, so a reason is
+// recorded for it.
+func isStaffStatus(status string) bool {
+	return status == StatusSuspended || status == StatusClosed
+}
+
+// StatusChange represents the structure of a CustomerStatusHistory record.
+type StatusChange struct {
+	CustomerID int64
+	FromStatus string
+	ToStatus   string
+	Reason     string
+	ChangedAt  string
+}
+
+// TransitionCustomer moves a customer to a new status, records the change with its reason and
+// publishes a CustomerStatusChanged event. A customer whose email is not verified is reactivated
+// as Pending.
+func (r *CustomerRepository) TransitionCustomer(email string, to string, reason string) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var customerID int64
+	var from string
+	var verified bool
+	err = tx.QueryRow(`SELECT id, COALESCE(status, ''), email_verified_at IS NOT NULL FROM Customer WHERE email = ? FOR UPDATE`, email).Scan(
+		&customerID, &from, &verified)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("%w: %s", ErrCustomerNotFound, email)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read customer status: %w", err)
+	}
+	if !CanTransition(from, to) {
+		return fmt.Errorf("%w: customer %s is %s and cannot become %s", ErrInvalidStatusTransition, email, from, to)
+	}
+	if to == StatusActive && !verified {
+		to = StatusPending
+	}
+
+	if _, err := tx.Exec(`UPDATE Customer SET status = ? WHERE id = ?`, to, customerID); err != nil {
+		return fmt.Errorf("failed to update customer status: %w", err)
+	}
+	_, err = tx.Exec(`INSERT INTO CustomerStatusHistory (customer_id, from_status, to_status, reason) VALUES (?, ?, ?, ?)`,
+		customerID, from, to, reason)
+	if err != nil {
+		return fmt.Errorf("failed to record customer status change: %w", err)
+	}
+	payload := map[string]interface{}{"id": customerID, "email": email, "from_status": from, "to_status": to, "reason": reason}
+	if err := event_db.Record(tx, event_db.CustomerStatusChanged, event_db.EntityCustomer, email, payload); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// GetStatusHistory retrieves the status changes made by staff to a customer, oldest first.
+func (r *CustomerRepository) GetStatusHistory(email string) ([]StatusChange, error) {
+	customerID, err := customerIDByEmail(r.DB, email)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `SELECT customer_id, from_status, to_status, reason, changed_at FROM CustomerStatusHistory WHERE customer_id = ? ORDER BY id`
+	rows, err := r.DB.Query(query, customerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query customer status history: %w", err)
+	}
+	defer rows.Close()
+
+	var history []StatusChange
+	for rows.Next() {
+		var change StatusChange
+		if err := rows.Scan(&change.CustomerID, &change.FromStatus, &change.ToStatus, &change.Reason, &change.ChangedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan customer status change: %w", err)
+		}
+		history = append(history, change)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+
+	return history, nil
+}
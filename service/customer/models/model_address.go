@@ -0,0 +1,63 @@
+package models
+
+import (
+	"errors"
+
+	"github.com/mayureshucsb2019/bookstore/service/common"
+)
+
+// Address is an entry in the address book of a customer.
+type Address struct {
+	// Assigned by the server; orders refer to the address by it
+	Id int64 `json:"id,omitempty"`
+
+	// Name the customer gives the address, such as Home or Office
+	Label string `json:"label"`
+
+	Unit string `json:"unit,omitempty"`
+
+	StreetName string `json:"street_name,omitempty"`
+
+	City string `json:"city,omitempty"`
+
+	State string `json:"state,omitempty"`
+
+	Country string `json:"country,omitempty"`
+
+	Zipcode string `json:"zipcode,omitempty"`
+
+	Landmark string `json:"landmark,omitempty"`
+
+	// Used at checkout when no shipping address is chosen. The first address of a customer is
+	// their default shipping and billing address.
+	IsDefaultShipping bool `json:"is_default_shipping"`
+
+	// Used at checkout when no billing address is chosen
+	IsDefaultBilling bool `json:"is_default_billing"`
+
+	CreatedAt string `json:"created_at,omitempty"`
+
+	UpdatedAt string `json:"updated_at,omitempty"`
+}
+
+// AssertAddressRequired checks if the required fields are not zero-ed
+func AssertAddressRequired(obj Address) error {
+	elements := map[string]interface{}{
+		"label": obj.Label,
+	}
+	for name, el := range elements {
+		if isZero := common.IsZeroValue(el); isZero {
+			return &common.RequiredError{Field: name}
+		}
+	}
+
+	return nil
+}
+
+// AssertAddressConstraints checks if the values respects the defined constraints
+func AssertAddressConstraints(obj Address) error {
+	if len(obj.Label) > 64 {
+		return &common.ParsingError{Param: "label", Err: errors.New("must be at most 64 characters")}
+	}
+	return nil
+}
@@ -1,8 +1,15 @@
 package models
 
-import "github.com/mayureshucsb2019/bookstore/service/common"
+import (
+	"errors"
+
+	"github.com/mayureshucsb2019/bookstore/service/common"
+)
 
 type Customer struct {
+	// Assigned by the server; orders and carts refer to the customer by it, so the email can change
+	Id int64 `json:"id,omitempty"`
+
 	Email string `json:"email"`
 
 	Name CustomerName `json:"name"`
@@ -18,6 +25,12 @@ type Customer struct {
 	Notes string `json:"notes,omitempty"`
 
 	Languages []string `json:"languages"`
+
+	// UTC time the customer proved they own the email; set by the server
+	EmailVerifiedAt string `json:"email_verified_at,omitempty"`
+
+	// Segments the customer is in, computed from their registration date and orders; set by the server
+	Segments []string `json:"segments,omitempty"`
 }
 
 // AssertCustomerRequired checks if the required fields are not zero-ed
@@ -40,5 +53,9 @@ func AssertCustomerRequired(obj Customer) error {
 
 // AssertCustomerConstraints checks if the values respects the defined constraints
 func AssertCustomerConstraints(obj Customer) error {
-	return nil
+	switch obj.Status {
+	case "", "Pending", "Active", "Inactive", "Suspended", "Closed":
+		return nil
+	}
+	return &common.ParsingError{Param: "status", Err: errors.New("must be one of Pending, Active, Inactive, Suspended, Closed")}
 }
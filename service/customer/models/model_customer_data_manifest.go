@@ -0,0 +1,16 @@
+package models
+
+// CustomerDataManifest describes the files of a customer data export archive.
+type CustomerDataManifest struct {
+	Email string `json:"email"`
+
+	ExportedAt string `json:"exported_at"`
+
+	// Archive file names mapped to what they hold
+	Files map[string]string `json:"files"`
+
+	Orders int `json:"orders"`
+
+	// Events about the customer and their orders still held in the outbox
+	Events int `json:"events"`
+}
@@ -0,0 +1,20 @@
+package models
+
+// CustomerErasure is the receipt of a customer erasure.
+type CustomerErasure struct {
+	Id string `json:"id"`
+
+	// SHA-256 of the lower case email, the only trace of the customer that is kept
+	EmailHash string `json:"email_hash"`
+
+	// Email of the placeholder customer the retained orders now belong to
+	Pseudonym string `json:"pseudonym"`
+
+	Reason string `json:"reason,omitempty"`
+
+	OrdersRetained int32 `json:"orders_retained"`
+
+	EventsRedacted int32 `json:"events_redacted"`
+
+	ErasedAt string `json:"erased_at"`
+}
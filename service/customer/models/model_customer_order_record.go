@@ -0,0 +1,14 @@
+package models
+
+import (
+	order_models "github.com/mayureshucsb2019/bookstore/service/order/models"
+)
+
+// CustomerOrderRecord is an order of a customer with its history, as included in a data export.
+type CustomerOrderRecord struct {
+	Order order_models.Order `json:"order"`
+
+	StatusHistory []order_models.OrderStatusChange `json:"status_history"`
+
+	Payments []order_models.PaymentAttempt `json:"payments"`
+}
@@ -0,0 +1,10 @@
+package models
+
+// CustomerProfile is everything stored about a customer, as included in a data export.
+type CustomerProfile struct {
+	Customer
+
+	RegistrationDate string `json:"registration_date,omitempty"`
+
+	LastLogin string `json:"last_login,omitempty"`
+}
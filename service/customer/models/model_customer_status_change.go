@@ -0,0 +1,12 @@
+package models
+
+// CustomerStatusChange is a change of the status of a customer made by staff.
+type CustomerStatusChange struct {
+	FromStatus string `json:"from_status"`
+
+	ToStatus string `json:"to_status"`
+
+	Reason string `json:"reason"`
+
+	ChangedAt string `json:"changed_at"`
+}
@@ -0,0 +1,34 @@
+package models
+
+import (
+	"errors"
+
+	"github.com/mayureshucsb2019/bookstore/service/common"
+)
+
+type CustomerStatusReason struct {
+	// Why the status of the customer changes, kept in their status history
+	Reason string `json:"reason"`
+}
+
+// AssertCustomerStatusReasonRequired checks if the required fields are not zero-ed
+func AssertCustomerStatusReasonRequired(obj CustomerStatusReason) error {
+	elements := map[string]interface{}{
+		"reason": obj.Reason,
+	}
+	for name, el := range elements {
+		if isZero := common.IsZeroValue(el); isZero {
+			return &common.RequiredError{Field: name}
+		}
+	}
+
+	return nil
+}
+
+// AssertCustomerStatusReasonConstraints checks if the values respects the defined constraints
+func AssertCustomerStatusReasonConstraints(obj CustomerStatusReason) error {
+	if len(obj.Reason) > 255 {
+		return &common.ParsingError{Param: "reason", Err: errors.New("must be at most 255 characters")}
+	}
+	return nil
+}
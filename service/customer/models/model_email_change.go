@@ -0,0 +1,13 @@
+package models
+
+import "errors"
+
+var errInvalidEmail = errors.New("must be a plain email address such as name@example.com")
+
+// EmailChange is a pending change of a customer's email.
+type EmailChange struct {
+	NewEmail string `json:"new_email"`
+
+	// UTC time after which the token can no longer be confirmed
+	ExpiresAt string `json:"expires_at"`
+}
@@ -0,0 +1,27 @@
+package models
+
+import "github.com/mayureshucsb2019/bookstore/service/common"
+
+type EmailChangeConfirmation struct {
+	// Token sent to the new email.
+	Token string `json:"token"`
+}
+
+// AssertEmailChangeConfirmationRequired checks if the required fields are not zero-ed
+func AssertEmailChangeConfirmationRequired(obj EmailChangeConfirmation) error {
+	elements := map[string]interface{}{
+		"token": obj.Token,
+	}
+	for name, el := range elements {
+		if isZero := common.IsZeroValue(el); isZero {
+			return &common.RequiredError{Field: name}
+		}
+	}
+
+	return nil
+}
+
+// AssertEmailChangeConfirmationConstraints checks if the values respects the defined constraints
+func AssertEmailChangeConfirmationConstraints(obj EmailChangeConfirmation) error {
+	return nil
+}
@@ -0,0 +1,34 @@
+package models
+
+import (
+	"net/mail"
+
+	"github.com/mayureshucsb2019/bookstore/service/common"
+)
+
+type EmailChangeRequest struct {
+	// Email the customer wants to use. The change is confirmed with the token sent to it.
+	NewEmail string `json:"new_email"`
+}
+
+// AssertEmailChangeRequestRequired checks if the required fields are not zero-ed
+func AssertEmailChangeRequestRequired(obj EmailChangeRequest) error {
+	elements := map[string]interface{}{
+		"new_email": obj.NewEmail,
+	}
+	for name, el := range elements {
+		if isZero := common.IsZeroValue(el); isZero {
+			return &common.RequiredError{Field: name}
+		}
+	}
+
+	return nil
+}
+
+// AssertEmailChangeRequestConstraints checks if the values respects the defined constraints
+func AssertEmailChangeRequestConstraints(obj EmailChangeRequest) error {
+	if address, err := mail.ParseAddress(obj.NewEmail); err != nil || address.Address != obj.NewEmail {
+		return &common.ParsingError{Param: "new_email", Err: errInvalidEmail}
+	}
+	return nil
+}
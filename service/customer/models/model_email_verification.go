@@ -0,0 +1,27 @@
+package models
+
+import "github.com/mayureshucsb2019/bookstore/service/common"
+
+type EmailVerification struct {
+	// Token sent to the customer's email by the registration or verification mail.
+	Token string `json:"token"`
+}
+
+// AssertEmailVerificationRequired checks if the required fields are not zero-ed
+func AssertEmailVerificationRequired(obj EmailVerification) error {
+	elements := map[string]interface{}{
+		"token": obj.Token,
+	}
+	for name, el := range elements {
+		if isZero := common.IsZeroValue(el); isZero {
+			return &common.RequiredError{Field: name}
+		}
+	}
+
+	return nil
+}
+
+// AssertEmailVerificationConstraints checks if the values respects the defined constraints
+func AssertEmailVerificationConstraints(obj EmailVerification) error {
+	return nil
+}
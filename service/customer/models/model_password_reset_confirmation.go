@@ -0,0 +1,36 @@
+package models
+
+import (
+	"github.com/mayureshucsb2019/bookstore/service/common"
+	"github.com/mayureshucsb2019/bookstore/service/password"
+)
+
+type PasswordResetConfirmation struct {
+	// Token sent to the customer's email by the password reset mail.
+	Token string `json:"token"`
+
+	NewPassword string `json:"new_password"`
+}
+
+// AssertPasswordResetConfirmationRequired checks if the required fields are not zero-ed
+func AssertPasswordResetConfirmationRequired(obj PasswordResetConfirmation) error {
+	elements := map[string]interface{}{
+		"token":        obj.Token,
+		"new_password": obj.NewPassword,
+	}
+	for name, el := range elements {
+		if isZero := common.IsZeroValue(el); isZero {
+			return &common.RequiredError{Field: name}
+		}
+	}
+
+	return nil
+}
+
+// AssertPasswordResetConfirmationConstraints checks if the values respects the defined constraints
+func AssertPasswordResetConfirmationConstraints(obj PasswordResetConfirmation) error {
+	if err := password.Validate(obj.NewPassword); err != nil {
+		return &common.ParsingError{Param: "new_password", Err: err}
+	}
+	return nil
+}
@@ -0,0 +1,27 @@
+package models
+
+import "github.com/mayureshucsb2019/bookstore/service/common"
+
+type PasswordResetRequest struct {
+	// Email of the account. The response is the same whether or not it exists.
+	Email string `json:"email"`
+}
+
+// AssertPasswordResetRequestRequired checks if the required fields are not zero-ed
+func AssertPasswordResetRequestRequired(obj PasswordResetRequest) error {
+	elements := map[string]interface{}{
+		"email": obj.Email,
+	}
+	for name, el := range elements {
+		if isZero := common.IsZeroValue(el); isZero {
+			return &common.RequiredError{Field: name}
+		}
+	}
+
+	return nil
+}
+
+// AssertPasswordResetRequestConstraints checks if the values respects the defined constraints
+func AssertPasswordResetRequestConstraints(obj PasswordResetRequest) error {
+	return nil
+}
@@ -0,0 +1,177 @@
+package openapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mayureshucsb2019/bookstore/service/common"
+	"github.com/mayureshucsb2019/bookstore/service/customer/db"
+	"github.com/mayureshucsb2019/bookstore/service/customer/models"
+	"github.com/mayureshucsb2019/bookstore/service/mail"
+	"github.com/mayureshucsb2019/bookstore/service/password"
+	"github.com/mayureshucsb2019/bookstore/service/token"
+)
+
+// Purposes and lifetimes of the tokens mailed to customers
+const (
+	purposeVerifyEmail   = "verify-email"
+	purposePasswordReset = "password-reset"
+
+	VerificationTTL  = 48 * time.Hour
+	PasswordResetTTL = time.Hour
+)
+
+var errMailNotConfigured = errors.New("no mailer is configured")
+
+// CustomersEmailVerificationPost - Send the email verification mail again
+func (s *DefaultAPIService) CustomersEmailVerificationPost(ctx context.Context, email string) (common.ImplResponse, error) {
+	if s.Mailer == nil || s.Tokens == nil {
+		return common.Response(http.StatusNotImplemented, nil), errMailNotConfigured
+	}
+	customer, err := s.Repo.GetCustomerByID(email)
+	if err != nil {
+		if errors.Is(err, db.ErrCustomerNotFound) {
+			return common.Response(http.StatusNotFound, nil), err
+		}
+		return common.Response(http.StatusInternalServerError, nil), err
+	}
+	if customer.EmailVerifiedAt.Valid {
+		return common.Response(http.StatusConflict, nil), errors.New("email is already verified")
+	}
+
+	if err := s.sendVerification(ctx, customer); err != nil {
+		return common.Response(http.StatusBadGateway, nil), err
+	}
+	return common.Response(http.StatusAccepted, nil), nil
+}
+
+// CustomersVerificationConfirmPost - Verify a customer's email
+func (s *DefaultAPIService) CustomersVerificationConfirmPost(ctx context.Context, verification models.EmailVerification) (common.ImplResponse, error) {
+	if s.Tokens == nil {
+		return common.Response(http.StatusNotImplemented, nil), errMailNotConfigured
+	}
+	subject, err := s.Tokens.Verify(verification.Token, purposeVerifyEmail)
+	if err != nil {
+		return tokenErrorResponse(err)
+	}
+	customerID, email, err := splitTokenSubject(subject)
+	if err != nil {
+		return tokenErrorResponse(err)
+	}
+	if err := s.Repo.VerifyEmail(customerID, email); err != nil {
+		return tokenErrorResponse(err)
+	}
+
+	customer, err := s.Repo.GetCustomerByID(email)
+	if err != nil {
+		return common.Response(http.StatusInternalServerError, nil), err
+	}
+	return common.Response(http.StatusOK, convertDBToAPIResponse(*customer)), nil
+}
+
+// CustomersPasswordResetPost - Send a password reset mail
+func (s *DefaultAPIService) CustomersPasswordResetPost(ctx context.Context, request models.PasswordResetRequest) (common.ImplResponse, error) {
+	if s.Mailer == nil || s.Tokens == nil {
+		return common.Response(http.StatusNotImplemented, nil), errMailNotConfigured
+	}
+	// The response never tells whether the email has an account
+	customer, err := s.Repo.GetCustomerByID(request.Email)
+	if errors.Is(err, db.ErrCustomerNotFound) {
+		return common.Response(http.StatusAccepted, nil), nil
+	}
+	if err != nil {
+		return common.Response(http.StatusInternalServerError, nil), err
+	}
+	fingerprint, err := s.Repo.PasswordFingerprint(customer.ID)
+	if err != nil {
+		return common.Response(http.StatusInternalServerError, nil), err
+	}
+
+	tok, expires, err := s.Tokens.Issue(purposePasswordReset, tokenSubject(customer.ID, fingerprint), PasswordResetTTL)
+	if err != nil {
+		return common.Response(http.StatusInternalServerError, nil), err
+	}
+	if err := s.sendTokenMail(ctx, mail.TemplatePasswordReset, customer, tok, expires); err != nil {
+		log.Printf("Failed to send password reset mail to customer %d: %v", customer.ID, err)
+	}
+	return common.Response(http.StatusAccepted, nil), nil
+}
+
+// CustomersPasswordResetConfirmPost - Set a new password with a password reset token
+func (s *DefaultAPIService) CustomersPasswordResetConfirmPost(ctx context.Context, confirmation models.PasswordResetConfirmation) (common.ImplResponse, error) {
+	if s.Tokens == nil {
+		return common.Response(http.StatusNotImplemented, nil), errMailNotConfigured
+	}
+	subject, err := s.Tokens.Verify(confirmation.Token, purposePasswordReset)
+	if err != nil {
+		return tokenErrorResponse(err)
+	}
+	customerID, fingerprint, err := splitTokenSubject(subject)
+	if err != nil {
+		return tokenErrorResponse(err)
+	}
+
+	passwordHash, err := password.Hash(confirmation.NewPassword)
+	if err != nil {
+		return common.Response(http.StatusInternalServerError, nil), err
+	}
+	if err := s.Repo.ResetPassword(customerID, fingerprint, passwordHash); err != nil {
+		return tokenErrorResponse(err)
+	}
+	return common.Response(http.StatusNoContent, nil), nil
+}
+
+// sendVerification mails a customer the token verifying their current email.
+func (s *DefaultAPIService) sendVerification(ctx context.Context, customer *db.Customer) error {
+	tok, expires, err := s.Tokens.Issue(purposeVerifyEmail, tokenSubject(customer.ID, customer.Email), VerificationTTL)
+	if err != nil {
+		return err
+	}
+	return s.sendTokenMail(ctx, mail.TemplateVerifyEmail, customer, tok, expires)
+}
+
+// sendTokenMail mails a customer the named template carrying a token.
+func (s *DefaultAPIService) sendTokenMail(ctx context.Context, template string, customer *db.Customer, tok string, expires time.Time) error {
+	message, err := mail.Render(template, customer.Email, mail.TokenData{
+		Name:      customer.FirstName,
+		Token:     tok,
+		ExpiresAt: expires.UTC().Format("2006-01-02 15:04:05"),
+	})
+	if err != nil {
+		return err
+	}
+	return s.Mailer.Send(ctx, message)
+}
+
+// tokenSubject binds a token to a customer and to a value that changes once the token has served
+// its purpose, such as the email it verifies.
+func tokenSubject(customerID int64, value string) string {
+	return strconv.FormatInt(customerID, 10) + ":" + value
+}
+
+func splitTokenSubject(subject string) (int64, string, error) {
+	id, value, ok := strings.Cut(subject, ":")
+	customerID, err := strconv.ParseInt(id, 10, 64)
+	if !ok || err != nil {
+		return 0, "", fmt.Errorf("%w: malformed subject", token.ErrInvalid)
+	}
+	return customerID, value, nil
+}
+
+// tokenErrorResponse maps the errors of verifying and using a mailed token to a response.
+func tokenErrorResponse(err error) (common.ImplResponse, error) {
+	switch {
+	case errors.Is(err, token.ErrInvalid), errors.Is(err, db.ErrCustomerNotFound):
+		// A customer missing or with another email means the token no longer applies
+		return common.Response(http.StatusNotFound, nil), err
+	case errors.Is(err, token.ErrExpired), errors.Is(err, db.ErrPasswordChanged):
+		return common.Response(http.StatusGone, nil), err
+	}
+	return common.Response(http.StatusInternalServerError, nil), err
+}
@@ -0,0 +1,21 @@
+package openapi
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/mayureshucsb2019/bookstore/service/token"
+)
+
+func TestTokenSubject(t *testing.T) {
+	// The value may contain the separator, as emails and fingerprints are not checked for it
+	customerID, value, err := splitTokenSubject(tokenSubject(42, "a:b@example.com"))
+	if err != nil || customerID != 42 || value != "a:b@example.com" {
+		t.Errorf("splitTokenSubject() = %d, %q, %v, want 42 and the value", customerID, value, err)
+	}
+	for _, subject := range []string{"", "42", "ann:3f2a"} {
+		if _, _, err := splitTokenSubject(subject); !errors.Is(err, token.ErrInvalid) {
+			t.Errorf("splitTokenSubject(%q) = %v, want ErrInvalid", subject, err)
+		}
+	}
+}
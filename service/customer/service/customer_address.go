@@ -0,0 +1,178 @@
+package openapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/mayureshucsb2019/bookstore/service/address"
+	"github.com/mayureshucsb2019/bookstore/service/common"
+	"github.com/mayureshucsb2019/bookstore/service/customer/db"
+	"github.com/mayureshucsb2019/bookstore/service/customer/models"
+)
+
+// CustomersEmailAddressesGet - Get the address book of a customer
+func (s *DefaultAPIService) CustomersEmailAddressesGet(ctx context.Context, email string) (common.ImplResponse, error) {
+	addresses, err := s.Repo.GetAddresses(email)
+	if err != nil {
+		return addressErrorResponse(err)
+	}
+	resp := []models.Address{}
+	for _, address := range addresses {
+		resp = append(resp, convertDBToAPIAddress(address))
+	}
+
+	return common.Response(http.StatusOK, resp), nil
+}
+
+// CustomersEmailAddressesPost - Add an address to the address book of a customer
+func (s *DefaultAPIService) CustomersEmailAddressesPost(ctx context.Context, email string, address models.Address) (common.ImplResponse, error) {
+	if err := normalizeBookAddress(&address); err != nil {
+		return common.Response(http.StatusUnprocessableEntity, nil), err
+	}
+	dbAddress := convertAPIToDBAddress(address)
+	if err := s.Repo.CreateAddress(email, &dbAddress); err != nil {
+		return addressErrorResponse(err)
+	}
+
+	return s.addressResponse(http.StatusCreated, email, dbAddress.ID)
+}
+
+// CustomersEmailAddressesIdGet - Get an address of a customer
+func (s *DefaultAPIService) CustomersEmailAddressesIdGet(ctx context.Context, email string, id int64) (common.ImplResponse, error) {
+	return s.addressResponse(http.StatusOK, email, id)
+}
+
+// CustomersEmailAddressesIdPut - Replace an address of a customer
+func (s *DefaultAPIService) CustomersEmailAddressesIdPut(ctx context.Context, email string, id int64, address models.Address) (common.ImplResponse, error) {
+	if address.Id != 0 && address.Id != id {
+		return common.Response(http.StatusBadRequest, nil), errors.New("id in the path does not match id in the body")
+	}
+	if err := normalizeBookAddress(&address); err != nil {
+		return common.Response(http.StatusUnprocessableEntity, nil), err
+	}
+	dbAddress := convertAPIToDBAddress(address)
+	dbAddress.ID = id
+	if err := s.Repo.UpdateAddress(email, &dbAddress); err != nil {
+		return addressErrorResponse(err)
+	}
+
+	return s.addressResponse(http.StatusOK, email, id)
+}
+
+// CustomersEmailAddressesIdDelete - Delete an address of a customer
+func (s *DefaultAPIService) CustomersEmailAddressesIdDelete(ctx context.Context, email string, id int64) (common.ImplResponse, error) {
+	if err := s.Repo.DeleteAddress(email, id); err != nil {
+		return addressErrorResponse(err)
+	}
+
+	return common.Response(http.StatusNoContent, nil), nil
+}
+
+// addressResponse reads an address back, so responses include what the repository set.
+func (s *DefaultAPIService) addressResponse(code int, email string, id int64) (common.ImplResponse, error) {
+	address, err := s.Repo.GetAddress(email, id)
+	if err != nil {
+		return addressErrorResponse(err)
+	}
+	return common.Response(code, convertDBToAPIAddress(*address)), nil
+}
+
+// addressErrorResponse maps repository errors to a not found or internal error response.
+func addressErrorResponse(err error) (common.ImplResponse, error) {
+	if errors.Is(err, db.ErrCustomerNotFound) || errors.Is(err, db.ErrAddressNotFound) {
+		return common.Response(http.StatusNotFound, nil), err
+	}
+	return common.Response(http.StatusInternalServerError, nil), err
+}
+
+// normalizeAddress validates an address and brings it into canonical form, see address.Normalize.
+// Errors name the fields after prefix.
+func normalizeAddress(customerAddress models.CustomerAddress, prefix string) (models.CustomerAddress, error) {
+	normalized, err := address.Normalize(address.Address(customerAddress), prefix)
+	return models.CustomerAddress(normalized), err
+}
+
+// normalizeBookAddress normalizes an address book entry in place, see normalizeAddress.
+func normalizeBookAddress(entry *models.Address) error {
+	normalized, err := normalizeAddress(models.CustomerAddress{
+		Unit:       entry.Unit,
+		StreetName: entry.StreetName,
+		City:       entry.City,
+		State:      entry.State,
+		Country:    entry.Country,
+		Zipcode:    entry.Zipcode,
+		Landmark:   entry.Landmark,
+	}, "")
+	if err != nil {
+		return err
+	}
+	entry.Unit, entry.StreetName, entry.City, entry.State = normalized.Unit, normalized.StreetName, normalized.City, normalized.State
+	entry.Country, entry.Zipcode, entry.Landmark = normalized.Country, normalized.Zipcode, normalized.Landmark
+	return nil
+}
+
+// convertAPIToDBAddress converts an API Address struct to a database Address struct.
+func convertAPIToDBAddress(address models.Address) db.Address {
+	return db.Address{
+		ID:    address.Id,
+		Label: address.Label,
+		PostalAddress: convertAPIToDBPostalAddress(models.CustomerAddress{
+			Unit:       address.Unit,
+			StreetName: address.StreetName,
+			City:       address.City,
+			State:      address.State,
+			Country:    address.Country,
+			Zipcode:    address.Zipcode,
+			Landmark:   address.Landmark,
+		}),
+		DefaultShipping: address.IsDefaultShipping,
+		DefaultBilling:  address.IsDefaultBilling,
+	}
+}
+
+// convertDBToAPIAddress converts a database Address struct to an API Address struct.
+func convertDBToAPIAddress(address db.Address) models.Address {
+	postal := convertDBToAPIPostalAddress(address.PostalAddress)
+	return models.Address{
+		Id:                address.ID,
+		Label:             address.Label,
+		Unit:              postal.Unit,
+		StreetName:        postal.StreetName,
+		City:              postal.City,
+		State:             postal.State,
+		Country:           postal.Country,
+		Zipcode:           postal.Zipcode,
+		Landmark:          postal.Landmark,
+		IsDefaultShipping: address.DefaultShipping,
+		IsDefaultBilling:  address.DefaultBilling,
+		CreatedAt:         address.CreatedAt,
+		UpdatedAt:         address.UpdatedAt,
+	}
+}
+
+// convertAPIToDBPostalAddress converts an API CustomerAddress struct to a database PostalAddress struct.
+func convertAPIToDBPostalAddress(address models.CustomerAddress) db.PostalAddress {
+	return db.PostalAddress{
+		UnitNo:     common.NullStringOrNil(address.Unit),
+		StreetName: common.NullStringOrNil(address.StreetName),
+		City:       common.NullStringOrNil(address.City),
+		State:      common.NullStringOrNil(address.State),
+		Country:    common.NullStringOrNil(address.Country),
+		Zipcode:    common.NullStringOrNil(address.Zipcode),
+		Landmark:   common.NullStringOrNil(address.Landmark),
+	}
+}
+
+// convertDBToAPIPostalAddress converts a database PostalAddress struct to an API CustomerAddress struct.
+func convertDBToAPIPostalAddress(address db.PostalAddress) models.CustomerAddress {
+	return models.CustomerAddress{
+		Unit:       common.StringOrEmpty(address.UnitNo),
+		StreetName: common.StringOrEmpty(address.StreetName),
+		City:       common.StringOrEmpty(address.City),
+		State:      common.StringOrEmpty(address.State),
+		Country:    common.StringOrEmpty(address.Country),
+		Zipcode:    common.StringOrEmpty(address.Zipcode),
+		Landmark:   common.StringOrEmpty(address.Landmark),
+	}
+}
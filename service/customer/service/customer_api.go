@@ -2,6 +2,7 @@ package openapi
 
 import (
 	"context"
+	"io"
 	"net/http"
 
 	"github.com/mayureshucsb2019/bookstore/service/common"
@@ -12,11 +13,31 @@ import (
 // The DefaultAPIRouter implementation should parse necessary information from the http request,
 // pass the data to a DefaultAPIServicer to perform the required actions, then write the service results to the http response.
 type DefaultAPIRouter interface {
+	AdminCustomerErasuresIdGet(http.ResponseWriter, *http.Request)
+	AdminCustomersEmailClosePost(http.ResponseWriter, *http.Request)
+	AdminCustomersEmailReactivatePost(http.ResponseWriter, *http.Request)
+	AdminCustomersEmailStatusHistoryGet(http.ResponseWriter, *http.Request)
+	AdminCustomersEmailSuspendPost(http.ResponseWriter, *http.Request)
+	BulkCustomersGet(http.ResponseWriter, *http.Request)
+	BulkCustomersPost(http.ResponseWriter, *http.Request)
+	CustomersEmailAddressesGet(http.ResponseWriter, *http.Request)
+	CustomersEmailAddressesIdDelete(http.ResponseWriter, *http.Request)
+	CustomersEmailAddressesIdGet(http.ResponseWriter, *http.Request)
+	CustomersEmailAddressesIdPut(http.ResponseWriter, *http.Request)
+	CustomersEmailAddressesPost(http.ResponseWriter, *http.Request)
+	CustomersEmailChangeConfirmPost(http.ResponseWriter, *http.Request)
+	CustomersEmailDataExportGet(http.ResponseWriter, *http.Request)
 	CustomersEmailDelete(http.ResponseWriter, *http.Request)
+	CustomersEmailEmailChangePost(http.ResponseWriter, *http.Request)
 	CustomersEmailGet(http.ResponseWriter, *http.Request)
 	CustomersEmailPatch(http.ResponseWriter, *http.Request)
+	CustomersEmailPersonalDataDelete(http.ResponseWriter, *http.Request)
+	CustomersEmailVerificationPost(http.ResponseWriter, *http.Request)
 	CustomersGet(http.ResponseWriter, *http.Request)
+	CustomersPasswordResetConfirmPost(http.ResponseWriter, *http.Request)
+	CustomersPasswordResetPost(http.ResponseWriter, *http.Request)
 	CustomersPost(http.ResponseWriter, *http.Request)
+	CustomersVerificationConfirmPost(http.ResponseWriter, *http.Request)
 }
 
 // DefaultAPIServicer defines the api actions for the DefaultAPI service
@@ -24,9 +45,29 @@ type DefaultAPIRouter interface {
 // while the service implementation can be ignored with the .openapi-generator-ignore file
 // and updated with the logic required for the API.
 type DefaultAPIServicer interface {
+	AdminCustomerErasuresIdGet(context.Context, string) (common.ImplResponse, error)
+	AdminCustomersEmailClosePost(context.Context, string, models.CustomerStatusReason) (common.ImplResponse, error)
+	AdminCustomersEmailReactivatePost(context.Context, string, models.CustomerStatusReason) (common.ImplResponse, error)
+	AdminCustomersEmailStatusHistoryGet(context.Context, string) (common.ImplResponse, error)
+	AdminCustomersEmailSuspendPost(context.Context, string, models.CustomerStatusReason) (common.ImplResponse, error)
+	BulkCustomersGet(context.Context) (common.ImplResponse, error)
+	BulkCustomersPost(context.Context, io.Reader, int32) (common.ImplResponse, error)
+	CustomersEmailAddressesGet(context.Context, string) (common.ImplResponse, error)
+	CustomersEmailAddressesIdDelete(context.Context, string, int64) (common.ImplResponse, error)
+	CustomersEmailAddressesIdGet(context.Context, string, int64) (common.ImplResponse, error)
+	CustomersEmailAddressesIdPut(context.Context, string, int64, models.Address) (common.ImplResponse, error)
+	CustomersEmailAddressesPost(context.Context, string, models.Address) (common.ImplResponse, error)
+	CustomersEmailChangeConfirmPost(context.Context, models.EmailChangeConfirmation) (common.ImplResponse, error)
+	CustomersEmailDataExportGet(context.Context, string) (common.ImplResponse, error)
 	CustomersEmailDelete(context.Context, string) (common.ImplResponse, error)
+	CustomersEmailEmailChangePost(context.Context, string, models.EmailChangeRequest) (common.ImplResponse, error)
 	CustomersEmailGet(context.Context, string) (common.ImplResponse, error)
 	CustomersEmailPatch(context.Context, string, models.Customer) (common.ImplResponse, error)
-	CustomersGet(context.Context, int32, int32) (common.ImplResponse, error)
+	CustomersEmailPersonalDataDelete(context.Context, string, string) (common.ImplResponse, error)
+	CustomersEmailVerificationPost(context.Context, string) (common.ImplResponse, error)
+	CustomersGet(context.Context, int32, int32, string, string, string, string, string, string) (common.ImplResponse, error)
+	CustomersPasswordResetConfirmPost(context.Context, models.PasswordResetConfirmation) (common.ImplResponse, error)
+	CustomersPasswordResetPost(context.Context, models.PasswordResetRequest) (common.ImplResponse, error)
 	CustomersPost(context.Context, models.Customer) (common.ImplResponse, error)
+	CustomersVerificationConfirmPost(context.Context, models.EmailVerification) (common.ImplResponse, error)
 }
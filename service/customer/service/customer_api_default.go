@@ -2,6 +2,7 @@ package openapi
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strings"
 
@@ -43,11 +44,86 @@ func NewDefaultAPIController(s DefaultAPIServicer, opts ...DefaultAPIOption) *De
 // Routes returns all the api routes for the DefaultAPIController
 func (c *DefaultAPIController) Routes() common.Routes {
 	return common.Routes{
+		"AdminCustomerErasuresIdGet": common.Route{
+			Method:      strings.ToUpper("Get"),
+			Pattern:     "/admin/customer-erasures/{id}",
+			HandlerFunc: c.AdminCustomerErasuresIdGet,
+		},
+		"AdminCustomersEmailClosePost": common.Route{
+			Method:      strings.ToUpper("Post"),
+			Pattern:     "/admin/customers/{email}/close",
+			HandlerFunc: c.AdminCustomersEmailClosePost,
+		},
+		"AdminCustomersEmailReactivatePost": common.Route{
+			Method:      strings.ToUpper("Post"),
+			Pattern:     "/admin/customers/{email}/reactivate",
+			HandlerFunc: c.AdminCustomersEmailReactivatePost,
+		},
+		"AdminCustomersEmailStatusHistoryGet": common.Route{
+			Method:      strings.ToUpper("Get"),
+			Pattern:     "/admin/customers/{email}/status-history",
+			HandlerFunc: c.AdminCustomersEmailStatusHistoryGet,
+		},
+		"AdminCustomersEmailSuspendPost": common.Route{
+			Method:      strings.ToUpper("Post"),
+			Pattern:     "/admin/customers/{email}/suspend",
+			HandlerFunc: c.AdminCustomersEmailSuspendPost,
+		},
+		"BulkCustomersGet": common.Route{
+			Method:      strings.ToUpper("Get"),
+			Pattern:     "/bulk/customers",
+			HandlerFunc: c.BulkCustomersGet,
+		},
+		"BulkCustomersPost": common.Route{
+			Method:      strings.ToUpper("Post"),
+			Pattern:     "/bulk/customers",
+			HandlerFunc: c.BulkCustomersPost,
+		},
+		"CustomersEmailAddressesGet": common.Route{
+			Method:      strings.ToUpper("Get"),
+			Pattern:     "/customers/{email}/addresses",
+			HandlerFunc: c.CustomersEmailAddressesGet,
+		},
+		"CustomersEmailAddressesIdDelete": common.Route{
+			Method:      strings.ToUpper("Delete"),
+			Pattern:     "/customers/{email}/addresses/{id}",
+			HandlerFunc: c.CustomersEmailAddressesIdDelete,
+		},
+		"CustomersEmailAddressesIdGet": common.Route{
+			Method:      strings.ToUpper("Get"),
+			Pattern:     "/customers/{email}/addresses/{id}",
+			HandlerFunc: c.CustomersEmailAddressesIdGet,
+		},
+		"CustomersEmailAddressesIdPut": common.Route{
+			Method:      strings.ToUpper("Put"),
+			Pattern:     "/customers/{email}/addresses/{id}",
+			HandlerFunc: c.CustomersEmailAddressesIdPut,
+		},
+		"CustomersEmailAddressesPost": common.Route{
+			Method:      strings.ToUpper("Post"),
+			Pattern:     "/customers/{email}/addresses",
+			HandlerFunc: c.CustomersEmailAddressesPost,
+		},
+		"CustomersEmailChangeConfirmPost": common.Route{
+			Method:      strings.ToUpper("Post"),
+			Pattern:     "/customers/email-change/confirm",
+			HandlerFunc: c.CustomersEmailChangeConfirmPost,
+		},
+		"CustomersEmailDataExportGet": common.Route{
+			Method:      strings.ToUpper("Get"),
+			Pattern:     "/customers/{email}/data-export",
+			HandlerFunc: c.CustomersEmailDataExportGet,
+		},
 		"CustomersEmailDelete": common.Route{
 			Method:      strings.ToUpper("Delete"),
 			Pattern:     "/customers/{email}",
 			HandlerFunc: c.CustomersEmailDelete,
 		},
+		"CustomersEmailEmailChangePost": common.Route{
+			Method:      strings.ToUpper("Post"),
+			Pattern:     "/customers/{email}/email-change",
+			HandlerFunc: c.CustomersEmailEmailChangePost,
+		},
 		"CustomersEmailGet": common.Route{
 			Method:      strings.ToUpper("Get"),
 			Pattern:     "/customers/{email}",
@@ -58,17 +134,410 @@ func (c *DefaultAPIController) Routes() common.Routes {
 			Pattern:     "/customers/{email}",
 			HandlerFunc: c.CustomersEmailPatch,
 		},
+		"CustomersEmailPersonalDataDelete": common.Route{
+			Method:      strings.ToUpper("Delete"),
+			Pattern:     "/customers/{email}/personal-data",
+			HandlerFunc: c.CustomersEmailPersonalDataDelete,
+		},
+		"CustomersEmailVerificationPost": common.Route{
+			Method:      strings.ToUpper("Post"),
+			Pattern:     "/customers/{email}/verification",
+			HandlerFunc: c.CustomersEmailVerificationPost,
+		},
 		"CustomersGet": common.Route{
 			Method:      strings.ToUpper("Get"),
 			Pattern:     "/customers",
 			HandlerFunc: c.CustomersGet,
 		},
+		"CustomersPasswordResetConfirmPost": common.Route{
+			Method:      strings.ToUpper("Post"),
+			Pattern:     "/customers/password-reset/confirm",
+			HandlerFunc: c.CustomersPasswordResetConfirmPost,
+		},
+		"CustomersPasswordResetPost": common.Route{
+			Method:      strings.ToUpper("Post"),
+			Pattern:     "/customers/password-reset",
+			HandlerFunc: c.CustomersPasswordResetPost,
+		},
 		"CustomersPost": common.Route{
 			Method:      strings.ToUpper("Post"),
 			Pattern:     "/customers",
 			HandlerFunc: c.CustomersPost,
 		},
+		"CustomersVerificationConfirmPost": common.Route{
+			Method:      strings.ToUpper("Post"),
+			Pattern:     "/customers/verification/confirm",
+			HandlerFunc: c.CustomersVerificationConfirmPost,
+		},
+	}
+}
+
+// AdminCustomerErasuresIdGet - Get an erasure receipt
+func (c *DefaultAPIController) AdminCustomerErasuresIdGet(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	idParam := params["id"]
+	if idParam == "" {
+		c.errorHandler(w, r, &common.RequiredError{Field: "id"}, nil)
+		return
+	}
+	result, err := c.service.AdminCustomerErasuresIdGet(r.Context(), idParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	_ = common.EncodeJSONResponse(result.Body, &result.Code, w)
+}
+
+// AdminCustomersEmailClosePost - Close the account of a customer
+func (c *DefaultAPIController) AdminCustomersEmailClosePost(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	emailParam := params["email"]
+	if emailParam == "" {
+		c.errorHandler(w, r, &common.RequiredError{Field: "email"}, nil)
+		return
+	}
+	customerStatusReasonParam := models.CustomerStatusReason{}
+	d := json.NewDecoder(r.Body)
+	d.DisallowUnknownFields()
+	if err := d.Decode(&customerStatusReasonParam); err != nil {
+		c.errorHandler(w, r, &common.ParsingError{Err: err}, nil)
+		return
+	}
+	if err := models.AssertCustomerStatusReasonRequired(customerStatusReasonParam); err != nil {
+		c.errorHandler(w, r, err, nil)
+		return
+	}
+	if err := models.AssertCustomerStatusReasonConstraints(customerStatusReasonParam); err != nil {
+		c.errorHandler(w, r, err, nil)
+		return
+	}
+	result, err := c.service.AdminCustomersEmailClosePost(r.Context(), emailParam, customerStatusReasonParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	_ = common.EncodeJSONResponse(result.Body, &result.Code, w)
+}
+
+// AdminCustomersEmailReactivatePost - Reactivate a suspended or inactive customer
+func (c *DefaultAPIController) AdminCustomersEmailReactivatePost(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	emailParam := params["email"]
+	if emailParam == "" {
+		c.errorHandler(w, r, &common.RequiredError{Field: "email"}, nil)
+		return
+	}
+	customerStatusReasonParam := models.CustomerStatusReason{}
+	d := json.NewDecoder(r.Body)
+	d.DisallowUnknownFields()
+	if err := d.Decode(&customerStatusReasonParam); err != nil {
+		c.errorHandler(w, r, &common.ParsingError{Err: err}, nil)
+		return
+	}
+	if err := models.AssertCustomerStatusReasonRequired(customerStatusReasonParam); err != nil {
+		c.errorHandler(w, r, err, nil)
+		return
+	}
+	if err := models.AssertCustomerStatusReasonConstraints(customerStatusReasonParam); err != nil {
+		c.errorHandler(w, r, err, nil)
+		return
+	}
+	result, err := c.service.AdminCustomersEmailReactivatePost(r.Context(), emailParam, customerStatusReasonParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	_ = common.EncodeJSONResponse(result.Body, &result.Code, w)
+}
+
+// AdminCustomersEmailStatusHistoryGet - Get the status changes made by staff to a customer
+func (c *DefaultAPIController) AdminCustomersEmailStatusHistoryGet(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	emailParam := params["email"]
+	if emailParam == "" {
+		c.errorHandler(w, r, &common.RequiredError{Field: "email"}, nil)
+		return
+	}
+	result, err := c.service.AdminCustomersEmailStatusHistoryGet(r.Context(), emailParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	_ = common.EncodeJSONResponse(result.Body, &result.Code, w)
+}
+
+// AdminCustomersEmailSuspendPost - Suspend a customer
+func (c *DefaultAPIController) AdminCustomersEmailSuspendPost(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	emailParam := params["email"]
+	if emailParam == "" {
+		c.errorHandler(w, r, &common.RequiredError{Field: "email"}, nil)
+		return
+	}
+	customerStatusReasonParam := models.CustomerStatusReason{}
+	d := json.NewDecoder(r.Body)
+	d.DisallowUnknownFields()
+	if err := d.Decode(&customerStatusReasonParam); err != nil {
+		c.errorHandler(w, r, &common.ParsingError{Err: err}, nil)
+		return
+	}
+	if err := models.AssertCustomerStatusReasonRequired(customerStatusReasonParam); err != nil {
+		c.errorHandler(w, r, err, nil)
+		return
+	}
+	if err := models.AssertCustomerStatusReasonConstraints(customerStatusReasonParam); err != nil {
+		c.errorHandler(w, r, err, nil)
+		return
+	}
+	result, err := c.service.AdminCustomersEmailSuspendPost(r.Context(), emailParam, customerStatusReasonParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	_ = common.EncodeJSONResponse(result.Body, &result.Code, w)
+}
+
+// BulkCustomersGet - Export every customer as NDJSON
+func (c *DefaultAPIController) BulkCustomersGet(w http.ResponseWriter, r *http.Request) {
+	result, err := c.service.BulkCustomersGet(r.Context())
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	_ = common.EncodeJSONResponse(result.Body, &result.Code, w)
+}
+
+// BulkCustomersPost - Create or replace customers from NDJSON
+func (c *DefaultAPIController) BulkCustomersPost(w http.ResponseWriter, r *http.Request) {
+	query, err := common.ParseQuery(r.URL.RawQuery)
+	if err != nil {
+		c.errorHandler(w, r, &common.ParsingError{Err: err}, nil)
+		return
+	}
+	var batchSizeParam int32
+	if query.Has("batch_size") {
+		param, err := common.ParseNumericParameter[int32](
+			query.Get("batch_size"),
+			common.WithParse[int32](common.ParseInt32),
+			common.WithMinimum[int32](1),
+			common.WithMaximum[int32](common.MaxBulkBatchSize),
+		)
+		if err != nil {
+			c.errorHandler(w, r, &common.ParsingError{Param: "batch_size", Err: err}, nil)
+			return
+		}
+
+		batchSizeParam = param
+	}
+	result, err := c.service.BulkCustomersPost(r.Context(), r.Body, batchSizeParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	_ = common.EncodeJSONResponse(result.Body, &result.Code, w)
+}
+
+// CustomersEmailAddressesGet - Get the address book of a customer
+func (c *DefaultAPIController) CustomersEmailAddressesGet(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	emailParam := params["email"]
+	if emailParam == "" {
+		c.errorHandler(w, r, &common.RequiredError{Field: "email"}, nil)
+		return
+	}
+	result, err := c.service.CustomersEmailAddressesGet(r.Context(), emailParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	_ = common.EncodeJSONResponse(result.Body, &result.Code, w)
+}
+
+// CustomersEmailAddressesIdDelete - Delete an address of a customer
+func (c *DefaultAPIController) CustomersEmailAddressesIdDelete(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	emailParam := params["email"]
+	if emailParam == "" {
+		c.errorHandler(w, r, &common.RequiredError{Field: "email"}, nil)
+		return
+	}
+	idParam, err := common.ParseNumericParameter[int64](
+		params["id"],
+		common.WithRequire[int64](common.ParseInt64),
+	)
+	if err != nil {
+		c.errorHandler(w, r, &common.ParsingError{Param: "id", Err: err}, nil)
+		return
+	}
+	result, err := c.service.CustomersEmailAddressesIdDelete(r.Context(), emailParam, idParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	_ = common.EncodeJSONResponse(result.Body, &result.Code, w)
+}
+
+// CustomersEmailAddressesIdGet - Get an address of a customer
+func (c *DefaultAPIController) CustomersEmailAddressesIdGet(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	emailParam := params["email"]
+	if emailParam == "" {
+		c.errorHandler(w, r, &common.RequiredError{Field: "email"}, nil)
+		return
 	}
+	idParam, err := common.ParseNumericParameter[int64](
+		params["id"],
+		common.WithRequire[int64](common.ParseInt64),
+	)
+	if err != nil {
+		c.errorHandler(w, r, &common.ParsingError{Param: "id", Err: err}, nil)
+		return
+	}
+	result, err := c.service.CustomersEmailAddressesIdGet(r.Context(), emailParam, idParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	_ = common.EncodeJSONResponse(result.Body, &result.Code, w)
+}
+
+// CustomersEmailAddressesIdPut - Replace an address of a customer
+func (c *DefaultAPIController) CustomersEmailAddressesIdPut(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	emailParam := params["email"]
+	if emailParam == "" {
+		c.errorHandler(w, r, &common.RequiredError{Field: "email"}, nil)
+		return
+	}
+	idParam, err := common.ParseNumericParameter[int64](
+		params["id"],
+		common.WithRequire[int64](common.ParseInt64),
+	)
+	if err != nil {
+		c.errorHandler(w, r, &common.ParsingError{Param: "id", Err: err}, nil)
+		return
+	}
+	addressParam := models.Address{}
+	d := json.NewDecoder(r.Body)
+	d.DisallowUnknownFields()
+	if err := d.Decode(&addressParam); err != nil {
+		c.errorHandler(w, r, &common.ParsingError{Err: err}, nil)
+		return
+	}
+	if err := models.AssertAddressRequired(addressParam); err != nil {
+		c.errorHandler(w, r, err, nil)
+		return
+	}
+	if err := models.AssertAddressConstraints(addressParam); err != nil {
+		c.errorHandler(w, r, err, nil)
+		return
+	}
+	result, err := c.service.CustomersEmailAddressesIdPut(r.Context(), emailParam, idParam, addressParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	_ = common.EncodeJSONResponse(result.Body, &result.Code, w)
+}
+
+// CustomersEmailAddressesPost - Add an address to the address book of a customer
+func (c *DefaultAPIController) CustomersEmailAddressesPost(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	emailParam := params["email"]
+	if emailParam == "" {
+		c.errorHandler(w, r, &common.RequiredError{Field: "email"}, nil)
+		return
+	}
+	addressParam := models.Address{}
+	d := json.NewDecoder(r.Body)
+	d.DisallowUnknownFields()
+	if err := d.Decode(&addressParam); err != nil {
+		c.errorHandler(w, r, &common.ParsingError{Err: err}, nil)
+		return
+	}
+	if err := models.AssertAddressRequired(addressParam); err != nil {
+		c.errorHandler(w, r, err, nil)
+		return
+	}
+	if err := models.AssertAddressConstraints(addressParam); err != nil {
+		c.errorHandler(w, r, err, nil)
+		return
+	}
+	result, err := c.service.CustomersEmailAddressesPost(r.Context(), emailParam, addressParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	_ = common.EncodeJSONResponse(result.Body, &result.Code, w)
+}
+
+// CustomersEmailChangeConfirmPost - Confirm an email change
+func (c *DefaultAPIController) CustomersEmailChangeConfirmPost(w http.ResponseWriter, r *http.Request) {
+	emailChangeConfirmationParam := models.EmailChangeConfirmation{}
+	d := json.NewDecoder(r.Body)
+	d.DisallowUnknownFields()
+	if err := d.Decode(&emailChangeConfirmationParam); err != nil {
+		c.errorHandler(w, r, &common.ParsingError{Err: err}, nil)
+		return
+	}
+	if err := models.AssertEmailChangeConfirmationRequired(emailChangeConfirmationParam); err != nil {
+		c.errorHandler(w, r, err, nil)
+		return
+	}
+	if err := models.AssertEmailChangeConfirmationConstraints(emailChangeConfirmationParam); err != nil {
+		c.errorHandler(w, r, err, nil)
+		return
+	}
+	result, err := c.service.CustomersEmailChangeConfirmPost(r.Context(), emailChangeConfirmationParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	_ = common.EncodeJSONResponse(result.Body, &result.Code, w)
+}
+
+// CustomersEmailDataExportGet - Export everything stored about a customer
+func (c *DefaultAPIController) CustomersEmailDataExportGet(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	emailParam := params["email"]
+	if emailParam == "" {
+		c.errorHandler(w, r, &common.RequiredError{Field: "email"}, nil)
+		return
+	}
+	result, err := c.service.CustomersEmailDataExportGet(r.Context(), emailParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	_ = common.EncodeJSONResponse(result.Body, &result.Code, w)
 }
 
 // CustomersEmailDelete - Delete a customer by email
@@ -89,6 +558,35 @@ func (c *DefaultAPIController) CustomersEmailDelete(w http.ResponseWriter, r *ht
 	_ = common.EncodeJSONResponse(result.Body, &result.Code, w)
 }
 
+// CustomersEmailEmailChangePost - Request a change of a customer's email
+func (c *DefaultAPIController) CustomersEmailEmailChangePost(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	emailParam := params["email"]
+	emailChangeRequestParam := models.EmailChangeRequest{}
+	d := json.NewDecoder(r.Body)
+	d.DisallowUnknownFields()
+	if err := d.Decode(&emailChangeRequestParam); err != nil {
+		c.errorHandler(w, r, &common.ParsingError{Err: err}, nil)
+		return
+	}
+	if err := models.AssertEmailChangeRequestRequired(emailChangeRequestParam); err != nil {
+		c.errorHandler(w, r, err, nil)
+		return
+	}
+	if err := models.AssertEmailChangeRequestConstraints(emailChangeRequestParam); err != nil {
+		c.errorHandler(w, r, err, nil)
+		return
+	}
+	result, err := c.service.CustomersEmailEmailChangePost(r.Context(), emailParam, emailChangeRequestParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	_ = common.EncodeJSONResponse(result.Body, &result.Code, w)
+}
+
 // CustomersEmailGet - Get a specific customer by email
 func (c *DefaultAPIController) CustomersEmailGet(w http.ResponseWriter, r *http.Request) {
 	params := mux.Vars(r)
@@ -140,6 +638,43 @@ func (c *DefaultAPIController) CustomersEmailPatch(w http.ResponseWriter, r *htt
 	_ = common.EncodeJSONResponse(result.Body, &result.Code, w)
 }
 
+// CustomersEmailPersonalDataDelete - Erase the personal data of a customer
+func (c *DefaultAPIController) CustomersEmailPersonalDataDelete(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	emailParam := params["email"]
+	if emailParam == "" {
+		c.errorHandler(w, r, &common.RequiredError{Field: "email"}, nil)
+		return
+	}
+	reasonParam := r.URL.Query().Get("reason")
+	if len(reasonParam) > 255 {
+		c.errorHandler(w, r, &common.ParsingError{Param: "reason", Err: errors.New("must be at most 255 characters")}, nil)
+		return
+	}
+	result, err := c.service.CustomersEmailPersonalDataDelete(r.Context(), emailParam, reasonParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	_ = common.EncodeJSONResponse(result.Body, &result.Code, w)
+}
+
+// CustomersEmailVerificationPost - Send the email verification mail again
+func (c *DefaultAPIController) CustomersEmailVerificationPost(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	emailParam := params["email"]
+	result, err := c.service.CustomersEmailVerificationPost(r.Context(), emailParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	_ = common.EncodeJSONResponse(result.Body, &result.Code, w)
+}
+
 // CustomersGet - Get a paginated list of customers
 func (c *DefaultAPIController) CustomersGet(w http.ResponseWriter, r *http.Request) {
 	query, err := common.ParseQuery(r.URL.RawQuery)
@@ -179,7 +714,86 @@ func (c *DefaultAPIController) CustomersGet(w http.ResponseWriter, r *http.Reque
 		var param int32 = 25
 		pageSizeParam = param
 	}
-	result, err := c.service.CustomersGet(r.Context(), pageNumberParam, pageSizeParam)
+	var phoneParam string
+	if query.Has("phone") {
+		phoneParam = query.Get("phone")
+	}
+	var statusParam string
+	if query.Has("status") {
+		statusParam = query.Get("status")
+	}
+	var segmentParam string
+	if query.Has("segment") {
+		segmentParam = query.Get("segment")
+	}
+	var registeredFromParam string
+	if query.Has("registeredFrom") {
+		registeredFromParam = query.Get("registeredFrom")
+	}
+	var registeredToParam string
+	if query.Has("registeredTo") {
+		registeredToParam = query.Get("registeredTo")
+	}
+	var countryParam string
+	if query.Has("country") {
+		countryParam = query.Get("country")
+	}
+	result, err := c.service.CustomersGet(r.Context(), pageNumberParam, pageSizeParam, phoneParam, statusParam, segmentParam,
+		registeredFromParam, registeredToParam, countryParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	_ = common.EncodeJSONResponse(result.Body, &result.Code, w)
+}
+
+// CustomersPasswordResetConfirmPost - Set a new password with a password reset token
+func (c *DefaultAPIController) CustomersPasswordResetConfirmPost(w http.ResponseWriter, r *http.Request) {
+	passwordResetConfirmationParam := models.PasswordResetConfirmation{}
+	d := json.NewDecoder(r.Body)
+	d.DisallowUnknownFields()
+	if err := d.Decode(&passwordResetConfirmationParam); err != nil {
+		c.errorHandler(w, r, &common.ParsingError{Err: err}, nil)
+		return
+	}
+	if err := models.AssertPasswordResetConfirmationRequired(passwordResetConfirmationParam); err != nil {
+		c.errorHandler(w, r, err, nil)
+		return
+	}
+	if err := models.AssertPasswordResetConfirmationConstraints(passwordResetConfirmationParam); err != nil {
+		c.errorHandler(w, r, err, nil)
+		return
+	}
+	result, err := c.service.CustomersPasswordResetConfirmPost(r.Context(), passwordResetConfirmationParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	_ = common.EncodeJSONResponse(result.Body, &result.Code, w)
+}
+
+// CustomersPasswordResetPost - Send a password reset mail
+func (c *DefaultAPIController) CustomersPasswordResetPost(w http.ResponseWriter, r *http.Request) {
+	passwordResetRequestParam := models.PasswordResetRequest{}
+	d := json.NewDecoder(r.Body)
+	d.DisallowUnknownFields()
+	if err := d.Decode(&passwordResetRequestParam); err != nil {
+		c.errorHandler(w, r, &common.ParsingError{Err: err}, nil)
+		return
+	}
+	if err := models.AssertPasswordResetRequestRequired(passwordResetRequestParam); err != nil {
+		c.errorHandler(w, r, err, nil)
+		return
+	}
+	if err := models.AssertPasswordResetRequestConstraints(passwordResetRequestParam); err != nil {
+		c.errorHandler(w, r, err, nil)
+		return
+	}
+	result, err := c.service.CustomersPasswordResetPost(r.Context(), passwordResetRequestParam)
 	// If an error occurred, encode the error with the status code
 	if err != nil {
 		c.errorHandler(w, r, err, &result)
@@ -215,3 +829,30 @@ func (c *DefaultAPIController) CustomersPost(w http.ResponseWriter, r *http.Requ
 	// If no error, encode the body and the result code
 	_ = common.EncodeJSONResponse(result.Body, &result.Code, w)
 }
+
+// CustomersVerificationConfirmPost - Verify a customer's email
+func (c *DefaultAPIController) CustomersVerificationConfirmPost(w http.ResponseWriter, r *http.Request) {
+	emailVerificationParam := models.EmailVerification{}
+	d := json.NewDecoder(r.Body)
+	d.DisallowUnknownFields()
+	if err := d.Decode(&emailVerificationParam); err != nil {
+		c.errorHandler(w, r, &common.ParsingError{Err: err}, nil)
+		return
+	}
+	if err := models.AssertEmailVerificationRequired(emailVerificationParam); err != nil {
+		c.errorHandler(w, r, err, nil)
+		return
+	}
+	if err := models.AssertEmailVerificationConstraints(emailVerificationParam); err != nil {
+		c.errorHandler(w, r, err, nil)
+		return
+	}
+	result, err := c.service.CustomersVerificationConfirmPost(r.Context(), emailVerificationParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	_ = common.EncodeJSONResponse(result.Body, &result.Code, w)
+}
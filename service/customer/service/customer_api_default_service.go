@@ -5,24 +5,55 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"log"
 	"net/http"
+	"strings"
+	"time"
 
+	"github.com/mayureshucsb2019/bookstore/service/address"
 	"github.com/mayureshucsb2019/bookstore/service/common"
 	"github.com/mayureshucsb2019/bookstore/service/customer/db"
 	"github.com/mayureshucsb2019/bookstore/service/customer/models"
+	event_db "github.com/mayureshucsb2019/bookstore/service/event/db"
+	"github.com/mayureshucsb2019/bookstore/service/mail"
+	order_db "github.com/mayureshucsb2019/bookstore/service/order/db"
+	payment_db "github.com/mayureshucsb2019/bookstore/service/payment/db"
+	review_db "github.com/mayureshucsb2019/bookstore/service/review/db"
+	"github.com/mayureshucsb2019/bookstore/service/token"
+	wishlist_db "github.com/mayureshucsb2019/bookstore/service/wishlist/db"
 )
 
 // DefaultAPIService is a service that implements the logic for the DefaultAPIServicer
 // This service should implement the business logic for every endpoint for the DefaultAPI API.
 // Include any external packages or services that will be required by this service.
 type DefaultAPIService struct {
-	Repo *db.CustomerRepository // Add a field to hold the repository
+	Repo     *db.CustomerRepository // Add a field to hold the repository
+	Orders   *order_db.OrderRepository
+	Payments *payment_db.PaymentRepository
+	Events   *event_db.OutboxRepository
+	// Reviews written by the customer are included in data exports when set
+	Reviews *review_db.ReviewRepository
+	// Wishlists and book alerts of the customer are included in data exports when set
+	Wishlists *wishlist_db.WishlistRepository
+
+	// Sends the mails of registration, password reset and email changes, which are refused without it
+	Mailer mail.Mailer
+	// Signs the tokens of email verification and password reset mails
+	Tokens *token.Signer
+
+	// Customers saved per transaction by bulk imports, common.DefaultBulkBatchSize when not set
+	BulkBatchSize int
 }
 
-// NewDefaultAPIService creates a default api service
-func NewDefaultAPIService(repo *db.CustomerRepository) *DefaultAPIService {
+// NewDefaultAPIService creates a default api service. The order, payment and outbox repositories
+// provide the history included in data exports.
+func NewDefaultAPIService(repo *db.CustomerRepository, orders *order_db.OrderRepository, payments *payment_db.PaymentRepository,
+	events *event_db.OutboxRepository) *DefaultAPIService {
 	return &DefaultAPIService{
-		Repo: repo,
+		Repo:     repo,
+		Orders:   orders,
+		Payments: payments,
+		Events:   events,
 	}
 }
 
@@ -46,6 +77,9 @@ func (s *DefaultAPIService) CustomersEmailGet(ctx context.Context, email string)
 	if err != nil {
 		return common.Response(http.StatusInternalServerError, nil), err
 	}
+	if customer.Segments, err = s.Repo.GetCustomerSegments(customer.ID); err != nil {
+		return common.Response(http.StatusInternalServerError, nil), err
+	}
 
 	return common.Response(http.StatusOK, convertDBToAPIResponse(*customer)), nil
 }
@@ -56,16 +90,23 @@ func (s *DefaultAPIService) CustomersEmailPatch(ctx context.Context, email strin
 	// return Response(404, nil),nil
 	// Check if the provided ISBN in the request path matches the ISBN in the body
 	if customer.Email != email {
-		return common.Response(http.StatusBadRequest, nil), errors.New("email in the path does not match email in the body")
+		return common.Response(http.StatusBadRequest, nil), errors.New("email in the path does not match email in the body, change it with POST /customers/{email}/email-change")
+	}
+	var err error
+	if customer.Address, err = normalizeAddress(customer.Address, "address."); err != nil {
+		return common.Response(http.StatusUnprocessableEntity, nil), err
 	}
 
 	// Call the repository method to update the customer
 	dbCustomer := convertApiToDBCustomer(customer)
-	err := s.Repo.UpdateCustomer(&dbCustomer)
+	err = s.Repo.UpdateCustomer(&dbCustomer)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return common.Response(http.StatusNotFound, nil), errors.New("customer not found")
 		}
+		if errors.Is(err, db.ErrInvalidStatusTransition) {
+			return common.Response(http.StatusConflict, nil), err
+		}
 		return common.Response(http.StatusInternalServerError, nil), err
 	}
 
@@ -73,10 +114,22 @@ func (s *DefaultAPIService) CustomersEmailPatch(ctx context.Context, email strin
 }
 
 // CustomersGet - Get a paginated list of customers
-func (s *DefaultAPIService) CustomersGet(ctx context.Context, pageNumber int32, pageSize int32) (common.ImplResponse, error) {
+func (s *DefaultAPIService) CustomersGet(ctx context.Context, pageNumber int32, pageSize int32, phone string, status string, segment string,
+	registeredFrom string, registeredTo string, country string) (common.ImplResponse, error) {
 	// TODO: Uncomment the next line to return response Response(404, {}) or use other options such as http.Ok ...
 	// return Response(404, nil),nil
-	customers, err := s.Repo.GetAllCustomers()
+	filter := db.CustomerFilter{
+		Phone:          phone,
+		Status:         status,
+		Segment:        segment,
+		RegisteredFrom: registeredFrom,
+		RegisteredTo:   registeredTo,
+		Country:        country,
+	}
+	if err := checkCustomerFilter(&filter); err != nil {
+		return common.Response(http.StatusBadRequest, nil), err
+	}
+	customers, err := s.Repo.FindCustomers(filter)
 	if err != nil {
 		return common.Response(http.StatusInternalServerError, nil), err
 	}
@@ -88,16 +141,61 @@ func (s *DefaultAPIService) CustomersGet(ctx context.Context, pageNumber int32,
 	return common.Response(http.StatusOK, customerResp), nil
 }
 
+// checkCustomerFilter validates the filters of CustomersGet and brings the country into the form
+// addresses are stored in.
+func checkCustomerFilter(filter *db.CustomerFilter) error {
+	if filter.Status != "" {
+		if err := models.AssertCustomerConstraints(models.Customer{Status: filter.Status}); err != nil {
+			return err
+		}
+	}
+	if filter.Segment != "" && !containsString(db.AllSegments, filter.Segment) {
+		return &common.ParsingError{Param: "segment", Err: fmt.Errorf("must be one of %s", strings.Join(db.AllSegments, ", "))}
+	}
+	for param, date := range map[string]string{"registeredFrom": filter.RegisteredFrom, "registeredTo": filter.RegisteredTo} {
+		if _, err := time.Parse("2006-01-02", date); date != "" && err != nil {
+			return &common.ParsingError{Param: param, Err: errors.New("must be a date such as 2024-01-31")}
+		}
+	}
+	if code, ok := address.CountryCode(filter.Country); ok {
+		filter.Country = code
+	}
+	return nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
 // CustomersPost - Add a new customer
 func (s *DefaultAPIService) CustomersPost(ctx context.Context, customer models.Customer) (common.ImplResponse, error) {
 	// TODO: Uncomment the next line to return response Response(404, {}) or use other options such as http.Ok ...
 	// return Response(404, nil),nil
+	var err error
+	if customer.Address, err = normalizeAddress(customer.Address, "address."); err != nil {
+		return common.Response(http.StatusUnprocessableEntity, nil), err
+	}
 	dbCustomer := convertApiToDBCustomer(customer)
+	if dbCustomer.Status == "" {
+		// Registered customers are activated by verifying their email
+		dbCustomer.Status = db.StatusPending
+	}
 
-	err := s.Repo.CreateCustomer(&dbCustomer)
+	err = s.Repo.CreateCustomer(&dbCustomer)
 	if err != nil {
 		return common.Response(http.StatusInternalServerError, nil), fmt.Errorf("failed to add customer: %w", err)
 	}
+	if dbCustomer.Status == db.StatusPending && s.Mailer != nil && s.Tokens != nil {
+		// The customer exists either way; they can ask for the mail again
+		if err := s.sendVerification(ctx, &dbCustomer); err != nil {
+			log.Printf("Failed to send verification mail to customer %d: %v", dbCustomer.ID, err)
+		}
+	}
 
 	return common.Response(http.StatusCreated, nil), nil
 }
@@ -105,28 +203,23 @@ func (s *DefaultAPIService) CustomersPost(ctx context.Context, customer models.C
 // ConvertApiToDBCustomer converts an API Customer struct to a database Customer struct.
 func convertApiToDBCustomer(apiCustomer models.Customer) db.Customer {
 	return db.Customer{
-		Email:       apiCustomer.Email,
-		FirstName:   apiCustomer.Name.FirstName,
-		MiddleName:  common.NullStringOrNil(apiCustomer.Name.MiddleName),
-		LastName:    apiCustomer.Name.LastName,
-		PhoneNumber: common.NullStringOrNil(apiCustomer.PhoneNumber),
-		Dob:         apiCustomer.DOB,
-		UnitNo:      common.NullStringOrNil(apiCustomer.Address.Unit),
-		StreetName:  common.NullStringOrNil(apiCustomer.Address.StreetName),
-		City:        common.NullStringOrNil(apiCustomer.Address.City),
-		State:       common.NullStringOrNil(apiCustomer.Address.State),
-		Country:     common.NullStringOrNil(apiCustomer.Address.Country),
-		Zipcode:     common.NullStringOrNil(apiCustomer.Address.Zipcode),
-		Landmark:    common.NullStringOrNil(apiCustomer.Address.Landmark),
-		Status:      apiCustomer.Status,
-		Notes:       common.NullStringOrNil(apiCustomer.Notes),
-		Languages:   apiCustomer.Languages,
+		Email:         apiCustomer.Email,
+		FirstName:     apiCustomer.Name.FirstName,
+		MiddleName:    common.NullStringOrNil(apiCustomer.Name.MiddleName),
+		LastName:      apiCustomer.Name.LastName,
+		PhoneNumber:   common.NullStringOrNil(apiCustomer.PhoneNumber),
+		Dob:           apiCustomer.DOB,
+		PostalAddress: convertAPIToDBPostalAddress(apiCustomer.Address),
+		Status:        apiCustomer.Status,
+		Notes:         common.NullStringOrNil(apiCustomer.Notes),
+		Languages:     apiCustomer.Languages,
 	}
 }
 
 // convertDBToAPIResponse converts a DBCustomer struct to an APICustomer struct.
 func convertDBToAPIResponse(dbCustomer db.Customer) models.Customer {
 	return models.Customer{
+		Id:    dbCustomer.ID,
 		Email: dbCustomer.Email,
 		Name: models.CustomerName{
 			FirstName:  dbCustomer.FirstName,
@@ -135,17 +228,12 @@ func convertDBToAPIResponse(dbCustomer db.Customer) models.Customer {
 		},
 		PhoneNumber: common.StringOrEmpty(dbCustomer.PhoneNumber),
 		DOB:         dbCustomer.Dob,
-		Address: models.CustomerAddress{
-			Unit:       common.StringOrEmpty(dbCustomer.UnitNo),
-			StreetName: common.StringOrEmpty(dbCustomer.StreetName),
-			City:       common.StringOrEmpty(dbCustomer.City),
-			State:      common.StringOrEmpty(dbCustomer.State),
-			Country:    common.StringOrEmpty(dbCustomer.Country),
-			Zipcode:    common.StringOrEmpty(dbCustomer.Zipcode),
-			Landmark:   common.StringOrEmpty(dbCustomer.Landmark),
-		},
-		Status:    dbCustomer.Status,
-		Notes:     common.StringOrEmpty(dbCustomer.Notes),
-		Languages: dbCustomer.Languages,
+		Address:     convertDBToAPIPostalAddress(dbCustomer.PostalAddress),
+		Status:      dbCustomer.Status,
+		Notes:       common.StringOrEmpty(dbCustomer.Notes),
+		Languages:   dbCustomer.Languages,
+
+		EmailVerifiedAt: common.StringOrEmpty(dbCustomer.EmailVerifiedAt),
+		Segments:        dbCustomer.Segments,
 	}
 }
@@ -0,0 +1,61 @@
+package openapi
+
+import (
+	"context"
+	"io"
+	"net/http"
+
+	"github.com/mayureshucsb2019/bookstore/service/common"
+	"github.com/mayureshucsb2019/bookstore/service/customer/db"
+	"github.com/mayureshucsb2019/bookstore/service/customer/models"
+)
+
+// BulkCustomersGet - Export every customer as NDJSON
+func (s *DefaultAPIService) BulkCustomersGet(ctx context.Context) (common.ImplResponse, error) {
+	export := func(encode func(v interface{}) error) error {
+		return s.Repo.ForEachCustomer(func(customer *db.Customer) error {
+			return encode(convertDBToAPIResponse(*customer))
+		})
+	}
+	return common.Response(http.StatusOK, common.NDJSONStream(export)), nil
+}
+
+// BulkCustomersPost - Create or replace customers from NDJSON
+func (s *DefaultAPIService) BulkCustomersPost(ctx context.Context, body io.Reader, batchSize int32) (common.ImplResponse, error) {
+	if batchSize == 0 {
+		batchSize = int32(s.BulkBatchSize)
+	}
+	bulk := common.BulkImport[db.Customer]{
+		Decode:    decodeBulkCustomer,
+		Save:      s.Repo.SaveCustomers,
+		BatchSize: int(batchSize),
+	}
+	results, err := common.SpoolStream(common.NDJSONContentType, func(w io.Writer) error {
+		_, err := bulk.Run(body, w)
+		return err
+	})
+	if err != nil {
+		return common.Response(http.StatusInternalServerError, nil), err
+	}
+	return common.Response(http.StatusOK, results), nil
+}
+
+// decodeBulkCustomer reads and validates one customer of a bulk import. Customers keep their
+// registration date when they are replaced.
+func decodeBulkCustomer(line []byte) (db.Customer, string, error) {
+	var customer models.Customer
+	if err := common.DecodeBulkRecord(line, &customer); err != nil {
+		return db.Customer{}, "", err
+	}
+	if err := models.AssertCustomerRequired(customer); err != nil {
+		return db.Customer{}, customer.Email, err
+	}
+	if err := models.AssertCustomerConstraints(customer); err != nil {
+		return db.Customer{}, customer.Email, err
+	}
+	var err error
+	if customer.Address, err = normalizeAddress(customer.Address, "address."); err != nil {
+		return db.Customer{}, customer.Email, err
+	}
+	return convertApiToDBCustomer(customer), customer.Email, nil
+}
@@ -0,0 +1,68 @@
+package openapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/mayureshucsb2019/bookstore/service/common"
+	"github.com/mayureshucsb2019/bookstore/service/customer/db"
+	"github.com/mayureshucsb2019/bookstore/service/customer/models"
+	"github.com/mayureshucsb2019/bookstore/service/mail"
+)
+
+// CustomersEmailEmailChangePost - Request a change of a customer's email
+func (s *DefaultAPIService) CustomersEmailEmailChangePost(ctx context.Context, email string, request models.EmailChangeRequest) (common.ImplResponse, error) {
+	if s.Mailer == nil {
+		return common.Response(http.StatusNotImplemented, nil), errMailNotConfigured
+	}
+	customer, err := s.Repo.GetCustomerByID(email)
+	if err != nil {
+		return emailChangeErrorResponse(err)
+	}
+
+	token, change, err := s.Repo.RequestEmailChange(email, request.NewEmail)
+	if err != nil {
+		return emailChangeErrorResponse(err)
+	}
+	message, err := mail.Render(mail.TemplateEmailChange, change.NewEmail, mail.TokenData{
+		Name:      customer.FirstName,
+		Token:     token,
+		ExpiresAt: change.ExpiresAt,
+	})
+	if err != nil {
+		return common.Response(http.StatusInternalServerError, nil), err
+	}
+	if err := s.Mailer.Send(ctx, message); err != nil {
+		return common.Response(http.StatusBadGateway, nil), err
+	}
+
+	return common.Response(http.StatusAccepted, models.EmailChange{NewEmail: change.NewEmail, ExpiresAt: change.ExpiresAt}), nil
+}
+
+// CustomersEmailChangeConfirmPost - Confirm an email change
+func (s *DefaultAPIService) CustomersEmailChangeConfirmPost(ctx context.Context, confirmation models.EmailChangeConfirmation) (common.ImplResponse, error) {
+	change, err := s.Repo.ConfirmEmailChange(confirmation.Token)
+	if err != nil {
+		return emailChangeErrorResponse(err)
+	}
+
+	customer, err := s.Repo.GetCustomerByID(change.NewEmail)
+	if err != nil {
+		return common.Response(http.StatusInternalServerError, nil), err
+	}
+	return common.Response(http.StatusOK, convertDBToAPIResponse(*customer)), nil
+}
+
+// emailChangeErrorResponse maps the errors of the email change repository methods to a response.
+func emailChangeErrorResponse(err error) (common.ImplResponse, error) {
+	switch {
+	case errors.Is(err, db.ErrCustomerNotFound), errors.Is(err, db.ErrEmailChangeNotFound):
+		return common.Response(http.StatusNotFound, nil), err
+	case errors.Is(err, db.ErrEmailChangeExpired):
+		return common.Response(http.StatusGone, nil), err
+	case errors.Is(err, db.ErrEmailTaken):
+		return common.Response(http.StatusConflict, nil), err
+	}
+	return common.Response(http.StatusInternalServerError, nil), err
+}
@@ -0,0 +1,198 @@
+package openapi
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/mayureshucsb2019/bookstore/service/common"
+	"github.com/mayureshucsb2019/bookstore/service/customer/db"
+	"github.com/mayureshucsb2019/bookstore/service/customer/models"
+	event_db "github.com/mayureshucsb2019/bookstore/service/event/db"
+	order_service "github.com/mayureshucsb2019/bookstore/service/order/service"
+	review_models "github.com/mayureshucsb2019/bookstore/service/review/models"
+	review_service "github.com/mayureshucsb2019/bookstore/service/review/service"
+	wishlist_models "github.com/mayureshucsb2019/bookstore/service/wishlist/models"
+	wishlist_service "github.com/mayureshucsb2019/bookstore/service/wishlist/service"
+)
+
+// CustomersEmailDataExportGet - Export everything stored about a customer
+func (s *DefaultAPIService) CustomersEmailDataExportGet(ctx context.Context, email string) (common.ImplResponse, error) {
+	customer, err := s.Repo.GetCustomerByID(email)
+	if err != nil {
+		if errors.Is(err, db.ErrCustomerNotFound) {
+			return common.Response(http.StatusNotFound, nil), err
+		}
+		return common.Response(http.StatusInternalServerError, nil), err
+	}
+
+	// Everything is read before the response starts, so a failure can still be reported
+	dbAddresses, err := s.Repo.GetAddresses(email)
+	if err != nil {
+		return common.Response(http.StatusInternalServerError, nil), err
+	}
+	addresses := []models.Address{}
+	for _, address := range dbAddresses {
+		addresses = append(addresses, convertDBToAPIAddress(address))
+	}
+	orders, err := s.Orders.GetOrdersByCustomer(email)
+	if err != nil {
+		return common.Response(http.StatusInternalServerError, nil), err
+	}
+	records := []models.CustomerOrderRecord{}
+	orderIDs := []string{}
+	for _, order := range orders {
+		history, err := s.Orders.GetStatusHistory(order.ID)
+		if err != nil {
+			return common.Response(http.StatusInternalServerError, nil), err
+		}
+		attempts, err := s.Payments.GetAttemptsByOrder(order.ID)
+		if err != nil {
+			return common.Response(http.StatusInternalServerError, nil), err
+		}
+		records = append(records, models.CustomerOrderRecord{
+			Order:         order_service.ConvertDBToAPIResponse(order),
+			StatusHistory: order_service.ConvertStatusHistory(history),
+			Payments:      order_service.ConvertPaymentAttempts(attempts),
+		})
+		orderIDs = append(orderIDs, strconv.FormatInt(order.ID, 10))
+	}
+	reviews := []review_models.Review{}
+	if s.Reviews != nil {
+		dbReviews, err := s.Reviews.GetCustomerReviews(email)
+		if err != nil {
+			return common.Response(http.StatusInternalServerError, nil), err
+		}
+		for _, review := range dbReviews {
+			reviews = append(reviews, review_service.ConvertDBToAPIResponse(review))
+		}
+	}
+	wishlists := []wishlist_models.Wishlist{}
+	alerts := []wishlist_models.BookAlert{}
+	if s.Wishlists != nil {
+		dbWishlists, err := s.Wishlists.GetWishlists(email)
+		if err != nil {
+			return common.Response(http.StatusInternalServerError, nil), err
+		}
+		for _, wishlist := range dbWishlists {
+			wishlists = append(wishlists, wishlist_service.ConvertDBToAPIWishlist(wishlist))
+		}
+		dbAlerts, err := s.Wishlists.GetAlerts(email)
+		if err != nil {
+			return common.Response(http.StatusInternalServerError, nil), err
+		}
+		for _, alert := range dbAlerts {
+			alerts = append(alerts, wishlist_service.ConvertDBToAPIAlert(alert))
+		}
+	}
+	// Events recorded before an email change refer to the previous email
+	emails, err := s.Repo.PreviousEmails(customer.ID)
+	if err != nil {
+		return common.Response(http.StatusInternalServerError, nil), err
+	}
+	customerEvents, err := s.Events.GetEntityEvents(event_db.EntityCustomer, append(emails, email))
+	if err != nil {
+		return common.Response(http.StatusInternalServerError, nil), err
+	}
+	orderEvents, err := s.Events.GetEntityEvents(event_db.EntityOrder, orderIDs)
+	if err != nil {
+		return common.Response(http.StatusInternalServerError, nil), err
+	}
+	events := append(append([]event_db.Event{}, customerEvents...), orderEvents...)
+
+	profile := models.CustomerProfile{
+		Customer:         convertDBToAPIResponse(*customer),
+		RegistrationDate: customer.RegistrationDate,
+		LastLogin:        common.StringOrEmpty(customer.LastLogin),
+	}
+	manifest := models.CustomerDataManifest{
+		Email:      email,
+		ExportedAt: time.Now().UTC().Format(time.RFC3339),
+		Files: map[string]string{
+			"customer.json":  "The customer record",
+			"addresses.json": "The address book of the customer",
+			"orders.json":    "Orders with their items, status history and payment attempts",
+			"reviews.json":   "Reviews of books written by the customer, whatever their moderation status",
+			"wishlists.json": "Wishlists of the customer with their books",
+			"alerts.json":    "Back in stock and price drop alerts set by the customer",
+			"events.json":    "Changes to the customer and their orders recorded in the event outbox",
+		},
+		Orders: len(records),
+		Events: len(events),
+	}
+	files := []struct {
+		name string
+		data interface{}
+	}{
+		{"manifest.json", manifest},
+		{"customer.json", profile},
+		{"addresses.json", addresses},
+		{"orders.json", records},
+		{"reviews.json", reviews},
+		{"wishlists.json", wishlists},
+		{"alerts.json", alerts},
+		{"events.json", events},
+	}
+
+	archive := func(w io.Writer) error {
+		zipWriter := zip.NewWriter(w)
+		for _, file := range files {
+			entry, err := zipWriter.Create(file.name)
+			if err != nil {
+				return err
+			}
+			encoder := json.NewEncoder(entry)
+			encoder.SetIndent("", "  ")
+			if err := encoder.Encode(file.data); err != nil {
+				return err
+			}
+		}
+		return zipWriter.Close()
+	}
+	return common.Response(http.StatusOK, common.Stream{ContentType: "application/zip", Filename: "customer-data.zip", Write: archive}), nil
+}
+
+// CustomersEmailPersonalDataDelete - Erase the personal data of a customer
+func (s *DefaultAPIService) CustomersEmailPersonalDataDelete(ctx context.Context, email string, reason string) (common.ImplResponse, error) {
+	erasure, err := s.Repo.EraseCustomer(email, reason)
+	if err != nil {
+		switch {
+		case errors.Is(err, db.ErrCustomerNotFound):
+			return common.Response(http.StatusNotFound, nil), err
+		case errors.Is(err, db.ErrErasureBlocked):
+			return common.Response(http.StatusConflict, nil), err
+		}
+		return common.Response(http.StatusInternalServerError, nil), err
+	}
+	return common.Response(http.StatusOK, convertErasureToAPIResponse(*erasure)), nil
+}
+
+// AdminCustomerErasuresIdGet - Get an erasure receipt
+func (s *DefaultAPIService) AdminCustomerErasuresIdGet(ctx context.Context, id string) (common.ImplResponse, error) {
+	erasure, err := s.Repo.GetErasure(id)
+	if err != nil {
+		if errors.Is(err, db.ErrErasureNotFound) {
+			return common.Response(http.StatusNotFound, nil), err
+		}
+		return common.Response(http.StatusInternalServerError, nil), err
+	}
+	return common.Response(http.StatusOK, convertErasureToAPIResponse(*erasure)), nil
+}
+
+// convertErasureToAPIResponse converts an erasure receipt to the API model
+func convertErasureToAPIResponse(erasure db.Erasure) models.CustomerErasure {
+	return models.CustomerErasure{
+		Id:             erasure.ID,
+		EmailHash:      erasure.EmailHash,
+		Pseudonym:      erasure.Pseudonym,
+		Reason:         erasure.Reason,
+		OrdersRetained: int32(erasure.OrdersRetained),
+		EventsRedacted: int32(erasure.EventsRedacted),
+		ErasedAt:       erasure.ErasedAt,
+	}
+}
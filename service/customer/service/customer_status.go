@@ -0,0 +1,64 @@
+package openapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/mayureshucsb2019/bookstore/service/common"
+	"github.com/mayureshucsb2019/bookstore/service/customer/db"
+	"github.com/mayureshucsb2019/bookstore/service/customer/models"
+)
+
+// AdminCustomersEmailClosePost - Close the account of a customer
+func (s *DefaultAPIService) AdminCustomersEmailClosePost(ctx context.Context, email string, reason models.CustomerStatusReason) (common.ImplResponse, error) {
+	return s.transitionCustomer(ctx, email, db.StatusClosed, reason.Reason)
+}
+
+// AdminCustomersEmailReactivatePost - Reactivate a suspended or inactive customer
+func (s *DefaultAPIService) AdminCustomersEmailReactivatePost(ctx context.Context, email string, reason models.CustomerStatusReason) (common.ImplResponse, error) {
+	return s.transitionCustomer(ctx, email, db.StatusActive, reason.Reason)
+}
+
+// AdminCustomersEmailStatusHistoryGet - Get the status changes made by staff to a customer
+func (s *DefaultAPIService) AdminCustomersEmailStatusHistoryGet(ctx context.Context, email string) (common.ImplResponse, error) {
+	history, err := s.Repo.GetStatusHistory(email)
+	if err != nil {
+		return statusErrorResponse(err)
+	}
+	resp := []models.CustomerStatusChange{}
+	for _, change := range history {
+		resp = append(resp, models.CustomerStatusChange{
+			FromStatus: change.FromStatus,
+			ToStatus:   change.ToStatus,
+			Reason:     change.Reason,
+			ChangedAt:  change.ChangedAt,
+		})
+	}
+
+	return common.Response(http.StatusOK, resp), nil
+}
+
+// AdminCustomersEmailSuspendPost - Suspend a customer
+func (s *DefaultAPIService) AdminCustomersEmailSuspendPost(ctx context.Context, email string, reason models.CustomerStatusReason) (common.ImplResponse, error) {
+	return s.transitionCustomer(ctx, email, db.StatusSuspended, reason.Reason)
+}
+
+// transitionCustomer moves a customer to a new status and responds with the updated customer.
+func (s *DefaultAPIService) transitionCustomer(ctx context.Context, email string, to string, reason string) (common.ImplResponse, error) {
+	if err := s.Repo.TransitionCustomer(email, to, reason); err != nil {
+		return statusErrorResponse(err)
+	}
+	return s.CustomersEmailGet(ctx, email)
+}
+
+// statusErrorResponse maps status change errors to a not found, conflict or internal error response.
+func statusErrorResponse(err error) (common.ImplResponse, error) {
+	switch {
+	case errors.Is(err, db.ErrCustomerNotFound):
+		return common.Response(http.StatusNotFound, nil), err
+	case errors.Is(err, db.ErrInvalidStatusTransition):
+		return common.Response(http.StatusConflict, nil), err
+	}
+	return common.Response(http.StatusInternalServerError, nil), err
+}
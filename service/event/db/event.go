@@ -0,0 +1,163 @@
+package db
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mayureshucsb2019/bookstore/service/common"
+)
+
+// Entity types events are published for
+const (
+	EntityBook      = "book"
+	EntityAuthor    = "author"
+	EntityInventory = "inventory" // Stock levels, keyed by ISBN
+	EntityCustomer  = "customer"
+	EntityOrder     = "order"
+)
+
+// Domain event types
+const (
+	BookCreated           = "BookCreated"
+	BookUpdated           = "BookUpdated"
+	BookPriceChanged      = "BookPriceChanged"
+	BookDeleted           = "BookDeleted"
+	BookStockChanged      = "BookStockChanged"
+	AuthorCreated         = "AuthorCreated"
+	AuthorUpdated         = "AuthorUpdated"
+	AuthorDeleted         = "AuthorDeleted"
+	CustomerCreated       = "CustomerCreated"
+	CustomerUpdated       = "CustomerUpdated"
+	CustomerDeactivated   = "CustomerDeactivated"
+	CustomerDeleted       = "CustomerDeleted"
+	CustomerErased        = "CustomerErased"
+	CustomerEmailChanged  = "CustomerEmailChanged"
+	CustomerStatusChanged = "CustomerStatusChanged"
+	OrderPlaced           = "OrderPlaced"
+	OrderStatusChanged    = "OrderStatusChanged"
+)
+
+// EventTypes lists every domain event type.
+var EventTypes = []string{
+	BookCreated, BookUpdated, BookPriceChanged, BookDeleted, BookStockChanged,
+	AuthorCreated, AuthorUpdated, AuthorDeleted,
+	CustomerCreated, CustomerUpdated, CustomerDeactivated, CustomerDeleted, CustomerErased, CustomerEmailChanged,
+	CustomerStatusChanged,
+	OrderPlaced, OrderStatusChanged,
+}
+
+// IsEventType reports whether eventType is a known domain event type.
+func IsEventType(eventType string) bool {
+	for _, t := range EventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// Event is a change to a domain entity, as stored in the Outbox table.
+type Event struct {
+	ID         int64           `json:"id"`
+	Type       string          `json:"type"`
+	EntityType string          `json:"entity_type"`
+	EntityID   string          `json:"entity_id"`
+	Payload    json.RawMessage `json:"payload"`
+	OccurredAt time.Time       `json:"occurred_at"`
+}
+
+// NewEvent creates an event with payload encoded as JSON.
+func NewEvent(eventType string, entityType string, entityID string, payload interface{}) (Event, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return Event{}, fmt.Errorf("failed to encode %s payload: %w", eventType, err)
+	}
+	return Event{
+		Type:       eventType,
+		EntityType: entityType,
+		EntityID:   entityID,
+		Payload:    data,
+		OccurredAt: time.Now().UTC(),
+	}, nil
+}
+
+// Append writes events to the outbox inside tx, so they are published if and only if the
+// change they describe is committed.
+func Append(tx *sql.Tx, events ...Event) error {
+	for _, event := range events {
+		_, err := tx.Exec(
+			`INSERT INTO Outbox (event_type, entity_type, entity_id, payload, occurred_at) VALUES (?, ?, ?, ?, ?)`,
+			event.Type, event.EntityType, event.EntityID, string(event.Payload), event.OccurredAt.Format(outboxTimeLayout),
+		)
+		if err != nil {
+			return fmt.Errorf("failed to write %s event to the outbox: %w", event.Type, err)
+		}
+	}
+	return nil
+}
+
+// StockChanged is the payload of BookStockChanged events.
+type StockChanged struct {
+	ISBN   string `json:"isbn"`
+	Stock  int    `json:"stock"`  // Copies in stock after the change
+	Change int    `json:"change"` // Copies added, negative when removed
+	Reason string `json:"reason"`
+}
+
+// RecordStockChange appends a BookStockChanged event inside tx.
+func RecordStockChange(tx *sql.Tx, isbn string, stock int, change int, reason string) error {
+	payload := StockChanged{ISBN: isbn, Stock: stock, Change: change, Reason: reason}
+	return Record(tx, BookStockChanged, EntityInventory, isbn, payload)
+}
+
+// PriceChanged is the payload of BookPriceChanged events.
+type PriceChanged struct {
+	ISBN    string       `json:"isbn"`
+	OldCost common.Money `json:"old_cost"`
+	NewCost common.Money `json:"new_cost"`
+}
+
+// Record creates an event and appends it to the outbox inside tx.
+func Record(tx *sql.Tx, eventType string, entityType string, entityID string, payload interface{}) error {
+	event, err := NewEvent(eventType, entityType, entityID, payload)
+	if err != nil {
+		return err
+	}
+	return Append(tx, event)
+}
+
+// RedactEvents rewrites the stored events of the given entities inside tx, so personal data that
+// must be erased does not live on in the outbox. redact changes each event in place; its entity
+// id and payload are saved. The rewritten events are returned.
+func RedactEvents(tx *sql.Tx, entityType string, entityIDs []string, redact func(event *Event) error) ([]Event, error) {
+	if len(entityIDs) == 0 {
+		return nil, nil
+	}
+	args := []interface{}{entityType}
+	for _, id := range entityIDs {
+		args = append(args, id)
+	}
+	rows, err := tx.Query(`SELECT `+eventColumns+` FROM Outbox WHERE entity_type = ? AND entity_id IN (?`+
+		strings.Repeat(", ?", len(entityIDs)-1)+`) ORDER BY id FOR UPDATE`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query the outbox: %w", err)
+	}
+	events, err := scanEvents(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range events {
+		if err := redact(&events[i]); err != nil {
+			return nil, err
+		}
+		_, err := tx.Exec(`UPDATE Outbox SET entity_id = ?, payload = ? WHERE id = ?`, events[i].EntityID, string(events[i].Payload), events[i].ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to redact outbox event %d: %w", events[i].ID, err)
+		}
+	}
+	return events, nil
+}
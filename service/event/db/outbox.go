@@ -0,0 +1,234 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	_ "github.com/go-sql-driver/mysql"
+)
+
+// outboxTimeLayout is the layout of the DATETIME(3) outbox columns, which hold UTC times.
+const outboxTimeLayout = "2006-01-02 15:04:05.000"
+
+// PendingEvent is an outbox event that has not been delivered to every sink yet.
+type PendingEvent struct {
+	Event
+	Attempts int // Failed delivery rounds so far
+}
+
+// OutboxRepository provides access to the Outbox storage.
+type OutboxRepository struct {
+	DB *sql.DB
+}
+
+// ClaimPending returns up to limit events that are due for delivery, oldest first, and hides
+// them from other dispatchers for lease. Rows are claimed with SKIP LOCKED so several
+// instances can dispatch from the same outbox.
+func (r *OutboxRepository) ClaimPending(limit int, lease time.Duration) ([]PendingEvent, error) {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now().UTC()
+	rows, err := tx.Query(`
+		SELECT id, event_type, entity_type, entity_id, payload, occurred_at, attempts
+		FROM Outbox
+		WHERE dispatched_at IS NULL AND next_attempt_at <= ?
+		ORDER BY id
+		LIMIT ?
+		FOR UPDATE SKIP LOCKED
+	`, now.Format(outboxTimeLayout), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query the outbox: %w", err)
+	}
+
+	var events []PendingEvent
+	for rows.Next() {
+		var event PendingEvent
+		var payload, occurredAt string
+		err := rows.Scan(&event.ID, &event.Type, &event.EntityType, &event.EntityID, &payload, &occurredAt, &event.Attempts)
+		if err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan outbox event: %w", err)
+		}
+		event.Payload = []byte(payload)
+		if event.OccurredAt, err = time.Parse(outboxTimeLayout, occurredAt); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to parse outbox event time: %w", err)
+		}
+		events = append(events, event)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+	if len(events) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]interface{}, 0, len(events)+1)
+	ids = append(ids, now.Add(lease).Format(outboxTimeLayout))
+	for _, event := range events {
+		ids = append(ids, event.ID)
+	}
+	query := `UPDATE Outbox SET next_attempt_at = ? WHERE id IN (?` + strings.Repeat(", ?", len(events)-1) + `)`
+	if _, err := tx.Exec(query, ids...); err != nil {
+		return nil, fmt.Errorf("failed to claim outbox events: %w", err)
+	}
+
+	return events, tx.Commit()
+}
+
+// GetDeliveredSinks returns the names of the sinks that already received an event.
+func (r *OutboxRepository) GetDeliveredSinks(eventID int64) (map[string]bool, error) {
+	rows, err := r.DB.Query(`SELECT sink FROM OutboxDeliveries WHERE event_id = ?`, eventID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query outbox deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	delivered := map[string]bool{}
+	for rows.Next() {
+		var sink string
+		if err := rows.Scan(&sink); err != nil {
+			return nil, fmt.Errorf("failed to scan outbox delivery: %w", err)
+		}
+		delivered[sink] = true
+	}
+	return delivered, rows.Err()
+}
+
+// MarkSinkDelivered records that a sink received an event, so a retry of the event skips it.
+func (r *OutboxRepository) MarkSinkDelivered(eventID int64, sink string) error {
+	_, err := r.DB.Exec(`INSERT IGNORE INTO OutboxDeliveries (event_id, sink) VALUES (?, ?)`, eventID, sink)
+	if err != nil {
+		return fmt.Errorf("failed to record outbox delivery: %w", err)
+	}
+	return nil
+}
+
+// MarkDispatched records that an event reached every sink.
+func (r *OutboxRepository) MarkDispatched(eventID int64) error {
+	_, err := r.DB.Exec(`UPDATE Outbox SET dispatched_at = ?, last_error = NULL WHERE id = ?`,
+		time.Now().UTC().Format(outboxTimeLayout), eventID)
+	if err != nil {
+		return fmt.Errorf("failed to mark outbox event dispatched: %w", err)
+	}
+	return nil
+}
+
+// MarkFailed records a failed delivery round and when the event should next be tried.
+func (r *OutboxRepository) MarkFailed(eventID int64, attempts int, nextAttempt time.Time, lastError string) error {
+	if len(lastError) > 1024 {
+		lastError = lastError[:1024]
+	}
+	_, err := r.DB.Exec(`UPDATE Outbox SET attempts = ?, next_attempt_at = ?, last_error = ? WHERE id = ?`,
+		attempts, nextAttempt.UTC().Format(outboxTimeLayout), lastError, eventID)
+	if err != nil {
+		return fmt.Errorf("failed to record outbox delivery failure: %w", err)
+	}
+	return nil
+}
+
+// DeleteDispatched removes events dispatched before cutoff and returns how many were removed.
+func (r *OutboxRepository) DeleteDispatched(cutoff time.Time) (int64, error) {
+	result, err := r.DB.Exec(`DELETE FROM Outbox WHERE dispatched_at IS NOT NULL AND dispatched_at < ?`,
+		cutoff.UTC().Format(outboxTimeLayout))
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete dispatched outbox events: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+const eventColumns = `id, event_type, entity_type, entity_id, payload, occurred_at`
+
+// GetEventsAfter retrieves up to limit events with ids above afterID, oldest first, whatever
+// their delivery state. throughID, when not zero, is the highest id returned; entityTypes, when
+// given, restricts the entity types.
+func (r *OutboxRepository) GetEventsAfter(afterID int64, throughID int64, entityTypes []string, limit int) ([]Event, error) {
+	query := `SELECT ` + eventColumns + ` FROM Outbox WHERE id > ?`
+	args := []interface{}{afterID}
+	if throughID > 0 {
+		query += ` AND id <= ?`
+		args = append(args, throughID)
+	}
+	if len(entityTypes) > 0 {
+		query += ` AND entity_type IN (?` + strings.Repeat(", ?", len(entityTypes)-1) + `)`
+		for _, entityType := range entityTypes {
+			args = append(args, entityType)
+		}
+	}
+	query += ` ORDER BY id LIMIT ?`
+	return r.queryEvents(query, append(args, limit)...)
+}
+
+// GetLatestEvents retrieves the last limit events, oldest first.
+func (r *OutboxRepository) GetLatestEvents(limit int) ([]Event, error) {
+	events, err := r.queryEvents(`SELECT `+eventColumns+` FROM Outbox ORDER BY id DESC LIMIT ?`, limit)
+	if err != nil {
+		return nil, err
+	}
+	for i, j := 0, len(events)-1; i < j; i, j = i+1, j-1 {
+		events[i], events[j] = events[j], events[i]
+	}
+	return events, nil
+}
+
+// GetOldestEventID returns the id of the oldest event still in the outbox, or 0 if it is empty.
+func (r *OutboxRepository) GetOldestEventID() (int64, error) {
+	var id sql.NullInt64
+	if err := r.DB.QueryRow(`SELECT MIN(id) FROM Outbox`).Scan(&id); err != nil {
+		return 0, fmt.Errorf("failed to read the oldest outbox event: %w", err)
+	}
+	return id.Int64, nil
+}
+
+// GetEntityEvents retrieves the events still in the outbox for the given entities, oldest first.
+func (r *OutboxRepository) GetEntityEvents(entityType string, entityIDs []string) ([]Event, error) {
+	if len(entityIDs) == 0 {
+		return nil, nil
+	}
+	args := []interface{}{entityType}
+	for _, id := range entityIDs {
+		args = append(args, id)
+	}
+	return r.queryEvents(`SELECT `+eventColumns+` FROM Outbox WHERE entity_type = ? AND entity_id IN (?`+
+		strings.Repeat(", ?", len(entityIDs)-1)+`) ORDER BY id`, args...)
+}
+
+func (r *OutboxRepository) queryEvents(query string, args ...interface{}) ([]Event, error) {
+	rows, err := r.DB.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query the outbox: %w", err)
+	}
+	return scanEvents(rows)
+}
+
+// scanEvents reads and closes rows of eventColumns.
+func scanEvents(rows *sql.Rows) ([]Event, error) {
+	defer rows.Close()
+
+	var events []Event
+	for rows.Next() {
+		var event Event
+		var payload, occurredAt string
+		if err := rows.Scan(&event.ID, &event.Type, &event.EntityType, &event.EntityID, &payload, &occurredAt); err != nil {
+			return nil, fmt.Errorf("failed to scan outbox event: %w", err)
+		}
+		event.Payload = []byte(payload)
+		var err error
+		if event.OccurredAt, err = time.Parse(outboxTimeLayout, occurredAt); err != nil {
+			return nil, fmt.Errorf("failed to parse outbox event time: %w", err)
+		}
+		events = append(events, event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+
+	return events, nil
+}
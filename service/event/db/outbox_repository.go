@@ -0,0 +1,19 @@
+package db
+
+import (
+	"sync"
+
+	"github.com/mayureshucsb2019/bookstore/service/common"
+)
+
+var outboxRepoInstance *OutboxRepository
+var outboxRepoOnce sync.Once
+
+func NewOutboxRepository(db *common.DBConnection) *OutboxRepository {
+	outboxRepoOnce.Do(func() {
+		outboxRepoInstance = &OutboxRepository{
+			DB: db.DB,
+		}
+	})
+	return outboxRepoInstance
+}
@@ -0,0 +1,152 @@
+package event
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mayureshucsb2019/bookstore/service/event/db"
+)
+
+// Sink receives domain events from the dispatcher. Delivery is at least once: an event can
+// reach a sink again after a crash or a failed round, so sinks must tolerate duplicates,
+// e.g. by remembering the event id. Names identify the sink in the outbox and must be stable.
+type Sink interface {
+	Name() string
+	Deliver(ctx context.Context, event db.Event) error
+}
+
+// Dispatcher delivers events from the outbox to the registered sinks in the background.
+// Events are tried in outbox order; one that fails for any sink is retried with exponential
+// backoff, only for the sinks that have not received it yet.
+type Dispatcher struct {
+	Repo *db.OutboxRepository
+
+	BatchSize  int           // Events claimed per poll
+	Lease      time.Duration // How long a claimed event is hidden from other dispatchers
+	MinBackoff time.Duration // Delay before the first retry, doubled for every further failure
+	MaxBackoff time.Duration
+
+	mu    sync.RWMutex
+	sinks []Sink
+}
+
+// NewDispatcher creates a dispatcher for the outbox with the default batch size and backoff.
+func NewDispatcher(repo *db.OutboxRepository, sinks ...Sink) *Dispatcher {
+	return &Dispatcher{
+		Repo:       repo,
+		BatchSize:  100,
+		Lease:      time.Minute,
+		MinBackoff: time.Second,
+		MaxBackoff: time.Hour,
+		sinks:      sinks,
+	}
+}
+
+// Register adds a sink. Events still in the outbox are delivered to it too.
+func (d *Dispatcher) Register(sink Sink) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.sinks = append(d.sinks, sink)
+}
+
+// Start polls the outbox every interval until the returned function is called.
+// Dispatched events older than retention are purged along the way.
+func (d *Dispatcher) Start(interval time.Duration, retention time.Duration) func() {
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		lastPurge := time.Now()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if _, err := d.DispatchPending(ctx); err != nil {
+					log.Printf("Failed to dispatch outbox events: %v", err)
+				}
+				if time.Since(lastPurge) >= time.Hour {
+					lastPurge = time.Now()
+					if _, err := d.Repo.DeleteDispatched(time.Now().Add(-retention)); err != nil {
+						log.Printf("Failed to purge dispatched outbox events: %v", err)
+					}
+				}
+			}
+		}
+	}()
+	return cancel
+}
+
+// DispatchPending delivers one batch of due events and returns how many reached every sink.
+func (d *Dispatcher) DispatchPending(ctx context.Context) (int, error) {
+	events, err := d.Repo.ClaimPending(d.BatchSize, d.Lease)
+	if err != nil {
+		return 0, err
+	}
+
+	d.mu.RLock()
+	sinks := append([]Sink(nil), d.sinks...)
+	d.mu.RUnlock()
+
+	dispatched := 0
+	for _, event := range events {
+		if err := d.deliver(ctx, event, sinks); err != nil {
+			attempts := event.Attempts + 1
+			if markErr := d.Repo.MarkFailed(event.ID, attempts, time.Now().Add(d.backoff(attempts)), err.Error()); markErr != nil {
+				return dispatched, markErr
+			}
+			log.Printf("Delivery of %s event %d failed (attempt %d): %v", event.Type, event.ID, attempts, err)
+			continue
+		}
+		if err := d.Repo.MarkDispatched(event.ID); err != nil {
+			return dispatched, err
+		}
+		dispatched++
+	}
+	return dispatched, nil
+}
+
+// deliver sends an event to every sink that has not received it yet.
+func (d *Dispatcher) deliver(ctx context.Context, event db.PendingEvent, sinks []Sink) error {
+	delivered := map[string]bool{}
+	if event.Attempts > 0 {
+		var err error
+		if delivered, err = d.Repo.GetDeliveredSinks(event.ID); err != nil {
+			return err
+		}
+	}
+
+	var failures []string
+	for _, sink := range sinks {
+		if delivered[sink.Name()] {
+			continue
+		}
+		if err := sink.Deliver(ctx, event.Event); err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", sink.Name(), err))
+			continue
+		}
+		if err := d.Repo.MarkSinkDelivered(event.ID, sink.Name()); err != nil {
+			return err
+		}
+	}
+	if len(failures) > 0 {
+		return fmt.Errorf("%s", strings.Join(failures, "; "))
+	}
+	return nil
+}
+
+// backoff returns the delay before retrying an event that has failed attempts times.
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+	delay := d.MinBackoff
+	for i := 1; i < attempts && delay < d.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > d.MaxBackoff {
+		delay = d.MaxBackoff
+	}
+	return delay
+}
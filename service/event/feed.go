@@ -0,0 +1,361 @@
+package event
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/mayureshucsb2019/bookstore/service/event/db"
+)
+
+// Feed follows the outbox and fans new events out to live subscribers, such as Server-Sent
+// Events streams. Unlike the dispatcher it does not claim events, so every instance sees all of
+// them. The latest events are kept in memory so reconnecting clients can resume from the cursor
+// of the last event they received; older ones are read back from the outbox while it still has them.
+//
+// Outbox ids are assigned when an event is written, not when its transaction commits, so an event
+// can become visible after events with higher ids. The feed remembers the ids it skipped over and
+// keeps looking for them until GapTimeout has passed, which only happens to ids of transactions
+// that rolled back or took longer than that to commit.
+type Feed struct {
+	Repo *db.OutboxRepository
+
+	BufferSize int           // Latest events kept in memory for resuming subscribers
+	BatchSize  int           // Events read per query
+	GapTimeout time.Duration // How long skipped ids are looked for before they are given up on
+	MaxBacklog int           // Most events replayed to a resuming subscriber before it is reset
+
+	mu          sync.Mutex
+	started     bool
+	buffer      []Delivery // In the order they were published, at most BufferSize
+	floor       int64      // Every published event with a higher id is in the buffer
+	lastID      int64      // Id of the newest event read
+	gaps        []gap      // Ids below lastID not read yet, lowest first
+	subscribers map[*Subscription]struct{}
+}
+
+// Delivery is an event published by the feed. Every event with an id up to Cursor was published
+// before it or with it, so a subscriber resuming from Cursor misses nothing, though it may get
+// events after Cursor it already had. Cursor is below the event's id while earlier ids are still
+// being committed.
+type Delivery struct {
+	db.Event
+	Cursor int64
+}
+
+// gap is a range of ids below the newest event read that have not been read. They belong to
+// transactions that are still running, or that rolled back.
+type gap struct {
+	from, to int64
+	since    time.Time // When the ids were first skipped over
+}
+
+// Subscription receives the events of a feed the subscriber asked for. The channel is closed
+// when the subscription is closed, or when the subscriber falls too far behind to keep up; it
+// should then resume from the cursor of the last event it got.
+type Subscription struct {
+	Events <-chan Delivery
+	Start  int64 // Cursor of the feed when the subscription started
+
+	feed        *Feed
+	events      chan Delivery
+	entityTypes map[string]bool
+	after       int64 // Cursor the subscriber resumed from
+}
+
+// NewFeed creates a feed over the outbox that keeps the latest bufferSize events in memory.
+func NewFeed(repo *db.OutboxRepository, bufferSize int) *Feed {
+	return &Feed{
+		Repo:        repo,
+		BufferSize:  bufferSize,
+		BatchSize:   500,
+		GapTimeout:  10 * time.Minute,
+		MaxBacklog:  1000,
+		subscribers: map[*Subscription]struct{}{},
+	}
+}
+
+// Start fills the buffer with the latest events and then polls the outbox every interval until
+// the returned function is called. Ids skipped over among the latest events are looked for like
+// any other gap; events committed later with ids below them were written before the feed started
+// and are not published.
+func (f *Feed) Start(interval time.Duration) (func(), error) {
+	events, err := f.Repo.GetLatestEvents(f.BufferSize)
+	if err != nil {
+		return nil, err
+	}
+	f.mu.Lock()
+	f.started = true
+	if len(events) > 0 {
+		f.floor = events[0].ID - 1
+		f.lastID = events[0].ID - 1
+	}
+	f.mu.Unlock()
+	f.publish(events, f.skip(events, time.Now(), nil))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if _, err := f.Poll(); err != nil {
+					log.Printf("Failed to read the event feed: %v", err)
+				}
+			}
+		}
+	}()
+	return cancel, nil
+}
+
+// Poll reads the events committed since the last poll, both into the gaps and after the newest
+// event, publishes them to the subscribers and returns how many there were. Polls must not run
+// concurrently.
+func (f *Feed) Poll() (int, error) {
+	f.mu.Lock()
+	lastID, gaps := f.lastID, append([]gap(nil), f.gaps...)
+	f.mu.Unlock()
+
+	now := time.Now()
+	var late []db.Event
+	var open []gap
+	for _, g := range gaps {
+		found, err := f.read(g.from-1, g.to)
+		if err != nil {
+			return 0, err
+		}
+		late = append(late, found...)
+		for _, rest := range g.without(found) {
+			if now.Sub(rest.since) > f.GapTimeout {
+				log.Printf("Gave up waiting for outbox events %d to %d after %v", rest.from, rest.to, f.GapTimeout)
+				continue
+			}
+			open = append(open, rest)
+		}
+	}
+	f.publish(late, open)
+
+	total := len(late)
+	for {
+		events, err := f.Repo.GetEventsAfter(lastID, 0, nil, f.BatchSize)
+		if err != nil {
+			return total, err
+		}
+		if len(events) == 0 {
+			return total, nil
+		}
+		open = f.skip(events, now, open)
+		f.publish(events, open)
+		total += len(events)
+		lastID = events[len(events)-1].ID
+		if len(events) < f.BatchSize {
+			return total, nil
+		}
+	}
+}
+
+// read retrieves all events with ids above afterID and up to throughID, oldest first.
+func (f *Feed) read(afterID int64, throughID int64) ([]db.Event, error) {
+	var events []db.Event
+	for {
+		batch, err := f.Repo.GetEventsAfter(afterID, throughID, nil, f.BatchSize)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, batch...)
+		if len(batch) < f.BatchSize {
+			return events, nil
+		}
+		afterID = batch[len(batch)-1].ID
+	}
+}
+
+// skip returns gaps with the ids skipped over by events, which follow the newest event read,
+// added at the end.
+func (f *Feed) skip(events []db.Event, now time.Time, gaps []gap) []gap {
+	f.mu.Lock()
+	lastID := f.lastID
+	f.mu.Unlock()
+
+	for _, event := range events {
+		if event.ID > lastID+1 {
+			gaps = append(gaps, gap{from: lastID + 1, to: event.ID - 1, since: now})
+		}
+		lastID = event.ID
+	}
+	return gaps
+}
+
+// without returns what is left of the gap once events, which fall into it, are read.
+func (g gap) without(events []db.Event) []gap {
+	var rest []gap
+	from := g.from
+	for _, event := range events {
+		if event.ID > from {
+			rest = append(rest, gap{from: from, to: event.ID - 1, since: g.since})
+		}
+		from = event.ID + 1
+	}
+	if from <= g.to {
+		rest = append(rest, gap{from: from, to: g.to, since: g.since})
+	}
+	return rest
+}
+
+// cursor returns the id every event up to which has been published. The caller holds the lock.
+func (f *Feed) cursor() int64 {
+	if len(f.gaps) > 0 {
+		return f.gaps[0].from - 1
+	}
+	return f.lastID
+}
+
+// publish appends events, either the next ones after the newest event or ones read from the gaps,
+// to the buffer and sends them to the subscribers; gaps are the ones still open afterwards.
+// Subscribers whose channel is full are dropped rather than holding up everyone else.
+func (f *Feed) publish(events []db.Event, gaps []gap) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	previousID := f.lastID
+	f.gaps = gaps
+	if len(events) > 0 && events[len(events)-1].ID > f.lastID {
+		f.lastID = events[len(events)-1].ID
+	}
+	cursor := f.cursor()
+	deliveries := make([]Delivery, len(events))
+	for i, event := range events {
+		// Everything up to the newest id published so far, short of the gaps still open
+		deliveries[i] = Delivery{Event: event, Cursor: previousID}
+		if event.ID > previousID {
+			deliveries[i].Cursor = event.ID
+		}
+		if deliveries[i].Cursor > cursor {
+			deliveries[i].Cursor = cursor
+		}
+	}
+
+	f.buffer = append(f.buffer, deliveries...)
+	if excess := len(f.buffer) - f.BufferSize; excess > 0 {
+		for _, delivery := range f.buffer[:excess] {
+			if delivery.ID > f.floor {
+				f.floor = delivery.ID
+			}
+		}
+		f.buffer = append(f.buffer[:0], f.buffer[excess:]...)
+	}
+
+	for subscription := range f.subscribers {
+		for _, delivery := range deliveries {
+			if !subscription.wants(delivery.Event) {
+				continue
+			}
+			select {
+			case subscription.events <- subscription.resume(delivery):
+			default:
+				f.remove(subscription)
+			}
+			if _, ok := f.subscribers[subscription]; !ok {
+				break
+			}
+		}
+	}
+}
+
+// Subscribe starts a subscription to the events of entityTypes, or to all events when none are
+// given. When lastEventID, the cursor of the last event the subscriber got, is not zero the
+// events after it are returned as a backlog to send before the live ones; reset is true when they
+// cannot all be replayed any more, in which case the subscriber should reload its state instead.
+func (f *Feed) Subscribe(lastEventID int64, entityTypes []string) (subscription *Subscription, backlog []Delivery, reset bool, err error) {
+	events := make(chan Delivery, 256)
+	subscription = &Subscription{Events: events, feed: f, events: events, after: lastEventID}
+	if len(entityTypes) > 0 {
+		subscription.entityTypes = map[string]bool{}
+		for _, entityType := range entityTypes {
+			subscription.entityTypes[entityType] = true
+		}
+	}
+
+	f.mu.Lock()
+	if !f.started {
+		f.mu.Unlock()
+		return nil, nil, false, fmt.Errorf("the event feed is not running")
+	}
+	f.subscribers[subscription] = struct{}{}
+	throughID, floor, cursor := f.lastID, f.floor, f.cursor()
+	subscription.Start = cursor
+	if lastEventID > 0 && lastEventID >= floor {
+		for _, delivery := range f.buffer {
+			if delivery.ID > lastEventID && subscription.wants(delivery.Event) {
+				backlog = append(backlog, subscription.resume(delivery))
+			}
+		}
+	}
+	f.mu.Unlock()
+
+	if lastEventID <= 0 || lastEventID >= floor || lastEventID >= throughID {
+		return subscription, backlog, false, nil
+	}
+
+	// The buffer does not reach back far enough, so replay from the outbox if it still has the
+	// events. Live events after throughID, or in the gaps, are already queued on the subscription.
+	oldest, err := f.Repo.GetOldestEventID()
+	if err != nil {
+		subscription.Close()
+		return nil, nil, false, err
+	}
+	if oldest > lastEventID+1 {
+		return subscription, nil, true, nil
+	}
+	missed, err := f.Repo.GetEventsAfter(lastEventID, throughID, entityTypes, f.MaxBacklog+1)
+	if err != nil {
+		subscription.Close()
+		return nil, nil, false, err
+	}
+	if len(missed) > f.MaxBacklog {
+		return subscription, nil, true, nil
+	}
+	for _, event := range missed {
+		// Every event up to the cursor of the feed was committed before the outbox was read
+		delivery := Delivery{Event: event, Cursor: event.ID}
+		if delivery.Cursor > cursor {
+			delivery.Cursor = cursor
+		}
+		backlog = append(backlog, subscription.resume(delivery))
+	}
+	return subscription, backlog, false, nil
+}
+
+// Close ends the subscription.
+func (s *Subscription) Close() {
+	s.feed.mu.Lock()
+	defer s.feed.mu.Unlock()
+	s.feed.remove(s)
+}
+
+// resume returns delivery with a cursor no lower than the one the subscriber resumed from, as
+// it already had the events up to there.
+func (s *Subscription) resume(delivery Delivery) Delivery {
+	if delivery.Cursor < s.after {
+		delivery.Cursor = s.after
+	}
+	return delivery
+}
+
+// wants reports whether the subscription is for the entity type of event.
+func (s *Subscription) wants(event db.Event) bool {
+	return s.entityTypes == nil || s.entityTypes[event.EntityType]
+}
+
+// remove unregisters a subscription and closes its channel. The caller holds the lock.
+func (f *Feed) remove(subscription *Subscription) {
+	if _, ok := f.subscribers[subscription]; ok {
+		delete(f.subscribers, subscription)
+		close(subscription.events)
+	}
+}
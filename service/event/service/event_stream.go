@@ -0,0 +1,169 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mayureshucsb2019/bookstore/service/common"
+	"github.com/mayureshucsb2019/bookstore/service/event"
+	"github.com/mayureshucsb2019/bookstore/service/event/db"
+)
+
+// StreamEntityTypes are the entity types clients can follow on the event stream.
+var StreamEntityTypes = []string{db.EntityBook, db.EntityAuthor, db.EntityInventory}
+
+// StreamController serves the domain event feed as Server-Sent Events. Streams are long-lived, so
+// it writes to the connection directly instead of going through a servicer.
+type StreamController struct {
+	feed      *event.Feed
+	keepAlive time.Duration
+}
+
+// NewStreamController creates a controller streaming the events of feed.
+func NewStreamController(feed *event.Feed) *StreamController {
+	return &StreamController{feed: feed, keepAlive: 15 * time.Second}
+}
+
+// Routes returns all the api routes for the StreamController
+func (c *StreamController) Routes() common.Routes {
+	return common.Routes{
+		"EventsStreamGet": common.Route{
+			Method:      strings.ToUpper("Get"),
+			Pattern:     "/events/stream",
+			HandlerFunc: c.EventsStreamGet,
+		},
+	}
+}
+
+// EventsStreamGet - Stream catalog and inventory changes
+func (c *StreamController) EventsStreamGet(w http.ResponseWriter, r *http.Request) {
+	query, err := common.ParseQuery(r.URL.RawQuery)
+	if err != nil {
+		common.DefaultErrorHandler(w, r, &common.ParsingError{Err: err}, nil)
+		return
+	}
+	entityTypes, err := parseEntityTypes(query.Get("types"))
+	if err != nil {
+		common.DefaultErrorHandler(w, r, &common.ParsingError{Param: "types", Err: err}, nil)
+		return
+	}
+	// Browsers resend the id of the last event they got on reconnect; other clients can pass it
+	// in the query string
+	lastEventIDParam := r.Header.Get("Last-Event-ID")
+	if lastEventIDParam == "" {
+		lastEventIDParam = query.Get("last_event_id")
+	}
+	var lastEventID int64
+	if lastEventIDParam != "" {
+		lastEventID, err = common.ParseNumericParameter[int64](lastEventIDParam, common.WithParse[int64](common.ParseInt64), common.WithMinimum[int64](0))
+		if err != nil {
+			common.DefaultErrorHandler(w, r, &common.ParsingError{Param: "Last-Event-ID", Err: err}, nil)
+			return
+		}
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		common.EncodeJSONResponse(map[string]string{"error": "streaming is not supported"}, intPtr(http.StatusInternalServerError), w)
+		return
+	}
+
+	subscription, backlog, reset, err := c.feed.Subscribe(lastEventID, entityTypes)
+	if err != nil {
+		log.Printf("Failed to subscribe to the event feed: %v", err)
+		common.EncodeJSONResponse(map[string]string{"error": "the event stream is unavailable"}, intPtr(http.StatusServiceUnavailable), w)
+		return
+	}
+	defer subscription.Close()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	// Ask clients to wait a few seconds before reconnecting
+	if _, err := fmt.Fprint(w, "retry: 3000\n\n"); err != nil {
+		return
+	}
+	if reset {
+		if err := writeReset(w, subscription.Start); err != nil {
+			return
+		}
+	}
+	for _, e := range backlog {
+		if err := writeEvent(w, e); err != nil {
+			return
+		}
+	}
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(c.keepAlive)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case e, ok := <-subscription.Events:
+			if !ok {
+				// Dropped for falling behind; the client reconnects with its last event id
+				return
+			}
+			if err := writeEvent(w, e); err != nil {
+				return
+			}
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+		}
+		flusher.Flush()
+	}
+}
+
+// parseEntityTypes parses a comma separated list of stream entity types, defaulting to all of them.
+func parseEntityTypes(param string) ([]string, error) {
+	if param == "" {
+		return StreamEntityTypes, nil
+	}
+	var entityTypes []string
+	for _, entityType := range strings.Split(param, ",") {
+		entityType = strings.TrimSpace(entityType)
+		valid := false
+		for _, t := range StreamEntityTypes {
+			valid = valid || t == entityType
+		}
+		if !valid {
+			return nil, fmt.Errorf("unknown entity type %q, expected one of %s", entityType, strings.Join(StreamEntityTypes, ", "))
+		}
+		entityTypes = append(entityTypes, entityType)
+	}
+	return entityTypes, nil
+}
+
+// writeEvent writes an event as an SSE message named after the event type, with the cursor of
+// the feed as the message id so clients can resume from it. The event's own id is in the data.
+func writeEvent(w http.ResponseWriter, d event.Delivery) error {
+	data, err := json.Marshal(d.Event)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", strconv.FormatInt(d.Cursor, 10), d.Type, data)
+	return err
+}
+
+// writeReset tells the client that events were missed and it should reload what it follows. The
+// message carries the id the stream continues from, so a reconnect does not reset again.
+func writeReset(w http.ResponseWriter, id int64) error {
+	_, err := fmt.Fprintf(w, "id: %d\nevent: reset\ndata: {\"reason\":\"the events after Last-Event-ID are no longer available\"}\n\n", id)
+	return err
+}
+
+func intPtr(i int) *int {
+	return &i
+}
@@ -0,0 +1,27 @@
+package event
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+
+	"github.com/mayureshucsb2019/bookstore/service/event/db"
+)
+
+// LogSink writes every event to the application log as JSON.
+type LogSink struct{}
+
+// Name identifies the sink in the outbox.
+func (LogSink) Name() string {
+	return "log"
+}
+
+// Deliver logs the event.
+func (LogSink) Deliver(ctx context.Context, event db.Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	log.Printf("Event %s", data)
+	return nil
+}
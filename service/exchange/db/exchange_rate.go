@@ -0,0 +1,138 @@
+package db
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	_ "github.com/go-sql-driver/mysql"
+	"github.com/mayureshucsb2019/bookstore/service/common"
+)
+
+// ExchangeRate represents the structure of an ExchangeRates record in the database.
+type ExchangeRate struct {
+	BaseCurrency  string `json:"base"`
+	QuoteCurrency string `json:"quote"`
+	Rate          string `json:"rate"` // Decimal string, one base unit buys Rate quote units
+	UpdatedAt     string `json:"updated_at,omitempty"`
+}
+
+// ExchangeRateRepository provides access to the ExchangeRates storage.
+type ExchangeRateRepository struct {
+	DB *sql.DB
+}
+
+// UpsertExchangeRate inserts or replaces the rate for a currency pair.
+func (r *ExchangeRateRepository) UpsertExchangeRate(rate *ExchangeRate) error {
+	query := `
+		INSERT INTO ExchangeRates (base_currency, quote_currency, rate)
+		VALUES (?, ?, ?)
+		ON DUPLICATE KEY UPDATE rate = VALUES(rate), updated_at = CURRENT_TIMESTAMP
+	`
+	_, err := r.DB.Exec(query, rate.BaseCurrency, rate.QuoteCurrency, rate.Rate)
+	if err != nil {
+		return fmt.Errorf("failed to upsert exchange rate: %w", err)
+	}
+	return nil
+}
+
+// GetExchangeRate retrieves the rate for a currency pair.
+func (r *ExchangeRateRepository) GetExchangeRate(base, quote string) (*ExchangeRate, error) {
+	query := `SELECT base_currency, quote_currency, rate, updated_at FROM ExchangeRates WHERE base_currency = ? AND quote_currency = ?`
+
+	var rate ExchangeRate
+	err := r.DB.QueryRow(query, base, quote).Scan(&rate.BaseCurrency, &rate.QuoteCurrency, &rate.Rate, &rate.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("%w: %s to %s", common.ErrRateNotFound, base, quote)
+		}
+		return nil, fmt.Errorf("failed to get exchange rate: %w", err)
+	}
+	return &rate, nil
+}
+
+// DeleteExchangeRate removes the rate for a currency pair.
+func (r *ExchangeRateRepository) DeleteExchangeRate(base, quote string) error {
+	result, err := r.DB.Exec(`DELETE FROM ExchangeRates WHERE base_currency = ? AND quote_currency = ?`, base, quote)
+	if err != nil {
+		return fmt.Errorf("failed to delete exchange rate: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("%w: %s to %s", common.ErrRateNotFound, base, quote)
+	}
+	return nil
+}
+
+// GetAllExchangeRates retrieves every configured rate.
+func (r *ExchangeRateRepository) GetAllExchangeRates() ([]ExchangeRate, error) {
+	rows, err := r.DB.Query(`SELECT base_currency, quote_currency, rate, updated_at FROM ExchangeRates ORDER BY base_currency, quote_currency`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query exchange rates: %w", err)
+	}
+	defer rows.Close()
+
+	var rates []ExchangeRate
+	for rows.Next() {
+		var rate ExchangeRate
+		if err := rows.Scan(&rate.BaseCurrency, &rate.QuoteCurrency, &rate.Rate, &rate.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan exchange rate: %w", err)
+		}
+		rates = append(rates, rate)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+
+	return rates, nil
+}
+
+// GetRateTable loads every configured rate into a table used for price conversion.
+func (r *ExchangeRateRepository) GetRateTable() (*common.RateTable, error) {
+	rates, err := r.GetAllExchangeRates()
+	if err != nil {
+		return nil, err
+	}
+
+	table := common.NewRateTable()
+	for _, rate := range rates {
+		parsed, err := common.ParseRate(rate.Rate)
+		if err != nil {
+			return nil, err
+		}
+		table.Set(rate.BaseCurrency, rate.QuoteCurrency, parsed)
+	}
+	return table, nil
+}
+
+// LoadExchangeRatesFile upserts the rates listed in a JSON file, e.g.
+// [{"base": "USD", "quote": "EUR", "rate": "0.92"}].
+func (r *ExchangeRateRepository) LoadExchangeRatesFile(filePath string) (int, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return 0, err
+	}
+
+	var rates []ExchangeRate
+	if err := json.Unmarshal(data, &rates); err != nil {
+		return 0, fmt.Errorf("failed to parse exchange rates file: %w", err)
+	}
+
+	for i := range rates {
+		if !common.IsValidCurrency(rates[i].BaseCurrency) || !common.IsValidCurrency(rates[i].QuoteCurrency) {
+			return i, fmt.Errorf("%w: %s/%s", common.ErrUnknownCurrency, rates[i].BaseCurrency, rates[i].QuoteCurrency)
+		}
+		if _, err := common.ParseRate(rates[i].Rate); err != nil {
+			return i, err
+		}
+		if err := r.UpsertExchangeRate(&rates[i]); err != nil {
+			return i, err
+		}
+	}
+	return len(rates), nil
+}
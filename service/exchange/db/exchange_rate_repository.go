@@ -0,0 +1,19 @@
+package db
+
+import (
+	"sync"
+
+	"github.com/mayureshucsb2019/bookstore/service/common"
+)
+
+var exchangeRateRepoInstance *ExchangeRateRepository
+var exchangeRateRepoOnce sync.Once
+
+func NewExchangeRateRepository(db *common.DBConnection) *ExchangeRateRepository {
+	exchangeRateRepoOnce.Do(func() {
+		exchangeRateRepoInstance = &ExchangeRateRepository{
+			DB: db.DB,
+		}
+	})
+	return exchangeRateRepoInstance
+}
@@ -0,0 +1,45 @@
+package models
+
+import (
+	"strings"
+
+	"github.com/mayureshucsb2019/bookstore/service/common"
+)
+
+type ExchangeRate struct {
+	Base string `json:"base"`
+
+	Quote string `json:"quote"`
+
+	// One unit of the base currency buys this many units of the quote currency.
+	Rate string `json:"rate"`
+
+	UpdatedAt string `json:"updated_at,omitempty"`
+}
+
+// AssertExchangeRateRequired checks if the required fields are not zero-ed
+func AssertExchangeRateRequired(obj ExchangeRate) error {
+	elements := map[string]interface{}{
+		"rate": obj.Rate,
+	}
+	for name, el := range elements {
+		if isZero := common.IsZeroValue(el); isZero {
+			return &common.RequiredError{Field: name}
+		}
+	}
+
+	return nil
+}
+
+// AssertExchangeRateConstraints checks if the values respects the defined constraints
+func AssertExchangeRateConstraints(obj ExchangeRate) error {
+	if _, err := common.ParseRate(obj.Rate); err != nil {
+		return &common.ParsingError{Param: "rate", Err: err}
+	}
+	for param, code := range map[string]string{"base": obj.Base, "quote": obj.Quote} {
+		if code != "" && !common.IsValidCurrency(strings.ToUpper(code)) {
+			return &common.ParsingError{Param: param, Err: common.ErrUnknownCurrency}
+		}
+	}
+	return nil
+}
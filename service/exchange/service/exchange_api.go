@@ -0,0 +1,30 @@
+package service
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/mayureshucsb2019/bookstore/service/common"
+	"github.com/mayureshucsb2019/bookstore/service/exchange/models"
+)
+
+// DefaultAPIRouter defines the required methods for binding the api requests to a responses for the DefaultAPI
+// The DefaultAPIRouter implementation should parse necessary information from the http request,
+// pass the data to a DefaultAPIServicer to perform the required actions, then write the service results to the http response.
+type DefaultAPIRouter interface {
+	AdminExchangeRatesGet(http.ResponseWriter, *http.Request)
+	AdminExchangeRatesBaseQuoteDelete(http.ResponseWriter, *http.Request)
+	AdminExchangeRatesBaseQuoteGet(http.ResponseWriter, *http.Request)
+	AdminExchangeRatesBaseQuotePut(http.ResponseWriter, *http.Request)
+}
+
+// DefaultAPIServicer defines the api actions for the DefaultAPI service
+// This interface intended to stay up to date with the openapi yaml used to generate it,
+// while the service implementation can be ignored with the .openapi-generator-ignore file
+// and updated with the logic required for the API.
+type DefaultAPIServicer interface {
+	AdminExchangeRatesGet(context.Context) (common.ImplResponse, error)
+	AdminExchangeRatesBaseQuoteDelete(context.Context, string, string) (common.ImplResponse, error)
+	AdminExchangeRatesBaseQuoteGet(context.Context, string, string) (common.ImplResponse, error)
+	AdminExchangeRatesBaseQuotePut(context.Context, string, string, models.ExchangeRate) (common.ImplResponse, error)
+}
@@ -0,0 +1,165 @@
+package service
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/mayureshucsb2019/bookstore/service/common"
+	"github.com/mayureshucsb2019/bookstore/service/exchange/models"
+)
+
+// DefaultAPIController binds http requests to an api service and writes the service results to the http response
+type DefaultAPIController struct {
+	service      DefaultAPIServicer
+	errorHandler common.ErrorHandler
+}
+
+// DefaultAPIOption for how the controller is set up.
+type DefaultAPIOption func(*DefaultAPIController)
+
+// WithDefaultAPIErrorHandler inject ErrorHandler into controller
+func WithDefaultAPIErrorHandler(h common.ErrorHandler) DefaultAPIOption {
+	return func(c *DefaultAPIController) {
+		c.errorHandler = h
+	}
+}
+
+// NewDefaultAPIController creates a default api controller
+func NewDefaultAPIController(s DefaultAPIServicer, opts ...DefaultAPIOption) *DefaultAPIController {
+	controller := &DefaultAPIController{
+		service:      s,
+		errorHandler: common.DefaultErrorHandler,
+	}
+
+	for _, opt := range opts {
+		opt(controller)
+	}
+
+	return controller
+}
+
+// Routes returns all the api routes for the DefaultAPIController
+func (c *DefaultAPIController) Routes() common.Routes {
+	return common.Routes{
+		"AdminExchangeRatesGet": common.Route{
+			Method:      strings.ToUpper("Get"),
+			Pattern:     "/admin/exchange-rates",
+			HandlerFunc: c.AdminExchangeRatesGet,
+		},
+		"AdminExchangeRatesBaseQuoteDelete": common.Route{
+			Method:      strings.ToUpper("Delete"),
+			Pattern:     "/admin/exchange-rates/{base}/{quote}",
+			HandlerFunc: c.AdminExchangeRatesBaseQuoteDelete,
+		},
+		"AdminExchangeRatesBaseQuoteGet": common.Route{
+			Method:      strings.ToUpper("Get"),
+			Pattern:     "/admin/exchange-rates/{base}/{quote}",
+			HandlerFunc: c.AdminExchangeRatesBaseQuoteGet,
+		},
+		"AdminExchangeRatesBaseQuotePut": common.Route{
+			Method:      strings.ToUpper("Put"),
+			Pattern:     "/admin/exchange-rates/{base}/{quote}",
+			HandlerFunc: c.AdminExchangeRatesBaseQuotePut,
+		},
+	}
+}
+
+// AdminExchangeRatesGet - List all exchange rates
+func (c *DefaultAPIController) AdminExchangeRatesGet(w http.ResponseWriter, r *http.Request) {
+	result, err := c.service.AdminExchangeRatesGet(r.Context())
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	_ = common.EncodeJSONResponse(result.Body, &result.Code, w)
+}
+
+// AdminExchangeRatesBaseQuoteDelete - Delete the exchange rate for a currency pair
+func (c *DefaultAPIController) AdminExchangeRatesBaseQuoteDelete(w http.ResponseWriter, r *http.Request) {
+	baseParam, quoteParam, err := parseCurrencyPair(r)
+	if err != nil {
+		c.errorHandler(w, r, err, nil)
+		return
+	}
+	result, err := c.service.AdminExchangeRatesBaseQuoteDelete(r.Context(), baseParam, quoteParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	_ = common.EncodeJSONResponse(result.Body, &result.Code, w)
+}
+
+// AdminExchangeRatesBaseQuoteGet - Get the exchange rate for a currency pair
+func (c *DefaultAPIController) AdminExchangeRatesBaseQuoteGet(w http.ResponseWriter, r *http.Request) {
+	baseParam, quoteParam, err := parseCurrencyPair(r)
+	if err != nil {
+		c.errorHandler(w, r, err, nil)
+		return
+	}
+	result, err := c.service.AdminExchangeRatesBaseQuoteGet(r.Context(), baseParam, quoteParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	_ = common.EncodeJSONResponse(result.Body, &result.Code, w)
+}
+
+// AdminExchangeRatesBaseQuotePut - Create or replace the exchange rate for a currency pair
+func (c *DefaultAPIController) AdminExchangeRatesBaseQuotePut(w http.ResponseWriter, r *http.Request) {
+	baseParam, quoteParam, err := parseCurrencyPair(r)
+	if err != nil {
+		c.errorHandler(w, r, err, nil)
+		return
+	}
+	exchangeRateParam := models.ExchangeRate{}
+	d := json.NewDecoder(r.Body)
+	d.DisallowUnknownFields()
+	if err := d.Decode(&exchangeRateParam); err != nil {
+		c.errorHandler(w, r, &common.ParsingError{Err: err}, nil)
+		return
+	}
+	if err := models.AssertExchangeRateRequired(exchangeRateParam); err != nil {
+		c.errorHandler(w, r, err, nil)
+		return
+	}
+	if err := models.AssertExchangeRateConstraints(exchangeRateParam); err != nil {
+		c.errorHandler(w, r, err, nil)
+		return
+	}
+	result, err := c.service.AdminExchangeRatesBaseQuotePut(r.Context(), baseParam, quoteParam, exchangeRateParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	_ = common.EncodeJSONResponse(result.Body, &result.Code, w)
+}
+
+// parseCurrencyPair reads and validates the base and quote currency path parameters
+func parseCurrencyPair(r *http.Request) (string, string, error) {
+	params := mux.Vars(r)
+	baseParam := strings.ToUpper(params["base"])
+	quoteParam := strings.ToUpper(params["quote"])
+	if baseParam == "" {
+		return "", "", &common.RequiredError{Field: "base"}
+	}
+	if quoteParam == "" {
+		return "", "", &common.RequiredError{Field: "quote"}
+	}
+	if !common.IsValidCurrency(baseParam) {
+		return "", "", &common.ParsingError{Param: "base", Err: common.ErrUnknownCurrency}
+	}
+	if !common.IsValidCurrency(quoteParam) {
+		return "", "", &common.ParsingError{Param: "quote", Err: common.ErrUnknownCurrency}
+	}
+	return baseParam, quoteParam, nil
+}
@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/mayureshucsb2019/bookstore/service/common"
+	"github.com/mayureshucsb2019/bookstore/service/exchange/db"
+	"github.com/mayureshucsb2019/bookstore/service/exchange/models"
+)
+
+// DefaultAPIService is a service that implements the logic for the DefaultAPIServicer
+// This service manages the exchange rates used to display prices in other currencies.
+type DefaultAPIService struct {
+	Repo *db.ExchangeRateRepository
+}
+
+// NewDefaultAPIService creates a default API service with the given repository.
+func NewDefaultAPIService(repo *db.ExchangeRateRepository) *DefaultAPIService {
+	return &DefaultAPIService{
+		Repo: repo,
+	}
+}
+
+// AdminExchangeRatesGet - List all exchange rates
+func (s *DefaultAPIService) AdminExchangeRatesGet(ctx context.Context) (common.ImplResponse, error) {
+	rates, err := s.Repo.GetAllExchangeRates()
+	if err != nil {
+		return common.Response(http.StatusInternalServerError, nil), err
+	}
+	var ratesResp []models.ExchangeRate
+	for _, rate := range rates {
+		ratesResp = append(ratesResp, convertDBToAPIResponse(rate))
+	}
+
+	return common.Response(http.StatusOK, ratesResp), nil
+}
+
+// AdminExchangeRatesBaseQuoteDelete - Delete the exchange rate for a currency pair
+func (s *DefaultAPIService) AdminExchangeRatesBaseQuoteDelete(ctx context.Context, base string, quote string) (common.ImplResponse, error) {
+	err := s.Repo.DeleteExchangeRate(base, quote)
+	if err != nil {
+		if errors.Is(err, common.ErrRateNotFound) {
+			return common.Response(http.StatusNotFound, nil), err
+		}
+		return common.Response(http.StatusInternalServerError, nil), err
+	}
+
+	return common.Response(http.StatusNoContent, nil), nil
+}
+
+// AdminExchangeRatesBaseQuoteGet - Get the exchange rate for a currency pair
+func (s *DefaultAPIService) AdminExchangeRatesBaseQuoteGet(ctx context.Context, base string, quote string) (common.ImplResponse, error) {
+	rate, err := s.Repo.GetExchangeRate(base, quote)
+	if err != nil {
+		if errors.Is(err, common.ErrRateNotFound) {
+			return common.Response(http.StatusNotFound, nil), err
+		}
+		return common.Response(http.StatusInternalServerError, nil), err
+	}
+
+	return common.Response(http.StatusOK, convertDBToAPIResponse(*rate)), nil
+}
+
+// AdminExchangeRatesBaseQuotePut - Create or replace the exchange rate for a currency pair
+func (s *DefaultAPIService) AdminExchangeRatesBaseQuotePut(ctx context.Context, base string, quote string, rate models.ExchangeRate) (common.ImplResponse, error) {
+	if base == quote {
+		return common.Response(http.StatusBadRequest, nil), errors.New("base and quote currency must differ")
+	}
+	if (rate.Base != "" && !strings.EqualFold(rate.Base, base)) || (rate.Quote != "" && !strings.EqualFold(rate.Quote, quote)) {
+		return common.Response(http.StatusBadRequest, nil), errors.New("currency pair in the path does not match the body")
+	}
+
+	parsed, err := common.ParseRate(rate.Rate)
+	if err != nil {
+		return common.Response(http.StatusBadRequest, nil), err
+	}
+	dbRate := db.ExchangeRate{
+		BaseCurrency:  base,
+		QuoteCurrency: quote,
+		Rate:          common.FormatRate(parsed),
+	}
+	if err := s.Repo.UpsertExchangeRate(&dbRate); err != nil {
+		return common.Response(http.StatusInternalServerError, nil), err
+	}
+
+	return common.Response(http.StatusOK, convertDBToAPIResponse(dbRate)), nil
+}
+
+// convertDBToAPIResponse converts the DB model to the API model
+func convertDBToAPIResponse(rate db.ExchangeRate) models.ExchangeRate {
+	return models.ExchangeRate{
+		Base:      rate.BaseCurrency,
+		Quote:     rate.QuoteCurrency,
+		Rate:      rate.Rate,
+		UpdatedAt: rate.UpdatedAt,
+	}
+}
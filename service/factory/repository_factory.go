@@ -5,8 +5,19 @@ import (
 
 	author_db "github.com/mayureshucsb2019/bookstore/service/author/db"
 	book_db "github.com/mayureshucsb2019/bookstore/service/book/db"
+	cart_db "github.com/mayureshucsb2019/bookstore/service/cart/db"
 	"github.com/mayureshucsb2019/bookstore/service/common"
 	customer_db "github.com/mayureshucsb2019/bookstore/service/customer/db"
+	event_db "github.com/mayureshucsb2019/bookstore/service/event/db"
+	exchange_db "github.com/mayureshucsb2019/bookstore/service/exchange/db"
+	order_db "github.com/mayureshucsb2019/bookstore/service/order/db"
+	payment_db "github.com/mayureshucsb2019/bookstore/service/payment/db"
+	promotion_db "github.com/mayureshucsb2019/bookstore/service/promotion/db"
+	recommendation_db "github.com/mayureshucsb2019/bookstore/service/recommendation/db"
+	review_db "github.com/mayureshucsb2019/bookstore/service/review/db"
+	shipping_db "github.com/mayureshucsb2019/bookstore/service/shipping/db"
+	webhook_db "github.com/mayureshucsb2019/bookstore/service/webhook/db"
+	wishlist_db "github.com/mayureshucsb2019/bookstore/service/wishlist/db"
 )
 
 type RepositoryFactory struct {
@@ -36,3 +47,47 @@ func (f *RepositoryFactory) CreateAuthorRepository() *author_db.AuthorRepository
 func (f *RepositoryFactory) CreateCustomerRepository() *customer_db.CustomerRepository {
 	return customer_db.NewCustomerRepository(f.dbConn)
 }
+
+func (f *RepositoryFactory) CreateOrderRepository() *order_db.OrderRepository {
+	return order_db.NewOrderRepository(f.dbConn)
+}
+
+func (f *RepositoryFactory) CreateExchangeRateRepository() *exchange_db.ExchangeRateRepository {
+	return exchange_db.NewExchangeRateRepository(f.dbConn)
+}
+
+func (f *RepositoryFactory) CreateCartRepository() *cart_db.CartRepository {
+	return cart_db.NewCartRepository(f.dbConn)
+}
+
+func (f *RepositoryFactory) CreatePromotionRepository() *promotion_db.PromotionRepository {
+	return promotion_db.NewPromotionRepository(f.dbConn)
+}
+
+func (f *RepositoryFactory) CreateShippingMethodRepository() *shipping_db.ShippingMethodRepository {
+	return shipping_db.NewShippingMethodRepository(f.dbConn)
+}
+
+func (f *RepositoryFactory) CreatePaymentRepository() *payment_db.PaymentRepository {
+	return payment_db.NewPaymentRepository(f.dbConn)
+}
+
+func (f *RepositoryFactory) CreateOutboxRepository() *event_db.OutboxRepository {
+	return event_db.NewOutboxRepository(f.dbConn)
+}
+
+func (f *RepositoryFactory) CreateWebhookRepository() *webhook_db.WebhookRepository {
+	return webhook_db.NewWebhookRepository(f.dbConn)
+}
+
+func (f *RepositoryFactory) CreateReviewRepository() *review_db.ReviewRepository {
+	return review_db.NewReviewRepository(f.dbConn)
+}
+
+func (f *RepositoryFactory) CreateWishlistRepository() *wishlist_db.WishlistRepository {
+	return wishlist_db.NewWishlistRepository(f.dbConn)
+}
+
+func (f *RepositoryFactory) CreateRecommendationRepository() *recommendation_db.RecommendationRepository {
+	return recommendation_db.NewRecommendationRepository(f.dbConn)
+}
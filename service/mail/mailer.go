@@ -0,0 +1,94 @@
+// Package mail sends the messages of account flows such as email verification and password
+// reset, and of book alerts.
+package mail
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net/mail"
+	"strings"
+	"time"
+)
+
+// Message is a plain text email.
+type Message struct {
+	From    string
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer delivers messages. Implementations fill in From when it is empty.
+type Mailer interface {
+	Send(ctx context.Context, message Message) error
+}
+
+// Config selects and configures a Mailer.
+type Config struct {
+	// "smtp", or "file" to write messages to Dir. Defaults to "file"
+	Provider string `json:"provider"`
+	// Sender address, defaults to DefaultFrom
+	From string `json:"from"`
+	// host:port of the SMTP server
+	SMTPAddr     string `json:"smtp_addr"`
+	SMTPUsername string `json:"smtp_username"`
+	SMTPPassword string `json:"smtp_password"`
+	// Directory the file mailer writes to, defaults to "mail"
+	Dir string `json:"dir"`
+}
+
+// DefaultFrom is the sender of messages when none is configured.
+const DefaultFrom = "Bookstore <no-reply@bookstore.local>"
+
+// NewMailer creates the mailer selected by config.
+func NewMailer(config Config) (Mailer, error) {
+	from := config.From
+	if from == "" {
+		from = DefaultFrom
+	}
+	if _, err := mail.ParseAddress(from); err != nil {
+		return nil, fmt.Errorf("invalid sender %q: %w", from, err)
+	}
+	switch config.Provider {
+	case "", "file":
+		dir := config.Dir
+		if dir == "" {
+			dir = "mail"
+		}
+		return NewFileMailer(dir, from)
+	case "smtp":
+		if config.SMTPAddr == "" {
+			return nil, fmt.Errorf("smtp mailer needs smtp_addr")
+		}
+		return NewSMTPMailer(config.SMTPAddr, config.SMTPUsername, config.SMTPPassword, from), nil
+	}
+	return nil, fmt.Errorf("unknown mail provider %q", config.Provider)
+}
+
+// format renders a message in RFC 5322 form with a quoted-printable UTF-8 body.
+func format(message Message, date time.Time) ([]byte, error) {
+	for _, value := range []string{message.From, message.To, message.Subject} {
+		if strings.ContainsAny(value, "\r\n") {
+			return nil, fmt.Errorf("header value %q contains a line break", value)
+		}
+	}
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "From: %s\r\n", message.From)
+	fmt.Fprintf(&b, "To: %s\r\n", message.To)
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", message.Subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", date.Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+	body := quotedprintable.NewWriter(&b)
+	if _, err := body.Write([]byte(strings.ReplaceAll(message.Body, "\n", "\r\n"))); err != nil {
+		return nil, err
+	}
+	if err := body.Close(); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
@@ -0,0 +1,55 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"time"
+)
+
+// SMTPMailer sends messages through an SMTP server, authenticating with PLAIN when a username is
+// set. net/smtp only allows PLAIN over TLS or to localhost, so credentials are never sent in the
+// clear.
+type SMTPMailer struct {
+	addr string
+	auth smtp.Auth
+	from string
+}
+
+// NewSMTPMailer creates a mailer for the server at addr (host:port).
+func NewSMTPMailer(addr string, username string, password string, from string) *SMTPMailer {
+	mailer := &SMTPMailer{addr: addr, from: from}
+	if username != "" {
+		host, _, _ := net.SplitHostPort(addr)
+		mailer.auth = smtp.PlainAuth("", username, password, host)
+	}
+	return mailer
+}
+
+// Send delivers a message. The context is not honored once the SMTP session has started.
+func (m *SMTPMailer) Send(ctx context.Context, message Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if message.From == "" {
+		message.From = m.from
+	}
+	from, err := mail.ParseAddress(message.From)
+	if err != nil {
+		return fmt.Errorf("invalid sender: %w", err)
+	}
+	to, err := mail.ParseAddress(message.To)
+	if err != nil {
+		return fmt.Errorf("invalid recipient: %w", err)
+	}
+	data, err := format(message, time.Now())
+	if err != nil {
+		return err
+	}
+	if err := smtp.SendMail(m.addr, m.auth, from.Address, []string{to.Address}, data); err != nil {
+		return fmt.Errorf("failed to send mail to %s: %w", to.Address, err)
+	}
+	return nil
+}
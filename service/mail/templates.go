@@ -0,0 +1,60 @@
+package mail
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// Names of the message templates
+const (
+	TemplateVerifyEmail   = "verify-email"
+	TemplatePasswordReset = "password-reset"
+	TemplateEmailChange   = "email-change"
+	TemplateBackInStock   = "back-in-stock"
+	TemplatePriceDrop     = "price-drop"
+)
+
+//go:embed templates/*.txt
+var templateFiles embed.FS
+
+// templates holds the messages of the account flows and book alerts. Each file starts with a "Subject:" line
+// followed by a blank line and the body, and both are executed with the data passed to Render.
+var templates = template.Must(template.New("").Option("missingkey=error").ParseFS(templateFiles, "templates/*.txt"))
+
+// TokenData is the data of the templates carrying a token.
+type TokenData struct {
+	Name      string
+	Token     string
+	ExpiresAt string
+}
+
+// BookAlertData is the data of the book alert templates.
+type BookAlertData struct {
+	Name  string
+	Title string
+	ISBN  string
+	Stock int64
+	Price string // Formatted with its currency, e.g. "12.99 USD"
+}
+
+// Render executes the named template and returns the message to send to to.
+func Render(name string, to string, data interface{}) (Message, error) {
+	var b bytes.Buffer
+	if err := templates.ExecuteTemplate(&b, name+".txt", data); err != nil {
+		return Message{}, fmt.Errorf("failed to render %s mail: %w", name, err)
+	}
+
+	header, body, _ := strings.Cut(b.String(), "\n")
+	if !strings.HasPrefix(header, "Subject:") {
+		return Message{}, fmt.Errorf("%s mail template must start with a Subject line", name)
+	}
+	body = strings.TrimPrefix(body, "\n")
+	return Message{
+		To:      to,
+		Subject: strings.TrimSpace(strings.TrimPrefix(header, "Subject:")),
+		Body:    body,
+	}, nil
+}
@@ -0,0 +1,143 @@
+package onix
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	author_db "github.com/mayureshucsb2019/bookstore/service/author/db"
+	book_db "github.com/mayureshucsb2019/bookstore/service/book/db"
+)
+
+// Report summarizes an import.
+type Report struct {
+	DryRun         bool
+	Products       int // Products in the message
+	Created        int // Books added
+	Updated        int // Books whose metadata or authors changed
+	Unchanged      int // Books already up to date
+	Skipped        int // Deletion notices, which are not applied
+	AuthorsCreated int
+	Errors         []ProductError
+
+	// Unmapped counts the elements no product field could be mapped from, by path within Product
+	Unmapped map[string]int
+}
+
+// ProductError says why a product was not imported.
+type ProductError struct {
+	Reference string
+	ISBN      string
+	Message   string
+}
+
+// Importer writes the products of ONIX messages to the catalog. Each product is saved in its own
+// transaction, so one bad record does not hold up the rest of a feed. Importing the same message
+// again changes nothing: books are matched by ISBN and authors by a stable ID, and books whose
+// data did not change are not written.
+type Importer struct {
+	Books *book_db.BookRepository
+}
+
+// NewImporter creates an importer writing to the book storage.
+func NewImporter(books *book_db.BookRepository) *Importer {
+	return &Importer{Books: books}
+}
+
+// Import reads an ONIX message and saves its products. With dryRun every product is saved and
+// rolled back, so the report tells what an import would do. An error is only returned when the
+// message cannot be read; the report then covers the products before the failure.
+func (i *Importer) Import(r io.Reader, dryRun bool) (*Report, error) {
+	report := &Report{DryRun: dryRun, Errors: []ProductError{}, Unmapped: map[string]int{}}
+	message, err := NewMessage(r)
+	if err != nil {
+		return report, err
+	}
+
+	for {
+		product, err := message.Next()
+		if errors.Is(err, io.EOF) {
+			return report, nil
+		}
+		if err != nil {
+			return report, err
+		}
+
+		report.Products++
+		for path, count := range product.Unmapped {
+			report.Unmapped[path] += count
+		}
+		switch {
+		case product.Invalid != nil:
+			report.addError(product, product.Invalid)
+		case product.Deletion:
+			report.Skipped++
+		default:
+			if err := i.save(product, dryRun, report); err != nil {
+				report.addError(product, err)
+			}
+		}
+	}
+}
+
+// save writes a product and its authors in one transaction and counts the outcome.
+func (i *Importer) save(product *Product, dryRun bool, report *Report) error {
+	tx, err := i.Books.DB.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	authorsCreated := 0
+	authorIDs := make([]string, len(product.Authors))
+	for j := range product.Authors {
+		created, err := author_db.EnsureAuthor(tx, &product.Authors[j])
+		if err != nil {
+			return err
+		}
+		if created {
+			authorsCreated++
+		}
+		authorIDs[j] = product.Authors[j].ID
+	}
+
+	book := product.Book
+	if !product.HasPrice {
+		// Keep the price of a known book rather than zeroing it
+		existing, err := book_db.GetBookForUpdate(tx, book.ISBN)
+		if err != nil {
+			return err
+		}
+		if existing != nil {
+			book.Cost = existing.Cost
+		}
+	}
+	created, changed, err := book_db.SaveBookMetadata(tx, &book)
+	if err != nil {
+		return err
+	}
+	linksChanged, err := author_db.SetBookAuthors(tx, book.ISBN, authorIDs)
+	if err != nil {
+		return err
+	}
+
+	if !dryRun {
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+	}
+	report.AuthorsCreated += authorsCreated
+	switch {
+	case created:
+		report.Created++
+	case changed || linksChanged:
+		report.Updated++
+	default:
+		report.Unchanged++
+	}
+	return nil
+}
+
+func (r *Report) addError(product *Product, err error) {
+	r.Errors = append(r.Errors, ProductError{Reference: product.Reference, ISBN: product.Book.ISBN, Message: err.Error()})
+}
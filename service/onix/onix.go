@@ -0,0 +1,169 @@
+// Package onix reads publisher metadata in ONIX for Books 3.0 and maps it onto the catalog.
+//
+// Only the reference tag form of ONIX 3.0 is supported. Products are decoded one at a time, so
+// feeds of any size can be streamed.
+package onix
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ErrUnsupportedFormat is returned for XML that is not an ONIX 3.0 message with reference tags.
+var ErrUnsupportedFormat = errors.New("not an ONIX 3.0 message with reference tags")
+
+// node is an XML element of a Product. Elements are marked as used when their value is mapped, so
+// what was left over can be reported.
+type node struct {
+	XMLName xml.Name
+	Attrs   []xml.Attr `xml:",any,attr"`
+	Text    string     `xml:",chardata"`
+	Nodes   []*node    `xml:",any"`
+
+	used bool
+}
+
+// children returns the child elements called name.
+func (n *node) children(name string) []*node {
+	if n == nil {
+		return nil
+	}
+	var children []*node
+	for _, child := range n.Nodes {
+		if child.XMLName.Local == name {
+			children = append(children, child)
+		}
+	}
+	return children
+}
+
+// child returns the first child element called name, or nil.
+func (n *node) child(name string) *node {
+	if children := n.children(name); len(children) > 0 {
+		return children[0]
+	}
+	return nil
+}
+
+// peek returns the text of the element at path without marking it as used.
+func (n *node) peek(path ...string) string {
+	for _, name := range path {
+		if n = n.child(name); n == nil {
+			return ""
+		}
+	}
+	return strings.TrimSpace(n.Text)
+}
+
+// take returns the text of the element at path and marks it as used.
+func (n *node) take(path ...string) string {
+	for _, name := range path {
+		if n = n.child(name); n == nil {
+			return ""
+		}
+	}
+	n.used = true
+	return strings.TrimSpace(n.Text)
+}
+
+// attr returns the value of an attribute.
+func (n *node) attr(name string) string {
+	return attrValue(n.Attrs, name)
+}
+
+// unused counts the leaf elements below n that were not mapped, by path.
+func (n *node) unused(prefix string, counts map[string]int) {
+	for _, child := range n.Nodes {
+		path := child.XMLName.Local
+		if prefix != "" {
+			path = prefix + "/" + path
+		}
+		if len(child.Nodes) == 0 {
+			if !child.used {
+				counts[path]++
+			}
+			continue
+		}
+		child.unused(path, counts)
+	}
+}
+
+// Message reads the products of an ONIX message one at a time.
+type Message struct {
+	decoder *xml.Decoder
+
+	// DefaultCurrency is the currency of prices without one, from the message header
+	DefaultCurrency string
+}
+
+// NewMessage starts reading an ONIX message. It fails unless the root element is ONIXMessage.
+func NewMessage(r io.Reader) (*Message, error) {
+	decoder := xml.NewDecoder(r)
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil, ErrUnsupportedFormat
+			}
+			return nil, fmt.Errorf("failed to read ONIX message: %w", err)
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			if start.Name.Local != "ONIXMessage" {
+				return nil, fmt.Errorf("%w: the root element is %s", ErrUnsupportedFormat, start.Name.Local)
+			}
+			if release := attrValue(start.Attr, "release"); release != "" && !strings.HasPrefix(release, "3.") {
+				return nil, fmt.Errorf("%w: the message is release %s", ErrUnsupportedFormat, release)
+			}
+			return &Message{decoder: decoder}, nil
+		}
+	}
+}
+
+// Next returns the next product of the message, or io.EOF after the last one. A product that
+// cannot be mapped onto the catalog is returned with Invalid set; errors are reserved for a
+// message that cannot be read any further.
+func (m *Message) Next() (*Product, error) {
+	for {
+		token, err := m.decoder.Token()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil, io.EOF
+			}
+			return nil, fmt.Errorf("failed to read ONIX message: %w", err)
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		switch start.Name.Local {
+		case "Header":
+			var header node
+			if err := m.decoder.DecodeElement(&header, &start); err != nil {
+				return nil, fmt.Errorf("failed to read ONIX header: %w", err)
+			}
+			m.DefaultCurrency = header.peek("DefaultCurrencyCode")
+		case "Product":
+			var product node
+			if err := m.decoder.DecodeElement(&product, &start); err != nil {
+				return nil, fmt.Errorf("failed to read ONIX product: %w", err)
+			}
+			return mapProduct(&product, m.DefaultCurrency), nil
+		default:
+			if err := m.decoder.Skip(); err != nil {
+				return nil, fmt.Errorf("failed to read ONIX message: %w", err)
+			}
+		}
+	}
+}
+
+func attrValue(attrs []xml.Attr, name string) string {
+	for _, attr := range attrs {
+		if attr.Name.Local == name {
+			return attr.Value
+		}
+	}
+	return ""
+}
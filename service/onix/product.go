@@ -0,0 +1,420 @@
+package onix
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	author_db "github.com/mayureshucsb2019/bookstore/service/author/db"
+	book_db "github.com/mayureshucsb2019/bookstore/service/book/db"
+	"github.com/mayureshucsb2019/bookstore/service/common"
+)
+
+// ONIX code list values the mapping relies on
+const (
+	notificationDelete = "05" // List 1: delete
+
+	productIDGTIN13 = "03" // List 5
+	productIDISBN13 = "15"
+
+	titleDistinctive  = "01" // List 15: distinctive title
+	titleLevelProduct = "01" // List 149: product level
+
+	roleByAuthor = "A01" // List 17: by (author)
+
+	nameIDProprietary = "01" // List 44
+	nameIDISNI        = "16"
+	nameIDORCID       = "21"
+
+	contributorBirthDate = "50" // List 177
+
+	extentMainContentPages = "00" // List 23
+	extentContentPages     = "11"
+	extentTotalNumbered    = "07"
+	extentUnitPages        = "03" // List 24
+
+	publisherRole       = "01" // List 45: publisher
+	publicationDateRole = "01" // List 163: publication date
+
+	subjectKeywords = "20" // List 26
+)
+
+// priceTypes are the price types (List 58) used as the cost, in order of preference:
+// RRP excluding tax, RRP including tax, then fixed retail prices.
+var priceTypes = []string{"01", "02", "03", "04"}
+
+// subjectSchemes names the subject schemes (List 26) whose codes become tags when a subject has no
+// heading text.
+var subjectSchemes = map[string]string{"10": "BISAC", "12": "BIC", "93": "Thema"}
+
+// Product is an ONIX product mapped onto the catalog.
+type Product struct {
+	Reference string // RecordReference, unique per product within the sender
+	Book      book_db.Book
+	Authors   []author_db.Author
+	Deletion  bool // The sender withdrew the record
+	HasPrice  bool // Book.Cost was given rather than defaulted to zero
+
+	// Invalid says why the product cannot be imported, if it cannot
+	Invalid error
+
+	// Unmapped counts the elements of the product the catalog has no place for, by path
+	Unmapped map[string]int
+}
+
+// mapProduct maps a Product element onto the catalog.
+func mapProduct(n *node, defaultCurrency string) *Product {
+	product := &Product{
+		Reference: n.take("RecordReference"),
+		Deletion:  n.take("NotificationType") == notificationDelete,
+	}
+	product.Book.ISBN = isbn(n)
+
+	descriptive := n.child("DescriptiveDetail")
+	publishing := n.child("PublishingDetail")
+	product.Book.Name = title(descriptive)
+	product.Authors = authors(descriptive)
+	names := make([]string, len(product.Authors))
+	for i, author := range product.Authors {
+		names[i] = displayName(author)
+	}
+	product.Book.AuthorName = strings.Join(names, ", ")
+	product.Book.Tags = subjects(descriptive)
+	product.Book.PublishingHouse = publisher(publishing)
+	product.Book.NumberOfPages = pages(descriptive)
+
+	var err error
+	if product.Book.DateOfPublish, err = publicationDate(publishing); err != nil {
+		product.Invalid = err
+	}
+	product.Book.Cost, product.HasPrice, err = price(n.child("ProductSupply"), defaultCurrency)
+	if err != nil && product.Invalid == nil {
+		product.Invalid = err
+	}
+
+	switch {
+	case product.Invalid != nil:
+	case product.Book.ISBN == "":
+		product.Invalid = errors.New("no ISBN-13 product identifier")
+	case product.Book.Name == "":
+		product.Invalid = errors.New("no distinctive title")
+	case len(product.Authors) == 0:
+		product.Invalid = fmt.Errorf("no contributor with role %s (by author)", roleByAuthor)
+	case product.Book.DateOfPublish == "":
+		product.Invalid = errors.New("no publication date")
+	}
+
+	product.Unmapped = map[string]int{}
+	n.unused("", product.Unmapped)
+	return product
+}
+
+// isbn returns the ISBN-13 of a product, from an ISBN-13 identifier or a GTIN-13 in the Bookland range.
+func isbn(n *node) string {
+	for _, wanted := range []string{productIDISBN13, productIDGTIN13} {
+		for _, identifier := range n.children("ProductIdentifier") {
+			if identifier.peek("ProductIDType") != wanted {
+				continue
+			}
+			value := strings.ReplaceAll(identifier.peek("IDValue"), "-", "")
+			if wanted == productIDGTIN13 && !strings.HasPrefix(value, "978") && !strings.HasPrefix(value, "979") {
+				continue
+			}
+			identifier.take("ProductIDType")
+			identifier.take("IDValue")
+			return value
+		}
+	}
+	return ""
+}
+
+// title returns the distinctive title of a product, with its subtitle.
+func title(descriptive *node) string {
+	for _, detail := range descriptive.children("TitleDetail") {
+		if detail.peek("TitleType") != titleDistinctive {
+			continue
+		}
+		detail.take("TitleType")
+		elements := detail.children("TitleElement")
+		if len(elements) == 0 {
+			return ""
+		}
+		element := elements[0]
+		for _, candidate := range elements {
+			if candidate.peek("TitleElementLevel") == titleLevelProduct {
+				element = candidate
+				break
+			}
+		}
+		element.take("TitleElementLevel")
+		text := element.take("TitleText")
+		if text == "" {
+			text = strings.TrimSpace(element.take("TitlePrefix") + " " + element.take("TitleWithoutPrefix"))
+		}
+		if subtitle := element.take("Subtitle"); subtitle != "" {
+			text += ": " + subtitle
+		}
+		return text
+	}
+	return ""
+}
+
+// authors returns the contributors of a product in the "by (author)" role, in sequence order.
+// Contributors in other roles are not mapped.
+func authors(descriptive *node) []author_db.Author {
+	contributors := descriptive.children("Contributor")
+	sort.SliceStable(contributors, func(i, j int) bool {
+		a, _ := strconv.Atoi(contributors[i].peek("SequenceNumber"))
+		b, _ := strconv.Atoi(contributors[j].peek("SequenceNumber"))
+		return a < b
+	})
+
+	var authors []author_db.Author
+	seen := map[string]bool{}
+	for _, contributor := range contributors {
+		isAuthor := false
+		for _, role := range contributor.children("ContributorRole") {
+			isAuthor = isAuthor || strings.TrimSpace(role.Text) == roleByAuthor
+		}
+		if !isAuthor {
+			continue
+		}
+		for _, role := range contributor.children("ContributorRole") {
+			role.used = true
+		}
+		contributor.take("SequenceNumber")
+
+		author := author_db.Author{
+			FirstName: contributor.take("NamesBeforeKey"),
+			LastName:  contributor.take("KeyNames"),
+			DOB:       sql.NullString{Valid: true},
+		}
+		if author.LastName != "" {
+			// The same name in other forms
+			contributor.take("PersonName")
+			contributor.take("PersonNameInverted")
+		} else if name := contributor.take("PersonName"); name != "" {
+			contributor.take("PersonNameInverted")
+			author.LastName = name
+			if i := strings.LastIndex(name, " "); i > 0 {
+				author.FirstName, author.LastName = name[:i], name[i+1:]
+			}
+		} else {
+			author.LastName = contributor.take("CorporateName")
+		}
+		if author.LastName == "" {
+			continue
+		}
+		for _, date := range contributor.children("ContributorDate") {
+			if date.peek("ContributorDateRole") == contributorBirthDate {
+				if dob, err := parseDate(date.child("Date")); err == nil {
+					date.take("ContributorDateRole")
+					date.take("Date")
+					author.DOB.String = dob
+				}
+			}
+		}
+		author.ID = authorID(contributor, author)
+		if seen[author.ID] {
+			continue
+		}
+		seen[author.ID] = true
+		authors = append(authors, author)
+	}
+	return authors
+}
+
+// authorID derives a stable author ID, so the same contributor maps to the same author on every
+// import: the ISNI or ORCID when the sender gives one, otherwise the name.
+func authorID(contributor *node, author author_db.Author) string {
+	for _, identifier := range contributor.children("NameIdentifier") {
+		value := strings.ReplaceAll(identifier.peek("IDValue"), " ", "")
+		if value == "" {
+			continue
+		}
+		switch identifier.peek("NameIDType") {
+		case nameIDISNI:
+			identifier.take("NameIDType")
+			identifier.take("IDValue")
+			return "isni-" + value
+		case nameIDORCID:
+			identifier.take("NameIDType")
+			identifier.take("IDValue")
+			return "orcid-" + value
+		case nameIDProprietary:
+			// Proprietary ids are only unique per sender, so they cannot identify an author
+		}
+	}
+	words := strings.FieldsFunc(strings.ToLower(displayName(author)), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	return "onix-" + strings.Join(words, "-")
+}
+
+// displayName returns the name an author is shown by.
+func displayName(author author_db.Author) string {
+	return strings.TrimSpace(author.FirstName + " " + author.LastName)
+}
+
+// subjects returns the subject headings of a product as tags, falling back to the scheme and code
+// for well-known schemes. Keyword subjects are split on semicolons.
+func subjects(descriptive *node) []string {
+	var tags []string
+	seen := map[string]bool{}
+	add := func(tag string) {
+		if tag = strings.TrimSpace(tag); tag != "" && !seen[tag] {
+			seen[tag] = true
+			tags = append(tags, tag)
+		}
+	}
+	for _, subject := range descriptive.children("Subject") {
+		scheme := subject.peek("SubjectSchemeIdentifier")
+		heading := subject.peek("SubjectHeadingText")
+		switch {
+		case scheme == subjectKeywords && heading != "":
+			for _, keyword := range strings.Split(heading, ";") {
+				add(keyword)
+			}
+		case heading != "":
+			add(heading)
+		case subjectSchemes[scheme] != "" && subject.peek("SubjectCode") != "":
+			add(subjectSchemes[scheme] + ":" + subject.take("SubjectCode"))
+		default:
+			continue
+		}
+		subject.take("SubjectSchemeIdentifier")
+		subject.take("SubjectHeadingText")
+		subject.take("MainSubject")
+	}
+	return tags
+}
+
+// publisher returns the name of the publisher, or of the imprint when no publisher is given.
+func publisher(publishing *node) string {
+	for _, p := range publishing.children("Publisher") {
+		if p.peek("PublishingRole") == publisherRole && p.peek("PublisherName") != "" {
+			p.take("PublishingRole")
+			return p.take("PublisherName")
+		}
+	}
+	for _, imprint := range publishing.children("Imprint") {
+		if name := imprint.take("ImprintName"); name != "" {
+			return name
+		}
+	}
+	return ""
+}
+
+// publicationDate returns the publication date as YYYY-MM-DD.
+func publicationDate(publishing *node) (string, error) {
+	for _, date := range publishing.children("PublishingDate") {
+		if date.peek("PublishingDateRole") != publicationDateRole {
+			continue
+		}
+		date.take("PublishingDateRole")
+		value, err := parseDate(date.child("Date"))
+		if err != nil {
+			return "", fmt.Errorf("publication date: %w", err)
+		}
+		date.take("Date")
+		return value, nil
+	}
+	return "", nil
+}
+
+// parseDate converts an ONIX date to YYYY-MM-DD. Dates given to the month or the year only are
+// taken as the first day of the period.
+func parseDate(date *node) (string, error) {
+	if date == nil {
+		return "", errors.New("no date")
+	}
+	value := strings.TrimSpace(date.Text)
+	layout := date.attr("dateformat")
+	switch {
+	case (layout == "" || layout == "00") && len(value) == 8:
+	case layout == "01" && len(value) == 6:
+		value += "01"
+	case layout == "05" && len(value) == 4:
+		value += "0101"
+	case layout == "14" && len(value) >= 8:
+		value = value[:8]
+	default:
+		return "", fmt.Errorf("unsupported date %q in format %q", value, layout)
+	}
+	if _, err := strconv.Atoi(value); err != nil {
+		return "", fmt.Errorf("invalid date %q", date.Text)
+	}
+	return value[:4] + "-" + value[4:6] + "-" + value[6:], nil
+}
+
+// pages returns the page count of a product, or 0 if it has none.
+func pages(descriptive *node) int {
+	for _, wanted := range []string{extentMainContentPages, extentContentPages, extentTotalNumbered} {
+		for _, extent := range descriptive.children("Extent") {
+			if extent.peek("ExtentType") != wanted || extent.peek("ExtentUnit") != extentUnitPages {
+				continue
+			}
+			count, err := strconv.Atoi(extent.peek("ExtentValue"))
+			if err != nil {
+				continue
+			}
+			extent.take("ExtentType")
+			extent.take("ExtentUnit")
+			extent.take("ExtentValue")
+			return count
+		}
+	}
+	return 0
+}
+
+// price returns the list price of a product, preferring prices in defaultCurrency or, without
+// one, the store's default currency. It reports whether the product has a price at all.
+func price(supply *node, defaultCurrency string) (common.Money, bool, error) {
+	preferred := defaultCurrency
+	if preferred == "" {
+		preferred = common.DefaultCurrency
+	}
+
+	var best *node
+	var bestCurrency string
+	bestRank := len(priceTypes) * 2
+	for _, detail := range supply.children("SupplyDetail") {
+		for _, p := range detail.children("Price") {
+			rank := -1
+			for i, priceType := range priceTypes {
+				if p.peek("PriceType") == priceType {
+					rank = i * 2
+				}
+			}
+			currency := p.peek("CurrencyCode")
+			if currency == "" {
+				currency = defaultCurrency
+			}
+			if rank < 0 || p.peek("PriceAmount") == "" || !common.IsValidCurrency(currency) {
+				continue
+			}
+			if currency != preferred {
+				rank++
+			}
+			if rank < bestRank {
+				best, bestCurrency, bestRank = p, currency, rank
+			}
+		}
+	}
+	if best == nil {
+		return common.NewMoney(0, preferred), false, nil
+	}
+
+	cost, err := common.ParseMoney(best.peek("PriceAmount"), bestCurrency)
+	if err != nil {
+		return common.Money{}, false, fmt.Errorf("price: %w", err)
+	}
+	best.take("PriceType")
+	best.take("PriceAmount")
+	best.take("CurrencyCode")
+	return cost, true, nil
+}
@@ -0,0 +1,549 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	_ "github.com/go-sql-driver/mysql"
+	"github.com/mayureshucsb2019/bookstore/service/common"
+	event_db "github.com/mayureshucsb2019/bookstore/service/event/db"
+)
+
+// ErrOrderNotFound is returned when no order exists with the requested id
+var ErrOrderNotFound = errors.New("order not found")
+
+// ErrCustomerBlocked is returned when a suspended or closed customer places an order
+var ErrCustomerBlocked = errors.New("customer cannot place orders")
+
+// ErrPromotionUsageExceeded is returned when an order uses a promotion the customer has already
+// used as often as it allows, e.g. in another checkout running at the same time
+var ErrPromotionUsageExceeded = errors.New("promotion usage limit reached")
+
+// Order represents the structure of an Orders record in the database.
+type Order struct {
+	ID            int64
+	CustomerEmail string
+	OrderDate     string
+	Subtotal      common.Money // Sum of the line totals
+	DiscountTotal common.Money // Sum of the promotion discounts
+	TaxTotal      common.Money // Sum of the per-line taxes
+	// ShippingAmount is the charge for ShippingMethod, zero when the order has no shipping method
+	ShippingAmount common.Money
+	TotalAmount    common.Money // Amount payable, the subtotal less discounts plus tax and shipping
+	// ExchangeRate is the rate applied when catalog prices in SourceCurrency were converted
+	// into the order currency. Both are empty when no conversion took place.
+	ExchangeRate   string
+	SourceCurrency string
+	ShippingMethod string
+	// ShippingAddress is copied from the customer's address book when the order is placed, so
+	// later address changes do not affect where the order is sent
+	ShippingAddress ShippingAddress
+	// ShippingAddressID and BillingAddressID are the address book entries the order was placed
+	// with, 0 when there was none or it has been deleted since
+	ShippingAddressID int64
+	BillingAddressID  int64
+	ShippingStatus    string
+	Status            string // Lifecycle status, see CanTransition
+	Items             []OrderItem
+	Discounts         []OrderDiscount
+	Shipments         []Shipment
+}
+
+// ShippingAddress is the snapshot of the customer address an order is shipped to.
+type ShippingAddress struct {
+	UnitNo     string
+	StreetName string
+	City       string
+	State      string
+	Country    string
+	Zipcode    string
+	Landmark   string
+}
+
+// OrderItem represents the structure of an OrderItems record in the database.
+type OrderItem struct {
+	OrderID   int64
+	ISBN      string
+	Quantity  int
+	UnitPrice common.Money
+	TaxRate   string       // Percentage applied to the discounted line amount, e.g. "7.2500"
+	Tax       common.Money // Tax charged on the line, zero when untaxed
+}
+
+// OrderDiscount represents the structure of an OrderDiscounts record, one entry of the
+// itemized discount breakdown of an order.
+type OrderDiscount struct {
+	OrderID       int64
+	PromotionCode string
+	Description   string
+	ISBN          string
+	Amount        common.Money
+}
+
+// LineTotal returns the unit price multiplied by the quantity.
+func (i OrderItem) LineTotal() common.Money {
+	return i.UnitPrice.Mul(int64(i.Quantity))
+}
+
+// ComputeTotal adds up the line totals of the items in the given currency.
+// Items priced in any other currency are rejected with common.ErrCurrencyMismatch.
+func ComputeTotal(currency string, items []OrderItem) (common.Money, error) {
+	lines := make([]common.Money, 0, len(items))
+	for _, item := range items {
+		lines = append(lines, item.LineTotal())
+	}
+	return common.SumMoney(currency, lines...)
+}
+
+// InsufficientStockError is returned when a book does not have enough copies to fill an order.
+type InsufficientStockError struct {
+	ISBN      string
+	Requested int
+	Available int
+}
+
+func (e *InsufficientStockError) Error() string {
+	return fmt.Sprintf("insufficient stock for book %s: requested %d, available %d", e.ISBN, e.Requested, e.Available)
+}
+
+// ComputeTotals sets the subtotal, discount total, tax total and payable total of the order from
+// its items, discounts and shipping charge, all of which must be in the order currency given by
+// TotalAmount.Currency.
+func (o *Order) ComputeTotals() error {
+	currency := o.TotalAmount.Currency
+	subtotal, err := ComputeTotal(currency, o.Items)
+	if err != nil {
+		return err
+	}
+
+	discounts := make([]common.Money, 0, len(o.Discounts))
+	for _, discount := range o.Discounts {
+		discounts = append(discounts, discount.Amount)
+	}
+	discountTotal, err := common.SumMoney(currency, discounts...)
+	if err != nil {
+		return err
+	}
+
+	taxes := make([]common.Money, 0, len(o.Items))
+	for _, item := range o.Items {
+		if item.Tax.Currency != "" {
+			taxes = append(taxes, item.Tax)
+		}
+	}
+	taxTotal, err := common.SumMoney(currency, taxes...)
+	if err != nil {
+		return err
+	}
+
+	total, err := subtotal.Sub(discountTotal)
+	if err != nil {
+		return err
+	}
+	if total.Amount < 0 {
+		return fmt.Errorf("discounts of %s exceed the order subtotal of %s", discountTotal, subtotal)
+	}
+	if total, err = total.Add(taxTotal); err != nil {
+		return err
+	}
+	if o.ShippingAmount.Currency == "" {
+		o.ShippingAmount = common.Money{Currency: currency}
+	}
+	if total, err = total.Add(o.ShippingAmount); err != nil {
+		return err
+	}
+
+	o.Subtotal, o.DiscountTotal, o.TaxTotal, o.TotalAmount = subtotal, discountTotal, taxTotal, total
+	return nil
+}
+
+// OrderRepository provides access to the Orders and OrderItems storage.
+type OrderRepository struct {
+	DB *sql.DB
+}
+
+// CreateOrder computes the order total, reserves stock for every item and inserts the order
+// with its items in a single transaction. Book rows are locked while stock is checked so
+// concurrent orders cannot oversell, and the customer row while the per-customer usage limits of
+// its promotions are checked so concurrent checkouts cannot both use the last one.
+func (r *OrderRepository) CreateOrder(order *Order) error {
+	if err := order.ComputeTotals(); err != nil {
+		return fmt.Errorf("failed to compute order total: %w", err)
+	}
+
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if order.ShippingStatus == "" {
+		order.ShippingStatus = ShippingPending
+	}
+	order.Status = StatusPlaced
+	customerID, err := lookupCustomerID(tx, order.CustomerEmail)
+	if err != nil {
+		return err
+	}
+	if err := checkPromotionUsage(tx, customerID, order.Discounts); err != nil {
+		return err
+	}
+	address := order.ShippingAddress
+	result, err := tx.Exec(
+		`INSERT INTO Orders (customer_id, subtotal_amount, discount_amount, tax_amount, shipping_amount, total_amount, currency,
+			exchange_rate, source_currency, shipping_method, shipping_address_id, billing_address_id, ship_unit_no,
+			ship_street_name, ship_city, ship_state, ship_country, ship_zipcode, ship_landmark, shipping_status, status)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		customerID, order.Subtotal.Decimal(), order.DiscountTotal.Decimal(), order.TaxTotal.Decimal(),
+		order.ShippingAmount.Decimal(), order.TotalAmount.Decimal(), order.TotalAmount.Currency,
+		common.NullStringOrNil(order.ExchangeRate), common.NullStringOrNil(order.SourceCurrency),
+		common.NullStringOrNil(order.ShippingMethod), nullID(order.ShippingAddressID), nullID(order.BillingAddressID),
+		common.NullStringOrNil(address.UnitNo),
+		common.NullStringOrNil(address.StreetName), common.NullStringOrNil(address.City), common.NullStringOrNil(address.State),
+		common.NullStringOrNil(address.Country), common.NullStringOrNil(address.Zipcode), common.NullStringOrNil(address.Landmark),
+		order.ShippingStatus, order.Status,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert order: %w", err)
+	}
+	if order.ID, err = result.LastInsertId(); err != nil {
+		return fmt.Errorf("failed to read order id: %w", err)
+	}
+	if err := recordStatusChange(tx, order.ID, "", StatusPlaced, "order placed"); err != nil {
+		return err
+	}
+
+	for i := range order.Items {
+		item := &order.Items[i]
+		if err := reserveStock(tx, item.ISBN, item.Quantity); err != nil {
+			return err
+		}
+
+		item.OrderID = order.ID
+		_, err := tx.Exec(
+			`INSERT INTO OrderItems (order_id, isbn, quantity, unit_price, currency, tax_rate, tax_amount) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+			item.OrderID, item.ISBN, item.Quantity, item.UnitPrice.Decimal(), item.UnitPrice.Currency,
+			common.NullStringOrNil(item.TaxRate), item.Tax.Decimal(),
+		)
+		if err != nil {
+			return fmt.Errorf("failed to insert order item %s: %w", item.ISBN, err)
+		}
+	}
+
+	for i := range order.Discounts {
+		discount := &order.Discounts[i]
+		discount.OrderID = order.ID
+		_, err := tx.Exec(
+			`INSERT INTO OrderDiscounts (order_id, promotion_code, description, isbn, amount, currency) VALUES (?, ?, ?, ?, ?, ?)`,
+			discount.OrderID, discount.PromotionCode, common.NullStringOrNil(discount.Description),
+			common.NullStringOrNil(discount.ISBN), discount.Amount.Decimal(), discount.Amount.Currency,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to insert order discount %s: %w", discount.PromotionCode, err)
+		}
+	}
+
+	if err := event_db.Record(tx, event_db.OrderPlaced, event_db.EntityOrder, strconv.FormatInt(order.ID, 10), orderPlacedPayload(order)); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// orderPlacedPayload is the representation of a new order carried by OrderPlaced events.
+func orderPlacedPayload(order *Order) map[string]interface{} {
+	items := make([]map[string]interface{}, 0, len(order.Items))
+	for _, item := range order.Items {
+		items = append(items, map[string]interface{}{
+			"isbn":       item.ISBN,
+			"quantity":   item.Quantity,
+			"unit_price": item.UnitPrice,
+		})
+	}
+	return map[string]interface{}{
+		"id":              order.ID,
+		"customer_email":  order.CustomerEmail,
+		"status":          order.Status,
+		"total_amount":    order.TotalAmount,
+		"shipping_method": order.ShippingMethod,
+		"items":           items,
+	}
+}
+
+// lookupCustomerID returns the id of the customer with an email, or NULL for orders without a
+// customer. Suspended and closed customers fail with ErrCustomerBlocked.
+func lookupCustomerID(tx *sql.Tx, email string) (sql.NullInt64, error) {
+	var id sql.NullInt64
+	if email == "" {
+		return id, nil
+	}
+	var status sql.NullString
+	err := tx.QueryRow(`SELECT id, status FROM Customer WHERE email = ? FOR UPDATE`, email).Scan(&id, &status)
+	if err == sql.ErrNoRows {
+		return id, fmt.Errorf("no customer with email %s", email)
+	}
+	if err != nil {
+		return id, fmt.Errorf("failed to look up customer: %w", err)
+	}
+	if status.String == "Suspended" || status.String == "Closed" {
+		return id, fmt.Errorf("%w: customer %s is %s", ErrCustomerBlocked, email, status.String)
+	}
+	return id, nil
+}
+
+// checkPromotionUsage fails with ErrPromotionUsageExceeded when the customer, whose row tx has
+// locked, already used a promotion of discounts as often as it allows. Cancelled and refunded
+// orders do not count.
+func checkPromotionUsage(tx *sql.Tx, customerID sql.NullInt64, discounts []OrderDiscount) error {
+	if !customerID.Valid || len(discounts) == 0 {
+		return nil
+	}
+	codes := map[string]bool{}
+	var args []interface{}
+	args = append(args, customerID.Int64)
+	for _, discount := range discounts {
+		if !codes[discount.PromotionCode] {
+			codes[discount.PromotionCode] = true
+			args = append(args, discount.PromotionCode)
+		}
+	}
+	rows, err := tx.Query(`
+		SELECT p.code, p.usage_limit_per_customer, COUNT(DISTINCT o.id)
+		FROM Promotions p
+		LEFT JOIN OrderDiscounts d ON d.promotion_code = p.code
+		LEFT JOIN Orders o ON o.id = d.order_id AND o.customer_id = ? AND o.status NOT IN ('cancelled', 'refunded')
+		WHERE p.usage_limit_per_customer > 0 AND p.code IN (?`+strings.Repeat(", ?", len(codes)-1)+`)
+		GROUP BY p.code, p.usage_limit_per_customer`, args...)
+	if err != nil {
+		return fmt.Errorf("failed to count promotion usage: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var code string
+		var limit, used int
+		if err := rows.Scan(&code, &limit, &used); err != nil {
+			return fmt.Errorf("failed to scan promotion usage: %w", err)
+		}
+		if used >= limit {
+			return fmt.Errorf("%w: %s", ErrPromotionUsageExceeded, code)
+		}
+	}
+	return rows.Err()
+}
+
+// reserveStock decrements the stock of a book, failing if fewer copies are available than requested.
+func reserveStock(tx *sql.Tx, isbn string, quantity int) error {
+	var available int
+	err := tx.QueryRow(`SELECT stock FROM Books WHERE isbn = ? FOR UPDATE`, isbn).Scan(&available)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return &InsufficientStockError{ISBN: isbn, Requested: quantity}
+		}
+		return fmt.Errorf("failed to read stock for book %s: %w", isbn, err)
+	}
+	if available < quantity {
+		return &InsufficientStockError{ISBN: isbn, Requested: quantity, Available: available}
+	}
+
+	if _, err := tx.Exec(`UPDATE Books SET stock = stock - ? WHERE isbn = ?`, quantity, isbn); err != nil {
+		return fmt.Errorf("failed to reserve stock for book %s: %w", isbn, err)
+	}
+	return event_db.RecordStockChange(tx, isbn, available-quantity, -quantity, "order placed")
+}
+
+// GetOrderByID retrieves an order and its items by the order id.
+func (r *OrderRepository) GetOrderByID(id int64) (*Order, error) {
+	query := `SELECT ` + orderColumns + ` FROM Orders WHERE id = ?`
+
+	order, err := scanOrder(r.DB.QueryRow(query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("%w: %d", ErrOrderNotFound, id)
+		}
+		return nil, fmt.Errorf("failed to get order by id: %w", err)
+	}
+
+	if err := r.loadOrderLines(order); err != nil {
+		return nil, err
+	}
+
+	return order, nil
+}
+
+// GetOrdersByCustomer retrieves all orders placed by the customer, newest first.
+func (r *OrderRepository) GetOrdersByCustomer(email string) ([]Order, error) {
+	query := `SELECT ` + orderColumns + ` FROM Orders WHERE customer_id = (SELECT id FROM Customer WHERE email = ?)
+		ORDER BY order_date DESC`
+
+	rows, err := r.DB.Query(query, email)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query orders: %w", err)
+	}
+	defer rows.Close()
+
+	var orders []Order
+	for rows.Next() {
+		order, err := scanOrder(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan order: %w", err)
+		}
+		orders = append(orders, *order)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+
+	for i := range orders {
+		if err := r.loadOrderLines(&orders[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	return orders, nil
+}
+
+// loadOrderLines loads the items, discounts and shipments belonging to an order.
+func (r *OrderRepository) loadOrderLines(order *Order) error {
+	var err error
+	if order.Items, err = r.getOrderItems(order.ID); err != nil {
+		return err
+	}
+	if order.Discounts, err = r.getOrderDiscounts(order.ID); err != nil {
+		return err
+	}
+	order.Shipments, err = r.getShipments(order.ID)
+	return err
+}
+
+// getOrderDiscounts retrieves the itemized discounts applied to an order.
+func (r *OrderRepository) getOrderDiscounts(orderID int64) ([]OrderDiscount, error) {
+	query := `SELECT order_id, promotion_code, description, isbn, amount, currency FROM OrderDiscounts WHERE order_id = ? ORDER BY id`
+
+	rows, err := r.DB.Query(query, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query order discounts: %w", err)
+	}
+	defer rows.Close()
+
+	var discounts []OrderDiscount
+	for rows.Next() {
+		var discount OrderDiscount
+		var description, isbn sql.NullString
+		var amount, currency string
+		if err := rows.Scan(&discount.OrderID, &discount.PromotionCode, &description, &isbn, &amount, &currency); err != nil {
+			return nil, fmt.Errorf("failed to scan order discount: %w", err)
+		}
+		discount.Description = common.StringOrEmpty(description)
+		discount.ISBN = common.StringOrEmpty(isbn)
+		if discount.Amount, err = common.ParseMoney(amount, currency); err != nil {
+			return nil, fmt.Errorf("failed to parse discount amount: %w", err)
+		}
+		discounts = append(discounts, discount)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+
+	return discounts, nil
+}
+
+// getOrderItems retrieves the items belonging to an order.
+func (r *OrderRepository) getOrderItems(orderID int64) ([]OrderItem, error) {
+	query := `SELECT order_id, isbn, quantity, unit_price, currency, tax_rate, tax_amount FROM OrderItems WHERE order_id = ?`
+
+	rows, err := r.DB.Query(query, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query order items: %w", err)
+	}
+	defer rows.Close()
+
+	var items []OrderItem
+	for rows.Next() {
+		var item OrderItem
+		var unitPrice, currency, tax string
+		var taxRate sql.NullString
+		if err := rows.Scan(&item.OrderID, &item.ISBN, &item.Quantity, &unitPrice, &currency, &taxRate, &tax); err != nil {
+			return nil, fmt.Errorf("failed to scan order item: %w", err)
+		}
+		item.TaxRate = common.StringOrEmpty(taxRate)
+		if item.UnitPrice, err = common.ParseMoney(unitPrice, currency); err != nil {
+			return nil, fmt.Errorf("failed to parse unit price: %w", err)
+		}
+		if item.Tax, err = common.ParseMoney(tax, currency); err != nil {
+			return nil, fmt.Errorf("failed to parse line tax: %w", err)
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+
+	return items, nil
+}
+
+// rowScanner is implemented by both *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// orderColumns are the Orders columns read by scanOrder. Orders refer to their customer by id, so
+// the current email of the customer is read in its place.
+const orderColumns = `id, (SELECT email FROM Customer WHERE Customer.id = Orders.customer_id), order_date, subtotal_amount, discount_amount, tax_amount, shipping_amount, total_amount,
+	currency, exchange_rate, source_currency, shipping_method, shipping_address_id, billing_address_id, ship_unit_no,
+	ship_street_name, ship_city, ship_state, ship_country, ship_zipcode, ship_landmark, shipping_status, status`
+
+// scanOrder scans the orderColumns selected by the repository queries.
+func scanOrder(row rowScanner) (*Order, error) {
+	var order Order
+	var customerEmail sql.NullString
+	var subtotal, discountTotal, taxTotal, shippingAmount, totalAmount, currency string
+	var exchangeRate, sourceCurrency, shippingMethod sql.NullString
+	var shippingAddressID, billingAddressID sql.NullInt64
+	var unitNo, streetName, city, state, country, zipcode, landmark sql.NullString
+
+	err := row.Scan(&order.ID, &customerEmail, &order.OrderDate, &subtotal, &discountTotal, &taxTotal, &shippingAmount,
+		&totalAmount, &currency, &exchangeRate, &sourceCurrency, &shippingMethod, &shippingAddressID, &billingAddressID,
+		&unitNo, &streetName, &city, &state, &country, &zipcode, &landmark, &order.ShippingStatus, &order.Status)
+	if err != nil {
+		return nil, err
+	}
+	order.CustomerEmail = common.StringOrEmpty(customerEmail)
+	order.ExchangeRate = common.StringOrEmpty(exchangeRate)
+	order.SourceCurrency = common.StringOrEmpty(sourceCurrency)
+	order.ShippingMethod = common.StringOrEmpty(shippingMethod)
+	order.ShippingAddressID, order.BillingAddressID = shippingAddressID.Int64, billingAddressID.Int64
+	order.ShippingAddress = ShippingAddress{
+		UnitNo:     common.StringOrEmpty(unitNo),
+		StreetName: common.StringOrEmpty(streetName),
+		City:       common.StringOrEmpty(city),
+		State:      common.StringOrEmpty(state),
+		Country:    common.StringOrEmpty(country),
+		Zipcode:    common.StringOrEmpty(zipcode),
+		Landmark:   common.StringOrEmpty(landmark),
+	}
+
+	if order.Subtotal, err = common.ParseMoney(subtotal, currency); err != nil {
+		return nil, fmt.Errorf("failed to parse subtotal: %w", err)
+	}
+	if order.DiscountTotal, err = common.ParseMoney(discountTotal, currency); err != nil {
+		return nil, fmt.Errorf("failed to parse discount amount: %w", err)
+	}
+	if order.TaxTotal, err = common.ParseMoney(taxTotal, currency); err != nil {
+		return nil, fmt.Errorf("failed to parse tax amount: %w", err)
+	}
+	if order.ShippingAmount, err = common.ParseMoney(shippingAmount, currency); err != nil {
+		return nil, fmt.Errorf("failed to parse shipping amount: %w", err)
+	}
+	if order.TotalAmount, err = common.ParseMoney(totalAmount, currency); err != nil {
+		return nil, fmt.Errorf("failed to parse total amount: %w", err)
+	}
+
+	return &order, nil
+}
+
+// nullID stores an id of 0 as NULL.
+func nullID(id int64) sql.NullInt64 {
+	return sql.NullInt64{Int64: id, Valid: id != 0}
+}
@@ -0,0 +1,19 @@
+package db
+
+import (
+	"sync"
+
+	"github.com/mayureshucsb2019/bookstore/service/common"
+)
+
+var orderRepoInstance *OrderRepository
+var orderRepoOnce sync.Once
+
+func NewOrderRepository(db *common.DBConnection) *OrderRepository {
+	orderRepoOnce.Do(func() {
+		orderRepoInstance = &OrderRepository{
+			DB: db.DB,
+		}
+	})
+	return orderRepoInstance
+}
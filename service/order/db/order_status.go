@@ -0,0 +1,245 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+
+	event_db "github.com/mayureshucsb2019/bookstore/service/event/db"
+)
+
+// Order statuses. Orders start as placed and move through paid, shipped and delivered;
+// cancelled, return_requested, returned and refunded branch off that path.
+const (
+	StatusPlaced          = "placed"
+	StatusPaid            = "paid"
+	StatusShipped         = "shipped"
+	StatusDelivered       = "delivered"
+	StatusCancelled       = "cancelled"
+	StatusReturnRequested = "return_requested"
+	StatusReturned        = "returned"
+	StatusRefunded        = "refunded"
+)
+
+// ErrInvalidTransition is returned when an order cannot move from its current status to the requested one
+var ErrInvalidTransition = errors.New("invalid order status transition")
+
+// orderTransitions lists the statuses each status can move to.
+var orderTransitions = map[string][]string{
+	StatusPlaced:          {StatusPaid, StatusCancelled},
+	StatusPaid:            {StatusShipped, StatusCancelled},
+	StatusShipped:         {StatusDelivered},
+	StatusDelivered:       {StatusReturnRequested},
+	StatusReturnRequested: {StatusReturned, StatusDelivered}, // back to delivered when the return is rejected
+	StatusReturned:        {StatusRefunded},
+	StatusCancelled:       {StatusRefunded}, // only orders that were paid before being cancelled
+}
+
+// CanTransition reports whether an order may move from one status to another.
+func CanTransition(from, to string) bool {
+	for _, next := range orderTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// StatusChange represents the structure of an OrderStatusHistory record.
+type StatusChange struct {
+	OrderID    int64
+	FromStatus string // Empty for the initial placed status
+	ToStatus   string
+	Reason     string
+	ChangedAt  string
+}
+
+// TransitionOrder moves an order to a new status and records the change in its history.
+// Stock is put back when an order is cancelled or returned.
+func (r *OrderRepository) TransitionOrder(orderID int64, to string, reason string) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := transitionOrder(tx, orderID, to, reason); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// TransitionOrderWithRefund moves an order to cancelled or refunded like TransitionOrder, and
+// calls refund when money goes back: on refunding, and on cancelling an order that was paid,
+// which then moves on to refunded in the same transaction. refund runs while the order row is
+// locked, before the status changes commit, so a failed refund leaves the order as it was and
+// a concurrent change cannot slip in between. Its error is returned as it is.
+func (r *OrderRepository) TransitionOrderWithRefund(orderID int64, to string, reason string, refund func() error) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var from string
+	err = tx.QueryRow(`SELECT status FROM Orders WHERE id = ? FOR UPDATE`, orderID).Scan(&from)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("%w: %d", ErrOrderNotFound, orderID)
+		}
+		return fmt.Errorf("failed to read order status: %w", err)
+	}
+	if err := transitionOrder(tx, orderID, to, reason); err != nil {
+		return err
+	}
+	if to == StatusCancelled && from == StatusPaid {
+		if err := transitionOrder(tx, orderID, StatusRefunded, reason); err != nil {
+			return err
+		}
+		to = StatusRefunded
+	}
+	if to == StatusRefunded {
+		if err := refund(); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// transitionOrder applies a status change inside tx, locking the order row while it is checked.
+func transitionOrder(tx *sql.Tx, orderID int64, to string, reason string) error {
+	var from string
+	err := tx.QueryRow(`SELECT status FROM Orders WHERE id = ? FOR UPDATE`, orderID).Scan(&from)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("%w: %d", ErrOrderNotFound, orderID)
+		}
+		return fmt.Errorf("failed to read order status: %w", err)
+	}
+	if !CanTransition(from, to) {
+		return fmt.Errorf("%w: order %d is %s and cannot become %s", ErrInvalidTransition, orderID, from, to)
+	}
+	if from == StatusCancelled && to == StatusRefunded {
+		var paid int
+		err := tx.QueryRow(`SELECT COUNT(*) FROM OrderStatusHistory WHERE order_id = ? AND to_status = ?`, orderID, StatusPaid).Scan(&paid)
+		if err != nil {
+			return fmt.Errorf("failed to read order history: %w", err)
+		}
+		if paid == 0 {
+			return fmt.Errorf("%w: order %d was cancelled before it was paid", ErrInvalidTransition, orderID)
+		}
+	}
+
+	if _, err := tx.Exec(`UPDATE Orders SET status = ? WHERE id = ?`, to, orderID); err != nil {
+		return fmt.Errorf("failed to update order status: %w", err)
+	}
+	if err := recordStatusChange(tx, orderID, from, to, reason); err != nil {
+		return err
+	}
+	payload := map[string]interface{}{"id": orderID, "from_status": from, "to_status": to, "reason": reason}
+	if err := event_db.Record(tx, event_db.OrderStatusChanged, event_db.EntityOrder, strconv.FormatInt(orderID, 10), payload); err != nil {
+		return err
+	}
+	if to == StatusCancelled || to == StatusReturned {
+		if err := restock(tx, orderID, "order "+to); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func recordStatusChange(tx *sql.Tx, orderID int64, from string, to string, reason string) error {
+	var fromStatus interface{}
+	if from != "" {
+		fromStatus = from
+	}
+	_, err := tx.Exec(
+		`INSERT INTO OrderStatusHistory (order_id, from_status, to_status, reason) VALUES (?, ?, ?, ?)`,
+		orderID, fromStatus, to, reason,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record order status change: %w", err)
+	}
+	return nil
+}
+
+// restock returns the copies of every item of the order to the stock of its book.
+func restock(tx *sql.Tx, orderID int64, reason string) error {
+	_, err := tx.Exec(
+		`UPDATE Books b JOIN OrderItems i ON i.isbn = b.isbn SET b.stock = b.stock + i.quantity WHERE i.order_id = ?`,
+		orderID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to restock order %d: %w", orderID, err)
+	}
+
+	rows, err := tx.Query(`SELECT b.isbn, b.stock, i.quantity FROM Books b JOIN OrderItems i ON i.isbn = b.isbn WHERE i.order_id = ?`, orderID)
+	if err != nil {
+		return fmt.Errorf("failed to read restocked books of order %d: %w", orderID, err)
+	}
+	var changes []event_db.StockChanged
+	for rows.Next() {
+		var change event_db.StockChanged
+		if err := rows.Scan(&change.ISBN, &change.Stock, &change.Change); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan restocked book: %w", err)
+		}
+		changes = append(changes, change)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating over rows: %w", err)
+	}
+
+	for _, change := range changes {
+		if err := event_db.RecordStockChange(tx, change.ISBN, change.Stock, change.Change, reason); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetStatusHistory retrieves the status changes of an order, oldest first.
+func (r *OrderRepository) GetStatusHistory(orderID int64) ([]StatusChange, error) {
+	if _, err := r.GetOrderStatus(orderID); err != nil {
+		return nil, err
+	}
+
+	query := `SELECT order_id, from_status, to_status, reason, changed_at FROM OrderStatusHistory WHERE order_id = ? ORDER BY id`
+	rows, err := r.DB.Query(query, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query order history: %w", err)
+	}
+	defer rows.Close()
+
+	var history []StatusChange
+	for rows.Next() {
+		var change StatusChange
+		var from, reason sql.NullString
+		if err := rows.Scan(&change.OrderID, &from, &change.ToStatus, &reason, &change.ChangedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan order status change: %w", err)
+		}
+		change.FromStatus = from.String
+		change.Reason = reason.String
+		history = append(history, change)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+
+	return history, nil
+}
+
+// GetOrderStatus returns the current status of an order.
+func (r *OrderRepository) GetOrderStatus(orderID int64) (string, error) {
+	var status string
+	err := r.DB.QueryRow(`SELECT status FROM Orders WHERE id = ?`, orderID).Scan(&status)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", fmt.Errorf("%w: %d", ErrOrderNotFound, orderID)
+		}
+		return "", fmt.Errorf("failed to read order status: %w", err)
+	}
+	return status, nil
+}
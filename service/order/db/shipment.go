@@ -0,0 +1,116 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// Shipping statuses an order moves through, in order. They follow the order status as it
+// moves from paid to shipped and delivered.
+const (
+	ShippingPending   = "pending"
+	ShippingShipped   = "shipped"
+	ShippingDelivered = "delivered"
+)
+
+// Shipment represents the structure of a Shipments record, a parcel handed to a carrier.
+type Shipment struct {
+	ID             int64
+	OrderID        int64
+	Carrier        string
+	TrackingNumber string
+	ShippedAt      string
+	DeliveredAt    string // Empty until the shipment is delivered
+}
+
+// ShipOrder records the shipment of a paid order and moves it to shipped.
+func (r *OrderRepository) ShipOrder(orderID int64, carrier string, trackingNumber string) (*Shipment, error) {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := transitionOrder(tx, orderID, StatusShipped, fmt.Sprintf("shipped with %s, tracking number %s", carrier, trackingNumber)); err != nil {
+		return nil, err
+	}
+
+	result, err := tx.Exec(`INSERT INTO Shipments (order_id, carrier, tracking_number) VALUES (?, ?, ?)`, orderID, carrier, trackingNumber)
+	if err != nil {
+		return nil, fmt.Errorf("failed to insert shipment: %w", err)
+	}
+	shipmentID, err := result.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read shipment id: %w", err)
+	}
+	if _, err := tx.Exec(`UPDATE Orders SET shipping_status = ? WHERE id = ?`, ShippingShipped, orderID); err != nil {
+		return nil, fmt.Errorf("failed to update shipping status: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	shipments, err := r.getShipments(orderID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range shipments {
+		if shipments[i].ID == shipmentID {
+			return &shipments[i], nil
+		}
+	}
+	return nil, fmt.Errorf("shipment %d not found after insert", shipmentID)
+}
+
+// DeliverOrder marks the shipments of a shipped order as delivered and moves it to delivered.
+// It also moves an order back to delivered when its return request is rejected.
+func (r *OrderRepository) DeliverOrder(orderID int64, reason string) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if reason == "" {
+		reason = "delivered"
+	}
+	if err := transitionOrder(tx, orderID, StatusDelivered, reason); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`UPDATE Shipments SET delivered_at = CURRENT_TIMESTAMP WHERE order_id = ? AND delivered_at IS NULL`, orderID); err != nil {
+		return fmt.Errorf("failed to update shipments: %w", err)
+	}
+	if _, err := tx.Exec(`UPDATE Orders SET shipping_status = ? WHERE id = ?`, ShippingDelivered, orderID); err != nil {
+		return fmt.Errorf("failed to update shipping status: %w", err)
+	}
+	return tx.Commit()
+}
+
+// getShipments retrieves the shipments of an order, oldest first.
+func (r *OrderRepository) getShipments(orderID int64) ([]Shipment, error) {
+	query := `SELECT id, order_id, carrier, tracking_number, shipped_at, delivered_at FROM Shipments WHERE order_id = ? ORDER BY id`
+
+	rows, err := r.DB.Query(query, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query shipments: %w", err)
+	}
+	defer rows.Close()
+
+	var shipments []Shipment
+	for rows.Next() {
+		var shipment Shipment
+		var deliveredAt sql.NullString
+		if err := rows.Scan(&shipment.ID, &shipment.OrderID, &shipment.Carrier, &shipment.TrackingNumber, &shipment.ShippedAt, &deliveredAt); err != nil {
+			return nil, fmt.Errorf("failed to scan shipment: %w", err)
+		}
+		if deliveredAt.Valid {
+			shipment.DeliveredAt = deliveredAt.String
+		}
+		shipments = append(shipments, shipment)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+
+	return shipments, nil
+}
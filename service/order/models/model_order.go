@@ -0,0 +1,78 @@
+package models
+
+import "github.com/mayureshucsb2019/bookstore/service/common"
+
+type Order struct {
+	Id int64 `json:"id"`
+
+	CustomerEmail string `json:"customer_email"`
+
+	OrderDate string `json:"order_date"`
+
+	// One of placed, paid, shipped, delivered, cancelled, return_requested, returned or refunded.
+	Status string `json:"status"`
+
+	Subtotal common.Money `json:"subtotal"`
+
+	DiscountTotal common.Money `json:"discount_total"`
+
+	TaxTotal common.Money `json:"tax_total"`
+
+	ShippingAmount common.Money `json:"shipping_amount"`
+
+	TotalAmount common.Money `json:"total_amount"`
+
+	// Rate applied to catalog prices in source_currency when the order was priced in another currency.
+	ExchangeRate string `json:"exchange_rate,omitempty"`
+
+	SourceCurrency string `json:"source_currency,omitempty"`
+
+	Items []OrderItem `json:"items"`
+
+	Discounts []OrderDiscount `json:"discounts"`
+
+	ShippingMethod string `json:"shipping_method,omitempty"`
+
+	// Customer address at the time the order was placed.
+	ShippingAddress ShippingAddress `json:"shipping_address"`
+
+	// Address book entries the order was placed with; omitted once deleted.
+	ShippingAddressId int64 `json:"shipping_address_id,omitempty"`
+
+	BillingAddressId int64 `json:"billing_address_id,omitempty"`
+
+	// One of pending, shipped or delivered.
+	ShippingStatus string `json:"shipping_status"`
+
+	Shipments []Shipment `json:"shipments"`
+}
+
+// AssertOrderRequired checks if the required fields are not zero-ed
+func AssertOrderRequired(obj Order) error {
+	elements := map[string]interface{}{
+		"customer_email": obj.CustomerEmail,
+		"items":          obj.Items,
+	}
+	for name, el := range elements {
+		if isZero := common.IsZeroValue(el); isZero {
+			return &common.RequiredError{Field: name}
+		}
+	}
+
+	for _, el := range obj.Items {
+		if err := AssertOrderItemRequired(el); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AssertOrderConstraints checks if the values respects the defined constraints
+func AssertOrderConstraints(obj Order) error {
+	for _, el := range obj.Items {
+		if err := AssertOrderItemConstraints(el); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,25 @@
+package models
+
+import "github.com/mayureshucsb2019/bookstore/service/common"
+
+type OrderDiscount struct {
+	// Promotion code that granted the discount.
+	Code string `json:"code"`
+
+	Description string `json:"description,omitempty"`
+
+	// Book the discount was applied to.
+	Isbn string `json:"isbn"`
+
+	Amount common.Money `json:"amount"`
+}
+
+// AssertOrderDiscountRequired checks if the required fields are not zero-ed
+func AssertOrderDiscountRequired(obj OrderDiscount) error {
+	return nil
+}
+
+// AssertOrderDiscountConstraints checks if the values respects the defined constraints
+func AssertOrderDiscountConstraints(obj OrderDiscount) error {
+	return nil
+}
@@ -0,0 +1,45 @@
+package models
+
+import (
+	"errors"
+
+	"github.com/mayureshucsb2019/bookstore/service/common"
+)
+
+type OrderItem struct {
+	Isbn string `json:"isbn"`
+
+	Quantity int32 `json:"quantity"`
+
+	UnitPrice common.Money `json:"unit_price"`
+
+	LineTotal common.Money `json:"line_total"`
+
+	// Percentage of tax charged on the line after discounts.
+	TaxRate string `json:"tax_rate,omitempty"`
+
+	Tax common.Money `json:"tax"`
+}
+
+// AssertOrderItemRequired checks if the required fields are not zero-ed
+func AssertOrderItemRequired(obj OrderItem) error {
+	elements := map[string]interface{}{
+		"isbn":     obj.Isbn,
+		"quantity": obj.Quantity,
+	}
+	for name, el := range elements {
+		if isZero := common.IsZeroValue(el); isZero {
+			return &common.RequiredError{Field: name}
+		}
+	}
+
+	return nil
+}
+
+// AssertOrderItemConstraints checks if the values respects the defined constraints
+func AssertOrderItemConstraints(obj OrderItem) error {
+	if obj.Quantity < 1 {
+		return &common.ParsingError{Param: "quantity", Err: errors.New("quantity must be at least 1")}
+	}
+	return nil
+}
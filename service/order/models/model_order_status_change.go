@@ -0,0 +1,22 @@
+package models
+
+type OrderStatusChange struct {
+	// Empty for the initial placed status.
+	FromStatus string `json:"from_status,omitempty"`
+
+	ToStatus string `json:"to_status"`
+
+	Reason string `json:"reason,omitempty"`
+
+	ChangedAt string `json:"changed_at"`
+}
+
+// AssertOrderStatusChangeRequired checks if the required fields are not zero-ed
+func AssertOrderStatusChangeRequired(obj OrderStatusChange) error {
+	return nil
+}
+
+// AssertOrderStatusChangeConstraints checks if the values respects the defined constraints
+func AssertOrderStatusChangeConstraints(obj OrderStatusChange) error {
+	return nil
+}
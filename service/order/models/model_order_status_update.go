@@ -0,0 +1,41 @@
+package models
+
+import (
+	"errors"
+
+	"github.com/mayureshucsb2019/bookstore/service/common"
+)
+
+type OrderStatusUpdate struct {
+	// One of paid, cancelled, return_requested, returned, refunded or delivered. Orders are
+	// shipped through the shipping status so the carrier and tracking number are recorded.
+	Status string `json:"status"`
+
+	// Why the order changed status, kept in its history.
+	Reason string `json:"reason,omitempty"`
+}
+
+// AssertOrderStatusUpdateRequired checks if the required fields are not zero-ed
+func AssertOrderStatusUpdateRequired(obj OrderStatusUpdate) error {
+	elements := map[string]interface{}{
+		"status": obj.Status,
+	}
+	for name, el := range elements {
+		if isZero := common.IsZeroValue(el); isZero {
+			return &common.RequiredError{Field: name}
+		}
+	}
+
+	return nil
+}
+
+// AssertOrderStatusUpdateConstraints checks if the values respects the defined constraints
+func AssertOrderStatusUpdateConstraints(obj OrderStatusUpdate) error {
+	switch obj.Status {
+	case "paid", "cancelled", "return_requested", "returned", "refunded", "delivered":
+		return nil
+	case "shipped":
+		return &common.ParsingError{Param: "status", Err: errors.New("orders are shipped through the shipping status with a carrier and tracking number")}
+	}
+	return &common.ParsingError{Param: "status", Err: errors.New("must be one of paid, cancelled, return_requested, returned, refunded, delivered")}
+}
@@ -0,0 +1,35 @@
+package models
+
+import (
+	"github.com/mayureshucsb2019/bookstore/service/common"
+)
+
+type PaymentAttempt struct {
+	Provider string `json:"provider"`
+
+	// One of authorize, capture, void or refund.
+	Operation string `json:"operation"`
+
+	IdempotencyKey string `json:"idempotency_key"`
+
+	TransactionId string `json:"transaction_id,omitempty"`
+
+	Amount common.Money `json:"amount"`
+
+	// One of succeeded, declined or failed.
+	Status string `json:"status"`
+
+	DeclineReason string `json:"decline_reason,omitempty"`
+
+	CreatedAt string `json:"created_at"`
+}
+
+// AssertPaymentAttemptRequired checks if the required fields are not zero-ed
+func AssertPaymentAttemptRequired(obj PaymentAttempt) error {
+	return nil
+}
+
+// AssertPaymentAttemptConstraints checks if the values respects the defined constraints
+func AssertPaymentAttemptConstraints(obj PaymentAttempt) error {
+	return nil
+}
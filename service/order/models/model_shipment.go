@@ -0,0 +1,23 @@
+package models
+
+type Shipment struct {
+	Id int64 `json:"id"`
+
+	Carrier string `json:"carrier"`
+
+	TrackingNumber string `json:"tracking_number"`
+
+	ShippedAt string `json:"shipped_at"`
+
+	DeliveredAt string `json:"delivered_at,omitempty"`
+}
+
+// AssertShipmentRequired checks if the required fields are not zero-ed
+func AssertShipmentRequired(obj Shipment) error {
+	return nil
+}
+
+// AssertShipmentConstraints checks if the values respects the defined constraints
+func AssertShipmentConstraints(obj Shipment) error {
+	return nil
+}
@@ -0,0 +1,27 @@
+package models
+
+type ShippingAddress struct {
+	UnitNo string `json:"unit_no,omitempty"`
+
+	StreetName string `json:"street_name,omitempty"`
+
+	City string `json:"city,omitempty"`
+
+	State string `json:"state,omitempty"`
+
+	Country string `json:"country,omitempty"`
+
+	Zipcode string `json:"zipcode,omitempty"`
+
+	Landmark string `json:"landmark,omitempty"`
+}
+
+// AssertShippingAddressRequired checks if the required fields are not zero-ed
+func AssertShippingAddressRequired(obj ShippingAddress) error {
+	return nil
+}
+
+// AssertShippingAddressConstraints checks if the values respects the defined constraints
+func AssertShippingAddressConstraints(obj ShippingAddress) error {
+	return nil
+}
@@ -0,0 +1,48 @@
+package models
+
+import (
+	"errors"
+
+	"github.com/mayureshucsb2019/bookstore/service/common"
+)
+
+type ShippingStatusUpdate struct {
+	// Either shipped or delivered.
+	Status string `json:"status"`
+
+	// Required when the order is shipped.
+	Carrier string `json:"carrier,omitempty"`
+
+	// Required when the order is shipped.
+	TrackingNumber string `json:"tracking_number,omitempty"`
+}
+
+// AssertShippingStatusUpdateRequired checks if the required fields are not zero-ed
+func AssertShippingStatusUpdateRequired(obj ShippingStatusUpdate) error {
+	elements := map[string]interface{}{
+		"status": obj.Status,
+	}
+	for name, el := range elements {
+		if isZero := common.IsZeroValue(el); isZero {
+			return &common.RequiredError{Field: name}
+		}
+	}
+
+	if obj.Status == "shipped" {
+		if obj.Carrier == "" {
+			return &common.RequiredError{Field: "carrier"}
+		}
+		if obj.TrackingNumber == "" {
+			return &common.RequiredError{Field: "tracking_number"}
+		}
+	}
+	return nil
+}
+
+// AssertShippingStatusUpdateConstraints checks if the values respects the defined constraints
+func AssertShippingStatusUpdateConstraints(obj ShippingStatusUpdate) error {
+	if obj.Status != "shipped" && obj.Status != "delivered" {
+		return &common.ParsingError{Param: "status", Err: errors.New("must be one of shipped, delivered")}
+	}
+	return nil
+}
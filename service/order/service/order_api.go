@@ -0,0 +1,34 @@
+package service
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/mayureshucsb2019/bookstore/service/common"
+	"github.com/mayureshucsb2019/bookstore/service/order/models"
+)
+
+// DefaultAPIRouter defines the required methods for binding the api requests to a responses for the DefaultAPI
+// The DefaultAPIRouter implementation should parse necessary information from the http request,
+// pass the data to a DefaultAPIServicer to perform the required actions, then write the service results to the http response.
+type DefaultAPIRouter interface {
+	CustomersEmailOrdersGet(http.ResponseWriter, *http.Request)
+	OrdersIdGet(http.ResponseWriter, *http.Request)
+	OrdersIdHistoryGet(http.ResponseWriter, *http.Request)
+	OrdersIdPaymentsGet(http.ResponseWriter, *http.Request)
+	OrdersIdShippingStatusPut(http.ResponseWriter, *http.Request)
+	OrdersIdStatusPut(http.ResponseWriter, *http.Request)
+}
+
+// DefaultAPIServicer defines the api actions for the DefaultAPI service
+// This interface intended to stay up to date with the openapi yaml used to generate it,
+// while the service implementation can be ignored with the .openapi-generator-ignore file
+// and updated with the logic required for the API.
+type DefaultAPIServicer interface {
+	CustomersEmailOrdersGet(context.Context, string) (common.ImplResponse, error)
+	OrdersIdGet(context.Context, int64) (common.ImplResponse, error)
+	OrdersIdHistoryGet(context.Context, int64) (common.ImplResponse, error)
+	OrdersIdPaymentsGet(context.Context, int64) (common.ImplResponse, error)
+	OrdersIdShippingStatusPut(context.Context, int64, models.ShippingStatusUpdate) (common.ImplResponse, error)
+	OrdersIdStatusPut(context.Context, int64, models.OrderStatusUpdate) (common.ImplResponse, error)
+}
@@ -0,0 +1,230 @@
+package service
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/mayureshucsb2019/bookstore/service/common"
+	"github.com/mayureshucsb2019/bookstore/service/order/models"
+)
+
+// DefaultAPIController binds http requests to an api service and writes the service results to the http response
+type DefaultAPIController struct {
+	service      DefaultAPIServicer
+	errorHandler common.ErrorHandler
+}
+
+// DefaultAPIOption for how the controller is set up.
+type DefaultAPIOption func(*DefaultAPIController)
+
+// WithDefaultAPIErrorHandler inject ErrorHandler into controller
+func WithDefaultAPIErrorHandler(h common.ErrorHandler) DefaultAPIOption {
+	return func(c *DefaultAPIController) {
+		c.errorHandler = h
+	}
+}
+
+// NewDefaultAPIController creates a default api controller
+func NewDefaultAPIController(s DefaultAPIServicer, opts ...DefaultAPIOption) *DefaultAPIController {
+	controller := &DefaultAPIController{
+		service:      s,
+		errorHandler: common.DefaultErrorHandler,
+	}
+
+	for _, opt := range opts {
+		opt(controller)
+	}
+
+	return controller
+}
+
+// Routes returns all the api routes for the DefaultAPIController
+func (c *DefaultAPIController) Routes() common.Routes {
+	return common.Routes{
+		"CustomersEmailOrdersGet": common.Route{
+			Method:      strings.ToUpper("Get"),
+			Pattern:     "/customers/{email}/orders",
+			HandlerFunc: c.CustomersEmailOrdersGet,
+		},
+		"OrdersIdGet": common.Route{
+			Method:      strings.ToUpper("Get"),
+			Pattern:     "/orders/{id}",
+			HandlerFunc: c.OrdersIdGet,
+		},
+		"OrdersIdHistoryGet": common.Route{
+			Method:      strings.ToUpper("Get"),
+			Pattern:     "/orders/{id}/history",
+			HandlerFunc: c.OrdersIdHistoryGet,
+		},
+		"OrdersIdPaymentsGet": common.Route{
+			Method:      strings.ToUpper("Get"),
+			Pattern:     "/orders/{id}/payments",
+			HandlerFunc: c.OrdersIdPaymentsGet,
+		},
+		"OrdersIdShippingStatusPut": common.Route{
+			Method:      strings.ToUpper("Put"),
+			Pattern:     "/orders/{id}/shipping-status",
+			HandlerFunc: c.OrdersIdShippingStatusPut,
+		},
+		"OrdersIdStatusPut": common.Route{
+			Method:      strings.ToUpper("Put"),
+			Pattern:     "/orders/{id}/status",
+			HandlerFunc: c.OrdersIdStatusPut,
+		},
+	}
+}
+
+// CustomersEmailOrdersGet - Get the orders placed by a customer
+func (c *DefaultAPIController) CustomersEmailOrdersGet(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	emailParam := params["email"]
+	if emailParam == "" {
+		c.errorHandler(w, r, &common.RequiredError{Field: "email"}, nil)
+		return
+	}
+	result, err := c.service.CustomersEmailOrdersGet(r.Context(), emailParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	_ = common.EncodeJSONResponse(result.Body, &result.Code, w)
+}
+
+// OrdersIdGet - Get a specific order by ID
+func (c *DefaultAPIController) OrdersIdGet(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	idParam, err := common.ParseNumericParameter[int64](
+		params["id"],
+		common.WithRequire[int64](common.ParseInt64),
+	)
+	if err != nil {
+		c.errorHandler(w, r, &common.ParsingError{Param: "id", Err: err}, nil)
+		return
+	}
+	result, err := c.service.OrdersIdGet(r.Context(), idParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	_ = common.EncodeJSONResponse(result.Body, &result.Code, w)
+}
+
+// OrdersIdHistoryGet - Get the status history of an order
+func (c *DefaultAPIController) OrdersIdHistoryGet(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	idParam, err := common.ParseNumericParameter[int64](
+		params["id"],
+		common.WithRequire[int64](common.ParseInt64),
+	)
+	if err != nil {
+		c.errorHandler(w, r, &common.ParsingError{Param: "id", Err: err}, nil)
+		return
+	}
+	result, err := c.service.OrdersIdHistoryGet(r.Context(), idParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	_ = common.EncodeJSONResponse(result.Body, &result.Code, w)
+}
+
+// OrdersIdPaymentsGet - Get the payment attempts of an order
+func (c *DefaultAPIController) OrdersIdPaymentsGet(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	idParam, err := common.ParseNumericParameter[int64](
+		params["id"],
+		common.WithRequire[int64](common.ParseInt64),
+	)
+	if err != nil {
+		c.errorHandler(w, r, &common.ParsingError{Param: "id", Err: err}, nil)
+		return
+	}
+	result, err := c.service.OrdersIdPaymentsGet(r.Context(), idParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	_ = common.EncodeJSONResponse(result.Body, &result.Code, w)
+}
+
+// OrdersIdShippingStatusPut - Move an order to the next shipping status
+func (c *DefaultAPIController) OrdersIdShippingStatusPut(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	idParam, err := common.ParseNumericParameter[int64](
+		params["id"],
+		common.WithRequire[int64](common.ParseInt64),
+	)
+	if err != nil {
+		c.errorHandler(w, r, &common.ParsingError{Param: "id", Err: err}, nil)
+		return
+	}
+	shippingStatusUpdateParam := models.ShippingStatusUpdate{}
+	d := json.NewDecoder(r.Body)
+	d.DisallowUnknownFields()
+	if err := d.Decode(&shippingStatusUpdateParam); err != nil {
+		c.errorHandler(w, r, &common.ParsingError{Err: err}, nil)
+		return
+	}
+	if err := models.AssertShippingStatusUpdateRequired(shippingStatusUpdateParam); err != nil {
+		c.errorHandler(w, r, err, nil)
+		return
+	}
+	if err := models.AssertShippingStatusUpdateConstraints(shippingStatusUpdateParam); err != nil {
+		c.errorHandler(w, r, err, nil)
+		return
+	}
+	result, err := c.service.OrdersIdShippingStatusPut(r.Context(), idParam, shippingStatusUpdateParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	_ = common.EncodeJSONResponse(result.Body, &result.Code, w)
+}
+
+// OrdersIdStatusPut - Move an order to a new status
+func (c *DefaultAPIController) OrdersIdStatusPut(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	idParam, err := common.ParseNumericParameter[int64](
+		params["id"],
+		common.WithRequire[int64](common.ParseInt64),
+	)
+	if err != nil {
+		c.errorHandler(w, r, &common.ParsingError{Param: "id", Err: err}, nil)
+		return
+	}
+	orderStatusUpdateParam := models.OrderStatusUpdate{}
+	d := json.NewDecoder(r.Body)
+	d.DisallowUnknownFields()
+	if err := d.Decode(&orderStatusUpdateParam); err != nil {
+		c.errorHandler(w, r, &common.ParsingError{Err: err}, nil)
+		return
+	}
+	if err := models.AssertOrderStatusUpdateRequired(orderStatusUpdateParam); err != nil {
+		c.errorHandler(w, r, err, nil)
+		return
+	}
+	if err := models.AssertOrderStatusUpdateConstraints(orderStatusUpdateParam); err != nil {
+		c.errorHandler(w, r, err, nil)
+		return
+	}
+	result, err := c.service.OrdersIdStatusPut(r.Context(), idParam, orderStatusUpdateParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	_ = common.EncodeJSONResponse(result.Body, &result.Code, w)
+}
@@ -0,0 +1,236 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/mayureshucsb2019/bookstore/service/common"
+	"github.com/mayureshucsb2019/bookstore/service/order/db"
+	"github.com/mayureshucsb2019/bookstore/service/order/models"
+	"github.com/mayureshucsb2019/bookstore/service/payment"
+	payment_db "github.com/mayureshucsb2019/bookstore/service/payment/db"
+)
+
+// DefaultAPIService is a service that implements the logic for the DefaultAPIServicer
+// This service exposes the orders placed through cart checkout.
+type DefaultAPIService struct {
+	Repo     *db.OrderRepository
+	Payments *payment.Processor
+}
+
+// NewDefaultAPIService creates a default API service with the given repository and payment processor.
+func NewDefaultAPIService(repo *db.OrderRepository, payments *payment.Processor) *DefaultAPIService {
+	return &DefaultAPIService{
+		Repo:     repo,
+		Payments: payments,
+	}
+}
+
+// CustomersEmailOrdersGet - Get the orders placed by a customer
+func (s *DefaultAPIService) CustomersEmailOrdersGet(ctx context.Context, email string) (common.ImplResponse, error) {
+	orders, err := s.Repo.GetOrdersByCustomer(email)
+	if err != nil {
+		return common.Response(http.StatusInternalServerError, nil), err
+	}
+	ordersResp := []models.Order{}
+	for _, order := range orders {
+		ordersResp = append(ordersResp, ConvertDBToAPIResponse(order))
+	}
+
+	return common.Response(http.StatusOK, ordersResp), nil
+}
+
+// OrdersIdGet - Get a specific order by ID
+func (s *DefaultAPIService) OrdersIdGet(ctx context.Context, id int64) (common.ImplResponse, error) {
+	order, err := s.Repo.GetOrderByID(id)
+	if err != nil {
+		if errors.Is(err, db.ErrOrderNotFound) {
+			return common.Response(http.StatusNotFound, nil), err
+		}
+		return common.Response(http.StatusInternalServerError, nil), err
+	}
+
+	return common.Response(http.StatusOK, ConvertDBToAPIResponse(*order)), nil
+}
+
+// OrdersIdShippingStatusPut - Move an order to the next shipping status
+func (s *DefaultAPIService) OrdersIdShippingStatusPut(ctx context.Context, id int64, update models.ShippingStatusUpdate) (common.ImplResponse, error) {
+	var err error
+	switch update.Status {
+	case db.ShippingShipped:
+		_, err = s.Repo.ShipOrder(id, update.Carrier, update.TrackingNumber)
+	case db.ShippingDelivered:
+		err = s.Repo.DeliverOrder(id, "")
+	}
+	if err != nil {
+		return transitionErrorResponse(err)
+	}
+
+	return s.OrdersIdGet(ctx, id)
+}
+
+// OrdersIdHistoryGet - Get the status history of an order
+func (s *DefaultAPIService) OrdersIdHistoryGet(ctx context.Context, id int64) (common.ImplResponse, error) {
+	history, err := s.Repo.GetStatusHistory(id)
+	if err != nil {
+		if errors.Is(err, db.ErrOrderNotFound) {
+			return common.Response(http.StatusNotFound, nil), err
+		}
+		return common.Response(http.StatusInternalServerError, nil), err
+	}
+	return common.Response(http.StatusOK, ConvertStatusHistory(history)), nil
+}
+
+// OrdersIdPaymentsGet - Get the payment attempts of an order
+func (s *DefaultAPIService) OrdersIdPaymentsGet(ctx context.Context, id int64) (common.ImplResponse, error) {
+	if _, err := s.Repo.GetOrderStatus(id); err != nil {
+		return transitionErrorResponse(err)
+	}
+	attempts, err := s.Payments.Repo.GetAttemptsByOrder(id)
+	if err != nil {
+		return common.Response(http.StatusInternalServerError, nil), err
+	}
+	return common.Response(http.StatusOK, ConvertPaymentAttempts(attempts)), nil
+}
+
+// OrdersIdStatusPut - Move an order to a new status
+func (s *DefaultAPIService) OrdersIdStatusPut(ctx context.Context, id int64, update models.OrderStatusUpdate) (common.ImplResponse, error) {
+	var err error
+	switch update.Status {
+	case db.StatusDelivered:
+		// Delivery also updates the shipments, whether it follows shipping or a rejected return
+		err = s.Repo.DeliverOrder(id, update.Reason)
+	case db.StatusCancelled, db.StatusRefunded:
+		// The captured payment is refunded while the order is locked, and the status only changes
+		// when the refund succeeds; the refund itself is idempotent per order
+		var refundErr error
+		err = s.Repo.TransitionOrderWithRefund(id, update.Status, update.Reason, func() error {
+			refundErr = s.Payments.Refund(ctx, id)
+			return refundErr
+		})
+		if refundErr != nil {
+			if errors.Is(refundErr, payment.ErrDeclined) {
+				return common.Response(http.StatusConflict, nil), refundErr
+			}
+			return common.Response(http.StatusBadGateway, nil), refundErr
+		}
+	default:
+		err = s.Repo.TransitionOrder(id, update.Status, update.Reason)
+	}
+	if err != nil {
+		return transitionErrorResponse(err)
+	}
+
+	return s.OrdersIdGet(ctx, id)
+}
+
+// transitionErrorResponse maps status change errors to a not found, conflict or internal error response.
+func transitionErrorResponse(err error) (common.ImplResponse, error) {
+	switch {
+	case errors.Is(err, db.ErrOrderNotFound):
+		return common.Response(http.StatusNotFound, nil), err
+	case errors.Is(err, db.ErrInvalidTransition):
+		return common.Response(http.StatusConflict, nil), err
+	}
+	return common.Response(http.StatusInternalServerError, nil), err
+}
+
+// ConvertDBToAPIResponse converts the DB model to the API model
+func ConvertDBToAPIResponse(order db.Order) models.Order {
+	items := make([]models.OrderItem, 0, len(order.Items))
+	for _, item := range order.Items {
+		items = append(items, models.OrderItem{
+			Isbn:      item.ISBN,
+			Quantity:  int32(item.Quantity),
+			UnitPrice: item.UnitPrice,
+			LineTotal: item.LineTotal(),
+			TaxRate:   item.TaxRate,
+			Tax:       item.Tax,
+		})
+	}
+
+	discounts := make([]models.OrderDiscount, 0, len(order.Discounts))
+	for _, discount := range order.Discounts {
+		discounts = append(discounts, models.OrderDiscount{
+			Code:        discount.PromotionCode,
+			Description: discount.Description,
+			Isbn:        discount.ISBN,
+			Amount:      discount.Amount,
+		})
+	}
+
+	shipments := make([]models.Shipment, 0, len(order.Shipments))
+	for _, shipment := range order.Shipments {
+		shipments = append(shipments, models.Shipment{
+			Id:             shipment.ID,
+			Carrier:        shipment.Carrier,
+			TrackingNumber: shipment.TrackingNumber,
+			ShippedAt:      shipment.ShippedAt,
+			DeliveredAt:    shipment.DeliveredAt,
+		})
+	}
+
+	address := order.ShippingAddress
+	return models.Order{
+		Id:             order.ID,
+		CustomerEmail:  order.CustomerEmail,
+		OrderDate:      order.OrderDate,
+		Status:         order.Status,
+		Subtotal:       order.Subtotal,
+		DiscountTotal:  order.DiscountTotal,
+		TaxTotal:       order.TaxTotal,
+		ShippingAmount: order.ShippingAmount,
+		TotalAmount:    order.TotalAmount,
+		ExchangeRate:   order.ExchangeRate,
+		SourceCurrency: order.SourceCurrency,
+		Items:          items,
+		Discounts:      discounts,
+		ShippingMethod: order.ShippingMethod,
+		ShippingAddress: models.ShippingAddress{
+			UnitNo:     address.UnitNo,
+			StreetName: address.StreetName,
+			City:       address.City,
+			State:      address.State,
+			Country:    address.Country,
+			Zipcode:    address.Zipcode,
+			Landmark:   address.Landmark,
+		},
+		ShippingAddressId: order.ShippingAddressID,
+		BillingAddressId:  order.BillingAddressID,
+		ShippingStatus:    order.ShippingStatus,
+		Shipments:         shipments,
+	}
+}
+
+// ConvertStatusHistory converts the status changes of an order to the API model
+func ConvertStatusHistory(history []db.StatusChange) []models.OrderStatusChange {
+	historyResp := []models.OrderStatusChange{}
+	for _, change := range history {
+		historyResp = append(historyResp, models.OrderStatusChange{
+			FromStatus: change.FromStatus,
+			ToStatus:   change.ToStatus,
+			Reason:     change.Reason,
+			ChangedAt:  change.ChangedAt,
+		})
+	}
+	return historyResp
+}
+
+// ConvertPaymentAttempts converts the payment attempts of an order to the API model
+func ConvertPaymentAttempts(attempts []payment_db.PaymentAttempt) []models.PaymentAttempt {
+	attemptsResp := []models.PaymentAttempt{}
+	for _, attempt := range attempts {
+		attemptsResp = append(attemptsResp, models.PaymentAttempt{
+			Provider:       attempt.Provider,
+			Operation:      attempt.Operation,
+			IdempotencyKey: attempt.IdempotencyKey,
+			TransactionId:  attempt.TransactionID,
+			Amount:         attempt.Amount,
+			Status:         attempt.Status,
+			DeclineReason:  attempt.DeclineReason,
+			CreatedAt:      attempt.CreatedAt,
+		})
+	}
+	return attemptsResp
+}
@@ -0,0 +1,105 @@
+// Package password hashes customer passwords for storage with PBKDF2-HMAC-SHA256.
+package password
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Length limits of passwords, in characters
+const (
+	MinLength = 10
+	MaxLength = 128
+)
+
+// Iterations is the PBKDF2 work factor of new hashes. Stored hashes carry their own, so it can be
+// raised without invalidating them.
+const Iterations = 600000
+
+const (
+	scheme  = "pbkdf2-sha256"
+	saltLen = 16
+	keyLen  = 32
+)
+
+// ErrMismatch is returned by Verify when the password does not match the hash.
+var ErrMismatch = errors.New("password does not match")
+
+// Validate checks the length of a new password.
+func Validate(password string) error {
+	n := utf8.RuneCountInString(password)
+	if n < MinLength || n > MaxLength {
+		return fmt.Errorf("must be between %d and %d characters", MinLength, MaxLength)
+	}
+	return nil
+}
+
+// Hash returns the encoded hash of a password with a random salt, in the form
+// pbkdf2-sha256$iterations$salt$key.
+func Hash(password string) (string, error) {
+	salt := make([]byte, saltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("failed to generate salt: %w", err)
+	}
+	key := pbkdf2([]byte(password), salt, Iterations, keyLen)
+	return strings.Join([]string{scheme, strconv.Itoa(Iterations),
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)}, "$"), nil
+}
+
+// Verify checks a password against an encoded hash.
+func Verify(password string, encoded string) error {
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 4 || parts[0] != scheme {
+		return errors.New("unsupported password hash")
+	}
+	iterations, err := strconv.Atoi(parts[1])
+	if err != nil || iterations <= 0 {
+		return errors.New("invalid password hash iterations")
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return errors.New("invalid password hash salt")
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[3])
+	if err != nil {
+		return errors.New("invalid password hash key")
+	}
+	if subtle.ConstantTimeCompare(pbkdf2([]byte(password), salt, iterations, len(key)), key) != 1 {
+		return ErrMismatch
+	}
+	return nil
+}
+
+// pbkdf2 derives a key as specified by RFC 8018 with HMAC-SHA256.
+func pbkdf2(password []byte, salt []byte, iterations int, length int) []byte {
+	prf := hmac.New(sha256.New, password)
+	var key []byte
+	block := make([]byte, 4)
+	for i := uint32(1); len(key) < length; i++ {
+		prf.Reset()
+		prf.Write(salt)
+		binary.BigEndian.PutUint32(block, i)
+		prf.Write(block)
+		u := prf.Sum(nil)
+		t := append([]byte(nil), u...)
+		for n := 1; n < iterations; n++ {
+			prf.Reset()
+			prf.Write(u)
+			u = prf.Sum(u[:0])
+			for j := range t {
+				t[j] ^= u[j]
+			}
+		}
+		key = append(key, t...)
+	}
+	return key[:length]
+}
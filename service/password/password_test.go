@@ -0,0 +1,100 @@
+package password
+
+import (
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// The inputs of the RFC 6070 test vectors with HMAC-SHA256 in place of HMAC-SHA1, and the
+// PBKDF2-HMAC-SHA256 vector of RFC 7914 section 11.
+func TestPBKDF2Vectors(t *testing.T) {
+	tests := []struct {
+		password   string
+		salt       string
+		iterations int
+		key        string
+	}{
+		{"password", "salt", 1, "120fb6cffcf8b32c43e7225256c4f837a86548c92ccc35480805987cb70be17b"},
+		{"password", "salt", 2, "ae4d0c95af6b46d32d0adff928f06dd02a303f8ef3c251dfd6e2d85a95474c43"},
+		{"password", "salt", 4096, "c5e478d59288c841aa530db6845c4c8d962893a001ce4e11a4963873aa98134a"},
+		{"passwordPASSWORDpassword", "saltSALTsaltSALTsaltSALTsaltSALTsalt", 4096,
+			"348c89dbcbd32b2f32d814b8116e84cf2b17347ebc1800181c4e2a1fb8dd53e1c635518c7dac47e9"},
+		{"pass\x00word", "sa\x00lt", 4096, "89b69d0516f829893c696226650a8687"},
+		{"passwd", "salt", 1,
+			"55ac046e56e3089fec1691c22544b605f94185216dde0465e68b9d57c20dacbc49ca9cccf179b645991664b39d77ef317c71b845b1e30bd509112041d3a19783"},
+	}
+	for _, test := range tests {
+		want, _ := hex.DecodeString(test.key)
+		got := pbkdf2([]byte(test.password), []byte(test.salt), test.iterations, len(want))
+		if hex.EncodeToString(got) != test.key {
+			t.Errorf("pbkdf2(%q, %q, %d) = %x, want %s", test.password, test.salt, test.iterations, got, test.key)
+		}
+	}
+}
+
+func TestHashVerify(t *testing.T) {
+	encoded, err := Hash("correct horse battery staple")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(encoded, "pbkdf2-sha256$600000$") {
+		t.Errorf("Hash() = %q, want the scheme and iterations first", encoded)
+	}
+	if err := Verify("correct horse battery staple", encoded); err != nil {
+		t.Errorf("Verify() with the password = %v", err)
+	}
+	if err := Verify("correct horse battery stapler", encoded); !errors.Is(err, ErrMismatch) {
+		t.Errorf("Verify() with another password = %v, want ErrMismatch", err)
+	}
+
+	again, err := Hash("correct horse battery staple")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again == encoded {
+		t.Error("two hashes of the same password are equal, want a random salt")
+	}
+}
+
+func TestVerifyStoredIterations(t *testing.T) {
+	// Hashes keep the work factor they were made with
+	encoded := "pbkdf2-sha256$1$c2FsdA$Eg+2z/z4syxD5yJSVsT4N6hlSMkszDVICAWYfLcL4Xs"
+	if err := Verify("password", encoded); err != nil {
+		t.Errorf("Verify() = %v", err)
+	}
+}
+
+func TestVerifyMalformed(t *testing.T) {
+	for _, encoded := range []string{
+		"",
+		"bcrypt$10$c2FsdA$a2V5",
+		"pbkdf2-sha256$0$c2FsdA$a2V5",
+		"pbkdf2-sha256$many$c2FsdA$a2V5",
+		"pbkdf2-sha256$1$not base64$a2V5",
+		"pbkdf2-sha256$1$c2FsdA",
+	} {
+		if err := Verify("password", encoded); err == nil || errors.Is(err, ErrMismatch) {
+			t.Errorf("Verify(%q) = %v, want a malformed hash error", encoded, err)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		password string
+		valid    bool
+	}{
+		{"short", false},
+		{"exactly10!", true},
+		{strings.Repeat("é", 10), true}, // Characters, not bytes
+		{strings.Repeat("a", 128), true},
+		{strings.Repeat("a", 129), false},
+	}
+	for _, test := range tests {
+		if err := Validate(test.password); (err == nil) != test.valid {
+			t.Errorf("Validate(%q) = %v, want valid %t", test.password, err, test.valid)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+
+	_ "github.com/go-sql-driver/mysql"
+	"github.com/mayureshucsb2019/bookstore/service/common"
+)
+
+// PaymentAttempt represents the structure of a Payments record, one call to the payment provider.
+type PaymentAttempt struct {
+	ID             int64
+	OrderID        int64
+	Provider       string
+	Operation      string // authorize, capture, void or refund
+	IdempotencyKey string
+	TransactionID  string
+	Amount         common.Money
+	Status         string // succeeded, declined or failed
+	DeclineReason  string // Why the provider declined, or the error for failed attempts
+	CreatedAt      string
+}
+
+// PaymentRepository provides access to the Payments storage.
+type PaymentRepository struct {
+	DB *sql.DB
+}
+
+// RecordAttempt stores the outcome of a provider call. A retry with the same idempotency key
+// replaces the outcome of the earlier attempt.
+func (r *PaymentRepository) RecordAttempt(attempt *PaymentAttempt) error {
+	query := `
+		INSERT INTO Payments (order_id, provider, operation, idempotency_key, transaction_id, amount, currency, status, decline_reason)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE transaction_id = VALUES(transaction_id), status = VALUES(status), decline_reason = VALUES(decline_reason)
+	`
+	_, err := r.DB.Exec(query, attempt.OrderID, attempt.Provider, attempt.Operation, attempt.IdempotencyKey,
+		common.NullStringOrNil(attempt.TransactionID), attempt.Amount.Decimal(), attempt.Amount.Currency, attempt.Status,
+		common.NullStringOrNil(attempt.DeclineReason))
+	if err != nil {
+		return fmt.Errorf("failed to record payment attempt: %w", err)
+	}
+	return nil
+}
+
+// GetAttemptByKey retrieves the attempt made with an idempotency key, or nil if there is none.
+func (r *PaymentRepository) GetAttemptByKey(idempotencyKey string) (*PaymentAttempt, error) {
+	attempts, err := r.queryAttempts(`SELECT `+paymentColumns+` FROM Payments WHERE idempotency_key = ?`, idempotencyKey)
+	if err != nil || len(attempts) == 0 {
+		return nil, err
+	}
+	return &attempts[0], nil
+}
+
+// GetAttemptsByOrder retrieves the payment attempts of an order, oldest first.
+func (r *PaymentRepository) GetAttemptsByOrder(orderID int64) ([]PaymentAttempt, error) {
+	return r.queryAttempts(`SELECT `+paymentColumns+` FROM Payments WHERE order_id = ? ORDER BY id`, orderID)
+}
+
+const paymentColumns = `id, order_id, provider, operation, idempotency_key, transaction_id, amount, currency, status, decline_reason, created_at`
+
+func (r *PaymentRepository) queryAttempts(query string, args ...interface{}) ([]PaymentAttempt, error) {
+	rows, err := r.DB.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query payment attempts: %w", err)
+	}
+	defer rows.Close()
+
+	var attempts []PaymentAttempt
+	for rows.Next() {
+		var attempt PaymentAttempt
+		var transactionID, declineReason sql.NullString
+		var amount, currency string
+		err := rows.Scan(&attempt.ID, &attempt.OrderID, &attempt.Provider, &attempt.Operation, &attempt.IdempotencyKey,
+			&transactionID, &amount, &currency, &attempt.Status, &declineReason, &attempt.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan payment attempt: %w", err)
+		}
+		attempt.TransactionID = common.StringOrEmpty(transactionID)
+		attempt.DeclineReason = common.StringOrEmpty(declineReason)
+		if attempt.Amount, err = common.ParseMoney(amount, currency); err != nil {
+			return nil, fmt.Errorf("failed to parse payment amount: %w", err)
+		}
+		attempts = append(attempts, attempt)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+
+	return attempts, nil
+}
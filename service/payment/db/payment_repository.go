@@ -0,0 +1,19 @@
+package db
+
+import (
+	"sync"
+
+	"github.com/mayureshucsb2019/bookstore/service/common"
+)
+
+var paymentRepoInstance *PaymentRepository
+var paymentRepoOnce sync.Once
+
+func NewPaymentRepository(db *common.DBConnection) *PaymentRepository {
+	paymentRepoOnce.Do(func() {
+		paymentRepoInstance = &PaymentRepository{
+			DB: db.DB,
+		}
+	})
+	return paymentRepoInstance
+}
@@ -0,0 +1,150 @@
+package payment
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sync"
+
+	"github.com/mayureshucsb2019/bookstore/service/common"
+)
+
+// DefaultDeclineCards are the test card numbers the fake gateway declines unless configured otherwise.
+var DefaultDeclineCards = map[string]string{
+	"4000000000000002": "card_declined",
+	"4000000000009995": "insufficient_funds",
+	"4000000000000069": "expired_card",
+}
+
+// FakeGateway is a deterministic in-memory Provider for running the checkout flow offline.
+// Any payment token is accepted as a card except those in the decline list, and transaction
+// ids are derived from the idempotency key so repeated runs produce the same ids.
+type FakeGateway struct {
+	mu           sync.Mutex
+	declineCards map[string]string
+	transactions map[string]*fakeTransaction
+	results      map[string]Result // Keyed by idempotency key
+}
+
+type fakeTransaction struct {
+	authorized common.Money
+	captured   int64
+	refunded   int64
+	voided     bool
+}
+
+// NewFakeGateway creates a fake gateway that declines the given card numbers with the mapped reason.
+// A nil map uses DefaultDeclineCards.
+func NewFakeGateway(declineCards map[string]string) *FakeGateway {
+	if declineCards == nil {
+		declineCards = DefaultDeclineCards
+	}
+	return &FakeGateway{
+		declineCards: declineCards,
+		transactions: map[string]*fakeTransaction{},
+		results:      map[string]Result{},
+	}
+}
+
+// Name identifies the provider in recorded payment attempts.
+func (g *FakeGateway) Name() string {
+	return "fake"
+}
+
+// Authorize reserves the amount unless the payment token is a decline card.
+func (g *FakeGateway) Authorize(ctx context.Context, request AuthorizeRequest) (*Result, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if result, ok := g.results[request.IdempotencyKey]; ok {
+		return &result, nil
+	}
+
+	result := Result{TransactionID: fakeTransactionID(request.IdempotencyKey), Status: StatusSucceeded}
+	if reason, ok := g.declineCards[request.PaymentToken]; ok {
+		result.Status, result.DeclineReason = StatusDeclined, reason
+	} else if request.PaymentToken == "" {
+		result.Status, result.DeclineReason = StatusDeclined, "missing_payment_method"
+	} else {
+		g.transactions[result.TransactionID] = &fakeTransaction{authorized: request.Amount}
+	}
+	return g.remember(request.IdempotencyKey, result), nil
+}
+
+// Capture collects up to the authorized amount.
+func (g *FakeGateway) Capture(ctx context.Context, request TransactionRequest) (*Result, error) {
+	return g.apply(request, func(t *fakeTransaction) error {
+		if t.voided || t.captured > 0 {
+			return fmt.Errorf("%w: transaction %s cannot be captured", ErrInvalidOperation, request.TransactionID)
+		}
+		if err := checkAmount(request.Amount, t.authorized, t.authorized.Amount); err != nil {
+			return err
+		}
+		t.captured = request.Amount.Amount
+		return nil
+	})
+}
+
+// Void releases an authorization that has not been captured.
+func (g *FakeGateway) Void(ctx context.Context, request TransactionRequest) (*Result, error) {
+	return g.apply(request, func(t *fakeTransaction) error {
+		if t.voided || t.captured > 0 {
+			return fmt.Errorf("%w: transaction %s cannot be voided", ErrInvalidOperation, request.TransactionID)
+		}
+		t.voided = true
+		return nil
+	})
+}
+
+// Refund returns up to the captured amount not yet refunded.
+func (g *FakeGateway) Refund(ctx context.Context, request TransactionRequest) (*Result, error) {
+	return g.apply(request, func(t *fakeTransaction) error {
+		if err := checkAmount(request.Amount, t.authorized, t.captured-t.refunded); err != nil {
+			return err
+		}
+		t.refunded += request.Amount.Amount
+		return nil
+	})
+}
+
+// apply runs an operation on an existing transaction once per idempotency key.
+func (g *FakeGateway) apply(request TransactionRequest, operation func(*fakeTransaction) error) (*Result, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if result, ok := g.results[request.IdempotencyKey]; ok {
+		return &result, nil
+	}
+	transaction, ok := g.transactions[request.TransactionID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrTransactionNotFound, request.TransactionID)
+	}
+	if err := operation(transaction); err != nil {
+		return nil, err
+	}
+	return g.remember(request.IdempotencyKey, Result{TransactionID: request.TransactionID, Status: StatusSucceeded}), nil
+}
+
+func (g *FakeGateway) remember(key string, result Result) *Result {
+	if key != "" {
+		g.results[key] = result
+	}
+	return &result
+}
+
+// checkAmount verifies the amount is positive, in the transaction currency and at most available.
+func checkAmount(amount common.Money, authorized common.Money, available int64) error {
+	if amount.Currency != authorized.Currency {
+		return fmt.Errorf("%w: transaction is in %s", common.ErrCurrencyMismatch, authorized.Currency)
+	}
+	if amount.Amount <= 0 || amount.Amount > available {
+		return fmt.Errorf("%w: amount %s exceeds the %s available", ErrInvalidOperation, amount, common.NewMoney(available, authorized.Currency))
+	}
+	return nil
+}
+
+func fakeTransactionID(idempotencyKey string) string {
+	sum := sha256.Sum256([]byte(idempotencyKey))
+	return "fake_" + hex.EncodeToString(sum[:8])
+}
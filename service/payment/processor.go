@@ -0,0 +1,134 @@
+package payment
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mayureshucsb2019/bookstore/service/common"
+	"github.com/mayureshucsb2019/bookstore/service/payment/db"
+)
+
+// StatusFailed is recorded when the provider could not be reached or rejected the request.
+const StatusFailed = "failed"
+
+// AttemptStore records the payment attempts of orders. It is implemented by
+// db.PaymentRepository.
+type AttemptStore interface {
+	RecordAttempt(attempt *db.PaymentAttempt) error
+	GetAttemptByKey(idempotencyKey string) (*db.PaymentAttempt, error)
+	GetAttemptsByOrder(orderID int64) ([]db.PaymentAttempt, error)
+}
+
+// Processor runs provider operations for orders and records every attempt against the order.
+// Idempotency keys are derived from the order, so retrying a checkout or refund never charges
+// or refunds twice, even across restarts.
+type Processor struct {
+	Provider Provider
+	Repo     AttemptStore
+}
+
+// NewProcessor creates a processor for the provider that records attempts in repo.
+func NewProcessor(provider Provider, repo AttemptStore) *Processor {
+	return &Processor{
+		Provider: provider,
+		Repo:     repo,
+	}
+}
+
+// Charge authorizes and captures the amount for an order. A declined payment returns an error
+// wrapping ErrDeclined. The authorization is voided if it cannot be captured.
+func (p *Processor) Charge(ctx context.Context, orderID int64, amount common.Money, paymentToken string) error {
+	auth, err := p.run(orderID, OperationAuthorize, fmt.Sprintf("order-%d-authorize", orderID), amount, func(key string) (*Result, error) {
+		return p.Provider.Authorize(ctx, AuthorizeRequest{IdempotencyKey: key, Amount: amount, PaymentToken: paymentToken})
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = p.run(orderID, OperationCapture, fmt.Sprintf("order-%d-capture", orderID), amount, func(key string) (*Result, error) {
+		return p.Provider.Capture(ctx, TransactionRequest{IdempotencyKey: key, TransactionID: auth.TransactionID, Amount: amount})
+	})
+	if err != nil {
+		_, voidErr := p.run(orderID, OperationVoid, fmt.Sprintf("order-%d-void", orderID), amount, func(key string) (*Result, error) {
+			return p.Provider.Void(ctx, TransactionRequest{IdempotencyKey: key, TransactionID: auth.TransactionID})
+		})
+		if voidErr != nil {
+			return fmt.Errorf("%w; voiding the authorization also failed: %v", err, voidErr)
+		}
+		return err
+	}
+	return nil
+}
+
+// ChargeAndRecord charges an order like Charge, then calls record to mark it paid. When record
+// fails the captured amount is refunded, so the customer is not charged for an order that stays
+// unpaid and a retried checkout does not charge them twice. The error of record is returned, or
+// one wrapping ErrChargeNotReversed when the refund failed too.
+func (p *Processor) ChargeAndRecord(ctx context.Context, orderID int64, amount common.Money, paymentToken string, record func() error) error {
+	if err := p.Charge(ctx, orderID, amount, paymentToken); err != nil {
+		return err
+	}
+	err := record()
+	if err == nil {
+		return nil
+	}
+	if refundErr := p.Refund(ctx, orderID); refundErr != nil {
+		return fmt.Errorf("%w: %v; refunding the capture also failed: %v", ErrChargeNotReversed, err, refundErr)
+	}
+	return err
+}
+
+// Refund returns the captured amount of an order. Orders without a captured payment, such as
+// those marked paid by hand, have nothing to refund.
+func (p *Processor) Refund(ctx context.Context, orderID int64) error {
+	capture, err := p.Repo.GetAttemptByKey(fmt.Sprintf("order-%d-capture", orderID))
+	if err != nil {
+		return err
+	}
+	if capture == nil || capture.Status != StatusSucceeded {
+		return nil
+	}
+
+	_, err = p.run(orderID, OperationRefund, fmt.Sprintf("order-%d-refund", orderID), capture.Amount, func(key string) (*Result, error) {
+		return p.Provider.Refund(ctx, TransactionRequest{IdempotencyKey: key, TransactionID: capture.TransactionID, Amount: capture.Amount})
+	})
+	return err
+}
+
+// run performs one provider operation unless an earlier attempt with the same key succeeded,
+// and records the outcome.
+func (p *Processor) run(orderID int64, operation string, key string, amount common.Money, call func(key string) (*Result, error)) (*Result, error) {
+	previous, err := p.Repo.GetAttemptByKey(key)
+	if err != nil {
+		return nil, err
+	}
+	if previous != nil && previous.Status == StatusSucceeded {
+		return &Result{TransactionID: previous.TransactionID, Status: StatusSucceeded}, nil
+	}
+
+	attempt := db.PaymentAttempt{
+		OrderID:        orderID,
+		Provider:       p.Provider.Name(),
+		Operation:      operation,
+		IdempotencyKey: key,
+		Amount:         amount,
+	}
+	result, callErr := call(key)
+	switch {
+	case callErr != nil:
+		attempt.Status, attempt.DeclineReason = StatusFailed, callErr.Error()
+	default:
+		attempt.TransactionID, attempt.Status, attempt.DeclineReason = result.TransactionID, result.Status, result.DeclineReason
+	}
+	if err := p.Repo.RecordAttempt(&attempt); err != nil {
+		return nil, err
+	}
+
+	if callErr != nil {
+		return nil, fmt.Errorf("payment %s failed: %w", operation, callErr)
+	}
+	if result.Status == StatusDeclined {
+		return nil, fmt.Errorf("%w: %s", ErrDeclined, result.DeclineReason)
+	}
+	return result, nil
+}
@@ -0,0 +1,234 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/mayureshucsb2019/bookstore/service/common"
+	"github.com/mayureshucsb2019/bookstore/service/payment/db"
+)
+
+// memoryAttempts is an in-memory AttemptStore keeping attempts in the order they were made.
+type memoryAttempts struct {
+	attempts []db.PaymentAttempt
+}
+
+func (m *memoryAttempts) RecordAttempt(attempt *db.PaymentAttempt) error {
+	for i := range m.attempts {
+		if m.attempts[i].IdempotencyKey == attempt.IdempotencyKey {
+			m.attempts[i] = *attempt
+			return nil
+		}
+	}
+	m.attempts = append(m.attempts, *attempt)
+	return nil
+}
+
+func (m *memoryAttempts) GetAttemptByKey(idempotencyKey string) (*db.PaymentAttempt, error) {
+	for _, attempt := range m.attempts {
+		if attempt.IdempotencyKey == idempotencyKey {
+			return &attempt, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *memoryAttempts) GetAttemptsByOrder(orderID int64) ([]db.PaymentAttempt, error) {
+	var attempts []db.PaymentAttempt
+	for _, attempt := range m.attempts {
+		if attempt.OrderID == orderID {
+			attempts = append(attempts, attempt)
+		}
+	}
+	return attempts, nil
+}
+
+// operations returns the operation and status of every attempt recorded.
+func (m *memoryAttempts) operations() []string {
+	var operations []string
+	for _, attempt := range m.attempts {
+		operations = append(operations, attempt.Operation+" "+attempt.Status)
+	}
+	return operations
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// capturedFor returns the amount captured on the transaction authorized with key.
+func (g *FakeGateway) capturedFor(key string) int64 {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.transactions[fakeTransactionID(key)].captured
+}
+
+// refundedFor returns the amount refunded on the transaction authorized with key.
+func (g *FakeGateway) refundedFor(key string) int64 {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.transactions[fakeTransactionID(key)].refunded
+}
+
+func TestChargeRetryDoesNotChargeTwice(t *testing.T) {
+	gateway := NewFakeGateway(nil)
+	attempts := &memoryAttempts{}
+	processor := NewProcessor(gateway, attempts)
+	amount := common.NewMoney(2599, "USD")
+
+	for i := 0; i < 2; i++ {
+		if err := processor.Charge(context.Background(), 1, amount, "tok_visa"); err != nil {
+			t.Fatalf("charge %d: %v", i+1, err)
+		}
+	}
+	want := []string{"authorize succeeded", "capture succeeded"}
+	if got := attempts.operations(); !equalStrings(got, want) {
+		t.Errorf("recorded %v, want %v", got, want)
+	}
+
+	// The attempts were lost, e.g. the server stopped before recording them: the gateway answers
+	// the retry from the same order-derived keys
+	processor = NewProcessor(gateway, &memoryAttempts{})
+	if err := processor.Charge(context.Background(), 1, amount, "tok_visa"); err != nil {
+		t.Fatalf("charge after losing the attempts: %v", err)
+	}
+	if captured := gateway.capturedFor("order-1-authorize"); captured != amount.Amount {
+		t.Errorf("gateway captured %d, want %d", captured, amount.Amount)
+	}
+}
+
+func TestChargeDeclineCards(t *testing.T) {
+	gateway := NewFakeGateway(map[string]string{"4000000000000002": "card_declined"})
+	attempts := &memoryAttempts{}
+	processor := NewProcessor(gateway, attempts)
+
+	err := processor.Charge(context.Background(), 2, common.NewMoney(1000, "USD"), "4000000000000002")
+	if !errors.Is(err, ErrDeclined) {
+		t.Fatalf("charge returned %v, want ErrDeclined", err)
+	}
+	if len(attempts.attempts) != 1 || attempts.attempts[0].Status != StatusDeclined || attempts.attempts[0].DeclineReason != "card_declined" {
+		t.Errorf("recorded %+v, want one authorization declined with card_declined", attempts.attempts)
+	}
+
+	if err := processor.Charge(context.Background(), 3, common.NewMoney(1000, "USD"), "4000000000009995"); err != nil {
+		t.Errorf("charge with a card missing from the configured decline list: %v", err)
+	}
+}
+
+// failingCapture is a gateway whose captures fail, so charges void their authorization.
+type failingCapture struct {
+	*FakeGateway
+}
+
+func (failingCapture) Capture(ctx context.Context, request TransactionRequest) (*Result, error) {
+	return nil, errors.New("gateway timeout")
+}
+
+func TestChargeVoidsWhenCaptureFails(t *testing.T) {
+	attempts := &memoryAttempts{}
+	processor := NewProcessor(failingCapture{NewFakeGateway(nil)}, attempts)
+
+	if err := processor.Charge(context.Background(), 4, common.NewMoney(1000, "USD"), "tok_visa"); err == nil {
+		t.Fatal("charge succeeded, want the capture error")
+	}
+	want := []string{"authorize succeeded", "capture failed", "void succeeded"}
+	if got := attempts.operations(); !equalStrings(got, want) {
+		t.Errorf("recorded %v, want %v", got, want)
+	}
+}
+
+func TestRefundRecordsAttempt(t *testing.T) {
+	gateway := NewFakeGateway(nil)
+	attempts := &memoryAttempts{}
+	processor := NewProcessor(gateway, attempts)
+	if err := processor.Charge(context.Background(), 5, common.NewMoney(1500, "EUR"), "tok_visa"); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := processor.Refund(context.Background(), 5); err != nil {
+			t.Fatalf("refund %d: %v", i+1, err)
+		}
+	}
+	want := []string{"authorize succeeded", "capture succeeded", "refund succeeded"}
+	if got := attempts.operations(); !equalStrings(got, want) {
+		t.Errorf("recorded %v, want %v", got, want)
+	}
+	refund, _ := attempts.GetAttemptByKey("order-5-refund")
+	if refund.Amount != common.NewMoney(1500, "EUR") || refund.OrderID != 5 {
+		t.Errorf("refund recorded %+v, want 15.00 EUR for order 5", refund)
+	}
+}
+
+func TestChargeAndRecordRefundsWhenRecordFails(t *testing.T) {
+	gateway := NewFakeGateway(nil)
+	attempts := &memoryAttempts{}
+	processor := NewProcessor(gateway, attempts)
+	recordErr := errors.New("deadlock found when trying to get lock")
+
+	err := processor.ChargeAndRecord(context.Background(), 6, common.NewMoney(3000, "USD"), "tok_visa", func() error {
+		return recordErr
+	})
+	if err != recordErr {
+		t.Fatalf("charge returned %v, want the record error", err)
+	}
+	want := []string{"authorize succeeded", "capture succeeded", "refund succeeded"}
+	if got := attempts.operations(); !equalStrings(got, want) {
+		t.Errorf("recorded %v, want %v", got, want)
+	}
+	if refunded := gateway.refundedFor("order-6-authorize"); refunded != 3000 {
+		t.Errorf("gateway refunded %d, want 3000", refunded)
+	}
+}
+
+func TestChargeAndRecordKeepsCaptureWhenRecorded(t *testing.T) {
+	gateway := NewFakeGateway(nil)
+	attempts := &memoryAttempts{}
+	processor := NewProcessor(gateway, attempts)
+
+	recorded := false
+	err := processor.ChargeAndRecord(context.Background(), 7, common.NewMoney(3000, "USD"), "tok_visa", func() error {
+		recorded = true
+		return nil
+	})
+	if err != nil || !recorded {
+		t.Fatalf("charge returned %v with recorded %t, want it recorded", err, recorded)
+	}
+	if refunded := gateway.refundedFor("order-7-authorize"); refunded != 0 {
+		t.Errorf("gateway refunded %d, want nothing", refunded)
+	}
+}
+
+// failingRefund is a gateway whose refunds fail.
+type failingRefund struct {
+	*FakeGateway
+}
+
+func (failingRefund) Refund(ctx context.Context, request TransactionRequest) (*Result, error) {
+	return nil, errors.New("gateway timeout")
+}
+
+func TestChargeAndRecordNotReversed(t *testing.T) {
+	attempts := &memoryAttempts{}
+	processor := NewProcessor(failingRefund{NewFakeGateway(nil)}, attempts)
+
+	err := processor.ChargeAndRecord(context.Background(), 8, common.NewMoney(3000, "USD"), "tok_visa", func() error {
+		return errors.New("connection refused")
+	})
+	if !errors.Is(err, ErrChargeNotReversed) {
+		t.Fatalf("charge returned %v, want ErrChargeNotReversed", err)
+	}
+	want := []string{"authorize succeeded", "capture succeeded", "refund failed"}
+	if got := attempts.operations(); !equalStrings(got, want) {
+		t.Errorf("recorded %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,80 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/mayureshucsb2019/bookstore/service/common"
+)
+
+// Results a provider reports for an operation
+const (
+	StatusSucceeded = "succeeded"
+	StatusDeclined  = "declined"
+)
+
+// Operations recorded for each payment attempt
+const (
+	OperationAuthorize = "authorize"
+	OperationCapture   = "capture"
+	OperationVoid      = "void"
+	OperationRefund    = "refund"
+)
+
+var (
+	// ErrDeclined is returned when the provider refuses to take the payment
+	ErrDeclined = errors.New("payment declined")
+	// ErrTransactionNotFound is returned when a provider does not know the transaction
+	ErrTransactionNotFound = errors.New("payment transaction not found")
+	// ErrInvalidOperation is returned when an operation does not apply to the transaction,
+	// e.g. capturing a voided authorization or refunding more than was captured
+	ErrInvalidOperation = errors.New("invalid payment operation")
+	// ErrChargeNotReversed is returned when a captured payment could neither be recorded against
+	// its order nor refunded, so the customer is still charged
+	ErrChargeNotReversed = errors.New("charge could not be reversed")
+)
+
+// AuthorizeRequest reserves an amount on the customer's payment method.
+type AuthorizeRequest struct {
+	IdempotencyKey string
+	Amount         common.Money
+	// PaymentToken identifies the payment method, as issued by the provider's client-side
+	// tokenization. Card numbers are never stored by the bookstore.
+	PaymentToken string
+}
+
+// TransactionRequest captures, voids or refunds an authorized transaction. Amount is ignored by void.
+type TransactionRequest struct {
+	IdempotencyKey string
+	TransactionID  string
+	Amount         common.Money
+}
+
+// Result is the outcome of a provider operation.
+type Result struct {
+	TransactionID string
+	Status        string
+	DeclineReason string // Set when Status is StatusDeclined
+}
+
+// Provider is a payment gateway. Every operation carries an idempotency key; repeating a
+// request with the same key returns the original result instead of moving money twice.
+// A declined payment is reported as a Result with StatusDeclined, not as an error.
+type Provider interface {
+	Name() string
+	Authorize(ctx context.Context, request AuthorizeRequest) (*Result, error)
+	Capture(ctx context.Context, request TransactionRequest) (*Result, error)
+	Void(ctx context.Context, request TransactionRequest) (*Result, error)
+	Refund(ctx context.Context, request TransactionRequest) (*Result, error)
+}
+
+// NewProvider creates the provider registered under name. declineCards configures the fake
+// gateway and is ignored by other providers.
+func NewProvider(name string, declineCards map[string]string) (Provider, error) {
+	switch name {
+	case "", "fake":
+		return NewFakeGateway(declineCards), nil
+	}
+	return nil, fmt.Errorf("unknown payment provider %q", name)
+}
@@ -0,0 +1,211 @@
+// Package pii encrypts personal data for storage.
+//
+// Records are protected with envelope encryption: each record gets its own random data key, its
+// fields are encrypted with that key, and the data key is stored next to them wrapped under a
+// key-encryption key from the keyring. Keys are named by an ID so they can be rotated: new records
+// use the current key, older ones keep naming the key they were wrapped with until they are
+// rewrapped. Rotation only rewrites the wrapped data keys, never the fields.
+package pii
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// KeysEnv is the environment variable holding the keyring, in the same JSON form as a key file.
+// It takes precedence over the file.
+const KeysEnv = "BOOKSTORE_PII_KEYS"
+
+// ErrUnknownKey is returned for data wrapped under a key that is not in the keyring.
+var ErrUnknownKey = errors.New("unknown encryption key")
+
+// KeyFile is the JSON form of a keyring. Keys are 32 random bytes in standard base64, e.g. the
+// output of openssl rand -base64 32.
+type KeyFile struct {
+	// ID of the key new data keys are wrapped with
+	Current string `json:"current"`
+	// Key-encryption keys by ID. Keep retired keys until every record is rewrapped
+	Keys map[string]string `json:"keys"`
+	// Key of the blind indexes. Changing it requires recomputing them, see Keyring.BlindIndex
+	IndexKey string `json:"index_key"`
+}
+
+// Keyring holds the key-encryption keys and the blind index key.
+type Keyring struct {
+	current  string
+	keys     map[string]cipher.AEAD
+	indexKey []byte
+}
+
+// NewKeyring builds a keyring from its JSON form.
+func NewKeyring(file KeyFile) (*Keyring, error) {
+	k := &Keyring{current: file.Current, keys: map[string]cipher.AEAD{}}
+	for id, encoded := range file.Keys {
+		if id == "" || strings.Contains(id, ":") {
+			return nil, fmt.Errorf("invalid key id %q", id)
+		}
+		key, err := decodeKey(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("key %s: %w", id, err)
+		}
+		if k.keys[id], err = newAEAD(key); err != nil {
+			return nil, err
+		}
+	}
+	if _, ok := k.keys[k.current]; !ok {
+		return nil, fmt.Errorf("current key %q is not in the keyring", k.current)
+	}
+	var err error
+	if k.indexKey, err = decodeKey(file.IndexKey); err != nil {
+		return nil, fmt.Errorf("index key: %w", err)
+	}
+	return k, nil
+}
+
+// LoadKeyring reads the keyring from KeysEnv when it is set, otherwise from the JSON file at
+// filePath. It returns nil without an error when neither is configured.
+func LoadKeyring(filePath string) (*Keyring, error) {
+	data := []byte(os.Getenv(KeysEnv))
+	source := KeysEnv
+	if len(data) == 0 {
+		if filePath == "" {
+			return nil, nil
+		}
+		var err error
+		if data, err = os.ReadFile(filePath); err != nil {
+			return nil, err
+		}
+		source = filePath
+	}
+
+	var file KeyFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse keys from %s: %w", source, err)
+	}
+	return NewKeyring(file)
+}
+
+// CurrentKeyID returns the ID of the key new data keys are wrapped with.
+func (k *Keyring) CurrentKeyID() string {
+	return k.current
+}
+
+// Envelope is the data key of a record wrapped under a key-encryption key, as stored.
+type Envelope struct {
+	KeyID      string
+	WrappedKey string
+}
+
+// NewRecordKey generates a data key for a record and wraps it under the current key.
+func (k *Keyring) NewRecordKey() (*RecordKey, Envelope, error) {
+	dataKey := make([]byte, 32)
+	if _, err := rand.Read(dataKey); err != nil {
+		return nil, Envelope{}, fmt.Errorf("failed to generate data key: %w", err)
+	}
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, Envelope{}, err
+	}
+	wrapped, err := seal(k.keys[k.current], dataKey, []byte(k.current))
+	if err != nil {
+		return nil, Envelope{}, err
+	}
+	return &RecordKey{aead: aead}, Envelope{KeyID: k.current, WrappedKey: wrapped}, nil
+}
+
+// OpenRecordKey unwraps the data key of a record.
+func (k *Keyring) OpenRecordKey(envelope Envelope) (*RecordKey, error) {
+	dataKey, err := k.unwrap(envelope)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	return &RecordKey{aead: aead}, nil
+}
+
+// Rewrap wraps the data key of a record under the current key. The record's fields stay valid.
+func (k *Keyring) Rewrap(envelope Envelope) (Envelope, error) {
+	dataKey, err := k.unwrap(envelope)
+	if err != nil {
+		return Envelope{}, err
+	}
+	wrapped, err := seal(k.keys[k.current], dataKey, []byte(k.current))
+	if err != nil {
+		return Envelope{}, err
+	}
+	return Envelope{KeyID: k.current, WrappedKey: wrapped}, nil
+}
+
+func (k *Keyring) unwrap(envelope Envelope) ([]byte, error) {
+	kek, ok := k.keys[envelope.KeyID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownKey, envelope.KeyID)
+	}
+	dataKey, err := open(kek, envelope.WrappedKey, []byte(envelope.KeyID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to unwrap data key with key %s: %w", envelope.KeyID, err)
+	}
+	return dataKey, nil
+}
+
+// BlindIndex returns a keyed hash of a field value, stored next to the encrypted value so exact
+// lookups still work without decrypting every record. The field name is part of the hash, so
+// equal values of different fields do not match.
+func (k *Keyring) BlindIndex(field string, value string) string {
+	mac := hmac.New(sha256.New, k.indexKey)
+	mac.Write([]byte(field + ":" + value))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func decodeKey(encoded string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("not base64: %w", err)
+	}
+	if len(key) != 32 {
+		return nil, fmt.Errorf("must be 32 bytes, got %d", len(key))
+	}
+	return key, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// seal encrypts plaintext with AES-GCM under a random nonce and returns nonce and ciphertext in
+// base64. additionalData is authenticated but not stored.
+func seal(aead cipher.AEAD, plaintext []byte, additionalData []byte) (string, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	return base64.StdEncoding.EncodeToString(aead.Seal(nonce, nonce, plaintext, additionalData)), nil
+}
+
+func open(aead cipher.AEAD, sealed string, additionalData []byte) ([]byte, error) {
+	data, err := base64.StdEncoding.DecodeString(sealed)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < aead.NonceSize() {
+		return nil, errors.New("ciphertext too short")
+	}
+	return aead.Open(nil, data[:aead.NonceSize()], data[aead.NonceSize():], additionalData)
+}
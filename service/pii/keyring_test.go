@@ -0,0 +1,126 @@
+package pii
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"testing"
+)
+
+// testKey returns a 32 byte key of b in standard base64.
+func testKey(b byte) string {
+	return base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{b}, 32))
+}
+
+func testKeyring(t *testing.T, current string, keys map[string]string, indexKey string) *Keyring {
+	t.Helper()
+	keyring, err := NewKeyring(KeyFile{Current: current, Keys: keys, IndexKey: indexKey})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return keyring
+}
+
+func TestNewKeyringRejectsInvalidKeys(t *testing.T) {
+	tests := map[string]KeyFile{
+		"missing current": {Current: "k2", Keys: map[string]string{"k1": testKey(1)}, IndexKey: testKey(9)},
+		"short key":       {Current: "k1", Keys: map[string]string{"k1": base64.StdEncoding.EncodeToString(make([]byte, 16))}, IndexKey: testKey(9)},
+		"not base64":      {Current: "k1", Keys: map[string]string{"k1": "not base64!"}, IndexKey: testKey(9)},
+		"id with colon":   {Current: "k:1", Keys: map[string]string{"k:1": testKey(1)}, IndexKey: testKey(9)},
+		"no index key":    {Current: "k1", Keys: map[string]string{"k1": testKey(1)}},
+	}
+	for name, file := range tests {
+		if _, err := NewKeyring(file); err == nil {
+			t.Errorf("NewKeyring() with %s succeeded", name)
+		}
+	}
+}
+
+func TestRecordKeyAfterRotation(t *testing.T) {
+	old := testKeyring(t, "k1", map[string]string{"k1": testKey(1)}, testKey(9))
+	recordKey, envelope, err := old.NewRecordKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if envelope.KeyID != "k1" {
+		t.Errorf("envelope is wrapped with %s, want k1", envelope.KeyID)
+	}
+	phone, err := recordKey.Encrypt("phone_number", "+1 555 0100")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// k2 becomes current; records wrapped with k1 still open while k1 is kept
+	rotated := testKeyring(t, "k2", map[string]string{"k1": testKey(1), "k2": testKey(2)}, testKey(9))
+	opened, err := rotated.OpenRecordKey(envelope)
+	if err != nil {
+		t.Fatalf("OpenRecordKey() after rotation = %v", err)
+	}
+	if got, err := opened.Decrypt("phone_number", phone); err != nil || got != "+1 555 0100" {
+		t.Errorf("Decrypt() after rotation = %q, %v", got, err)
+	}
+
+	// Rewrapping moves the data key to k2 without touching the fields
+	rewrapped, err := rotated.Rewrap(envelope)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rewrapped.KeyID != "k2" || rewrapped.WrappedKey == envelope.WrappedKey {
+		t.Errorf("Rewrap() = %+v, want the data key wrapped with k2", rewrapped)
+	}
+	retired := testKeyring(t, "k2", map[string]string{"k2": testKey(2)}, testKey(9))
+	opened, err = retired.OpenRecordKey(rewrapped)
+	if err != nil {
+		t.Fatalf("OpenRecordKey() after retiring k1 = %v", err)
+	}
+	if got, err := opened.Decrypt("phone_number", phone); err != nil || got != "+1 555 0100" {
+		t.Errorf("Decrypt() after retiring k1 = %q, %v", got, err)
+	}
+	if _, err := retired.OpenRecordKey(envelope); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("OpenRecordKey() of an envelope wrapped with a retired key = %v, want ErrUnknownKey", err)
+	}
+}
+
+func TestOpenRecordKeyTampered(t *testing.T) {
+	keyring := testKeyring(t, "k1", map[string]string{"k1": testKey(1), "k2": testKey(2)}, testKey(9))
+	_, envelope, err := keyring.NewRecordKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wrapped, _ := base64.StdEncoding.DecodeString(envelope.WrappedKey)
+	wrapped[len(wrapped)-1] ^= 1
+	for name, tampered := range map[string]Envelope{
+		"wrapped key": {KeyID: "k1", WrappedKey: base64.StdEncoding.EncodeToString(wrapped)},
+		"key id":      {KeyID: "k2", WrappedKey: envelope.WrappedKey},
+		"truncated":   {KeyID: "k1", WrappedKey: base64.StdEncoding.EncodeToString(wrapped[:8])},
+	} {
+		if _, err := keyring.OpenRecordKey(tampered); err == nil {
+			t.Errorf("OpenRecordKey() with a tampered %s succeeded", name)
+		}
+	}
+}
+
+func TestBlindIndex(t *testing.T) {
+	keyring := testKeyring(t, "k1", map[string]string{"k1": testKey(1)}, testKey(9))
+	index := keyring.BlindIndex("phone_number", "+15550100")
+	if len(index) != 64 {
+		t.Errorf("BlindIndex() = %q, want 64 hex characters", index)
+	}
+
+	// The index depends on the index key only, so rotating the record keys keeps it
+	rotated := testKeyring(t, "k2", map[string]string{"k1": testKey(1), "k2": testKey(2)}, testKey(9))
+	if rotated.BlindIndex("phone_number", "+15550100") != index {
+		t.Error("BlindIndex() changed when the record keys were rotated")
+	}
+	if keyring.BlindIndex("phone_number", "+15550101") == index {
+		t.Error("BlindIndex() of another value matched")
+	}
+	if keyring.BlindIndex("dob", "+15550100") == index {
+		t.Error("BlindIndex() of another field matched")
+	}
+	other := testKeyring(t, "k1", map[string]string{"k1": testKey(1)}, testKey(8))
+	if other.BlindIndex("phone_number", "+15550100") == index {
+		t.Error("BlindIndex() with another index key matched")
+	}
+}
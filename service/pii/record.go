@@ -0,0 +1,61 @@
+package pii
+
+import (
+	"crypto/cipher"
+	"strings"
+	"unicode"
+)
+
+// prefix marks encrypted field values. Values without it were written before encryption was
+// enabled and are read as they are.
+const prefix = "enc:v1:"
+
+// RecordKey encrypts and decrypts the fields of one record with its data key.
+type RecordKey struct {
+	aead cipher.AEAD
+}
+
+// Encrypt encrypts the value of a field. Empty values are kept empty, so optional fields stay
+// recognisable as unset. The field name is authenticated, so a value cannot be moved to another
+// field of the record.
+func (r *RecordKey) Encrypt(field string, value string) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+	sealed, err := seal(r.aead, []byte(value), []byte(field))
+	if err != nil {
+		return "", err
+	}
+	return prefix + sealed, nil
+}
+
+// Decrypt returns the plaintext of a field value written by Encrypt. Values that are not
+// encrypted are returned unchanged.
+func (r *RecordKey) Decrypt(field string, value string) (string, error) {
+	if !IsEncrypted(value) {
+		return value, nil
+	}
+	plaintext, err := open(r.aead, strings.TrimPrefix(value, prefix), []byte(field))
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+// IsEncrypted reports whether a stored value was written by Encrypt.
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, prefix)
+}
+
+// NormalizePhone reduces a phone number to its digits, keeping a leading +, so differently
+// formatted numbers get the same blind index.
+func NormalizePhone(phone string) string {
+	phone = strings.TrimSpace(phone)
+	var b strings.Builder
+	for i, r := range phone {
+		if unicode.IsDigit(r) || (i == 0 && r == '+') {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
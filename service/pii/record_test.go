@@ -0,0 +1,89 @@
+package pii
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEncryptDecrypt(t *testing.T) {
+	keyring := testKeyring(t, "k1", map[string]string{"k1": testKey(1)}, testKey(9))
+	recordKey, _, err := keyring.NewRecordKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, value := range []string{"1990-04-01", "12 Rue de l'Église", "東京都"} {
+		encrypted, err := recordKey.Encrypt("street_name", value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !IsEncrypted(encrypted) || strings.Contains(encrypted, value) {
+			t.Errorf("Encrypt(%q) = %q, want it encrypted", value, encrypted)
+		}
+		again, _ := recordKey.Encrypt("street_name", value)
+		if again == encrypted {
+			t.Errorf("Encrypt(%q) twice gave the same value, want a random nonce", value)
+		}
+		if got, err := recordKey.Decrypt("street_name", encrypted); err != nil || got != value {
+			t.Errorf("Decrypt() = %q, %v, want %q", got, err, value)
+		}
+	}
+
+	if encrypted, err := recordKey.Encrypt("street_name", ""); encrypted != "" || err != nil {
+		t.Errorf("Encrypt(\"\") = %q, %v, want it kept empty", encrypted, err)
+	}
+	// Values stored before encryption was enabled are read as they are
+	if got, err := recordKey.Decrypt("street_name", "Main Street"); err != nil || got != "Main Street" {
+		t.Errorf("Decrypt() of a plaintext value = %q, %v", got, err)
+	}
+}
+
+func TestDecryptTampered(t *testing.T) {
+	keyring := testKeyring(t, "k1", map[string]string{"k1": testKey(1)}, testKey(9))
+	recordKey, _, err := keyring.NewRecordKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherKey, _, err := keyring.NewRecordKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	encrypted, err := recordKey.Encrypt("dob", "1990-04-01")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	flipped := []byte(encrypted)
+	last := len(flipped) - 2 // Before any padding
+	if flipped[last] == 'A' {
+		flipped[last] = 'B'
+	} else {
+		flipped[last] = 'A'
+	}
+	if _, err := recordKey.Decrypt("dob", string(flipped)); err == nil {
+		t.Error("Decrypt() of a tampered value succeeded")
+	}
+	if _, err := recordKey.Decrypt("phone_number", encrypted); err == nil {
+		t.Error("Decrypt() of a value moved to another field succeeded")
+	}
+	if _, err := otherKey.Decrypt("dob", encrypted); err == nil {
+		t.Error("Decrypt() with the data key of another record succeeded")
+	}
+	if _, err := recordKey.Decrypt("dob", prefix+"c2hvcnQ="); err == nil {
+		t.Error("Decrypt() of a value shorter than a nonce succeeded")
+	}
+}
+
+func TestNormalizePhone(t *testing.T) {
+	tests := map[string]string{
+		"+1 (555) 010-0100": "+15550100100",
+		" 555.010.0100 ":    "5550100100",
+		"00 44 20 7946":     "0044207946",
+		"1+2":               "12",
+	}
+	for phone, want := range tests {
+		if got := NormalizePhone(phone); got != want {
+			t.Errorf("NormalizePhone(%q) = %q, want %q", phone, got, want)
+		}
+	}
+}
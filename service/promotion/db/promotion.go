@@ -0,0 +1,224 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	_ "github.com/go-sql-driver/mysql"
+	"github.com/mayureshucsb2019/bookstore/service/common"
+)
+
+// Promotion types supported by the evaluation engine
+const (
+	TypePercentage  = "percentage"
+	TypeFixedAmount = "fixed_amount"
+	TypeBuyXGetY    = "buy_x_get_y"
+)
+
+// ErrPromotionNotFound is returned when no promotion exists with the requested code
+var ErrPromotionNotFound = errors.New("promotion not found")
+
+// Promotion represents the structure of a Promotions record in the database.
+type Promotion struct {
+	Code        string
+	Description string
+	Type        string
+	PercentOff  string       // Decimal percentage for percentage promotions, e.g. "20.00"
+	AmountOff   common.Money // Amount for fixed_amount promotions
+	BuyQuantity int          // Copies to pay for in buy_x_get_y promotions
+	GetQuantity int          // Copies given free in buy_x_get_y promotions
+	Tag         string       // When set, only books carrying this tag are discounted
+	StartsAt    sql.NullString
+	EndsAt      sql.NullString
+	// Maximum number of orders per customer the promotion can be used on, 0 for unlimited
+	UsageLimitPerCustomer int
+	Stackable             bool // Whether the promotion can be combined with other promotions
+	Priority              int  // Higher priorities are applied first
+	AutoApply             bool // Applied without the customer entering the code
+	Active                bool
+}
+
+// PromotionRepository provides access to the Promotions storage.
+type PromotionRepository struct {
+	DB *sql.DB
+}
+
+const promotionColumns = `code, description, type, percent_off, amount_off, currency, buy_quantity, get_quantity, tag,
+	starts_at, ends_at, usage_limit_per_customer, stackable, priority, auto_apply, active`
+
+// CreatePromotion inserts a new promotion into the database.
+func (r *PromotionRepository) CreatePromotion(promotion *Promotion) error {
+	query := `INSERT INTO Promotions (` + promotionColumns + `) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	_, err := r.DB.Exec(query, promotionArgs(promotion)...)
+	if err != nil {
+		return fmt.Errorf("failed to insert promotion: %w", err)
+	}
+	return nil
+}
+
+// UpdatePromotion updates an existing promotion record in the database.
+func (r *PromotionRepository) UpdatePromotion(promotion *Promotion) error {
+	query := `
+		UPDATE Promotions
+		SET
+			description = ?, type = ?, percent_off = ?, amount_off = ?, currency = ?, buy_quantity = ?,
+			get_quantity = ?, tag = ?, starts_at = ?, ends_at = ?, usage_limit_per_customer = ?,
+			stackable = ?, priority = ?, auto_apply = ?, active = ?
+		WHERE code = ?
+	`
+	args := promotionArgs(promotion)
+	result, err := r.DB.Exec(query, append(args[1:], promotion.Code)...)
+	if err != nil {
+		return fmt.Errorf("failed to update promotion: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		// MySQL reports zero rows for an update that changes nothing, so confirm the row exists
+		if _, err := r.GetPromotionByCode(promotion.Code); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeletePromotion removes a promotion from the database by its code.
+func (r *PromotionRepository) DeletePromotion(code string) error {
+	result, err := r.DB.Exec(`DELETE FROM Promotions WHERE code = ?`, code)
+	if err != nil {
+		return fmt.Errorf("failed to delete promotion: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("%w: %s", ErrPromotionNotFound, code)
+	}
+	return nil
+}
+
+// GetPromotionByCode retrieves a promotion by its coupon code.
+func (r *PromotionRepository) GetPromotionByCode(code string) (*Promotion, error) {
+	query := `SELECT ` + promotionColumns + ` FROM Promotions WHERE code = ?`
+	promotion, err := scanPromotion(r.DB.QueryRow(query, code))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("%w: %s", ErrPromotionNotFound, code)
+		}
+		return nil, fmt.Errorf("failed to get promotion: %w", err)
+	}
+	return promotion, nil
+}
+
+// GetAllPromotions retrieves every promotion.
+func (r *PromotionRepository) GetAllPromotions() ([]Promotion, error) {
+	return r.queryPromotions(`SELECT ` + promotionColumns + ` FROM Promotions ORDER BY priority DESC, code`)
+}
+
+// GetApplicablePromotions retrieves the active promotions matching the given coupon codes
+// together with every active auto-apply promotion. Validity windows are checked by the engine.
+func (r *PromotionRepository) GetApplicablePromotions(codes []string) ([]Promotion, error) {
+	query := `SELECT ` + promotionColumns + ` FROM Promotions WHERE active = TRUE AND (auto_apply = TRUE`
+	args := make([]interface{}, 0, len(codes))
+	if len(codes) > 0 {
+		query += ` OR code IN (?` + strings.Repeat(", ?", len(codes)-1) + `)`
+		for _, code := range codes {
+			args = append(args, code)
+		}
+	}
+	query += `) ORDER BY priority DESC, code`
+
+	return r.queryPromotions(query, args...)
+}
+
+// CountCustomerUsage returns the number of orders of the customer that used the promotion.
+// Cancelled and refunded orders give the use back. Checkout checks the limit again when the
+// order is created, see order_db.ErrPromotionUsageExceeded.
+func (r *PromotionRepository) CountCustomerUsage(code string, email string) (int, error) {
+	query := `
+		SELECT COUNT(DISTINCT d.order_id)
+		FROM OrderDiscounts d JOIN Orders o ON o.id = d.order_id JOIN Customer c ON c.id = o.customer_id
+		WHERE d.promotion_code = ? AND c.email = ? AND o.status NOT IN ('cancelled', 'refunded')
+	`
+	var count int
+	if err := r.DB.QueryRow(query, code, email).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count promotion usage: %w", err)
+	}
+	return count, nil
+}
+
+func (r *PromotionRepository) queryPromotions(query string, args ...interface{}) ([]Promotion, error) {
+	rows, err := r.DB.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query promotions: %w", err)
+	}
+	defer rows.Close()
+
+	var promotions []Promotion
+	for rows.Next() {
+		promotion, err := scanPromotion(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan promotion: %w", err)
+		}
+		promotions = append(promotions, *promotion)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+
+	return promotions, nil
+}
+
+// promotionArgs returns the promotion fields in promotionColumns order.
+func promotionArgs(p *Promotion) []interface{} {
+	return []interface{}{
+		p.Code,
+		common.NullStringOrNil(p.Description),
+		p.Type,
+		common.NullStringOrNil(p.PercentOff),
+		p.AmountOff.Decimal(),
+		p.AmountOff.Currency,
+		p.BuyQuantity,
+		p.GetQuantity,
+		common.NullStringOrNil(p.Tag),
+		p.StartsAt,
+		p.EndsAt,
+		p.UsageLimitPerCustomer,
+		p.Stackable,
+		p.Priority,
+		p.AutoApply,
+		p.Active,
+	}
+}
+
+// rowScanner is implemented by both *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanPromotion(row rowScanner) (*Promotion, error) {
+	var p Promotion
+	var description, percentOff, tag sql.NullString
+	var amountOff, currency string
+
+	err := row.Scan(&p.Code, &description, &p.Type, &percentOff, &amountOff, &currency, &p.BuyQuantity, &p.GetQuantity,
+		&tag, &p.StartsAt, &p.EndsAt, &p.UsageLimitPerCustomer, &p.Stackable, &p.Priority, &p.AutoApply, &p.Active)
+	if err != nil {
+		return nil, err
+	}
+	p.Description = common.StringOrEmpty(description)
+	p.PercentOff = common.StringOrEmpty(percentOff)
+	p.Tag = common.StringOrEmpty(tag)
+	if p.AmountOff, err = common.ParseMoney(amountOff, currency); err != nil {
+		return nil, fmt.Errorf("failed to parse amount off: %w", err)
+	}
+
+	return &p, nil
+}
@@ -0,0 +1,19 @@
+package db
+
+import (
+	"sync"
+
+	"github.com/mayureshucsb2019/bookstore/service/common"
+)
+
+var promotionRepoInstance *PromotionRepository
+var promotionRepoOnce sync.Once
+
+func NewPromotionRepository(db *common.DBConnection) *PromotionRepository {
+	promotionRepoOnce.Do(func() {
+		promotionRepoInstance = &PromotionRepository{
+			DB: db.DB,
+		}
+	})
+	return promotionRepoInstance
+}
@@ -0,0 +1,131 @@
+package models
+
+import (
+	"errors"
+	"math/big"
+	"time"
+
+	"github.com/mayureshucsb2019/bookstore/service/common"
+)
+
+type Promotion struct {
+	// Coupon code customers enter at checkout.
+	Code string `json:"code"`
+
+	Description string `json:"description,omitempty"`
+
+	// One of percentage, fixed_amount or buy_x_get_y.
+	Type string `json:"type"`
+
+	// Percentage taken off, e.g. "20" for 20% off. Used by percentage promotions.
+	PercentOff string `json:"percent_off,omitempty"`
+
+	// Amount taken off the order. Used by fixed_amount promotions.
+	AmountOff *common.Money `json:"amount_off,omitempty"`
+
+	// Copies to pay for in buy_x_get_y promotions.
+	BuyQuantity int32 `json:"buy_quantity,omitempty"`
+
+	// Copies given free in buy_x_get_y promotions.
+	GetQuantity int32 `json:"get_quantity,omitempty"`
+
+	// When set, only books with this tag are discounted.
+	Tag string `json:"tag,omitempty"`
+
+	// RFC 3339 start of the validity window.
+	StartsAt string `json:"starts_at,omitempty"`
+
+	// RFC 3339 end of the validity window, exclusive.
+	EndsAt string `json:"ends_at,omitempty"`
+
+	// Maximum number of orders per customer, 0 for unlimited.
+	UsageLimitPerCustomer int32 `json:"usage_limit_per_customer,omitempty"`
+
+	// Whether the promotion can be combined with other promotions.
+	Stackable bool `json:"stackable"`
+
+	// Higher priorities are applied first.
+	Priority int32 `json:"priority,omitempty"`
+
+	// Applied to every qualifying order without entering the code.
+	AutoApply bool `json:"auto_apply"`
+
+	// Defaults to true when omitted.
+	Active *bool `json:"active,omitempty"`
+}
+
+// AssertPromotionRequired checks if the required fields are not zero-ed
+func AssertPromotionRequired(obj Promotion) error {
+	elements := map[string]interface{}{
+		"code": obj.Code,
+		"type": obj.Type,
+	}
+	for name, el := range elements {
+		if isZero := common.IsZeroValue(el); isZero {
+			return &common.RequiredError{Field: name}
+		}
+	}
+
+	switch obj.Type {
+	case "percentage":
+		if obj.PercentOff == "" {
+			return &common.RequiredError{Field: "percent_off"}
+		}
+	case "fixed_amount":
+		if obj.AmountOff == nil {
+			return &common.RequiredError{Field: "amount_off"}
+		}
+	case "buy_x_get_y":
+		if obj.BuyQuantity == 0 {
+			return &common.RequiredError{Field: "buy_quantity"}
+		}
+		if obj.GetQuantity == 0 {
+			return &common.RequiredError{Field: "get_quantity"}
+		}
+	}
+	return nil
+}
+
+// AssertPromotionConstraints checks if the values respects the defined constraints
+func AssertPromotionConstraints(obj Promotion) error {
+	switch obj.Type {
+	case "percentage":
+		percent, err := common.ParseRate(obj.PercentOff)
+		if err != nil || percent.Cmp(big.NewRat(100, 1)) > 0 {
+			return &common.ParsingError{Param: "percent_off", Err: errors.New("must be greater than 0 and at most 100")}
+		}
+	case "fixed_amount":
+		if obj.AmountOff.Amount <= 0 {
+			return &common.ParsingError{Param: "amount_off", Err: errors.New("must be greater than 0")}
+		}
+	case "buy_x_get_y":
+		if obj.BuyQuantity < 1 || obj.GetQuantity < 1 {
+			return &common.ParsingError{Param: "buy_quantity", Err: errors.New("buy and get quantities must be at least 1")}
+		}
+	default:
+		return &common.ParsingError{Param: "type", Err: errors.New("must be one of percentage, fixed_amount, buy_x_get_y")}
+	}
+
+	var startsAt, endsAt time.Time
+	for param, value := range map[string]string{"starts_at": obj.StartsAt, "ends_at": obj.EndsAt} {
+		if value == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return &common.ParsingError{Param: param, Err: err}
+		}
+		if param == "starts_at" {
+			startsAt = t
+		} else {
+			endsAt = t
+		}
+	}
+	if !startsAt.IsZero() && !endsAt.IsZero() && !endsAt.After(startsAt) {
+		return &common.ParsingError{Param: "ends_at", Err: errors.New("must be after starts_at")}
+	}
+	if obj.UsageLimitPerCustomer < 0 {
+		return &common.ParsingError{Param: "usage_limit_per_customer", Err: errors.New("cannot be negative")}
+	}
+	return nil
+}
@@ -0,0 +1,180 @@
+package service
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/mayureshucsb2019/bookstore/service/common"
+	"github.com/mayureshucsb2019/bookstore/service/promotion/models"
+)
+
+// DefaultAPIController binds http requests to an api service and writes the service results to the http response
+type DefaultAPIController struct {
+	service      DefaultAPIServicer
+	errorHandler common.ErrorHandler
+}
+
+// DefaultAPIOption for how the controller is set up.
+type DefaultAPIOption func(*DefaultAPIController)
+
+// WithDefaultAPIErrorHandler inject ErrorHandler into controller
+func WithDefaultAPIErrorHandler(h common.ErrorHandler) DefaultAPIOption {
+	return func(c *DefaultAPIController) {
+		c.errorHandler = h
+	}
+}
+
+// NewDefaultAPIController creates a default api controller
+func NewDefaultAPIController(s DefaultAPIServicer, opts ...DefaultAPIOption) *DefaultAPIController {
+	controller := &DefaultAPIController{
+		service:      s,
+		errorHandler: common.DefaultErrorHandler,
+	}
+
+	for _, opt := range opts {
+		opt(controller)
+	}
+
+	return controller
+}
+
+// Routes returns all the api routes for the DefaultAPIController
+func (c *DefaultAPIController) Routes() common.Routes {
+	return common.Routes{
+		"AdminPromotionsCodeDelete": common.Route{
+			Method:      strings.ToUpper("Delete"),
+			Pattern:     "/admin/promotions/{code}",
+			HandlerFunc: c.AdminPromotionsCodeDelete,
+		},
+		"AdminPromotionsCodeGet": common.Route{
+			Method:      strings.ToUpper("Get"),
+			Pattern:     "/admin/promotions/{code}",
+			HandlerFunc: c.AdminPromotionsCodeGet,
+		},
+		"AdminPromotionsCodePatch": common.Route{
+			Method:      strings.ToUpper("Patch"),
+			Pattern:     "/admin/promotions/{code}",
+			HandlerFunc: c.AdminPromotionsCodePatch,
+		},
+		"AdminPromotionsGet": common.Route{
+			Method:      strings.ToUpper("Get"),
+			Pattern:     "/admin/promotions",
+			HandlerFunc: c.AdminPromotionsGet,
+		},
+		"AdminPromotionsPost": common.Route{
+			Method:      strings.ToUpper("Post"),
+			Pattern:     "/admin/promotions",
+			HandlerFunc: c.AdminPromotionsPost,
+		},
+	}
+}
+
+// AdminPromotionsCodeDelete - Delete a promotion by code
+func (c *DefaultAPIController) AdminPromotionsCodeDelete(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	codeParam := params["code"]
+	if codeParam == "" {
+		c.errorHandler(w, r, &common.RequiredError{Field: "code"}, nil)
+		return
+	}
+	result, err := c.service.AdminPromotionsCodeDelete(r.Context(), codeParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	_ = common.EncodeJSONResponse(result.Body, &result.Code, w)
+}
+
+// AdminPromotionsCodeGet - Get a specific promotion by code
+func (c *DefaultAPIController) AdminPromotionsCodeGet(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	codeParam := params["code"]
+	if codeParam == "" {
+		c.errorHandler(w, r, &common.RequiredError{Field: "code"}, nil)
+		return
+	}
+	result, err := c.service.AdminPromotionsCodeGet(r.Context(), codeParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	_ = common.EncodeJSONResponse(result.Body, &result.Code, w)
+}
+
+// AdminPromotionsCodePatch - Update a promotion by code
+func (c *DefaultAPIController) AdminPromotionsCodePatch(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	codeParam := params["code"]
+	if codeParam == "" {
+		c.errorHandler(w, r, &common.RequiredError{Field: "code"}, nil)
+		return
+	}
+	promotionParam := models.Promotion{}
+	d := json.NewDecoder(r.Body)
+	d.DisallowUnknownFields()
+	if err := d.Decode(&promotionParam); err != nil {
+		c.errorHandler(w, r, &common.ParsingError{Err: err}, nil)
+		return
+	}
+	if err := models.AssertPromotionRequired(promotionParam); err != nil {
+		c.errorHandler(w, r, err, nil)
+		return
+	}
+	if err := models.AssertPromotionConstraints(promotionParam); err != nil {
+		c.errorHandler(w, r, err, nil)
+		return
+	}
+	result, err := c.service.AdminPromotionsCodePatch(r.Context(), codeParam, promotionParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	_ = common.EncodeJSONResponse(result.Body, &result.Code, w)
+}
+
+// AdminPromotionsGet - Get a list of promotions
+func (c *DefaultAPIController) AdminPromotionsGet(w http.ResponseWriter, r *http.Request) {
+	result, err := c.service.AdminPromotionsGet(r.Context())
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	_ = common.EncodeJSONResponse(result.Body, &result.Code, w)
+}
+
+// AdminPromotionsPost - Add a new promotion
+func (c *DefaultAPIController) AdminPromotionsPost(w http.ResponseWriter, r *http.Request) {
+	promotionParam := models.Promotion{}
+	d := json.NewDecoder(r.Body)
+	d.DisallowUnknownFields()
+	if err := d.Decode(&promotionParam); err != nil {
+		c.errorHandler(w, r, &common.ParsingError{Err: err}, nil)
+		return
+	}
+	if err := models.AssertPromotionRequired(promotionParam); err != nil {
+		c.errorHandler(w, r, err, nil)
+		return
+	}
+	if err := models.AssertPromotionConstraints(promotionParam); err != nil {
+		c.errorHandler(w, r, err, nil)
+		return
+	}
+	result, err := c.service.AdminPromotionsPost(r.Context(), promotionParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	_ = common.EncodeJSONResponse(result.Body, &result.Code, w)
+}
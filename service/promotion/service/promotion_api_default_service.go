@@ -0,0 +1,175 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/mayureshucsb2019/bookstore/service/common"
+	"github.com/mayureshucsb2019/bookstore/service/promotion/db"
+	"github.com/mayureshucsb2019/bookstore/service/promotion/models"
+)
+
+// DefaultAPIService is a service that implements the logic for the DefaultAPIServicer
+// This service manages the promotions and coupon codes evaluated at checkout.
+type DefaultAPIService struct {
+	Repo *db.PromotionRepository
+}
+
+// NewDefaultAPIService creates a default API service with the given repository.
+func NewDefaultAPIService(repo *db.PromotionRepository) *DefaultAPIService {
+	return &DefaultAPIService{
+		Repo: repo,
+	}
+}
+
+// AdminPromotionsCodeDelete - Delete a promotion by code
+func (s *DefaultAPIService) AdminPromotionsCodeDelete(ctx context.Context, code string) (common.ImplResponse, error) {
+	if err := s.Repo.DeletePromotion(code); err != nil {
+		return promotionErrorResponse(err)
+	}
+
+	return common.Response(http.StatusNoContent, nil), nil
+}
+
+// AdminPromotionsCodeGet - Get a specific promotion by code
+func (s *DefaultAPIService) AdminPromotionsCodeGet(ctx context.Context, code string) (common.ImplResponse, error) {
+	promotion, err := s.Repo.GetPromotionByCode(code)
+	if err != nil {
+		return promotionErrorResponse(err)
+	}
+
+	return common.Response(http.StatusOK, convertDBToAPIResponse(*promotion)), nil
+}
+
+// AdminPromotionsCodePatch - Update a promotion by code
+func (s *DefaultAPIService) AdminPromotionsCodePatch(ctx context.Context, code string, promotion models.Promotion) (common.ImplResponse, error) {
+	if promotion.Code != code {
+		return common.Response(http.StatusBadRequest, nil), errors.New("code in the path does not match code in the body")
+	}
+
+	dbPromotion := convertApiToDBPromotion(promotion)
+	if err := s.Repo.UpdatePromotion(&dbPromotion); err != nil {
+		return promotionErrorResponse(err)
+	}
+
+	return common.Response(http.StatusOK, nil), nil
+}
+
+// AdminPromotionsGet - Get a list of promotions
+func (s *DefaultAPIService) AdminPromotionsGet(ctx context.Context) (common.ImplResponse, error) {
+	promotions, err := s.Repo.GetAllPromotions()
+	if err != nil {
+		return common.Response(http.StatusInternalServerError, nil), err
+	}
+	var promotionResp []models.Promotion
+	for _, promotion := range promotions {
+		promotionResp = append(promotionResp, convertDBToAPIResponse(promotion))
+	}
+
+	return common.Response(http.StatusOK, promotionResp), nil
+}
+
+// AdminPromotionsPost - Add a new promotion
+func (s *DefaultAPIService) AdminPromotionsPost(ctx context.Context, promotion models.Promotion) (common.ImplResponse, error) {
+	dbPromotion := convertApiToDBPromotion(promotion)
+	if err := s.Repo.CreatePromotion(&dbPromotion); err != nil {
+		return common.Response(http.StatusInternalServerError, nil), err
+	}
+
+	return common.Response(http.StatusCreated, nil), nil
+}
+
+// promotionErrorResponse maps repository errors to a not found or internal error response.
+func promotionErrorResponse(err error) (common.ImplResponse, error) {
+	if errors.Is(err, db.ErrPromotionNotFound) {
+		return common.Response(http.StatusNotFound, nil), err
+	}
+	return common.Response(http.StatusInternalServerError, nil), err
+}
+
+// convertApiToDBPromotion converts an API model Promotion to a database model Promotion.
+// Validity windows are stored in UTC.
+func convertApiToDBPromotion(promotion models.Promotion) db.Promotion {
+	amountOff := common.NewMoney(0, common.DefaultCurrency)
+	if promotion.AmountOff != nil {
+		amountOff = *promotion.AmountOff
+	}
+	active := promotion.Active == nil || *promotion.Active
+
+	return db.Promotion{
+		Code:                  promotion.Code,
+		Description:           promotion.Description,
+		Type:                  promotion.Type,
+		PercentOff:            promotion.PercentOff,
+		AmountOff:             amountOff,
+		BuyQuantity:           int(promotion.BuyQuantity),
+		GetQuantity:           int(promotion.GetQuantity),
+		Tag:                   promotion.Tag,
+		StartsAt:              toDBTime(promotion.StartsAt),
+		EndsAt:                toDBTime(promotion.EndsAt),
+		UsageLimitPerCustomer: int(promotion.UsageLimitPerCustomer),
+		Stackable:             promotion.Stackable,
+		Priority:              int(promotion.Priority),
+		AutoApply:             promotion.AutoApply,
+		Active:                active,
+	}
+}
+
+// convertDBToAPIResponse converts the DB model to the API model
+func convertDBToAPIResponse(promotion db.Promotion) models.Promotion {
+	active := promotion.Active
+	resp := models.Promotion{
+		Code:                  promotion.Code,
+		Description:           promotion.Description,
+		Type:                  promotion.Type,
+		PercentOff:            trimDecimal(promotion.PercentOff),
+		BuyQuantity:           int32(promotion.BuyQuantity),
+		GetQuantity:           int32(promotion.GetQuantity),
+		Tag:                   promotion.Tag,
+		StartsAt:              fromDBTime(promotion.StartsAt),
+		EndsAt:                fromDBTime(promotion.EndsAt),
+		UsageLimitPerCustomer: int32(promotion.UsageLimitPerCustomer),
+		Stackable:             promotion.Stackable,
+		Priority:              int32(promotion.Priority),
+		AutoApply:             promotion.AutoApply,
+		Active:                &active,
+	}
+	if promotion.Type == db.TypeFixedAmount {
+		amountOff := promotion.AmountOff
+		resp.AmountOff = &amountOff
+	}
+	return resp
+}
+
+// toDBTime converts an RFC 3339 timestamp to the UTC DATETIME layout, or NULL when empty.
+func toDBTime(value string) sql.NullString {
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return sql.NullString{Valid: false}
+	}
+	return sql.NullString{String: t.UTC().Format(DBTimeLayout), Valid: true}
+}
+
+// fromDBTime converts a UTC DATETIME value to RFC 3339, or an empty string when NULL.
+func fromDBTime(value sql.NullString) string {
+	if !value.Valid {
+		return ""
+	}
+	t, err := time.Parse(DBTimeLayout, value.String)
+	if err != nil {
+		return value.String
+	}
+	return t.Format(time.RFC3339)
+}
+
+// trimDecimal drops the trailing zeros the DECIMAL column pads percentages with, e.g. "20.00" to "20".
+func trimDecimal(value string) string {
+	if !strings.Contains(value, ".") {
+		return value
+	}
+	return strings.TrimRight(strings.TrimRight(value, "0"), ".")
+}
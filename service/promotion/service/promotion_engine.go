@@ -0,0 +1,241 @@
+package service
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/mayureshucsb2019/bookstore/service/common"
+	"github.com/mayureshucsb2019/bookstore/service/promotion/db"
+)
+
+// DBTimeLayout is the layout of the DATETIME columns holding promotion validity windows in UTC.
+const DBTimeLayout = "2006-01-02 15:04:05"
+
+// Line is a priced order line the promotion engine evaluates.
+type Line struct {
+	ISBN      string
+	Tags      []string
+	Quantity  int
+	UnitPrice common.Money
+}
+
+// Discount is one entry of the itemized discount breakdown, attributed to the line it reduces.
+type Discount struct {
+	Code        string       `json:"code"`
+	Description string       `json:"description,omitempty"`
+	Isbn        string       `json:"isbn"`
+	Amount      common.Money `json:"amount"`
+}
+
+// Rejection explains why a coupon code the customer entered was not applied.
+type Rejection struct {
+	Code   string `json:"code"`
+	Reason string `json:"reason"`
+}
+
+// Evaluation is the result of running the promotion rules against an order.
+type Evaluation struct {
+	Discounts []Discount   `json:"discounts"`
+	Total     common.Money `json:"total"`
+	Rejected  []Rejection  `json:"rejected,omitempty"`
+}
+
+// Evaluate applies the promotions to the lines, which must all be priced in currency.
+//
+// Promotions are considered in priority order (highest first, then by code). A promotion that
+// is not stackable is only applied when nothing else has been applied yet, and once applied it
+// excludes every later promotion. Each discount is capped by what is left to pay on the lines it
+// targets, so the discounted total never goes below zero. usage holds how many orders the
+// customer has already used each code on. requested lists the codes the customer entered;
+// rejections are only reported for those, not for auto-applied promotions.
+func Evaluate(promotions []db.Promotion, lines []Line, usage map[string]int, requested []string, currency string, now time.Time) (*Evaluation, error) {
+	evaluation := &Evaluation{Discounts: []Discount{}, Total: common.Money{Currency: currency}}
+
+	wanted := map[string]bool{}
+	for _, code := range requested {
+		wanted[code] = true
+	}
+	reject := func(code, reason string) {
+		if wanted[code] {
+			evaluation.Rejected = append(evaluation.Rejected, Rejection{Code: code, Reason: reason})
+		}
+	}
+
+	// remaining tracks what is still payable on each line after earlier discounts
+	remaining := make([]int64, len(lines))
+	for i, line := range lines {
+		if line.UnitPrice.Currency != currency {
+			return nil, fmt.Errorf("%w: line %s is priced in %s", common.ErrCurrencyMismatch, line.ISBN, line.UnitPrice.Currency)
+		}
+		remaining[i] = line.UnitPrice.Amount * int64(line.Quantity)
+	}
+
+	sorted := make([]db.Promotion, len(promotions))
+	copy(sorted, promotions)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Priority != sorted[j].Priority {
+			return sorted[i].Priority > sorted[j].Priority
+		}
+		return sorted[i].Code < sorted[j].Code
+	})
+
+	seen := map[string]bool{}
+	exclusive := false
+	for _, promotion := range sorted {
+		if seen[promotion.Code] {
+			continue
+		}
+		seen[promotion.Code] = true
+
+		if reason := checkEligibility(promotion, usage, now); reason != "" {
+			reject(promotion.Code, reason)
+			continue
+		}
+		if exclusive {
+			reject(promotion.Code, "cannot be combined with other promotions")
+			continue
+		}
+		if !promotion.Stackable && len(evaluation.Discounts) > 0 {
+			reject(promotion.Code, "cannot be combined with other promotions")
+			continue
+		}
+
+		discounts, err := applyPromotion(promotion, lines, remaining, currency)
+		if err != nil {
+			reject(promotion.Code, err.Error())
+			continue
+		}
+		if len(discounts) == 0 {
+			reject(promotion.Code, "no items in the order qualify")
+			continue
+		}
+
+		evaluation.Discounts = append(evaluation.Discounts, discounts...)
+		if !promotion.Stackable {
+			exclusive = true
+		}
+	}
+
+	for _, discount := range evaluation.Discounts {
+		total, err := evaluation.Total.Add(discount.Amount)
+		if err != nil {
+			return nil, err
+		}
+		evaluation.Total = total
+	}
+
+	return evaluation, nil
+}
+
+// checkEligibility returns why a promotion cannot be used, or an empty string if it can.
+func checkEligibility(promotion db.Promotion, usage map[string]int, now time.Time) string {
+	if !promotion.Active {
+		return "promotion is not active"
+	}
+	now = now.UTC()
+	if promotion.StartsAt.Valid {
+		startsAt, err := time.Parse(DBTimeLayout, promotion.StartsAt.String)
+		if err == nil && now.Before(startsAt) {
+			return "promotion has not started yet"
+		}
+	}
+	if promotion.EndsAt.Valid {
+		endsAt, err := time.Parse(DBTimeLayout, promotion.EndsAt.String)
+		if err == nil && !now.Before(endsAt) {
+			return "promotion has expired"
+		}
+	}
+	if promotion.UsageLimitPerCustomer > 0 && usage[promotion.Code] >= promotion.UsageLimitPerCustomer {
+		return "usage limit reached"
+	}
+	return ""
+}
+
+// applyPromotion computes the discounts a single promotion grants and deducts them from remaining.
+func applyPromotion(promotion db.Promotion, lines []Line, remaining []int64, currency string) ([]Discount, error) {
+	eligible := make([]int, 0, len(lines))
+	for i, line := range lines {
+		if remaining[i] > 0 && (promotion.Tag == "" || hasTag(line.Tags, promotion.Tag)) {
+			eligible = append(eligible, i)
+		}
+	}
+
+	var discounts []Discount
+	addDiscount := func(i int, amount int64) {
+		if amount > remaining[i] {
+			amount = remaining[i]
+		}
+		if amount <= 0 {
+			return
+		}
+		remaining[i] -= amount
+		discounts = append(discounts, Discount{
+			Code:        promotion.Code,
+			Description: promotion.Description,
+			Isbn:        lines[i].ISBN,
+			Amount:      common.NewMoney(amount, currency),
+		})
+	}
+
+	switch promotion.Type {
+	case db.TypePercentage:
+		percent, err := common.ParseRate(promotion.PercentOff)
+		if err != nil || percent.Cmp(big.NewRat(100, 1)) > 0 {
+			return nil, fmt.Errorf("invalid percentage %q", promotion.PercentOff)
+		}
+		fraction := new(big.Rat).Quo(percent, big.NewRat(100, 1))
+		for _, i := range eligible {
+			amount, err := common.ConvertMoney(common.NewMoney(remaining[i], currency), fraction, currency)
+			if err != nil {
+				return nil, err
+			}
+			addDiscount(i, amount.Amount)
+		}
+
+	case db.TypeFixedAmount:
+		if promotion.AmountOff.Currency != currency {
+			return nil, fmt.Errorf("only valid for orders in %s", promotion.AmountOff.Currency)
+		}
+		// The amount is taken off the eligible lines in order, so tag scoping and the
+		// zero floor are respected while the breakdown stays itemized
+		left := promotion.AmountOff.Amount
+		for _, i := range eligible {
+			if left <= 0 {
+				break
+			}
+			amount := left
+			if amount > remaining[i] {
+				amount = remaining[i]
+			}
+			addDiscount(i, amount)
+			left -= amount
+		}
+
+	case db.TypeBuyXGetY:
+		if promotion.BuyQuantity < 1 || promotion.GetQuantity < 1 {
+			return nil, fmt.Errorf("invalid buy %d get %d promotion", promotion.BuyQuantity, promotion.GetQuantity)
+		}
+		// Bundles are counted per book, e.g. buy 2 get 1 gives one free copy for every three of the same ISBN
+		bundle := promotion.BuyQuantity + promotion.GetQuantity
+		for _, i := range eligible {
+			free := (lines[i].Quantity / bundle) * promotion.GetQuantity
+			addDiscount(i, lines[i].UnitPrice.Amount*int64(free))
+		}
+
+	default:
+		return nil, fmt.Errorf("unsupported promotion type %q", promotion.Type)
+	}
+
+	return discounts, nil
+}
+
+func hasTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,224 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mayureshucsb2019/bookstore/service/common"
+	"github.com/mayureshucsb2019/bookstore/service/promotion/db"
+)
+
+func usd(amount int64) common.Money {
+	return common.NewMoney(amount, "USD")
+}
+
+// describe summarises discounts as "CODE ISBN amount" for comparison.
+func describe(discounts []Discount) string {
+	var parts []string
+	for _, discount := range discounts {
+		parts = append(parts, fmt.Sprintf("%s %s %s", discount.Code, discount.Isbn, discount.Amount.Decimal()))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func TestApplyPromotion(t *testing.T) {
+	lines := []Line{
+		{ISBN: "A", Tags: []string{"fiction"}, Quantity: 5, UnitPrice: usd(1000)},
+		{ISBN: "B", Tags: []string{"poetry"}, Quantity: 3, UnitPrice: usd(201)},
+		{ISBN: "C", Tags: []string{"fiction"}, Quantity: 2, UnitPrice: usd(1005)},
+	}
+
+	tests := []struct {
+		name      string
+		promotion db.Promotion
+		want      string
+		err       string
+	}{
+		{"percentage", db.Promotion{Code: "P", Type: db.TypePercentage, PercentOff: "10.00"},
+			"P A 5.00, P B 0.60, P C 2.01", ""},
+		{"percentage ties round half to even", db.Promotion{Code: "P", Type: db.TypePercentage, PercentOff: "5", Tag: "fiction"},
+			"P A 2.50, P C 1.00", ""},
+		{"percentage at the 100 cap", db.Promotion{Code: "P", Type: db.TypePercentage, PercentOff: "100.00"},
+			"P A 50.00, P B 6.03, P C 20.10", ""},
+		{"percentage over the 100 cap", db.Promotion{Code: "P", Type: db.TypePercentage, PercentOff: "100.01"},
+			"", `invalid percentage "100.01"`},
+		{"percentage of zero", db.Promotion{Code: "P", Type: db.TypePercentage, PercentOff: "0"},
+			"", `invalid percentage "0"`},
+		{"fixed amount", db.Promotion{Code: "F", Type: db.TypeFixedAmount, AmountOff: usd(500)},
+			"F A 5.00", ""},
+		{"fixed amount spread over lines", db.Promotion{Code: "F", Type: db.TypeFixedAmount, AmountOff: usd(5100)},
+			"F A 50.00, F B 1.00", ""},
+		{"fixed amount floored at zero", db.Promotion{Code: "F", Type: db.TypeFixedAmount, AmountOff: usd(100000)},
+			"F A 50.00, F B 6.03, F C 20.10", ""},
+		{"fixed amount scoped to a tag", db.Promotion{Code: "F", Type: db.TypeFixedAmount, AmountOff: usd(5100), Tag: "fiction"},
+			"F A 50.00, F C 1.00", ""},
+		{"fixed amount in the wrong currency", db.Promotion{Code: "F", Type: db.TypeFixedAmount, AmountOff: common.NewMoney(500, "EUR")},
+			"", "only valid for orders in EUR"},
+		{"buy 2 get 1 per ISBN", db.Promotion{Code: "B", Type: db.TypeBuyXGetY, BuyQuantity: 2, GetQuantity: 1},
+			"B A 10.00, B B 2.01", ""},
+		{"buy 1 get 1", db.Promotion{Code: "B", Type: db.TypeBuyXGetY, BuyQuantity: 1, GetQuantity: 1},
+			"B A 20.00, B B 2.01, B C 10.05", ""},
+		{"buy 2 get 3", db.Promotion{Code: "B", Type: db.TypeBuyXGetY, BuyQuantity: 2, GetQuantity: 3},
+			"B A 30.00", ""},
+		{"buy 0", db.Promotion{Code: "B", Type: db.TypeBuyXGetY, BuyQuantity: 0, GetQuantity: 1},
+			"", "invalid buy 0 get 1 promotion"},
+		{"no eligible lines", db.Promotion{Code: "F", Type: db.TypeFixedAmount, AmountOff: usd(500), Tag: "cookery"},
+			"", ""},
+		{"unsupported type", db.Promotion{Code: "X", Type: "free_shipping"},
+			"", `unsupported promotion type "free_shipping"`},
+	}
+	for _, test := range tests {
+		remaining := []int64{5000, 603, 2010}
+		discounts, err := applyPromotion(test.promotion, lines, remaining, "USD")
+		if test.err != "" {
+			if err == nil || err.Error() != test.err {
+				t.Errorf("%s: applyPromotion() = %v, want %q", test.name, err, test.err)
+			}
+			if remaining[0] != 5000 || remaining[1] != 603 || remaining[2] != 2010 {
+				t.Errorf("%s: remaining changed to %v on error", test.name, remaining)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: applyPromotion() = %v", test.name, err)
+			continue
+		}
+		if got := describe(discounts); got != test.want {
+			t.Errorf("%s: applyPromotion() = %q, want %q", test.name, got, test.want)
+		}
+		var total int64
+		for i, amount := range []int64{5000, 603, 2010} {
+			total += amount - remaining[i]
+		}
+		for _, discount := range discounts {
+			total -= discount.Amount.Amount
+		}
+		if total != 0 {
+			t.Errorf("%s: remaining %v does not match the discounts", test.name, remaining)
+		}
+	}
+}
+
+func TestApplyPromotionAfterEarlierDiscounts(t *testing.T) {
+	lines := []Line{{ISBN: "A", Quantity: 3, UnitPrice: usd(1000)}}
+
+	// A free copy is not worth more than what is left to pay on the line
+	remaining := []int64{500}
+	discounts, err := applyPromotion(db.Promotion{Code: "B", Type: db.TypeBuyXGetY, BuyQuantity: 2, GetQuantity: 1}, lines, remaining, "USD")
+	if err != nil || describe(discounts) != "B A 5.00" || remaining[0] != 0 {
+		t.Errorf("applyPromotion() = %q, %v with %d left, want 5.00 off and nothing left", describe(discounts), err, remaining[0])
+	}
+
+	discounts, err = applyPromotion(db.Promotion{Code: "P", Type: db.TypePercentage, PercentOff: "50"}, lines, remaining, "USD")
+	if err != nil || len(discounts) != 0 {
+		t.Errorf("applyPromotion() on a paid off line = %q, %v, want no discount", describe(discounts), err)
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	now := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+	lines := []Line{
+		{ISBN: "A", Tags: []string{"fiction"}, Quantity: 3, UnitPrice: usd(1000)},
+		{ISBN: "B", Quantity: 1, UnitPrice: usd(2000)},
+	}
+	percent := func(code string, priority int, stackable bool) db.Promotion {
+		return db.Promotion{Code: code, Type: db.TypePercentage, PercentOff: "10", Priority: priority, Stackable: stackable, Active: true}
+	}
+	fixed := func(code string, priority int, stackable bool) db.Promotion {
+		return db.Promotion{Code: code, Type: db.TypeFixedAmount, AmountOff: usd(500), Priority: priority, Stackable: stackable, Active: true}
+	}
+	window := func(promotion db.Promotion, startsAt, endsAt string) db.Promotion {
+		promotion.StartsAt = sql.NullString{String: startsAt, Valid: startsAt != ""}
+		promotion.EndsAt = sql.NullString{String: endsAt, Valid: endsAt != ""}
+		return promotion
+	}
+	limited := func(promotion db.Promotion, limit int) db.Promotion {
+		promotion.UsageLimitPerCustomer = limit
+		return promotion
+	}
+	inactive := percent("OFF", 0, true)
+	inactive.Active = false
+
+	tests := []struct {
+		name       string
+		promotions []db.Promotion
+		usage      map[string]int
+		requested  []string
+		want       string
+		total      int64
+		rejected   string
+	}{
+		{"stackable promotions in priority order", []db.Promotion{fixed("FIVE", 1, true), percent("TEN", 2, true)}, nil, nil,
+			"TEN A 3.00, TEN B 2.00, FIVE A 5.00", 1000, ""},
+		{"the order changes the result", []db.Promotion{fixed("FIVE", 2, true), percent("TEN", 1, true)}, nil, nil,
+			"FIVE A 5.00, TEN A 2.50, TEN B 2.00", 950, ""},
+		{"equal priorities go by code", []db.Promotion{percent("TEN", 0, true), fixed("FIVE", 0, true)}, nil, nil,
+			"FIVE A 5.00, TEN A 2.50, TEN B 2.00", 950, ""},
+		{"exclusive first excludes the rest", []db.Promotion{fixed("FIVE", 1, true), percent("TEN", 2, false)}, nil, []string{"FIVE"},
+			"TEN A 3.00, TEN B 2.00", 500, "FIVE: cannot be combined with other promotions"},
+		{"exclusive after another is skipped", []db.Promotion{fixed("FIVE", 2, true), percent("TEN", 1, false)}, nil, []string{"TEN"},
+			"FIVE A 5.00", 500, "TEN: cannot be combined with other promotions"},
+		{"the first exclusive wins", []db.Promotion{percent("TEN", 1, false), fixed("FIVE", 2, false)}, nil, []string{"TEN", "FIVE"},
+			"FIVE A 5.00", 500, "TEN: cannot be combined with other promotions"},
+		{"usage below the limit", []db.Promotion{limited(fixed("ONCE", 0, true), 2)}, map[string]int{"ONCE": 1}, []string{"ONCE"},
+			"ONCE A 5.00", 500, ""},
+		{"usage limit reached", []db.Promotion{limited(fixed("ONCE", 0, true), 1)}, map[string]int{"ONCE": 1}, []string{"ONCE"},
+			"", 0, "ONCE: usage limit reached"},
+		{"usage of another customer's code", []db.Promotion{limited(fixed("ONCE", 0, true), 1)}, map[string]int{"OTHER": 5}, []string{"ONCE"},
+			"ONCE A 5.00", 500, ""},
+		{"unlimited usage", []db.Promotion{fixed("ANY", 0, true)}, map[string]int{"ANY": 100}, []string{"ANY"},
+			"ANY A 5.00", 500, ""},
+		{"limit reached excludes nothing else", []db.Promotion{limited(percent("ONCE", 2, false), 1), fixed("FIVE", 1, true)},
+			map[string]int{"ONCE": 1}, []string{"ONCE"}, "FIVE A 5.00", 500, "ONCE: usage limit reached"},
+		{"inactive", []db.Promotion{inactive}, nil, []string{"OFF"},
+			"", 0, "OFF: promotion is not active"},
+		{"not started", []db.Promotion{window(fixed("SOON", 0, true), "2026-06-01 12:00:01", "")}, nil, []string{"SOON"},
+			"", 0, "SOON: promotion has not started yet"},
+		{"started", []db.Promotion{window(fixed("NOW", 0, true), "2026-06-01 12:00:00", "2026-06-01 12:00:01")}, nil, []string{"NOW"},
+			"NOW A 5.00", 500, ""},
+		{"expired", []db.Promotion{window(fixed("OLD", 0, true), "", "2026-06-01 12:00:00")}, nil, []string{"OLD"},
+			"", 0, "OLD: promotion has expired"},
+		{"wrong currency", []db.Promotion{{Code: "EURO", Type: db.TypeFixedAmount, AmountOff: common.NewMoney(500, "EUR"), Active: true}},
+			nil, []string{"EURO"}, "", 0, "EURO: only valid for orders in EUR"},
+		{"nothing qualifies", []db.Promotion{{Code: "POEMS", Type: db.TypePercentage, PercentOff: "10", Tag: "poetry", Active: true}},
+			nil, []string{"POEMS"}, "", 0, "POEMS: no items in the order qualify"},
+		{"auto-applied promotions are not reported", []db.Promotion{limited(fixed("AUTO", 0, true), 1)}, map[string]int{"AUTO": 1}, nil,
+			"", 0, ""},
+		{"codes are considered once", []db.Promotion{fixed("FIVE", 0, true), fixed("FIVE", 0, true)}, nil, nil,
+			"FIVE A 5.00", 500, ""},
+		{"the total never exceeds the order", []db.Promotion{
+			{Code: "ALL", Type: db.TypePercentage, PercentOff: "100", Priority: 1, Stackable: true, Active: true}, fixed("FIVE", 0, true)},
+			nil, []string{"FIVE"}, "ALL A 30.00, ALL B 20.00", 5000, "FIVE: no items in the order qualify"},
+	}
+	for _, test := range tests {
+		evaluation, err := Evaluate(test.promotions, lines, test.usage, test.requested, "USD", now)
+		if err != nil {
+			t.Errorf("%s: Evaluate() = %v", test.name, err)
+			continue
+		}
+		if got := describe(evaluation.Discounts); got != test.want {
+			t.Errorf("%s: Evaluate() discounts = %q, want %q", test.name, got, test.want)
+		}
+		if evaluation.Total != usd(test.total) {
+			t.Errorf("%s: Evaluate() total = %v, want %v", test.name, evaluation.Total, usd(test.total))
+		}
+		var rejected []string
+		for _, rejection := range evaluation.Rejected {
+			rejected = append(rejected, rejection.Code+": "+rejection.Reason)
+		}
+		if got := strings.Join(rejected, "; "); got != test.rejected {
+			t.Errorf("%s: Evaluate() rejected %q, want %q", test.name, got, test.rejected)
+		}
+	}
+}
+
+func TestEvaluateLineCurrency(t *testing.T) {
+	lines := []Line{{ISBN: "A", Quantity: 1, UnitPrice: common.NewMoney(1000, "EUR")}}
+	if _, err := Evaluate(nil, lines, nil, nil, "USD", time.Now()); !errors.Is(err, common.ErrCurrencyMismatch) {
+		t.Errorf("Evaluate() with a line in EUR = %v, want ErrCurrencyMismatch", err)
+	}
+}
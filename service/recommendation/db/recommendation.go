@@ -0,0 +1,276 @@
+package db
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	_ "github.com/go-sql-driver/mysql"
+	"github.com/mayureshucsb2019/bookstore/service/common"
+)
+
+// Reasons a book is recommended, in order of preference
+const (
+	ReasonBoughtTogether = "bought_together" // Bought by the same customers
+	ReasonSameAuthor     = "same_author"
+	ReasonSimilarTags    = "similar_tags"
+	ReasonPopular        = "popular" // Among the most bought books; only for customers without purchases
+)
+
+var (
+	// ErrBookNotFound is returned when no book exists with the requested ISBN
+	ErrBookNotFound = errors.New("book not found")
+	// ErrCustomerNotFound is returned when no customer exists with the requested email
+	ErrCustomerNotFound = errors.New("customer not found")
+)
+
+// purchasedStatuses are the statuses of orders whose books count as bought: paid and not
+// cancelled, returned or refunded.
+const purchasedStatuses = `'paid', 'shipped', 'delivered', 'return_requested'`
+
+// Purchase is a book bought by a buyer, a customer or, for orders without one, the order itself.
+type Purchase struct {
+	Buyer string
+	ISBN  string
+}
+
+// BookAttributes are the tags and authors of a book, which recommendations fall back to.
+type BookAttributes struct {
+	ISBN      string
+	Tags      []string
+	AuthorIDs []string
+}
+
+// Neighbour is a book recommended with another, as stored in the BookRecommendations table.
+type Neighbour struct {
+	ISBN   string
+	Score  float64
+	Reason string
+}
+
+// Recommendation is a recommended book with its current price and stock.
+type Recommendation struct {
+	ISBN   string
+	Name   string
+	Cost   common.Money
+	Stock  int
+	Score  float64
+	Reason string
+}
+
+// RecommendationRepository provides access to the BookRecommendations storage and the purchases
+// and catalog it is computed from.
+type RecommendationRepository struct {
+	DB *sql.DB
+}
+
+// GetPurchases retrieves every book bought by every buyer, once per buyer and book.
+func (r *RecommendationRepository) GetPurchases() ([]Purchase, error) {
+	rows, err := r.DB.Query(`
+		SELECT DISTINCT COALESCE(CONCAT('customer:', o.customer_id), CONCAT('order:', o.id)), i.isbn
+		FROM OrderItems i JOIN Orders o ON o.id = i.order_id
+		WHERE o.status IN (` + purchasedStatuses + `)`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query purchases: %w", err)
+	}
+	defer rows.Close()
+
+	var purchases []Purchase
+	for rows.Next() {
+		var purchase Purchase
+		if err := rows.Scan(&purchase.Buyer, &purchase.ISBN); err != nil {
+			return nil, fmt.Errorf("failed to scan purchase: %w", err)
+		}
+		purchases = append(purchases, purchase)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+	return purchases, nil
+}
+
+// GetBookAttributes retrieves the tags and authors of every book.
+func (r *RecommendationRepository) GetBookAttributes() ([]BookAttributes, error) {
+	rows, err := r.DB.Query(`SELECT isbn, tags FROM Books ORDER BY isbn`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query books: %w", err)
+	}
+	defer rows.Close()
+
+	var books []BookAttributes
+	byISBN := make(map[string]int)
+	for rows.Next() {
+		var book BookAttributes
+		var tags sql.NullString
+		if err := rows.Scan(&book.ISBN, &tags); err != nil {
+			return nil, fmt.Errorf("failed to scan book: %w", err)
+		}
+		// Books with tags that are not a JSON array are recommended without them
+		if tags.Valid {
+			_ = json.Unmarshal([]byte(tags.String), &book.Tags)
+		}
+		byISBN[book.ISBN] = len(books)
+		books = append(books, book)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+
+	authorRows, err := r.DB.Query(`SELECT author_id, book_isbn FROM AuthorBook ORDER BY author_id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query book authors: %w", err)
+	}
+	defer authorRows.Close()
+
+	for authorRows.Next() {
+		var authorID, isbn string
+		if err := authorRows.Scan(&authorID, &isbn); err != nil {
+			return nil, fmt.Errorf("failed to scan book author: %w", err)
+		}
+		if i, ok := byISBN[isbn]; ok {
+			books[i].AuthorIDs = append(books[i].AuthorIDs, authorID)
+		}
+	}
+	if err := authorRows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+	return books, nil
+}
+
+// ReplaceRecommendations replaces the recommendations of every book in a single transaction, so
+// readers see either the previous or the new set. Neighbours are stored in the order given.
+func (r *RecommendationRepository) ReplaceRecommendations(neighbours map[string][]Neighbour) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM BookRecommendations`); err != nil {
+		return fmt.Errorf("failed to clear recommendations: %w", err)
+	}
+
+	const batchSize = 500
+	var values []string
+	var args []interface{}
+	flush := func() error {
+		if len(values) == 0 {
+			return nil
+		}
+		_, err := tx.Exec(`INSERT INTO BookRecommendations (isbn, recommended_isbn, position, score, reason) VALUES `+
+			strings.Join(values, ", "), args...)
+		values, args = values[:0], args[:0]
+		if err != nil {
+			return fmt.Errorf("failed to insert recommendations: %w", err)
+		}
+		return nil
+	}
+	for isbn, books := range neighbours {
+		for position, neighbour := range books {
+			values = append(values, `(?, ?, ?, ?, ?)`)
+			args = append(args, isbn, neighbour.ISBN, position+1, neighbour.Score, neighbour.Reason)
+			if len(values) == batchSize {
+				if err := flush(); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	if err := flush(); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// recommendationColumns are the columns read by queryRecommendations, in scan order, from
+// BookRecommendations r joined with the recommended Books b.
+const recommendationColumns = `b.isbn, b.name, b.cost, b.currency, b.stock`
+
+// GetBookRecommendations retrieves up to limit books recommended with a book, best first.
+func (r *RecommendationRepository) GetBookRecommendations(isbn string, limit int) ([]Recommendation, error) {
+	var exists int
+	if err := r.DB.QueryRow(`SELECT COUNT(*) FROM Books WHERE isbn = ?`, isbn).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("failed to look up book: %w", err)
+	}
+	if exists == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrBookNotFound, isbn)
+	}
+	return r.queryRecommendations(`
+		SELECT `+recommendationColumns+`, r.score, r.reason
+		FROM BookRecommendations r JOIN Books b ON b.isbn = r.recommended_isbn
+		WHERE r.isbn = ? ORDER BY r.position LIMIT ?`, isbn, limit)
+}
+
+// GetCustomerRecommendations retrieves up to limit books recommended to a customer, best first:
+// the neighbours of the books they bought, scored by their total score and leaving out books they
+// bought. Customers without purchases are recommended the most bought books.
+func (r *RecommendationRepository) GetCustomerRecommendations(email string, limit int) ([]Recommendation, error) {
+	var customerID int64
+	err := r.DB.QueryRow(`SELECT id FROM Customer WHERE email = ?`, email).Scan(&customerID)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("%w: %s", ErrCustomerNotFound, email)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up customer: %w", err)
+	}
+
+	purchased := `SELECT i.isbn FROM OrderItems i JOIN Orders o ON o.id = i.order_id
+		WHERE o.customer_id = ? AND o.status IN (` + purchasedStatuses + `)`
+	// MIN compares the reasons as strings, which happens to be their order of preference
+	recommendations, err := r.queryRecommendations(`
+		SELECT `+recommendationColumns+`, SUM(r.score) AS total, MIN(CAST(r.reason AS CHAR))
+		FROM BookRecommendations r JOIN Books b ON b.isbn = r.recommended_isbn
+		WHERE r.isbn IN (`+purchased+`) AND r.recommended_isbn NOT IN (`+purchased+`)
+		GROUP BY b.isbn, b.name, b.cost, b.currency, b.stock
+		ORDER BY total DESC, b.isbn LIMIT ?`, customerID, customerID, limit)
+	if err != nil || len(recommendations) > 0 {
+		return recommendations, err
+	}
+
+	var purchases int
+	if err := r.DB.QueryRow(`SELECT COUNT(*) FROM (`+purchased+`) p`, customerID).Scan(&purchases); err != nil {
+		return nil, fmt.Errorf("failed to count purchases: %w", err)
+	}
+	if purchases > 0 {
+		return recommendations, nil
+	}
+	return r.queryRecommendations(`
+		SELECT `+recommendationColumns+`, COUNT(DISTINCT o.id) AS orders, ?
+		FROM OrderItems i JOIN Orders o ON o.id = i.order_id JOIN Books b ON b.isbn = i.isbn
+		WHERE o.status IN (`+purchasedStatuses+`)
+		GROUP BY b.isbn, b.name, b.cost, b.currency, b.stock
+		ORDER BY orders DESC, b.isbn LIMIT ?`, ReasonPopular, limit)
+}
+
+func (r *RecommendationRepository) queryRecommendations(query string, args ...interface{}) ([]Recommendation, error) {
+	rows, err := r.DB.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query recommendations: %w", err)
+	}
+	defer rows.Close()
+
+	recommendations := []Recommendation{}
+	for rows.Next() {
+		var recommendation Recommendation
+		var cost sql.NullString
+		var currency string
+		err := rows.Scan(&recommendation.ISBN, &recommendation.Name, &cost, &currency, &recommendation.Stock,
+			&recommendation.Score, &recommendation.Reason)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan recommendation: %w", err)
+		}
+		recommendation.Cost = common.NewMoney(0, currency)
+		if cost.Valid {
+			if recommendation.Cost, err = common.ParseMoney(cost.String, currency); err != nil {
+				return nil, err
+			}
+		}
+		recommendations = append(recommendations, recommendation)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+	return recommendations, nil
+}
@@ -0,0 +1,19 @@
+package db
+
+import (
+	"sync"
+
+	"github.com/mayureshucsb2019/bookstore/service/common"
+)
+
+var recommendationRepoInstance *RecommendationRepository
+var recommendationRepoOnce sync.Once
+
+func NewRecommendationRepository(db *common.DBConnection) *RecommendationRepository {
+	recommendationRepoOnce.Do(func() {
+		recommendationRepoInstance = &RecommendationRepository{
+			DB: db.DB,
+		}
+	})
+	return recommendationRepoInstance
+}
@@ -0,0 +1,200 @@
+// Package recommendation computes the books recommended with each book from what customers
+// bought together, falling back to books by the same authors and with the same tags.
+package recommendation
+
+import (
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/mayureshucsb2019/bookstore/service/recommendation/db"
+)
+
+// Scores of the fallbacks are scaled down, so books bought together weigh more when the
+// neighbours of several books are combined for a customer.
+const (
+	sameAuthorWeight  = 0.5
+	similarTagsWeight = 0.25
+)
+
+// Report sums up a run of the job.
+type Report struct {
+	Books          int // Books in the catalog
+	Recommended    int // Books with at least one neighbour
+	BoughtTogether int // Neighbours of each reason
+	SameAuthor     int
+	SimilarTags    int
+}
+
+// Job recomputes the recommendations of every book. It reads all purchases and the whole
+// catalog, so it is meant to run offline, e.g. nightly.
+type Job struct {
+	Repo *db.RecommendationRepository
+
+	TopN      int // Neighbours kept per book
+	MinBuyers int // Customers who must have bought two books for them to count as bought together
+}
+
+// NewJob creates a job keeping 10 neighbours per book.
+func NewJob(repo *db.RecommendationRepository) *Job {
+	return &Job{
+		Repo:      repo,
+		TopN:      10,
+		MinBuyers: 1,
+	}
+}
+
+// Run computes the neighbours of every book and replaces the stored recommendations with them.
+func (j *Job) Run() (Report, error) {
+	purchases, err := j.Repo.GetPurchases()
+	if err != nil {
+		return Report{}, err
+	}
+	books, err := j.Repo.GetBookAttributes()
+	if err != nil {
+		return Report{}, err
+	}
+
+	neighbours := Compute(purchases, books, j.TopN, j.MinBuyers)
+	report := Report{Books: len(books), Recommended: len(neighbours)}
+	for _, list := range neighbours {
+		for _, neighbour := range list {
+			switch neighbour.Reason {
+			case db.ReasonBoughtTogether:
+				report.BoughtTogether++
+			case db.ReasonSameAuthor:
+				report.SameAuthor++
+			case db.ReasonSimilarTags:
+				report.SimilarTags++
+			}
+		}
+	}
+	return report, j.Repo.ReplaceRecommendations(neighbours)
+}
+
+// Compute returns up to topN neighbours of every book, best first, leaving out books without any.
+// Books bought together by at least minBuyers customers come first, scored by the cosine
+// similarity of their buyers. Remaining places go to books sharing authors, then tags, scored by
+// the Jaccard index of their authors or tags.
+func Compute(purchases []db.Purchase, books []db.BookAttributes, topN int, minBuyers int) map[string][]db.Neighbour {
+	buyers := make(map[string]int)              // Buyers of each book
+	baskets := make(map[string][]string)        // Books of each buyer
+	together := make(map[string]map[string]int) // Buyers of both books, by book and book
+	for _, purchase := range purchases {
+		buyers[purchase.ISBN]++
+		baskets[purchase.Buyer] = append(baskets[purchase.Buyer], purchase.ISBN)
+	}
+	for _, basket := range baskets {
+		for _, a := range basket {
+			for _, b := range basket {
+				if a == b {
+					continue
+				}
+				if together[a] == nil {
+					together[a] = make(map[string]int)
+				}
+				together[a][b]++
+			}
+		}
+	}
+
+	authors := make(map[string][]string) // Books of each author
+	tags := make(map[string][]string)    // Books with each tag
+	attributes := make(map[string]db.BookAttributes)
+	for _, book := range books {
+		book.Tags = normalizeTags(book.Tags)
+		attributes[book.ISBN] = book
+		for _, author := range book.AuthorIDs {
+			authors[author] = append(authors[author], book.ISBN)
+		}
+		for _, tag := range book.Tags {
+			tags[tag] = append(tags[tag], book.ISBN)
+		}
+	}
+
+	neighbours := make(map[string][]db.Neighbour)
+	for _, book := range books {
+		chosen := map[string]bool{book.ISBN: true}
+		var list []db.Neighbour
+		add := func(candidates []db.Neighbour) {
+			sortNeighbours(candidates)
+			for _, candidate := range candidates {
+				if len(list) == topN {
+					return
+				}
+				if !chosen[candidate.ISBN] {
+					chosen[candidate.ISBN] = true
+					list = append(list, candidate)
+				}
+			}
+		}
+
+		var candidates []db.Neighbour
+		for other, count := range together[book.ISBN] {
+			if _, ok := attributes[other]; !ok || count < minBuyers {
+				continue
+			}
+			score := float64(count) / math.Sqrt(float64(buyers[book.ISBN]*buyers[other]))
+			candidates = append(candidates, db.Neighbour{ISBN: other, Score: score, Reason: db.ReasonBoughtTogether})
+		}
+		add(candidates)
+
+		if len(list) < topN {
+			add(similar(book.ISBN, book.AuthorIDs, authors, func(isbn string) []string { return attributes[isbn].AuthorIDs },
+				sameAuthorWeight, db.ReasonSameAuthor))
+		}
+		if len(list) < topN {
+			add(similar(book.ISBN, attributes[book.ISBN].Tags, tags, func(isbn string) []string { return attributes[isbn].Tags },
+				similarTagsWeight, db.ReasonSimilarTags))
+		}
+		if len(list) > 0 {
+			neighbours[book.ISBN] = list
+		}
+	}
+	return neighbours
+}
+
+// similar returns the books sharing any of values with isbn, found through index, scored by the
+// Jaccard index of their values times weight.
+func similar(isbn string, values []string, index map[string][]string, valuesOf func(isbn string) []string,
+	weight float64, reason string) []db.Neighbour {
+	shared := make(map[string]int)
+	for _, value := range values {
+		for _, other := range index[value] {
+			if other != isbn {
+				shared[other]++
+			}
+		}
+	}
+	var candidates []db.Neighbour
+	for other, count := range shared {
+		union := len(values) + len(valuesOf(other)) - count
+		candidates = append(candidates, db.Neighbour{ISBN: other, Score: weight * float64(count) / float64(union), Reason: reason})
+	}
+	return candidates
+}
+
+// sortNeighbours orders neighbours by descending score, then ISBN so runs are repeatable.
+func sortNeighbours(neighbours []db.Neighbour) {
+	sort.Slice(neighbours, func(i, j int) bool {
+		if neighbours[i].Score != neighbours[j].Score {
+			return neighbours[i].Score > neighbours[j].Score
+		}
+		return neighbours[i].ISBN < neighbours[j].ISBN
+	})
+}
+
+// normalizeTags lower-cases and trims tags, dropping empty and repeated ones, so "Sci-Fi " and
+// "sci-fi" match.
+func normalizeTags(tags []string) []string {
+	seen := make(map[string]bool)
+	var normalized []string
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag != "" && !seen[tag] {
+			seen[tag] = true
+			normalized = append(normalized, tag)
+		}
+	}
+	return normalized
+}
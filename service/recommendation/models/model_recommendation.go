@@ -0,0 +1,18 @@
+package models
+
+import "github.com/mayureshucsb2019/bookstore/service/common"
+
+// Recommendation is a recommended book, with its current price and stock and why it is recommended.
+type Recommendation struct {
+	Isbn string `json:"isbn"`
+
+	Name string `json:"name"`
+
+	Cost common.Money `json:"cost"`
+
+	Stock int32 `json:"stock"`
+
+	Score float64 `json:"score"`
+
+	Reason string `json:"reason"`
+}
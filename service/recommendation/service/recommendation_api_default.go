@@ -0,0 +1,137 @@
+package service
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/mayureshucsb2019/bookstore/service/common"
+)
+
+// DefaultAPIController binds http requests to an api service and writes the service results to the http response
+type DefaultAPIController struct {
+	service      DefaultAPIServicer
+	errorHandler common.ErrorHandler
+}
+
+// DefaultAPIOption for how the controller is set up.
+type DefaultAPIOption func(*DefaultAPIController)
+
+// WithDefaultAPIErrorHandler inject ErrorHandler into controller
+func WithDefaultAPIErrorHandler(h common.ErrorHandler) DefaultAPIOption {
+	return func(c *DefaultAPIController) {
+		c.errorHandler = h
+	}
+}
+
+// NewDefaultAPIController creates a default api controller
+func NewDefaultAPIController(s DefaultAPIServicer, opts ...DefaultAPIOption) *DefaultAPIController {
+	controller := &DefaultAPIController{
+		service:      s,
+		errorHandler: common.DefaultErrorHandler,
+	}
+
+	for _, opt := range opts {
+		opt(controller)
+	}
+
+	return controller
+}
+
+// Routes returns all the api routes for the DefaultAPIController
+func (c *DefaultAPIController) Routes() common.Routes {
+	return common.Routes{
+		"BooksIsbnRecommendationsGet": common.Route{
+			Method:      strings.ToUpper("Get"),
+			Pattern:     "/books/{isbn}/recommendations",
+			HandlerFunc: c.BooksIsbnRecommendationsGet,
+		},
+		"CustomersEmailRecommendationsGet": common.Route{
+			Method:      strings.ToUpper("Get"),
+			Pattern:     "/customers/{email}/recommendations",
+			HandlerFunc: c.CustomersEmailRecommendationsGet,
+		},
+	}
+}
+
+// BooksIsbnRecommendationsGet - Get the books customers who bought a book also bought
+func (c *DefaultAPIController) BooksIsbnRecommendationsGet(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	query, err := common.ParseQuery(r.URL.RawQuery)
+	if err != nil {
+		c.errorHandler(w, r, &common.ParsingError{Err: err}, nil)
+		return
+	}
+	isbnParam := params["isbn"]
+	if isbnParam == "" {
+		c.errorHandler(w, r, &common.RequiredError{Field: "isbn"}, nil)
+		return
+	}
+	var limitParam int32
+	if query.Has("limit") {
+		param, err := common.ParseNumericParameter[int32](
+			query.Get("limit"),
+			common.WithParse[int32](common.ParseInt32),
+			common.WithMinimum[int32](1),
+			common.WithMaximum[int32](50),
+		)
+		if err != nil {
+			c.errorHandler(w, r, &common.ParsingError{Param: "limit", Err: err}, nil)
+			return
+		}
+
+		limitParam = param
+	} else {
+		var param int32 = 10
+		limitParam = param
+	}
+	result, err := c.service.BooksIsbnRecommendationsGet(r.Context(), isbnParam, limitParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	_ = common.EncodeJSONResponse(result.Body, &result.Code, w)
+}
+
+// CustomersEmailRecommendationsGet - Get the books recommended to a customer
+func (c *DefaultAPIController) CustomersEmailRecommendationsGet(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	query, err := common.ParseQuery(r.URL.RawQuery)
+	if err != nil {
+		c.errorHandler(w, r, &common.ParsingError{Err: err}, nil)
+		return
+	}
+	emailParam := params["email"]
+	if emailParam == "" {
+		c.errorHandler(w, r, &common.RequiredError{Field: "email"}, nil)
+		return
+	}
+	var limitParam int32
+	if query.Has("limit") {
+		param, err := common.ParseNumericParameter[int32](
+			query.Get("limit"),
+			common.WithParse[int32](common.ParseInt32),
+			common.WithMinimum[int32](1),
+			common.WithMaximum[int32](50),
+		)
+		if err != nil {
+			c.errorHandler(w, r, &common.ParsingError{Param: "limit", Err: err}, nil)
+			return
+		}
+
+		limitParam = param
+	} else {
+		var param int32 = 10
+		limitParam = param
+	}
+	result, err := c.service.CustomersEmailRecommendationsGet(r.Context(), emailParam, limitParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	_ = common.EncodeJSONResponse(result.Body, &result.Code, w)
+}
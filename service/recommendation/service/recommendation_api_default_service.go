@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/mayureshucsb2019/bookstore/service/common"
+	"github.com/mayureshucsb2019/bookstore/service/recommendation/db"
+	"github.com/mayureshucsb2019/bookstore/service/recommendation/models"
+)
+
+// DefaultAPIService is a service that implements the logic for the DefaultAPIServicer
+// This service serves the recommendations computed by the compute-recommendations job.
+type DefaultAPIService struct {
+	Repo *db.RecommendationRepository
+}
+
+// NewDefaultAPIService creates a default API service with the given repository.
+func NewDefaultAPIService(repo *db.RecommendationRepository) *DefaultAPIService {
+	return &DefaultAPIService{
+		Repo: repo,
+	}
+}
+
+// BooksIsbnRecommendationsGet - Get the books customers who bought a book also bought
+func (s *DefaultAPIService) BooksIsbnRecommendationsGet(ctx context.Context, isbn string, limit int32) (common.ImplResponse, error) {
+	recommendations, err := s.Repo.GetBookRecommendations(isbn, int(limit))
+	if err != nil {
+		return recommendationErrorResponse(err)
+	}
+
+	return common.Response(http.StatusOK, convertDBToAPIRecommendations(recommendations)), nil
+}
+
+// CustomersEmailRecommendationsGet - Get the books recommended to a customer
+func (s *DefaultAPIService) CustomersEmailRecommendationsGet(ctx context.Context, email string, limit int32) (common.ImplResponse, error) {
+	recommendations, err := s.Repo.GetCustomerRecommendations(email, int(limit))
+	if err != nil {
+		return recommendationErrorResponse(err)
+	}
+
+	return common.Response(http.StatusOK, convertDBToAPIRecommendations(recommendations)), nil
+}
+
+// recommendationErrorResponse maps repository errors to their response status, or an internal error.
+func recommendationErrorResponse(err error) (common.ImplResponse, error) {
+	if errors.Is(err, db.ErrBookNotFound) || errors.Is(err, db.ErrCustomerNotFound) {
+		return common.Response(http.StatusNotFound, nil), err
+	}
+	return common.Response(http.StatusInternalServerError, nil), err
+}
+
+// convertDBToAPIRecommendations converts the DB models to the API models
+func convertDBToAPIRecommendations(recommendations []db.Recommendation) []models.Recommendation {
+	resp := []models.Recommendation{}
+	for _, recommendation := range recommendations {
+		resp = append(resp, models.Recommendation{
+			Isbn:   recommendation.ISBN,
+			Name:   recommendation.Name,
+			Cost:   recommendation.Cost,
+			Stock:  int32(recommendation.Stock),
+			Score:  recommendation.Score,
+			Reason: recommendation.Reason,
+		})
+	}
+	return resp
+}
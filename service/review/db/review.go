@@ -0,0 +1,334 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	_ "github.com/go-sql-driver/mysql"
+)
+
+// Moderation statuses. Reviews are pending until a moderator approves or rejects them; only
+// approved reviews are shown and counted in the rating of a book.
+const (
+	StatusPending  = "pending"
+	StatusApproved = "approved"
+	StatusRejected = "rejected"
+)
+
+var (
+	// ErrReviewNotFound is returned when the customer has not reviewed the book, or no review
+	// exists with the requested id
+	ErrReviewNotFound = errors.New("review not found")
+	// ErrReviewExists is returned when the customer has already reviewed the book
+	ErrReviewExists = errors.New("customer has already reviewed the book")
+	// ErrBookNotFound is returned when no book exists with the requested ISBN
+	ErrBookNotFound = errors.New("book not found")
+	// ErrCustomerNotFound is returned when no customer exists with the requested email
+	ErrCustomerNotFound = errors.New("customer not found")
+	// ErrCustomerBlocked is returned when a suspended or closed customer writes a review
+	ErrCustomerBlocked = errors.New("customer cannot write reviews")
+)
+
+// Review represents the structure of a BookReviews record in the database.
+type Review struct {
+	ID             int64
+	ISBN           string
+	CustomerEmail  string
+	ReviewerName   string // First name and initial of the last name of the customer, shown with the review
+	Rating         int    // 1 to 5 stars
+	Title          sql.NullString
+	Text           sql.NullString
+	Status         string
+	ModerationNote sql.NullString
+	CreatedAt      string
+	UpdatedAt      string
+
+	// The customer bought the book: an order of theirs containing it was paid and not cancelled,
+	// returned or refunded. Derived from the orders whenever the review is read.
+	VerifiedPurchase bool
+}
+
+// Rating summarizes the approved reviews of a book.
+type Rating struct {
+	Average float64 // Rounded to two decimals, 0 without reviews
+	Count   int
+}
+
+// ReviewRepository provides access to the BookReviews storage.
+type ReviewRepository struct {
+	DB *sql.DB
+}
+
+// reviewColumns are the columns of reviewTables read by scanReview, in scan order.
+const reviewColumns = `r.id, r.isbn, c.email, c.first_name, c.last_name, r.rating, r.title, r.text, r.status,
+	r.moderation_note, r.created_at, r.updated_at,
+	EXISTS (
+		SELECT 1 FROM Orders o JOIN OrderItems i ON i.order_id = o.id
+		WHERE o.customer_id = r.customer_id AND i.isbn = r.isbn AND o.status IN ('paid', 'shipped', 'delivered', 'return_requested')
+	)`
+
+// reviewTables joins reviews with the customers who wrote them.
+const reviewTables = `BookReviews r JOIN Customer c ON c.id = r.customer_id`
+
+// CreateReview inserts a new pending review of a book by a customer.
+func (r *ReviewRepository) CreateReview(review *Review) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	customerID, err := lockReviewer(tx, review.CustomerEmail)
+	if err != nil {
+		return err
+	}
+	var exists int
+	err = tx.QueryRow(`SELECT COUNT(*) FROM BookReviews WHERE isbn = ? AND customer_id = ?`, review.ISBN, customerID).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("failed to look up review: %w", err)
+	}
+	if exists > 0 {
+		return fmt.Errorf("%w: %s of %s", ErrReviewExists, review.CustomerEmail, review.ISBN)
+	}
+	if err := checkBook(tx, review.ISBN); err != nil {
+		return err
+	}
+
+	result, err := tx.Exec(`INSERT INTO BookReviews (isbn, customer_id, rating, title, text, status) VALUES (?, ?, ?, ?, ?, ?)`,
+		review.ISBN, customerID, review.Rating, review.Title, review.Text, StatusPending)
+	if err != nil {
+		return fmt.Errorf("failed to insert review: %w", err)
+	}
+	if review.ID, err = result.LastInsertId(); err != nil {
+		return fmt.Errorf("failed to read review id: %w", err)
+	}
+	return tx.Commit()
+}
+
+// GetReview retrieves the review of a book by a customer, whatever its status.
+func (r *ReviewRepository) GetReview(isbn string, email string) (*Review, error) {
+	query := `SELECT ` + reviewColumns + ` FROM ` + reviewTables + ` WHERE r.isbn = ? AND c.email = ?`
+	review, err := scanReview(r.DB.QueryRow(query, isbn, email))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: %s of %s", ErrReviewNotFound, email, isbn)
+	}
+	return review, err
+}
+
+// UpdateReview replaces the rating, title and text of the review of a book by a customer. The
+// review goes back to pending, as the moderator has not seen the new text.
+func (r *ReviewRepository) UpdateReview(review *Review) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	customerID, err := lockReviewer(tx, review.CustomerEmail)
+	if err != nil {
+		return err
+	}
+	result, err := tx.Exec(`
+		UPDATE BookReviews SET rating = ?, title = ?, text = ?, status = ?, moderation_note = NULL
+		WHERE isbn = ? AND customer_id = ?`,
+		review.Rating, review.Title, review.Text, StatusPending, review.ISBN, customerID)
+	if err != nil {
+		return fmt.Errorf("failed to update review: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		// The status is always pending afterwards, so an existing review that was pending with the
+		// same text also reports zero rows
+		var exists int
+		err := tx.QueryRow(`SELECT COUNT(*) FROM BookReviews WHERE isbn = ? AND customer_id = ?`, review.ISBN, customerID).Scan(&exists)
+		if err != nil {
+			return fmt.Errorf("failed to look up review: %w", err)
+		}
+		if exists == 0 {
+			return fmt.Errorf("%w: %s of %s", ErrReviewNotFound, review.CustomerEmail, review.ISBN)
+		}
+	}
+	return tx.Commit()
+}
+
+// DeleteReview removes the review of a book by a customer.
+func (r *ReviewRepository) DeleteReview(isbn string, email string) error {
+	result, err := r.DB.Exec(`
+		DELETE r FROM BookReviews r JOIN Customer c ON c.id = r.customer_id
+		WHERE r.isbn = ? AND c.email = ?`, isbn, email)
+	if err != nil {
+		return fmt.Errorf("failed to delete review: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("%w: %s of %s", ErrReviewNotFound, email, isbn)
+	}
+	return nil
+}
+
+// GetBookReviews retrieves the approved reviews of a book, newest first.
+func (r *ReviewRepository) GetBookReviews(isbn string) ([]Review, error) {
+	if err := checkBook(r.DB, isbn); err != nil {
+		return nil, err
+	}
+	return r.queryReviews(`WHERE r.isbn = ? AND r.status = ? ORDER BY r.created_at DESC, r.id DESC`, isbn, StatusApproved)
+}
+
+// GetCustomerReviews retrieves the reviews written by a customer, whatever their status, newest
+// first.
+func (r *ReviewRepository) GetCustomerReviews(email string) ([]Review, error) {
+	var exists int
+	if err := r.DB.QueryRow(`SELECT COUNT(*) FROM Customer WHERE email = ?`, email).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("failed to look up customer: %w", err)
+	}
+	if exists == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrCustomerNotFound, email)
+	}
+	return r.queryReviews(`WHERE c.email = ? ORDER BY r.created_at DESC, r.id DESC`, email)
+}
+
+// GetReviewsByStatus retrieves the reviews with a moderation status, oldest first, so pending
+// reviews are moderated in the order they were written.
+func (r *ReviewRepository) GetReviewsByStatus(status string) ([]Review, error) {
+	return r.queryReviews(`WHERE r.status = ? ORDER BY r.updated_at, r.id`, status)
+}
+
+// ModerateReview sets the moderation status of a review, with a note on why it was rejected.
+func (r *ReviewRepository) ModerateReview(id int64, status string, note string) (*Review, error) {
+	var moderationNote sql.NullString
+	if note != "" {
+		moderationNote = sql.NullString{String: note, Valid: true}
+	}
+	_, err := r.DB.Exec(`UPDATE BookReviews SET status = ?, moderation_note = ? WHERE id = ?`, status, moderationNote, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to moderate review: %w", err)
+	}
+
+	query := `SELECT ` + reviewColumns + ` FROM ` + reviewTables + ` WHERE r.id = ?`
+	review, err := scanReview(r.DB.QueryRow(query, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: %d", ErrReviewNotFound, id)
+	}
+	return review, err
+}
+
+// GetRatings returns the ratings of books from their approved reviews, by ISBN. Books without
+// approved reviews are left out. Without ISBNs, the ratings of every book are returned.
+func (r *ReviewRepository) GetRatings(isbns []string) (map[string]Rating, error) {
+	query := `SELECT isbn, AVG(rating), COUNT(*) FROM BookReviews WHERE status = ?`
+	args := []interface{}{StatusApproved}
+	if len(isbns) > 0 {
+		query += ` AND isbn IN (?` + strings.Repeat(`, ?`, len(isbns)-1) + `)`
+		for _, isbn := range isbns {
+			args = append(args, isbn)
+		}
+	}
+	query += ` GROUP BY isbn`
+
+	rows, err := r.DB.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query ratings: %w", err)
+	}
+	defer rows.Close()
+
+	ratings := make(map[string]Rating)
+	for rows.Next() {
+		var isbn string
+		var rating Rating
+		if err := rows.Scan(&isbn, &rating.Average, &rating.Count); err != nil {
+			return nil, fmt.Errorf("failed to scan rating: %w", err)
+		}
+		rating.Average = math.Round(rating.Average*100) / 100
+		ratings[isbn] = rating
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+	return ratings, nil
+}
+
+func (r *ReviewRepository) queryReviews(where string, args ...interface{}) ([]Review, error) {
+	rows, err := r.DB.Query(`SELECT `+reviewColumns+` FROM `+reviewTables+` `+where, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query reviews: %w", err)
+	}
+	defer rows.Close()
+
+	var reviews []Review
+	for rows.Next() {
+		review, err := scanReview(rows)
+		if err != nil {
+			return nil, err
+		}
+		reviews = append(reviews, *review)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+	return reviews, nil
+}
+
+// rowScanner is implemented by *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanReview reads a row of reviewColumns. sql.ErrNoRows is wrapped, not replaced.
+func scanReview(row rowScanner) (*Review, error) {
+	var review Review
+	var firstName, lastName string
+	err := row.Scan(&review.ID, &review.ISBN, &review.CustomerEmail, &firstName, &lastName, &review.Rating, &review.Title,
+		&review.Text, &review.Status, &review.ModerationNote, &review.CreatedAt, &review.UpdatedAt, &review.VerifiedPurchase)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve review: %w", err)
+	}
+	review.ReviewerName = firstName
+	if initial := []rune(lastName); len(initial) > 0 {
+		review.ReviewerName += " " + string(initial[0]) + "."
+	}
+	return &review, nil
+}
+
+// lockReviewer returns the id of the customer with an email, locking their row so their reviews
+// are written one at a time. Suspended and closed customers fail with ErrCustomerBlocked.
+func lockReviewer(tx *sql.Tx, email string) (int64, error) {
+	var id int64
+	var status sql.NullString
+	err := tx.QueryRow(`SELECT id, status FROM Customer WHERE email = ? FOR UPDATE`, email).Scan(&id, &status)
+	if err == sql.ErrNoRows {
+		return 0, fmt.Errorf("%w: %s", ErrCustomerNotFound, email)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to look up customer: %w", err)
+	}
+	if status.String == "Suspended" || status.String == "Closed" {
+		return 0, fmt.Errorf("%w: customer %s is %s", ErrCustomerBlocked, email, status.String)
+	}
+	return id, nil
+}
+
+// queryRower is implemented by *sql.DB and *sql.Tx.
+type queryRower interface {
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// checkBook fails with ErrBookNotFound when no book exists with the ISBN.
+func checkBook(q queryRower, isbn string) error {
+	var exists int
+	if err := q.QueryRow(`SELECT COUNT(*) FROM Books WHERE isbn = ?`, isbn).Scan(&exists); err != nil {
+		return fmt.Errorf("failed to look up book: %w", err)
+	}
+	if exists == 0 {
+		return fmt.Errorf("%w: %s", ErrBookNotFound, isbn)
+	}
+	return nil
+}
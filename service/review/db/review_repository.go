@@ -0,0 +1,19 @@
+package db
+
+import (
+	"sync"
+
+	"github.com/mayureshucsb2019/bookstore/service/common"
+)
+
+var reviewRepoInstance *ReviewRepository
+var reviewRepoOnce sync.Once
+
+func NewReviewRepository(db *common.DBConnection) *ReviewRepository {
+	reviewRepoOnce.Do(func() {
+		reviewRepoInstance = &ReviewRepository{
+			DB: db.DB,
+		}
+	})
+	return reviewRepoInstance
+}
@@ -0,0 +1,70 @@
+package models
+
+import (
+	"errors"
+
+	"github.com/mayureshucsb2019/bookstore/service/common"
+)
+
+type Review struct {
+	// Assigned by the server; moderators refer to the review by it
+	Id int64 `json:"id,omitempty"`
+
+	// Set by the server from the path
+	Isbn string `json:"isbn,omitempty"`
+
+	// Required when a review is created. Left out of the reviews listed for a book, which show the
+	// reviewer name instead.
+	CustomerEmail string `json:"customer_email,omitempty"`
+
+	// First name and initial of the last name of the customer; set by the server
+	ReviewerName string `json:"reviewer_name,omitempty"`
+
+	// From 1 to 5 stars
+	Rating int32 `json:"rating"`
+
+	Title string `json:"title,omitempty"`
+
+	Text string `json:"text,omitempty"`
+
+	// The customer bought the book; set by the server from their orders
+	VerifiedPurchase bool `json:"verified_purchase"`
+
+	// Moderation status, one of pending, approved or rejected; set by the server
+	Status string `json:"status,omitempty"`
+
+	// Why the review was rejected; set by the moderator
+	ModerationNote string `json:"moderation_note,omitempty"`
+
+	CreatedAt string `json:"created_at,omitempty"`
+
+	UpdatedAt string `json:"updated_at,omitempty"`
+}
+
+// AssertReviewRequired checks if the required fields are not zero-ed
+func AssertReviewRequired(obj Review) error {
+	elements := map[string]interface{}{
+		"rating": obj.Rating,
+	}
+	for name, el := range elements {
+		if isZero := common.IsZeroValue(el); isZero {
+			return &common.RequiredError{Field: name}
+		}
+	}
+
+	return nil
+}
+
+// AssertReviewConstraints checks if the values respects the defined constraints
+func AssertReviewConstraints(obj Review) error {
+	if obj.Rating < 1 || obj.Rating > 5 {
+		return &common.ParsingError{Param: "rating", Err: errors.New("must be from 1 to 5")}
+	}
+	if len(obj.Title) > 255 {
+		return &common.ParsingError{Param: "title", Err: errors.New("must be at most 255 characters")}
+	}
+	if len(obj.Text) > 10000 {
+		return &common.ParsingError{Param: "text", Err: errors.New("must be at most 10000 characters")}
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package models
+
+import (
+	"errors"
+
+	"github.com/mayureshucsb2019/bookstore/service/common"
+)
+
+type ReviewModeration struct {
+	// One of approved, rejected or pending
+	Status string `json:"status"`
+
+	// Why the review was rejected, shown to the customer who wrote it
+	Note string `json:"note,omitempty"`
+}
+
+// AssertReviewModerationRequired checks if the required fields are not zero-ed
+func AssertReviewModerationRequired(obj ReviewModeration) error {
+	elements := map[string]interface{}{
+		"status": obj.Status,
+	}
+	for name, el := range elements {
+		if isZero := common.IsZeroValue(el); isZero {
+			return &common.RequiredError{Field: name}
+		}
+	}
+
+	return nil
+}
+
+// AssertReviewModerationConstraints checks if the values respects the defined constraints
+func AssertReviewModerationConstraints(obj ReviewModeration) error {
+	switch obj.Status {
+	case "approved", "rejected", "pending":
+	default:
+		return &common.ParsingError{Param: "status", Err: errors.New("must be one of approved, rejected, pending")}
+	}
+	if len(obj.Note) > 255 {
+		return &common.ParsingError{Param: "note", Err: errors.New("must be at most 255 characters")}
+	}
+	return nil
+}
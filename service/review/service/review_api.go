@@ -0,0 +1,38 @@
+package service
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/mayureshucsb2019/bookstore/service/common"
+	"github.com/mayureshucsb2019/bookstore/service/review/models"
+)
+
+// DefaultAPIRouter defines the required methods for binding the api requests to a responses for the DefaultAPI
+// The DefaultAPIRouter implementation should parse necessary information from the http request,
+// pass the data to a DefaultAPIServicer to perform the required actions, then write the service results to the http response.
+type DefaultAPIRouter interface {
+	AdminReviewsGet(http.ResponseWriter, *http.Request)
+	AdminReviewsIdModerationPut(http.ResponseWriter, *http.Request)
+	BooksIsbnReviewsEmailDelete(http.ResponseWriter, *http.Request)
+	BooksIsbnReviewsEmailGet(http.ResponseWriter, *http.Request)
+	BooksIsbnReviewsEmailPut(http.ResponseWriter, *http.Request)
+	BooksIsbnReviewsGet(http.ResponseWriter, *http.Request)
+	BooksIsbnReviewsPost(http.ResponseWriter, *http.Request)
+	CustomersEmailReviewsGet(http.ResponseWriter, *http.Request)
+}
+
+// DefaultAPIServicer defines the api actions for the DefaultAPI service
+// This interface intended to stay up to date with the openapi yaml used to generate it,
+// while the service implementation can be ignored with the .openapi-generator-ignore file
+// and updated with the logic required for the API.
+type DefaultAPIServicer interface {
+	AdminReviewsGet(context.Context, string) (common.ImplResponse, error)
+	AdminReviewsIdModerationPut(context.Context, int64, models.ReviewModeration) (common.ImplResponse, error)
+	BooksIsbnReviewsEmailDelete(context.Context, string, string) (common.ImplResponse, error)
+	BooksIsbnReviewsEmailGet(context.Context, string, string) (common.ImplResponse, error)
+	BooksIsbnReviewsEmailPut(context.Context, string, string, models.Review) (common.ImplResponse, error)
+	BooksIsbnReviewsGet(context.Context, string) (common.ImplResponse, error)
+	BooksIsbnReviewsPost(context.Context, string, models.Review) (common.ImplResponse, error)
+	CustomersEmailReviewsGet(context.Context, string) (common.ImplResponse, error)
+}
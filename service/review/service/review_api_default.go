@@ -0,0 +1,299 @@
+package service
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/mayureshucsb2019/bookstore/service/common"
+	"github.com/mayureshucsb2019/bookstore/service/review/models"
+)
+
+// DefaultAPIController binds http requests to an api service and writes the service results to the http response
+type DefaultAPIController struct {
+	service      DefaultAPIServicer
+	errorHandler common.ErrorHandler
+}
+
+// DefaultAPIOption for how the controller is set up.
+type DefaultAPIOption func(*DefaultAPIController)
+
+// WithDefaultAPIErrorHandler inject ErrorHandler into controller
+func WithDefaultAPIErrorHandler(h common.ErrorHandler) DefaultAPIOption {
+	return func(c *DefaultAPIController) {
+		c.errorHandler = h
+	}
+}
+
+// NewDefaultAPIController creates a default api controller
+func NewDefaultAPIController(s DefaultAPIServicer, opts ...DefaultAPIOption) *DefaultAPIController {
+	controller := &DefaultAPIController{
+		service:      s,
+		errorHandler: common.DefaultErrorHandler,
+	}
+
+	for _, opt := range opts {
+		opt(controller)
+	}
+
+	return controller
+}
+
+// Routes returns all the api routes for the DefaultAPIController
+func (c *DefaultAPIController) Routes() common.Routes {
+	return common.Routes{
+		"AdminReviewsGet": common.Route{
+			Method:      strings.ToUpper("Get"),
+			Pattern:     "/admin/reviews",
+			HandlerFunc: c.AdminReviewsGet,
+		},
+		"AdminReviewsIdModerationPut": common.Route{
+			Method:      strings.ToUpper("Put"),
+			Pattern:     "/admin/reviews/{id}/moderation",
+			HandlerFunc: c.AdminReviewsIdModerationPut,
+		},
+		"BooksIsbnReviewsEmailDelete": common.Route{
+			Method:      strings.ToUpper("Delete"),
+			Pattern:     "/books/{isbn}/reviews/{email}",
+			HandlerFunc: c.BooksIsbnReviewsEmailDelete,
+		},
+		"BooksIsbnReviewsEmailGet": common.Route{
+			Method:      strings.ToUpper("Get"),
+			Pattern:     "/books/{isbn}/reviews/{email}",
+			HandlerFunc: c.BooksIsbnReviewsEmailGet,
+		},
+		"BooksIsbnReviewsEmailPut": common.Route{
+			Method:      strings.ToUpper("Put"),
+			Pattern:     "/books/{isbn}/reviews/{email}",
+			HandlerFunc: c.BooksIsbnReviewsEmailPut,
+		},
+		"BooksIsbnReviewsGet": common.Route{
+			Method:      strings.ToUpper("Get"),
+			Pattern:     "/books/{isbn}/reviews",
+			HandlerFunc: c.BooksIsbnReviewsGet,
+		},
+		"BooksIsbnReviewsPost": common.Route{
+			Method:      strings.ToUpper("Post"),
+			Pattern:     "/books/{isbn}/reviews",
+			HandlerFunc: c.BooksIsbnReviewsPost,
+		},
+		"CustomersEmailReviewsGet": common.Route{
+			Method:      strings.ToUpper("Get"),
+			Pattern:     "/customers/{email}/reviews",
+			HandlerFunc: c.CustomersEmailReviewsGet,
+		},
+	}
+}
+
+// AdminReviewsGet - Get the reviews with a moderation status
+func (c *DefaultAPIController) AdminReviewsGet(w http.ResponseWriter, r *http.Request) {
+	query, err := common.ParseQuery(r.URL.RawQuery)
+	if err != nil {
+		c.errorHandler(w, r, &common.ParsingError{Err: err}, nil)
+		return
+	}
+	var statusParam string
+	if query.Has("status") {
+		statusParam = query.Get("status")
+	} else {
+		statusParam = "pending"
+	}
+	result, err := c.service.AdminReviewsGet(r.Context(), statusParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	_ = common.EncodeJSONResponse(result.Body, &result.Code, w)
+}
+
+// AdminReviewsIdModerationPut - Approve or reject a review
+func (c *DefaultAPIController) AdminReviewsIdModerationPut(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	idParam, err := common.ParseNumericParameter[int64](
+		params["id"],
+		common.WithRequire[int64](common.ParseInt64),
+	)
+	if err != nil {
+		c.errorHandler(w, r, &common.ParsingError{Param: "id", Err: err}, nil)
+		return
+	}
+	reviewModerationParam := models.ReviewModeration{}
+	d := json.NewDecoder(r.Body)
+	d.DisallowUnknownFields()
+	if err := d.Decode(&reviewModerationParam); err != nil {
+		c.errorHandler(w, r, &common.ParsingError{Err: err}, nil)
+		return
+	}
+	if err := models.AssertReviewModerationRequired(reviewModerationParam); err != nil {
+		c.errorHandler(w, r, err, nil)
+		return
+	}
+	if err := models.AssertReviewModerationConstraints(reviewModerationParam); err != nil {
+		c.errorHandler(w, r, err, nil)
+		return
+	}
+	result, err := c.service.AdminReviewsIdModerationPut(r.Context(), idParam, reviewModerationParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	_ = common.EncodeJSONResponse(result.Body, &result.Code, w)
+}
+
+// BooksIsbnReviewsEmailDelete - Delete the review of a book by a customer
+func (c *DefaultAPIController) BooksIsbnReviewsEmailDelete(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	isbnParam := params["isbn"]
+	if isbnParam == "" {
+		c.errorHandler(w, r, &common.RequiredError{Field: "isbn"}, nil)
+		return
+	}
+	emailParam := params["email"]
+	if emailParam == "" {
+		c.errorHandler(w, r, &common.RequiredError{Field: "email"}, nil)
+		return
+	}
+	result, err := c.service.BooksIsbnReviewsEmailDelete(r.Context(), isbnParam, emailParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	_ = common.EncodeJSONResponse(result.Body, &result.Code, w)
+}
+
+// BooksIsbnReviewsEmailGet - Get the review of a book by a customer
+func (c *DefaultAPIController) BooksIsbnReviewsEmailGet(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	isbnParam := params["isbn"]
+	if isbnParam == "" {
+		c.errorHandler(w, r, &common.RequiredError{Field: "isbn"}, nil)
+		return
+	}
+	emailParam := params["email"]
+	if emailParam == "" {
+		c.errorHandler(w, r, &common.RequiredError{Field: "email"}, nil)
+		return
+	}
+	result, err := c.service.BooksIsbnReviewsEmailGet(r.Context(), isbnParam, emailParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	_ = common.EncodeJSONResponse(result.Body, &result.Code, w)
+}
+
+// BooksIsbnReviewsEmailPut - Replace the review of a book by a customer
+func (c *DefaultAPIController) BooksIsbnReviewsEmailPut(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	isbnParam := params["isbn"]
+	if isbnParam == "" {
+		c.errorHandler(w, r, &common.RequiredError{Field: "isbn"}, nil)
+		return
+	}
+	emailParam := params["email"]
+	if emailParam == "" {
+		c.errorHandler(w, r, &common.RequiredError{Field: "email"}, nil)
+		return
+	}
+	reviewParam := models.Review{}
+	d := json.NewDecoder(r.Body)
+	d.DisallowUnknownFields()
+	if err := d.Decode(&reviewParam); err != nil {
+		c.errorHandler(w, r, &common.ParsingError{Err: err}, nil)
+		return
+	}
+	if err := models.AssertReviewRequired(reviewParam); err != nil {
+		c.errorHandler(w, r, err, nil)
+		return
+	}
+	if err := models.AssertReviewConstraints(reviewParam); err != nil {
+		c.errorHandler(w, r, err, nil)
+		return
+	}
+	result, err := c.service.BooksIsbnReviewsEmailPut(r.Context(), isbnParam, emailParam, reviewParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	_ = common.EncodeJSONResponse(result.Body, &result.Code, w)
+}
+
+// BooksIsbnReviewsGet - Get the approved reviews of a book
+func (c *DefaultAPIController) BooksIsbnReviewsGet(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	isbnParam := params["isbn"]
+	if isbnParam == "" {
+		c.errorHandler(w, r, &common.RequiredError{Field: "isbn"}, nil)
+		return
+	}
+	result, err := c.service.BooksIsbnReviewsGet(r.Context(), isbnParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	_ = common.EncodeJSONResponse(result.Body, &result.Code, w)
+}
+
+// BooksIsbnReviewsPost - Review a book
+func (c *DefaultAPIController) BooksIsbnReviewsPost(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	isbnParam := params["isbn"]
+	if isbnParam == "" {
+		c.errorHandler(w, r, &common.RequiredError{Field: "isbn"}, nil)
+		return
+	}
+	reviewParam := models.Review{}
+	d := json.NewDecoder(r.Body)
+	d.DisallowUnknownFields()
+	if err := d.Decode(&reviewParam); err != nil {
+		c.errorHandler(w, r, &common.ParsingError{Err: err}, nil)
+		return
+	}
+	if err := models.AssertReviewRequired(reviewParam); err != nil {
+		c.errorHandler(w, r, err, nil)
+		return
+	}
+	if err := models.AssertReviewConstraints(reviewParam); err != nil {
+		c.errorHandler(w, r, err, nil)
+		return
+	}
+	result, err := c.service.BooksIsbnReviewsPost(r.Context(), isbnParam, reviewParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	_ = common.EncodeJSONResponse(result.Body, &result.Code, w)
+}
+
+// CustomersEmailReviewsGet - Get the reviews written by a customer
+func (c *DefaultAPIController) CustomersEmailReviewsGet(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	emailParam := params["email"]
+	if emailParam == "" {
+		c.errorHandler(w, r, &common.RequiredError{Field: "email"}, nil)
+		return
+	}
+	result, err := c.service.CustomersEmailReviewsGet(r.Context(), emailParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	_ = common.EncodeJSONResponse(result.Body, &result.Code, w)
+}
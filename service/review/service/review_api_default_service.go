@@ -0,0 +1,187 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/mayureshucsb2019/bookstore/service/common"
+	"github.com/mayureshucsb2019/bookstore/service/review/db"
+	"github.com/mayureshucsb2019/bookstore/service/review/models"
+)
+
+// DefaultAPIService is a service that implements the logic for the DefaultAPIServicer
+// This service manages the reviews customers write about books and their moderation.
+type DefaultAPIService struct {
+	Repo *db.ReviewRepository
+}
+
+// NewDefaultAPIService creates a default API service with the given repository.
+func NewDefaultAPIService(repo *db.ReviewRepository) *DefaultAPIService {
+	return &DefaultAPIService{
+		Repo: repo,
+	}
+}
+
+// AdminReviewsGet - Get the reviews with a moderation status
+func (s *DefaultAPIService) AdminReviewsGet(ctx context.Context, status string) (common.ImplResponse, error) {
+	if err := checkStatus(status); err != nil {
+		return common.Response(http.StatusBadRequest, nil), err
+	}
+	reviews, err := s.Repo.GetReviewsByStatus(status)
+	if err != nil {
+		return common.Response(http.StatusInternalServerError, nil), err
+	}
+
+	return common.Response(http.StatusOK, convertDBToAPIReviews(reviews, false)), nil
+}
+
+// AdminReviewsIdModerationPut - Approve or reject a review
+func (s *DefaultAPIService) AdminReviewsIdModerationPut(ctx context.Context, id int64, moderation models.ReviewModeration) (common.ImplResponse, error) {
+	review, err := s.Repo.ModerateReview(id, moderation.Status, moderation.Note)
+	if err != nil {
+		return reviewErrorResponse(err)
+	}
+
+	return common.Response(http.StatusOK, ConvertDBToAPIResponse(*review)), nil
+}
+
+// BooksIsbnReviewsEmailDelete - Delete the review of a book by a customer
+func (s *DefaultAPIService) BooksIsbnReviewsEmailDelete(ctx context.Context, isbn string, email string) (common.ImplResponse, error) {
+	if err := s.Repo.DeleteReview(isbn, email); err != nil {
+		return reviewErrorResponse(err)
+	}
+
+	return common.Response(http.StatusNoContent, nil), nil
+}
+
+// BooksIsbnReviewsEmailGet - Get the review of a book by a customer
+func (s *DefaultAPIService) BooksIsbnReviewsEmailGet(ctx context.Context, isbn string, email string) (common.ImplResponse, error) {
+	review, err := s.Repo.GetReview(isbn, email)
+	if err != nil {
+		return reviewErrorResponse(err)
+	}
+
+	return common.Response(http.StatusOK, ConvertDBToAPIResponse(*review)), nil
+}
+
+// BooksIsbnReviewsEmailPut - Replace the review of a book by a customer
+func (s *DefaultAPIService) BooksIsbnReviewsEmailPut(ctx context.Context, isbn string, email string, review models.Review) (common.ImplResponse, error) {
+	if review.CustomerEmail != "" && review.CustomerEmail != email {
+		return common.Response(http.StatusBadRequest, nil), errors.New("email in the path does not match email in the body")
+	}
+	review.Isbn = isbn
+	review.CustomerEmail = email
+
+	dbReview := convertApiToDBReview(review)
+	if err := s.Repo.UpdateReview(&dbReview); err != nil {
+		return reviewErrorResponse(err)
+	}
+	updated, err := s.Repo.GetReview(isbn, email)
+	if err != nil {
+		return reviewErrorResponse(err)
+	}
+
+	return common.Response(http.StatusOK, ConvertDBToAPIResponse(*updated)), nil
+}
+
+// BooksIsbnReviewsGet - Get the approved reviews of a book
+func (s *DefaultAPIService) BooksIsbnReviewsGet(ctx context.Context, isbn string) (common.ImplResponse, error) {
+	reviews, err := s.Repo.GetBookReviews(isbn)
+	if err != nil {
+		return reviewErrorResponse(err)
+	}
+
+	// Reviews are public, so the reviewers are only named
+	return common.Response(http.StatusOK, convertDBToAPIReviews(reviews, true)), nil
+}
+
+// BooksIsbnReviewsPost - Review a book
+func (s *DefaultAPIService) BooksIsbnReviewsPost(ctx context.Context, isbn string, review models.Review) (common.ImplResponse, error) {
+	if review.CustomerEmail == "" {
+		return common.Response(http.StatusBadRequest, nil), &common.RequiredError{Field: "customer_email"}
+	}
+	review.Isbn = isbn
+
+	dbReview := convertApiToDBReview(review)
+	if err := s.Repo.CreateReview(&dbReview); err != nil {
+		return reviewErrorResponse(err)
+	}
+	created, err := s.Repo.GetReview(isbn, review.CustomerEmail)
+	if err != nil {
+		return reviewErrorResponse(err)
+	}
+
+	return common.Response(http.StatusCreated, ConvertDBToAPIResponse(*created)), nil
+}
+
+// CustomersEmailReviewsGet - Get the reviews written by a customer
+func (s *DefaultAPIService) CustomersEmailReviewsGet(ctx context.Context, email string) (common.ImplResponse, error) {
+	reviews, err := s.Repo.GetCustomerReviews(email)
+	if err != nil {
+		return reviewErrorResponse(err)
+	}
+
+	return common.Response(http.StatusOK, convertDBToAPIReviews(reviews, false)), nil
+}
+
+// checkStatus validates a moderation status given as a query parameter.
+func checkStatus(status string) error {
+	return models.AssertReviewModerationConstraints(models.ReviewModeration{Status: status})
+}
+
+// reviewErrorResponse maps repository errors to their response status, or an internal error.
+func reviewErrorResponse(err error) (common.ImplResponse, error) {
+	switch {
+	case errors.Is(err, db.ErrReviewNotFound), errors.Is(err, db.ErrBookNotFound), errors.Is(err, db.ErrCustomerNotFound):
+		return common.Response(http.StatusNotFound, nil), err
+	case errors.Is(err, db.ErrReviewExists):
+		return common.Response(http.StatusConflict, nil), err
+	case errors.Is(err, db.ErrCustomerBlocked):
+		return common.Response(http.StatusForbidden, nil), err
+	}
+	return common.Response(http.StatusInternalServerError, nil), err
+}
+
+// convertApiToDBReview converts an API model Review to a database model Review.
+func convertApiToDBReview(review models.Review) db.Review {
+	return db.Review{
+		ISBN:          review.Isbn,
+		CustomerEmail: review.CustomerEmail,
+		Rating:        int(review.Rating),
+		Title:         common.NullStringOrNil(review.Title),
+		Text:          common.NullStringOrNil(review.Text),
+	}
+}
+
+// ConvertDBToAPIResponse converts the DB model to the API model
+func ConvertDBToAPIResponse(review db.Review) models.Review {
+	return models.Review{
+		Id:               review.ID,
+		Isbn:             review.ISBN,
+		CustomerEmail:    review.CustomerEmail,
+		ReviewerName:     review.ReviewerName,
+		Rating:           int32(review.Rating),
+		Title:            common.StringOrEmpty(review.Title),
+		Text:             common.StringOrEmpty(review.Text),
+		VerifiedPurchase: review.VerifiedPurchase,
+		Status:           review.Status,
+		ModerationNote:   common.StringOrEmpty(review.ModerationNote),
+		CreatedAt:        review.CreatedAt,
+		UpdatedAt:        review.UpdatedAt,
+	}
+}
+
+// convertDBToAPIReviews converts a list of reviews, leaving out the emails of the customers when
+// the list is public.
+func convertDBToAPIReviews(reviews []db.Review, public bool) []models.Review {
+	reviewsResp := []models.Review{}
+	for _, review := range reviews {
+		resp := ConvertDBToAPIResponse(review)
+		if public {
+			resp.CustomerEmail = ""
+		}
+		reviewsResp = append(reviewsResp, resp)
+	}
+	return reviewsResp
+}
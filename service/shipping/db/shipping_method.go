@@ -0,0 +1,179 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	_ "github.com/go-sql-driver/mysql"
+	"github.com/mayureshucsb2019/bookstore/service/common"
+)
+
+// ErrShippingMethodNotFound is returned when no shipping method exists with the requested code
+var ErrShippingMethodNotFound = errors.New("shipping method not found")
+
+// ShippingMethod represents the structure of a ShippingMethods record in the database.
+// Books carry no weight, so the number of pages is used as a proxy: every started block of
+// PagesPerUnit pages in the order adds RatePerUnit to the BaseRate.
+type ShippingMethod struct {
+	Code          string
+	Name          string
+	Carrier       string
+	BaseRate      common.Money
+	RatePerUnit   common.Money // Same currency as BaseRate
+	PagesPerUnit  int          // 0 charges the base rate only
+	EstimatedDays int
+	Active        bool
+}
+
+// Rate returns the cost of shipping an order with the given total number of pages.
+func (m ShippingMethod) Rate(pages int) common.Money {
+	rate := m.BaseRate
+	if m.PagesPerUnit > 0 && pages > 0 {
+		units := (pages + m.PagesPerUnit - 1) / m.PagesPerUnit
+		rate.Amount += m.RatePerUnit.Amount * int64(units)
+	}
+	return rate
+}
+
+// ShippingMethodRepository provides access to the ShippingMethods storage.
+type ShippingMethodRepository struct {
+	DB *sql.DB
+}
+
+const shippingMethodColumns = `code, name, carrier, base_rate, rate_per_unit, currency, pages_per_unit, estimated_days, active`
+
+// CreateShippingMethod inserts a new shipping method into the database.
+func (r *ShippingMethodRepository) CreateShippingMethod(method *ShippingMethod) error {
+	query := `INSERT INTO ShippingMethods (` + shippingMethodColumns + `) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	_, err := r.DB.Exec(query, shippingMethodArgs(method)...)
+	if err != nil {
+		return fmt.Errorf("failed to insert shipping method: %w", err)
+	}
+	return nil
+}
+
+// UpdateShippingMethod updates an existing shipping method in the database.
+func (r *ShippingMethodRepository) UpdateShippingMethod(method *ShippingMethod) error {
+	query := `
+		UPDATE ShippingMethods
+		SET name = ?, carrier = ?, base_rate = ?, rate_per_unit = ?, currency = ?, pages_per_unit = ?, estimated_days = ?, active = ?
+		WHERE code = ?
+	`
+	args := shippingMethodArgs(method)
+	result, err := r.DB.Exec(query, append(args[1:], method.Code)...)
+	if err != nil {
+		return fmt.Errorf("failed to update shipping method: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		// MySQL reports zero rows for an update that changes nothing, so confirm the row exists
+		if _, err := r.GetShippingMethodByCode(method.Code); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeleteShippingMethod removes a shipping method from the database by its code.
+func (r *ShippingMethodRepository) DeleteShippingMethod(code string) error {
+	result, err := r.DB.Exec(`DELETE FROM ShippingMethods WHERE code = ?`, code)
+	if err != nil {
+		return fmt.Errorf("failed to delete shipping method: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("%w: %s", ErrShippingMethodNotFound, code)
+	}
+	return nil
+}
+
+// GetShippingMethodByCode retrieves a shipping method by its code.
+func (r *ShippingMethodRepository) GetShippingMethodByCode(code string) (*ShippingMethod, error) {
+	query := `SELECT ` + shippingMethodColumns + ` FROM ShippingMethods WHERE code = ?`
+	method, err := scanShippingMethod(r.DB.QueryRow(query, code))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("%w: %s", ErrShippingMethodNotFound, code)
+		}
+		return nil, fmt.Errorf("failed to get shipping method: %w", err)
+	}
+	return method, nil
+}
+
+// GetAllShippingMethods retrieves every shipping method, or only the active ones.
+func (r *ShippingMethodRepository) GetAllShippingMethods(activeOnly bool) ([]ShippingMethod, error) {
+	query := `SELECT ` + shippingMethodColumns + ` FROM ShippingMethods`
+	if activeOnly {
+		query += ` WHERE active = TRUE`
+	}
+	query += ` ORDER BY base_rate, code`
+
+	rows, err := r.DB.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query shipping methods: %w", err)
+	}
+	defer rows.Close()
+
+	var methods []ShippingMethod
+	for rows.Next() {
+		method, err := scanShippingMethod(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan shipping method: %w", err)
+		}
+		methods = append(methods, *method)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+
+	return methods, nil
+}
+
+// shippingMethodArgs returns the shipping method fields in shippingMethodColumns order.
+func shippingMethodArgs(m *ShippingMethod) []interface{} {
+	return []interface{}{
+		m.Code,
+		m.Name,
+		common.NullStringOrNil(m.Carrier),
+		m.BaseRate.Decimal(),
+		m.RatePerUnit.Decimal(),
+		m.BaseRate.Currency,
+		m.PagesPerUnit,
+		m.EstimatedDays,
+		m.Active,
+	}
+}
+
+// rowScanner is implemented by both *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanShippingMethod(row rowScanner) (*ShippingMethod, error) {
+	var m ShippingMethod
+	var carrier sql.NullString
+	var baseRate, ratePerUnit, currency string
+
+	err := row.Scan(&m.Code, &m.Name, &carrier, &baseRate, &ratePerUnit, &currency, &m.PagesPerUnit, &m.EstimatedDays, &m.Active)
+	if err != nil {
+		return nil, err
+	}
+	m.Carrier = common.StringOrEmpty(carrier)
+	if m.BaseRate, err = common.ParseMoney(baseRate, currency); err != nil {
+		return nil, fmt.Errorf("failed to parse base rate: %w", err)
+	}
+	if m.RatePerUnit, err = common.ParseMoney(ratePerUnit, currency); err != nil {
+		return nil, fmt.Errorf("failed to parse rate per unit: %w", err)
+	}
+
+	return &m, nil
+}
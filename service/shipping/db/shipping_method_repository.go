@@ -0,0 +1,19 @@
+package db
+
+import (
+	"sync"
+
+	"github.com/mayureshucsb2019/bookstore/service/common"
+)
+
+var shippingMethodRepoInstance *ShippingMethodRepository
+var shippingMethodRepoOnce sync.Once
+
+func NewShippingMethodRepository(db *common.DBConnection) *ShippingMethodRepository {
+	shippingMethodRepoOnce.Do(func() {
+		shippingMethodRepoInstance = &ShippingMethodRepository{
+			DB: db.DB,
+		}
+	})
+	return shippingMethodRepoInstance
+}
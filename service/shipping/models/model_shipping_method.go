@@ -0,0 +1,71 @@
+package models
+
+import (
+	"errors"
+
+	"github.com/mayureshucsb2019/bookstore/service/common"
+)
+
+type ShippingMethod struct {
+	// Code customers choose at checkout, e.g. "standard".
+	Code string `json:"code"`
+
+	Name string `json:"name"`
+
+	Carrier string `json:"carrier,omitempty"`
+
+	// Charged on every order shipped with this method.
+	BaseRate common.Money `json:"base_rate"`
+
+	// Added for every started block of pages_per_unit pages in the order.
+	RatePerUnit *common.Money `json:"rate_per_unit,omitempty"`
+
+	// Page count standing in for weight, 0 charges the base rate only.
+	PagesPerUnit int32 `json:"pages_per_unit,omitempty"`
+
+	EstimatedDays int32 `json:"estimated_days,omitempty"`
+
+	// Defaults to true when omitted.
+	Active *bool `json:"active,omitempty"`
+}
+
+// AssertShippingMethodRequired checks if the required fields are not zero-ed
+func AssertShippingMethodRequired(obj ShippingMethod) error {
+	elements := map[string]interface{}{
+		"code":      obj.Code,
+		"name":      obj.Name,
+		"base_rate": obj.BaseRate,
+	}
+	for name, el := range elements {
+		if isZero := common.IsZeroValue(el); isZero {
+			return &common.RequiredError{Field: name}
+		}
+	}
+	if obj.PagesPerUnit > 0 && obj.RatePerUnit == nil {
+		return &common.RequiredError{Field: "rate_per_unit"}
+	}
+
+	return nil
+}
+
+// AssertShippingMethodConstraints checks if the values respects the defined constraints
+func AssertShippingMethodConstraints(obj ShippingMethod) error {
+	if obj.BaseRate.Amount < 0 {
+		return &common.ParsingError{Param: "base_rate", Err: errors.New("cannot be negative")}
+	}
+	if obj.RatePerUnit != nil {
+		if obj.RatePerUnit.Amount < 0 {
+			return &common.ParsingError{Param: "rate_per_unit", Err: errors.New("cannot be negative")}
+		}
+		if obj.RatePerUnit.Currency != obj.BaseRate.Currency {
+			return &common.ParsingError{Param: "rate_per_unit", Err: common.ErrCurrencyMismatch}
+		}
+	}
+	if obj.PagesPerUnit < 0 {
+		return &common.ParsingError{Param: "pages_per_unit", Err: errors.New("cannot be negative")}
+	}
+	if obj.EstimatedDays < 0 {
+		return &common.ParsingError{Param: "estimated_days", Err: errors.New("cannot be negative")}
+	}
+	return nil
+}
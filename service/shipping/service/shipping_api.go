@@ -0,0 +1,34 @@
+package service
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/mayureshucsb2019/bookstore/service/common"
+	"github.com/mayureshucsb2019/bookstore/service/shipping/models"
+)
+
+// DefaultAPIRouter defines the required methods for binding the api requests to a responses for the DefaultAPI
+// The DefaultAPIRouter implementation should parse necessary information from the http request,
+// pass the data to a DefaultAPIServicer to perform the required actions, then write the service results to the http response.
+type DefaultAPIRouter interface {
+	AdminShippingMethodsCodeDelete(http.ResponseWriter, *http.Request)
+	AdminShippingMethodsCodeGet(http.ResponseWriter, *http.Request)
+	AdminShippingMethodsCodePut(http.ResponseWriter, *http.Request)
+	AdminShippingMethodsGet(http.ResponseWriter, *http.Request)
+	AdminShippingMethodsPost(http.ResponseWriter, *http.Request)
+	ShippingMethodsGet(http.ResponseWriter, *http.Request)
+}
+
+// DefaultAPIServicer defines the api actions for the DefaultAPI service
+// This interface intended to stay up to date with the openapi yaml used to generate it,
+// while the service implementation can be ignored with the .openapi-generator-ignore file
+// and updated with the logic required for the API.
+type DefaultAPIServicer interface {
+	AdminShippingMethodsCodeDelete(context.Context, string) (common.ImplResponse, error)
+	AdminShippingMethodsCodeGet(context.Context, string) (common.ImplResponse, error)
+	AdminShippingMethodsCodePut(context.Context, string, models.ShippingMethod) (common.ImplResponse, error)
+	AdminShippingMethodsGet(context.Context) (common.ImplResponse, error)
+	AdminShippingMethodsPost(context.Context, models.ShippingMethod) (common.ImplResponse, error)
+	ShippingMethodsGet(context.Context) (common.ImplResponse, error)
+}
@@ -0,0 +1,197 @@
+package service
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/mayureshucsb2019/bookstore/service/common"
+	"github.com/mayureshucsb2019/bookstore/service/shipping/models"
+)
+
+// DefaultAPIController binds http requests to an api service and writes the service results to the http response
+type DefaultAPIController struct {
+	service      DefaultAPIServicer
+	errorHandler common.ErrorHandler
+}
+
+// DefaultAPIOption for how the controller is set up.
+type DefaultAPIOption func(*DefaultAPIController)
+
+// WithDefaultAPIErrorHandler inject ErrorHandler into controller
+func WithDefaultAPIErrorHandler(h common.ErrorHandler) DefaultAPIOption {
+	return func(c *DefaultAPIController) {
+		c.errorHandler = h
+	}
+}
+
+// NewDefaultAPIController creates a default api controller
+func NewDefaultAPIController(s DefaultAPIServicer, opts ...DefaultAPIOption) *DefaultAPIController {
+	controller := &DefaultAPIController{
+		service:      s,
+		errorHandler: common.DefaultErrorHandler,
+	}
+
+	for _, opt := range opts {
+		opt(controller)
+	}
+
+	return controller
+}
+
+// Routes returns all the api routes for the DefaultAPIController
+func (c *DefaultAPIController) Routes() common.Routes {
+	return common.Routes{
+		"AdminShippingMethodsCodeDelete": common.Route{
+			Method:      strings.ToUpper("Delete"),
+			Pattern:     "/admin/shipping-methods/{code}",
+			HandlerFunc: c.AdminShippingMethodsCodeDelete,
+		},
+		"AdminShippingMethodsCodeGet": common.Route{
+			Method:      strings.ToUpper("Get"),
+			Pattern:     "/admin/shipping-methods/{code}",
+			HandlerFunc: c.AdminShippingMethodsCodeGet,
+		},
+		"AdminShippingMethodsCodePut": common.Route{
+			Method:      strings.ToUpper("Put"),
+			Pattern:     "/admin/shipping-methods/{code}",
+			HandlerFunc: c.AdminShippingMethodsCodePut,
+		},
+		"AdminShippingMethodsGet": common.Route{
+			Method:      strings.ToUpper("Get"),
+			Pattern:     "/admin/shipping-methods",
+			HandlerFunc: c.AdminShippingMethodsGet,
+		},
+		"AdminShippingMethodsPost": common.Route{
+			Method:      strings.ToUpper("Post"),
+			Pattern:     "/admin/shipping-methods",
+			HandlerFunc: c.AdminShippingMethodsPost,
+		},
+		"ShippingMethodsGet": common.Route{
+			Method:      strings.ToUpper("Get"),
+			Pattern:     "/shipping-methods",
+			HandlerFunc: c.ShippingMethodsGet,
+		},
+	}
+}
+
+// AdminShippingMethodsCodeDelete - Delete a shipping method by code
+func (c *DefaultAPIController) AdminShippingMethodsCodeDelete(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	codeParam := params["code"]
+	if codeParam == "" {
+		c.errorHandler(w, r, &common.RequiredError{Field: "code"}, nil)
+		return
+	}
+	result, err := c.service.AdminShippingMethodsCodeDelete(r.Context(), codeParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	_ = common.EncodeJSONResponse(result.Body, &result.Code, w)
+}
+
+// AdminShippingMethodsCodeGet - Get a specific shipping method by code
+func (c *DefaultAPIController) AdminShippingMethodsCodeGet(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	codeParam := params["code"]
+	if codeParam == "" {
+		c.errorHandler(w, r, &common.RequiredError{Field: "code"}, nil)
+		return
+	}
+	result, err := c.service.AdminShippingMethodsCodeGet(r.Context(), codeParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	_ = common.EncodeJSONResponse(result.Body, &result.Code, w)
+}
+
+// AdminShippingMethodsCodePut - Replace a shipping method by code
+func (c *DefaultAPIController) AdminShippingMethodsCodePut(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	codeParam := params["code"]
+	if codeParam == "" {
+		c.errorHandler(w, r, &common.RequiredError{Field: "code"}, nil)
+		return
+	}
+	shippingMethodParam := models.ShippingMethod{}
+	d := json.NewDecoder(r.Body)
+	d.DisallowUnknownFields()
+	if err := d.Decode(&shippingMethodParam); err != nil {
+		c.errorHandler(w, r, &common.ParsingError{Err: err}, nil)
+		return
+	}
+	if err := models.AssertShippingMethodRequired(shippingMethodParam); err != nil {
+		c.errorHandler(w, r, err, nil)
+		return
+	}
+	if err := models.AssertShippingMethodConstraints(shippingMethodParam); err != nil {
+		c.errorHandler(w, r, err, nil)
+		return
+	}
+	result, err := c.service.AdminShippingMethodsCodePut(r.Context(), codeParam, shippingMethodParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	_ = common.EncodeJSONResponse(result.Body, &result.Code, w)
+}
+
+// AdminShippingMethodsGet - Get a list of all shipping methods
+func (c *DefaultAPIController) AdminShippingMethodsGet(w http.ResponseWriter, r *http.Request) {
+	result, err := c.service.AdminShippingMethodsGet(r.Context())
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	_ = common.EncodeJSONResponse(result.Body, &result.Code, w)
+}
+
+// AdminShippingMethodsPost - Add a new shipping method
+func (c *DefaultAPIController) AdminShippingMethodsPost(w http.ResponseWriter, r *http.Request) {
+	shippingMethodParam := models.ShippingMethod{}
+	d := json.NewDecoder(r.Body)
+	d.DisallowUnknownFields()
+	if err := d.Decode(&shippingMethodParam); err != nil {
+		c.errorHandler(w, r, &common.ParsingError{Err: err}, nil)
+		return
+	}
+	if err := models.AssertShippingMethodRequired(shippingMethodParam); err != nil {
+		c.errorHandler(w, r, err, nil)
+		return
+	}
+	if err := models.AssertShippingMethodConstraints(shippingMethodParam); err != nil {
+		c.errorHandler(w, r, err, nil)
+		return
+	}
+	result, err := c.service.AdminShippingMethodsPost(r.Context(), shippingMethodParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	_ = common.EncodeJSONResponse(result.Body, &result.Code, w)
+}
+
+// ShippingMethodsGet - Get the shipping methods customers can choose
+func (c *DefaultAPIController) ShippingMethodsGet(w http.ResponseWriter, r *http.Request) {
+	result, err := c.service.ShippingMethodsGet(r.Context())
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	_ = common.EncodeJSONResponse(result.Body, &result.Code, w)
+}
@@ -0,0 +1,136 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/mayureshucsb2019/bookstore/service/common"
+	"github.com/mayureshucsb2019/bookstore/service/shipping/db"
+	"github.com/mayureshucsb2019/bookstore/service/shipping/models"
+)
+
+// DefaultAPIService is a service that implements the logic for the DefaultAPIServicer
+// This service manages the shipping methods customers choose from at checkout.
+type DefaultAPIService struct {
+	Repo *db.ShippingMethodRepository
+}
+
+// NewDefaultAPIService creates a default API service with the given repository.
+func NewDefaultAPIService(repo *db.ShippingMethodRepository) *DefaultAPIService {
+	return &DefaultAPIService{
+		Repo: repo,
+	}
+}
+
+// AdminShippingMethodsCodeDelete - Delete a shipping method by code
+func (s *DefaultAPIService) AdminShippingMethodsCodeDelete(ctx context.Context, code string) (common.ImplResponse, error) {
+	if err := s.Repo.DeleteShippingMethod(code); err != nil {
+		return shippingErrorResponse(err)
+	}
+
+	return common.Response(http.StatusNoContent, nil), nil
+}
+
+// AdminShippingMethodsCodeGet - Get a specific shipping method by code
+func (s *DefaultAPIService) AdminShippingMethodsCodeGet(ctx context.Context, code string) (common.ImplResponse, error) {
+	method, err := s.Repo.GetShippingMethodByCode(code)
+	if err != nil {
+		return shippingErrorResponse(err)
+	}
+
+	return common.Response(http.StatusOK, convertDBToAPIResponse(*method)), nil
+}
+
+// AdminShippingMethodsCodePut - Replace a shipping method by code
+func (s *DefaultAPIService) AdminShippingMethodsCodePut(ctx context.Context, code string, method models.ShippingMethod) (common.ImplResponse, error) {
+	if method.Code != code {
+		return common.Response(http.StatusBadRequest, nil), errors.New("code in the path does not match code in the body")
+	}
+
+	dbMethod := convertApiToDBShippingMethod(method)
+	if err := s.Repo.UpdateShippingMethod(&dbMethod); err != nil {
+		return shippingErrorResponse(err)
+	}
+
+	return common.Response(http.StatusOK, convertDBToAPIResponse(dbMethod)), nil
+}
+
+// AdminShippingMethodsGet - Get a list of all shipping methods
+func (s *DefaultAPIService) AdminShippingMethodsGet(ctx context.Context) (common.ImplResponse, error) {
+	return s.listShippingMethods(false)
+}
+
+// AdminShippingMethodsPost - Add a new shipping method
+func (s *DefaultAPIService) AdminShippingMethodsPost(ctx context.Context, method models.ShippingMethod) (common.ImplResponse, error) {
+	dbMethod := convertApiToDBShippingMethod(method)
+	if err := s.Repo.CreateShippingMethod(&dbMethod); err != nil {
+		return common.Response(http.StatusInternalServerError, nil), err
+	}
+
+	return common.Response(http.StatusCreated, nil), nil
+}
+
+// ShippingMethodsGet - Get the shipping methods customers can choose
+func (s *DefaultAPIService) ShippingMethodsGet(ctx context.Context) (common.ImplResponse, error) {
+	return s.listShippingMethods(true)
+}
+
+func (s *DefaultAPIService) listShippingMethods(activeOnly bool) (common.ImplResponse, error) {
+	methods, err := s.Repo.GetAllShippingMethods(activeOnly)
+	if err != nil {
+		return common.Response(http.StatusInternalServerError, nil), err
+	}
+	methodsResp := []models.ShippingMethod{}
+	for _, method := range methods {
+		methodsResp = append(methodsResp, convertDBToAPIResponse(method))
+	}
+
+	return common.Response(http.StatusOK, methodsResp), nil
+}
+
+// shippingErrorResponse maps repository errors to a not found or internal error response.
+func shippingErrorResponse(err error) (common.ImplResponse, error) {
+	if errors.Is(err, db.ErrShippingMethodNotFound) {
+		return common.Response(http.StatusNotFound, nil), err
+	}
+	return common.Response(http.StatusInternalServerError, nil), err
+}
+
+// convertApiToDBShippingMethod converts an API model ShippingMethod to a database model ShippingMethod.
+func convertApiToDBShippingMethod(method models.ShippingMethod) db.ShippingMethod {
+	ratePerUnit := common.NewMoney(0, method.BaseRate.Currency)
+	if method.RatePerUnit != nil {
+		ratePerUnit = *method.RatePerUnit
+	}
+
+	return db.ShippingMethod{
+		Code:          method.Code,
+		Name:          method.Name,
+		Carrier:       method.Carrier,
+		BaseRate:      method.BaseRate,
+		RatePerUnit:   ratePerUnit,
+		PagesPerUnit:  int(method.PagesPerUnit),
+		EstimatedDays: int(method.EstimatedDays),
+		Active:        method.Active == nil || *method.Active,
+	}
+}
+
+// convertDBToAPIResponse converts the DB model to the API model
+func convertDBToAPIResponse(method db.ShippingMethod) models.ShippingMethod {
+	active := method.Active
+	resp := models.ShippingMethod{
+		Code:          method.Code,
+		Name:          method.Name,
+		Carrier:       method.Carrier,
+		BaseRate:      method.BaseRate,
+		PagesPerUnit:  int32(method.PagesPerUnit),
+		EstimatedDays: int32(method.EstimatedDays),
+		Active:        &active,
+	}
+	if method.PagesPerUnit > 0 {
+		ratePerUnit := method.RatePerUnit
+		resp.RatePerUnit = &ratePerUnit
+	}
+	return resp
+}
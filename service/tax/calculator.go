@@ -0,0 +1,35 @@
+package tax
+
+import (
+	"context"
+
+	"github.com/mayureshucsb2019/bookstore/service/common"
+)
+
+// Address is the part of a customer address that determines the tax jurisdiction.
+type Address struct {
+	Country string
+	State   string
+	Zipcode string
+}
+
+// Line is a taxable order line. Amount is what the customer pays for the line after discounts.
+type Line struct {
+	ISBN   string
+	Tags   []string
+	Amount common.Money
+}
+
+// LineTax is the tax charged on one line. Rate is the percentage applied, kept with the amount
+// so receipts and refunds can be reproduced without recalculating against today's rates.
+type LineTax struct {
+	ISBN   string
+	Rate   string
+	Amount common.Money
+}
+
+// Calculator computes the tax owed on each line of an order shipped to an address.
+// Implementations return exactly one LineTax per line, in the same order.
+type Calculator interface {
+	Calculate(ctx context.Context, address Address, lines []Line) ([]LineTax, error)
+}
@@ -0,0 +1,118 @@
+package tax
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/mayureshucsb2019/bookstore/service/common"
+)
+
+// Rate is one row of a tax table. A rate without a state applies to the whole country;
+// a rate for a state replaces the country-wide rate, including its exemptions.
+type Rate struct {
+	Country string `json:"country"`
+	State   string `json:"state,omitempty"`
+	// Percentage charged, e.g. "7.25"
+	Rate string `json:"rate"`
+	// Books carrying any of these tags are not taxed in this jurisdiction
+	ExemptTags []string `json:"exempt_tags,omitempty"`
+}
+
+// TableCalculator is a Calculator driven by a fixed table of rates by country and state.
+// Addresses without a matching rate are not taxed.
+type TableCalculator struct {
+	rates map[[2]string]tableRate
+}
+
+type tableRate struct {
+	percent *big.Rat
+	rate    string
+	exempt  map[string]bool
+}
+
+// NewTableCalculator builds a calculator from the given rates.
+func NewTableCalculator(rates []Rate) (*TableCalculator, error) {
+	t := &TableCalculator{rates: map[[2]string]tableRate{}}
+	for _, rate := range rates {
+		percent, ok := new(big.Rat).SetString(strings.TrimSpace(rate.Rate))
+		if !ok || percent.Sign() < 0 || percent.Cmp(big.NewRat(100, 1)) > 0 {
+			return nil, fmt.Errorf("invalid tax rate %q for %s", rate.Rate, jurisdiction(rate.Country, rate.State))
+		}
+		if rate.Country == "" {
+			return nil, fmt.Errorf("tax rate %q has no country", rate.Rate)
+		}
+
+		exempt := map[string]bool{}
+		for _, tag := range rate.ExemptTags {
+			exempt[tag] = true
+		}
+		t.rates[key(rate.Country, rate.State)] = tableRate{
+			percent: percent,
+			rate:    percent.FloatString(4),
+			exempt:  exempt,
+		}
+	}
+	return t, nil
+}
+
+// LoadTableFile reads a JSON array of rates and builds a calculator from it.
+func LoadTableFile(filePath string) (*TableCalculator, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	var rates []Rate
+	if err := json.Unmarshal(data, &rates); err != nil {
+		return nil, fmt.Errorf("failed to parse tax rates file: %w", err)
+	}
+	return NewTableCalculator(rates)
+}
+
+// Calculate applies the rate of the address's state, or failing that its country, to each line.
+// Tax is rounded half to even once per line.
+func (t *TableCalculator) Calculate(ctx context.Context, address Address, lines []Line) ([]LineTax, error) {
+	rate, ok := t.rates[key(address.Country, address.State)]
+	if !ok {
+		rate, ok = t.rates[key(address.Country, "")]
+	}
+
+	taxes := make([]LineTax, 0, len(lines))
+	for _, line := range lines {
+		lineTax := LineTax{ISBN: line.ISBN, Rate: "0.0000", Amount: common.Money{Currency: line.Amount.Currency}}
+		if ok && !isExempt(rate, line.Tags) {
+			amount, err := common.ConvertMoney(line.Amount, new(big.Rat).Quo(rate.percent, big.NewRat(100, 1)), line.Amount.Currency)
+			if err != nil {
+				return nil, err
+			}
+			lineTax.Rate, lineTax.Amount = rate.rate, amount
+		}
+		taxes = append(taxes, lineTax)
+	}
+	return taxes, nil
+}
+
+func isExempt(rate tableRate, tags []string) bool {
+	for _, tag := range tags {
+		if rate.exempt[tag] {
+			return true
+		}
+	}
+	return false
+}
+
+// key normalizes a jurisdiction so free-text customer addresses match regardless of case.
+func key(country, state string) [2]string {
+	return [2]string{strings.ToUpper(strings.TrimSpace(country)), strings.ToUpper(strings.TrimSpace(state))}
+}
+
+func jurisdiction(country, state string) string {
+	if state == "" {
+		return country
+	}
+	return country + "/" + state
+}
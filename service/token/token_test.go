@@ -0,0 +1,113 @@
+package token
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testSigner returns a signer with a fixed key whose clock is read from now.
+func testSigner(t *testing.T, now *time.Time) *Signer {
+	t.Helper()
+	signer, err := NewSigner(bytes.Repeat([]byte{7}, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer.now = func() time.Time { return *now }
+	return signer
+}
+
+func TestIssueVerify(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 500, time.UTC)
+	signer := testSigner(t, &now)
+
+	tok, expires, err := signer.Issue("password-reset", "42:3f2a", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(2026, 3, 1, 13, 0, 0, 0, time.UTC); !expires.Equal(want) {
+		t.Errorf("token expires at %s, want %s", expires, want)
+	}
+	subject, err := signer.Verify(tok, "password-reset")
+	if err != nil || subject != "42:3f2a" {
+		t.Errorf("Verify() = %q, %v, want the subject", subject, err)
+	}
+	if _, err := signer.Verify(tok, "verify-email"); !errors.Is(err, ErrInvalid) {
+		t.Errorf("Verify() for another purpose = %v, want ErrInvalid", err)
+	}
+}
+
+func TestVerifyTampered(t *testing.T) {
+	now := time.Now()
+	signer := testSigner(t, &now)
+	tok, _, err := signer.Issue("verify-email", "42:ann@example.com", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload, signature, _ := strings.Cut(tok, ".")
+
+	forged := base64.RawURLEncoding.EncodeToString([]byte(`{"p":"verify-email","s":"43:ann@example.com","e":9999999999}`))
+	flipped := []byte(signature)
+	if flipped[0] == 'A' {
+		flipped[0] = 'B'
+	} else {
+		flipped[0] = 'A'
+	}
+	other, err := NewSigner(bytes.Repeat([]byte{8}, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherTok, _, err := other.Issue("verify-email", "42:ann@example.com", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, tampered := range map[string]string{
+		"payload":      forged + "." + signature,
+		"signature":    payload + "." + string(flipped),
+		"no signature": payload,
+		"not base64":   payload + ".!!!",
+		"other key":    otherTok,
+		"empty":        "",
+	} {
+		if _, err := signer.Verify(tampered, "verify-email"); !errors.Is(err, ErrInvalid) {
+			t.Errorf("Verify() with a tampered %s = %v, want ErrInvalid", name, err)
+		}
+	}
+}
+
+func TestVerifyExpired(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	signer := testSigner(t, &now)
+	tok, _, err := signer.Issue("password-reset", "42:3f2a", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now = now.Add(time.Hour - time.Second)
+	if _, err := signer.Verify(tok, "password-reset"); err != nil {
+		t.Errorf("Verify() a second before expiry = %v", err)
+	}
+	now = now.Add(time.Second)
+	if _, err := signer.Verify(tok, "password-reset"); !errors.Is(err, ErrExpired) {
+		t.Errorf("Verify() at expiry = %v, want ErrExpired", err)
+	}
+}
+
+func TestNewSignerFromSecret(t *testing.T) {
+	if signer, err := NewSignerFromSecret(""); signer != nil || err != nil {
+		t.Errorf("NewSignerFromSecret(\"\") = %v, %v, want neither", signer, err)
+	}
+	if _, err := NewSignerFromSecret("not base64!"); err == nil {
+		t.Error("NewSignerFromSecret() with a secret that is not base64 succeeded")
+	}
+	if _, err := NewSignerFromSecret(base64.StdEncoding.EncodeToString(make([]byte, 31))); err == nil {
+		t.Error("NewSignerFromSecret() with a 31 byte key succeeded")
+	}
+	if _, err := NewSignerFromSecret(base64.StdEncoding.EncodeToString(make([]byte, 32))); err != nil {
+		t.Errorf("NewSignerFromSecret() with a 32 byte key = %v", err)
+	}
+}
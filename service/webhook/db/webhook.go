@@ -0,0 +1,410 @@
+package db
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	_ "github.com/go-sql-driver/mysql"
+	"github.com/mayureshucsb2019/bookstore/service/common"
+)
+
+// Delivery statuses. Pending deliveries are waiting for their first or next attempt; dead ones
+// ran out of attempts and are only sent again when redelivered by hand.
+const (
+	DeliveryPending   = "pending"
+	DeliverySucceeded = "succeeded"
+	DeliveryDead      = "dead"
+)
+
+// AllEvents subscribes to every event type.
+const AllEvents = "*"
+
+// TimeLayout is the layout of the DATETIME(3) webhook columns, which hold UTC times.
+const TimeLayout = "2006-01-02 15:04:05.000"
+
+var (
+	// ErrSubscriptionNotFound is returned when no webhook subscription exists with the requested id
+	ErrSubscriptionNotFound = errors.New("webhook subscription not found")
+	// ErrDeliveryNotFound is returned when the subscription has no delivery with the requested id
+	ErrDeliveryNotFound = errors.New("webhook delivery not found")
+)
+
+// Subscription represents the structure of a WebhookSubscriptions record in the database.
+type Subscription struct {
+	ID         int64
+	URL        string
+	EventTypes []string // Event types to send, or AllEvents
+	Secret     string   // Key the payloads are signed with
+	Active     bool
+	CreatedAt  string
+}
+
+// Matches reports whether the subscription wants events of eventType.
+func (s Subscription) Matches(eventType string) bool {
+	for _, t := range s.EventTypes {
+		if t == AllEvents || t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// Delivery represents the structure of a WebhookDeliveries record, one event sent to one subscription.
+type Delivery struct {
+	ID             int64
+	SubscriptionID int64
+	EventID        int64
+	EventType      string
+	Payload        []byte // Request body, kept so redeliveries send exactly the same bytes
+	Status         string
+	Attempts       int
+	NextAttemptAt  string
+	LastStatusCode int
+	LastError      string
+	CreatedAt      string
+	DeliveredAt    string
+}
+
+// Attempt represents the structure of a WebhookDeliveryAttempts record, one HTTP request of a delivery.
+type Attempt struct {
+	DeliveryID  int64
+	Attempt     int
+	StatusCode  int // 0 when no response was received
+	Error       string
+	DurationMs  int64
+	AttemptedAt string
+}
+
+// WebhookRepository provides access to the webhook subscription and delivery storage.
+type WebhookRepository struct {
+	DB *sql.DB
+}
+
+const subscriptionColumns = `id, url, event_types, secret, active, created_at`
+
+// CreateSubscription inserts a new subscription and sets its ID.
+func (r *WebhookRepository) CreateSubscription(subscription *Subscription) error {
+	eventTypes, err := json.Marshal(subscription.EventTypes)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event types: %w", err)
+	}
+	result, err := r.DB.Exec(
+		`INSERT INTO WebhookSubscriptions (url, event_types, secret, active) VALUES (?, ?, ?, ?)`,
+		subscription.URL, eventTypes, subscription.Secret, subscription.Active,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert webhook subscription: %w", err)
+	}
+	if subscription.ID, err = result.LastInsertId(); err != nil {
+		return fmt.Errorf("failed to read webhook subscription id: %w", err)
+	}
+	return nil
+}
+
+// UpdateSubscription updates the URL, event types, secret and active flag of a subscription.
+func (r *WebhookRepository) UpdateSubscription(subscription *Subscription) error {
+	eventTypes, err := json.Marshal(subscription.EventTypes)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event types: %w", err)
+	}
+	result, err := r.DB.Exec(
+		`UPDATE WebhookSubscriptions SET url = ?, event_types = ?, secret = ?, active = ? WHERE id = ?`,
+		subscription.URL, eventTypes, subscription.Secret, subscription.Active, subscription.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update webhook subscription: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		// MySQL reports zero rows for an update that changes nothing, so confirm the row exists
+		if _, err := r.GetSubscriptionByID(subscription.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeleteSubscription removes a subscription together with its deliveries.
+func (r *WebhookRepository) DeleteSubscription(id int64) error {
+	result, err := r.DB.Exec(`DELETE FROM WebhookSubscriptions WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook subscription: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("%w: %d", ErrSubscriptionNotFound, id)
+	}
+	return nil
+}
+
+// GetSubscriptionByID retrieves a subscription by its id.
+func (r *WebhookRepository) GetSubscriptionByID(id int64) (*Subscription, error) {
+	subscriptions, err := r.querySubscriptions(`SELECT `+subscriptionColumns+` FROM WebhookSubscriptions WHERE id = ?`, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(subscriptions) == 0 {
+		return nil, fmt.Errorf("%w: %d", ErrSubscriptionNotFound, id)
+	}
+	return &subscriptions[0], nil
+}
+
+// GetAllSubscriptions retrieves every subscription, or only the active ones.
+func (r *WebhookRepository) GetAllSubscriptions(activeOnly bool) ([]Subscription, error) {
+	query := `SELECT ` + subscriptionColumns + ` FROM WebhookSubscriptions`
+	if activeOnly {
+		query += ` WHERE active = TRUE`
+	}
+	return r.querySubscriptions(query + ` ORDER BY id`)
+}
+
+func (r *WebhookRepository) querySubscriptions(query string, args ...interface{}) ([]Subscription, error) {
+	rows, err := r.DB.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query webhook subscriptions: %w", err)
+	}
+	defer rows.Close()
+
+	var subscriptions []Subscription
+	for rows.Next() {
+		var subscription Subscription
+		var eventTypes []byte
+		err := rows.Scan(&subscription.ID, &subscription.URL, &eventTypes, &subscription.Secret, &subscription.Active, &subscription.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan webhook subscription: %w", err)
+		}
+		if err := json.Unmarshal(eventTypes, &subscription.EventTypes); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal event types: %w", err)
+		}
+		subscriptions = append(subscriptions, subscription)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+
+	return subscriptions, nil
+}
+
+const deliveryColumns = `id, subscription_id, event_id, event_type, payload, status, attempts, next_attempt_at,
+	last_status_code, last_error, created_at, delivered_at`
+
+// EnqueueDelivery schedules an event for a subscription. Enqueuing the same event twice is a
+// no-op, so the outbox can redeliver events to the webhook sink safely.
+func (r *WebhookRepository) EnqueueDelivery(subscriptionID int64, eventID int64, eventType string, payload []byte) error {
+	_, err := r.DB.Exec(
+		`INSERT IGNORE INTO WebhookDeliveries (subscription_id, event_id, event_type, payload, status, next_attempt_at)
+		VALUES (?, ?, ?, ?, ?, ?)`,
+		subscriptionID, eventID, eventType, string(payload), DeliveryPending, time.Now().UTC().Format(TimeLayout),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to enqueue webhook delivery: %w", err)
+	}
+	return nil
+}
+
+// ClaimDueDeliveries returns up to limit pending deliveries whose next attempt is due, oldest
+// first, and hides them from other workers for lease.
+func (r *WebhookRepository) ClaimDueDeliveries(limit int, lease time.Duration) ([]Delivery, error) {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now().UTC()
+	deliveries, err := queryDeliveries(tx, `SELECT `+deliveryColumns+` FROM WebhookDeliveries
+		WHERE status = ? AND next_attempt_at <= ? ORDER BY id LIMIT ? FOR UPDATE SKIP LOCKED`,
+		DeliveryPending, now.Format(TimeLayout), limit)
+	if err != nil || len(deliveries) == 0 {
+		return nil, err
+	}
+
+	args := []interface{}{now.Add(lease).Format(TimeLayout)}
+	for _, delivery := range deliveries {
+		args = append(args, delivery.ID)
+	}
+	query := `UPDATE WebhookDeliveries SET next_attempt_at = ? WHERE id IN (?` + strings.Repeat(", ?", len(deliveries)-1) + `)`
+	if _, err := tx.Exec(query, args...); err != nil {
+		return nil, fmt.Errorf("failed to claim webhook deliveries: %w", err)
+	}
+
+	return deliveries, tx.Commit()
+}
+
+// RecordAttempt logs one attempt of a delivery and moves the delivery to its new status.
+// nextAttempt is only used while the delivery stays pending.
+func (r *WebhookRepository) RecordAttempt(delivery *Delivery, attempt Attempt, nextAttempt time.Time) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if len(attempt.Error) > 1024 {
+		attempt.Error = attempt.Error[:1024]
+	}
+	_, err = tx.Exec(
+		`INSERT INTO WebhookDeliveryAttempts (delivery_id, attempt, status_code, error, duration_ms, attempted_at) VALUES (?, ?, ?, ?, ?, ?)`,
+		delivery.ID, attempt.Attempt, nullInt(attempt.StatusCode), common.NullStringOrNil(attempt.Error), attempt.DurationMs, attempt.AttemptedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to log webhook delivery attempt: %w", err)
+	}
+
+	var deliveredAt interface{}
+	if delivery.Status == DeliverySucceeded {
+		deliveredAt = attempt.AttemptedAt
+	}
+	_, err = tx.Exec(
+		`UPDATE WebhookDeliveries SET status = ?, attempts = ?, next_attempt_at = ?, last_status_code = ?, last_error = ?, delivered_at = ?
+		WHERE id = ?`,
+		delivery.Status, delivery.Attempts, nextAttempt.UTC().Format(TimeLayout), nullInt(attempt.StatusCode),
+		common.NullStringOrNil(attempt.Error), deliveredAt, delivery.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update webhook delivery: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// GetDeliveries retrieves the latest deliveries of a subscription, newest first, optionally
+// only those with the given status.
+func (r *WebhookRepository) GetDeliveries(subscriptionID int64, status string, limit int) ([]Delivery, error) {
+	if _, err := r.GetSubscriptionByID(subscriptionID); err != nil {
+		return nil, err
+	}
+	query := `SELECT ` + deliveryColumns + ` FROM WebhookDeliveries WHERE subscription_id = ?`
+	args := []interface{}{subscriptionID}
+	if status != "" {
+		query += ` AND status = ?`
+		args = append(args, status)
+	}
+	query += ` ORDER BY id DESC LIMIT ?`
+	return queryDeliveries(r.DB, query, append(args, limit)...)
+}
+
+// GetDelivery retrieves a delivery of a subscription.
+func (r *WebhookRepository) GetDelivery(subscriptionID int64, deliveryID int64) (*Delivery, error) {
+	deliveries, err := queryDeliveries(r.DB, `SELECT `+deliveryColumns+` FROM WebhookDeliveries WHERE subscription_id = ? AND id = ?`,
+		subscriptionID, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+	if len(deliveries) == 0 {
+		return nil, fmt.Errorf("%w: %d", ErrDeliveryNotFound, deliveryID)
+	}
+	return &deliveries[0], nil
+}
+
+// GetAttempts retrieves the attempts of a delivery, oldest first.
+func (r *WebhookRepository) GetAttempts(deliveryID int64) ([]Attempt, error) {
+	rows, err := r.DB.Query(
+		`SELECT delivery_id, attempt, status_code, error, duration_ms, attempted_at FROM WebhookDeliveryAttempts
+		WHERE delivery_id = ? ORDER BY id`, deliveryID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query webhook delivery attempts: %w", err)
+	}
+	defer rows.Close()
+
+	attempts := []Attempt{}
+	for rows.Next() {
+		var attempt Attempt
+		var statusCode sql.NullInt64
+		var errMsg sql.NullString
+		if err := rows.Scan(&attempt.DeliveryID, &attempt.Attempt, &statusCode, &errMsg, &attempt.DurationMs, &attempt.AttemptedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan webhook delivery attempt: %w", err)
+		}
+		attempt.StatusCode = int(statusCode.Int64)
+		attempt.Error = errMsg.String
+		attempts = append(attempts, attempt)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+
+	return attempts, nil
+}
+
+// ResetDelivery puts a delivery back in the queue with a fresh set of attempts, whatever its status.
+func (r *WebhookRepository) ResetDelivery(subscriptionID int64, deliveryID int64) error {
+	result, err := r.DB.Exec(
+		`UPDATE WebhookDeliveries SET status = ?, attempts = 0, next_attempt_at = ? WHERE subscription_id = ? AND id = ?`,
+		DeliveryPending, time.Now().UTC().Format(TimeLayout), subscriptionID, deliveryID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to reset webhook delivery: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		if _, err := r.GetDelivery(subscriptionID, deliveryID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// querier is implemented by both *sql.DB and *sql.Tx.
+type querier interface {
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+}
+
+func queryDeliveries(q querier, query string, args ...interface{}) ([]Delivery, error) {
+	rows, err := q.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	var deliveries []Delivery
+	for rows.Next() {
+		var delivery Delivery
+		var payload string
+		var statusCode sql.NullInt64
+		var lastError, deliveredAt sql.NullString
+		err := rows.Scan(&delivery.ID, &delivery.SubscriptionID, &delivery.EventID, &delivery.EventType, &payload, &delivery.Status,
+			&delivery.Attempts, &delivery.NextAttemptAt, &statusCode, &lastError, &delivery.CreatedAt, &deliveredAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan webhook delivery: %w", err)
+		}
+		delivery.Payload = []byte(payload)
+		delivery.LastStatusCode = int(statusCode.Int64)
+		delivery.LastError = lastError.String
+		delivery.DeliveredAt = deliveredAt.String
+		deliveries = append(deliveries, delivery)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+
+	return deliveries, nil
+}
+
+func nullInt(value int) sql.NullInt64 {
+	return sql.NullInt64{Int64: int64(value), Valid: value != 0}
+}
+
+// RedactDeliveries replaces the stored payload of every delivery of an event inside tx, after
+// personal data was erased from the event. Later redeliveries send the redacted payload.
+func RedactDeliveries(tx *sql.Tx, eventID int64, payload []byte) error {
+	if _, err := tx.Exec(`UPDATE WebhookDeliveries SET payload = ? WHERE event_id = ?`, string(payload), eventID); err != nil {
+		return fmt.Errorf("failed to redact webhook deliveries of event %d: %w", eventID, err)
+	}
+	return nil
+}
@@ -0,0 +1,19 @@
+package db
+
+import (
+	"sync"
+
+	"github.com/mayureshucsb2019/bookstore/service/common"
+)
+
+var webhookRepoInstance *WebhookRepository
+var webhookRepoOnce sync.Once
+
+func NewWebhookRepository(db *common.DBConnection) *WebhookRepository {
+	webhookRepoOnce.Do(func() {
+		webhookRepoInstance = &WebhookRepository{
+			DB: db.DB,
+		}
+	})
+	return webhookRepoInstance
+}
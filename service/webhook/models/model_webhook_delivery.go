@@ -0,0 +1,48 @@
+package models
+
+import (
+	"encoding/json"
+)
+
+type WebhookDelivery struct {
+	Id int64 `json:"id"`
+
+	SubscriptionId int64 `json:"subscription_id"`
+
+	// Id of the outbox event, also sent in the X-Bookstore-Event-Id header.
+	EventId int64 `json:"event_id"`
+
+	EventType string `json:"event_type"`
+
+	// One of pending, succeeded or dead.
+	Status string `json:"status"`
+
+	Attempts int32 `json:"attempts"`
+
+	// When a pending delivery is tried next.
+	NextAttemptAt string `json:"next_attempt_at,omitempty"`
+
+	LastStatusCode int32 `json:"last_status_code,omitempty"`
+
+	LastError string `json:"last_error,omitempty"`
+
+	CreatedAt string `json:"created_at"`
+
+	DeliveredAt string `json:"delivered_at,omitempty"`
+
+	// The request body sent to the subscriber, only included for a single delivery.
+	Payload json.RawMessage `json:"payload,omitempty"`
+
+	// Every request made for the delivery, only included for a single delivery.
+	AttemptLog []WebhookDeliveryAttempt `json:"attempt_log,omitempty"`
+}
+
+// AssertWebhookDeliveryRequired checks if the required fields are not zero-ed
+func AssertWebhookDeliveryRequired(obj WebhookDelivery) error {
+	return nil
+}
+
+// AssertWebhookDeliveryConstraints checks if the values respects the defined constraints
+func AssertWebhookDeliveryConstraints(obj WebhookDelivery) error {
+	return nil
+}
@@ -0,0 +1,24 @@
+package models
+
+type WebhookDeliveryAttempt struct {
+	Attempt int32 `json:"attempt"`
+
+	// Response status, omitted when the subscriber could not be reached.
+	StatusCode int32 `json:"status_code,omitempty"`
+
+	Error string `json:"error,omitempty"`
+
+	DurationMs int64 `json:"duration_ms"`
+
+	AttemptedAt string `json:"attempted_at"`
+}
+
+// AssertWebhookDeliveryAttemptRequired checks if the required fields are not zero-ed
+func AssertWebhookDeliveryAttemptRequired(obj WebhookDeliveryAttempt) error {
+	return nil
+}
+
+// AssertWebhookDeliveryAttemptConstraints checks if the values respects the defined constraints
+func AssertWebhookDeliveryAttemptConstraints(obj WebhookDeliveryAttempt) error {
+	return nil
+}
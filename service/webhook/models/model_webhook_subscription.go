@@ -0,0 +1,63 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+
+	"github.com/mayureshucsb2019/bookstore/service/common"
+	event_db "github.com/mayureshucsb2019/bookstore/service/event/db"
+)
+
+type WebhookSubscription struct {
+	Id int64 `json:"id,omitempty"`
+
+	// HTTP or HTTPS endpoint the events are POSTed to.
+	Url string `json:"url"`
+
+	// Event types to send, e.g. ["BookPriceChanged", "OrderPlaced"], or ["*"] for every event.
+	EventTypes []string `json:"event_types"`
+
+	// Key the payloads are signed with. Generated when omitted on creation, and only returned
+	// when the subscription is created or the secret is replaced.
+	Secret string `json:"secret,omitempty"`
+
+	// Defaults to true when omitted.
+	Active *bool `json:"active,omitempty"`
+
+	CreatedAt string `json:"created_at,omitempty"`
+}
+
+// AssertWebhookSubscriptionRequired checks if the required fields are not zero-ed
+func AssertWebhookSubscriptionRequired(obj WebhookSubscription) error {
+	elements := map[string]interface{}{
+		"url": obj.Url,
+	}
+	for name, el := range elements {
+		if isZero := common.IsZeroValue(el); isZero {
+			return &common.RequiredError{Field: name}
+		}
+	}
+	if len(obj.EventTypes) == 0 {
+		return &common.RequiredError{Field: "event_types"}
+	}
+
+	return nil
+}
+
+// AssertWebhookSubscriptionConstraints checks if the values respects the defined constraints
+func AssertWebhookSubscriptionConstraints(obj WebhookSubscription) error {
+	target, err := url.Parse(obj.Url)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return &common.ParsingError{Param: "url", Err: errors.New("must be an absolute http or https URL")}
+	}
+	for _, eventType := range obj.EventTypes {
+		if eventType != "*" && !event_db.IsEventType(eventType) {
+			return &common.ParsingError{Param: "event_types", Err: fmt.Errorf("unknown event type %q", eventType)}
+		}
+	}
+	if obj.Secret != "" && len(obj.Secret) < 16 {
+		return &common.ParsingError{Param: "secret", Err: errors.New("must be at least 16 characters")}
+	}
+	return nil
+}
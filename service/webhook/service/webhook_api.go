@@ -0,0 +1,38 @@
+package service
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/mayureshucsb2019/bookstore/service/common"
+	"github.com/mayureshucsb2019/bookstore/service/webhook/models"
+)
+
+// DefaultAPIRouter defines the required methods for binding the api requests to a responses for the DefaultAPI
+// The DefaultAPIRouter implementation should parse necessary information from the http request,
+// pass the data to a DefaultAPIServicer to perform the required actions, then write the service results to the http response.
+type DefaultAPIRouter interface {
+	AdminWebhooksGet(http.ResponseWriter, *http.Request)
+	AdminWebhooksIdDeliveriesDeliveryIdGet(http.ResponseWriter, *http.Request)
+	AdminWebhooksIdDeliveriesDeliveryIdRedeliverPost(http.ResponseWriter, *http.Request)
+	AdminWebhooksIdDeliveriesGet(http.ResponseWriter, *http.Request)
+	AdminWebhooksIdDelete(http.ResponseWriter, *http.Request)
+	AdminWebhooksIdGet(http.ResponseWriter, *http.Request)
+	AdminWebhooksIdPut(http.ResponseWriter, *http.Request)
+	AdminWebhooksPost(http.ResponseWriter, *http.Request)
+}
+
+// DefaultAPIServicer defines the api actions for the DefaultAPI service
+// This interface intended to stay up to date with the openapi yaml used to generate it,
+// while the service implementation can be ignored with the .openapi-generator-ignore file
+// and updated with the logic required for the API.
+type DefaultAPIServicer interface {
+	AdminWebhooksGet(context.Context) (common.ImplResponse, error)
+	AdminWebhooksIdDeliveriesDeliveryIdGet(context.Context, int64, int64) (common.ImplResponse, error)
+	AdminWebhooksIdDeliveriesDeliveryIdRedeliverPost(context.Context, int64, int64) (common.ImplResponse, error)
+	AdminWebhooksIdDeliveriesGet(context.Context, int64, string) (common.ImplResponse, error)
+	AdminWebhooksIdDelete(context.Context, int64) (common.ImplResponse, error)
+	AdminWebhooksIdGet(context.Context, int64) (common.ImplResponse, error)
+	AdminWebhooksIdPut(context.Context, int64, models.WebhookSubscription) (common.ImplResponse, error)
+	AdminWebhooksPost(context.Context, models.WebhookSubscription) (common.ImplResponse, error)
+}
@@ -0,0 +1,284 @@
+package service
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/mayureshucsb2019/bookstore/service/common"
+	"github.com/mayureshucsb2019/bookstore/service/webhook/models"
+)
+
+// DefaultAPIController binds http requests to an api service and writes the service results to the http response
+type DefaultAPIController struct {
+	service      DefaultAPIServicer
+	errorHandler common.ErrorHandler
+}
+
+// DefaultAPIOption for how the controller is set up.
+type DefaultAPIOption func(*DefaultAPIController)
+
+// WithDefaultAPIErrorHandler inject ErrorHandler into controller
+func WithDefaultAPIErrorHandler(h common.ErrorHandler) DefaultAPIOption {
+	return func(c *DefaultAPIController) {
+		c.errorHandler = h
+	}
+}
+
+// NewDefaultAPIController creates a default api controller
+func NewDefaultAPIController(s DefaultAPIServicer, opts ...DefaultAPIOption) *DefaultAPIController {
+	controller := &DefaultAPIController{
+		service:      s,
+		errorHandler: common.DefaultErrorHandler,
+	}
+
+	for _, opt := range opts {
+		opt(controller)
+	}
+
+	return controller
+}
+
+// Routes returns all the api routes for the DefaultAPIController
+func (c *DefaultAPIController) Routes() common.Routes {
+	return common.Routes{
+		"AdminWebhooksGet": common.Route{
+			Method:      strings.ToUpper("Get"),
+			Pattern:     "/admin/webhooks",
+			HandlerFunc: c.AdminWebhooksGet,
+		},
+		"AdminWebhooksIdDelete": common.Route{
+			Method:      strings.ToUpper("Delete"),
+			Pattern:     "/admin/webhooks/{id}",
+			HandlerFunc: c.AdminWebhooksIdDelete,
+		},
+		"AdminWebhooksIdDeliveriesDeliveryIdGet": common.Route{
+			Method:      strings.ToUpper("Get"),
+			Pattern:     "/admin/webhooks/{id}/deliveries/{deliveryId}",
+			HandlerFunc: c.AdminWebhooksIdDeliveriesDeliveryIdGet,
+		},
+		"AdminWebhooksIdDeliveriesDeliveryIdRedeliverPost": common.Route{
+			Method:      strings.ToUpper("Post"),
+			Pattern:     "/admin/webhooks/{id}/deliveries/{deliveryId}/redeliver",
+			HandlerFunc: c.AdminWebhooksIdDeliveriesDeliveryIdRedeliverPost,
+		},
+		"AdminWebhooksIdDeliveriesGet": common.Route{
+			Method:      strings.ToUpper("Get"),
+			Pattern:     "/admin/webhooks/{id}/deliveries",
+			HandlerFunc: c.AdminWebhooksIdDeliveriesGet,
+		},
+		"AdminWebhooksIdGet": common.Route{
+			Method:      strings.ToUpper("Get"),
+			Pattern:     "/admin/webhooks/{id}",
+			HandlerFunc: c.AdminWebhooksIdGet,
+		},
+		"AdminWebhooksIdPut": common.Route{
+			Method:      strings.ToUpper("Put"),
+			Pattern:     "/admin/webhooks/{id}",
+			HandlerFunc: c.AdminWebhooksIdPut,
+		},
+		"AdminWebhooksPost": common.Route{
+			Method:      strings.ToUpper("Post"),
+			Pattern:     "/admin/webhooks",
+			HandlerFunc: c.AdminWebhooksPost,
+		},
+	}
+}
+
+// AdminWebhooksGet - Get a list of all webhook subscriptions
+func (c *DefaultAPIController) AdminWebhooksGet(w http.ResponseWriter, r *http.Request) {
+	result, err := c.service.AdminWebhooksGet(r.Context())
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	_ = common.EncodeJSONResponse(result.Body, &result.Code, w)
+}
+
+// AdminWebhooksIdDelete - Delete a webhook subscription by ID
+func (c *DefaultAPIController) AdminWebhooksIdDelete(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	idParam, err := common.ParseNumericParameter[int64](
+		params["id"],
+		common.WithRequire[int64](common.ParseInt64),
+	)
+	if err != nil {
+		c.errorHandler(w, r, &common.ParsingError{Param: "id", Err: err}, nil)
+		return
+	}
+	result, err := c.service.AdminWebhooksIdDelete(r.Context(), idParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	_ = common.EncodeJSONResponse(result.Body, &result.Code, w)
+}
+
+// AdminWebhooksIdDeliveriesDeliveryIdGet - Get a delivery with its attempt log
+func (c *DefaultAPIController) AdminWebhooksIdDeliveriesDeliveryIdGet(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	idParam, err := common.ParseNumericParameter[int64](
+		params["id"],
+		common.WithRequire[int64](common.ParseInt64),
+	)
+	if err != nil {
+		c.errorHandler(w, r, &common.ParsingError{Param: "id", Err: err}, nil)
+		return
+	}
+	deliveryIdParam, err := common.ParseNumericParameter[int64](
+		params["deliveryId"],
+		common.WithRequire[int64](common.ParseInt64),
+	)
+	if err != nil {
+		c.errorHandler(w, r, &common.ParsingError{Param: "deliveryId", Err: err}, nil)
+		return
+	}
+	result, err := c.service.AdminWebhooksIdDeliveriesDeliveryIdGet(r.Context(), idParam, deliveryIdParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	_ = common.EncodeJSONResponse(result.Body, &result.Code, w)
+}
+
+// AdminWebhooksIdDeliveriesDeliveryIdRedeliverPost - Queue a delivery to be sent again
+func (c *DefaultAPIController) AdminWebhooksIdDeliveriesDeliveryIdRedeliverPost(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	idParam, err := common.ParseNumericParameter[int64](
+		params["id"],
+		common.WithRequire[int64](common.ParseInt64),
+	)
+	if err != nil {
+		c.errorHandler(w, r, &common.ParsingError{Param: "id", Err: err}, nil)
+		return
+	}
+	deliveryIdParam, err := common.ParseNumericParameter[int64](
+		params["deliveryId"],
+		common.WithRequire[int64](common.ParseInt64),
+	)
+	if err != nil {
+		c.errorHandler(w, r, &common.ParsingError{Param: "deliveryId", Err: err}, nil)
+		return
+	}
+	result, err := c.service.AdminWebhooksIdDeliveriesDeliveryIdRedeliverPost(r.Context(), idParam, deliveryIdParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	_ = common.EncodeJSONResponse(result.Body, &result.Code, w)
+}
+
+// AdminWebhooksIdDeliveriesGet - Get the latest deliveries of a webhook subscription
+func (c *DefaultAPIController) AdminWebhooksIdDeliveriesGet(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	idParam, err := common.ParseNumericParameter[int64](
+		params["id"],
+		common.WithRequire[int64](common.ParseInt64),
+	)
+	if err != nil {
+		c.errorHandler(w, r, &common.ParsingError{Param: "id", Err: err}, nil)
+		return
+	}
+	statusParam := r.URL.Query().Get("status")
+	result, err := c.service.AdminWebhooksIdDeliveriesGet(r.Context(), idParam, statusParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	_ = common.EncodeJSONResponse(result.Body, &result.Code, w)
+}
+
+// AdminWebhooksIdGet - Get a specific webhook subscription by ID
+func (c *DefaultAPIController) AdminWebhooksIdGet(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	idParam, err := common.ParseNumericParameter[int64](
+		params["id"],
+		common.WithRequire[int64](common.ParseInt64),
+	)
+	if err != nil {
+		c.errorHandler(w, r, &common.ParsingError{Param: "id", Err: err}, nil)
+		return
+	}
+	result, err := c.service.AdminWebhooksIdGet(r.Context(), idParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	_ = common.EncodeJSONResponse(result.Body, &result.Code, w)
+}
+
+// AdminWebhooksIdPut - Replace a webhook subscription by ID
+func (c *DefaultAPIController) AdminWebhooksIdPut(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	idParam, err := common.ParseNumericParameter[int64](
+		params["id"],
+		common.WithRequire[int64](common.ParseInt64),
+	)
+	if err != nil {
+		c.errorHandler(w, r, &common.ParsingError{Param: "id", Err: err}, nil)
+		return
+	}
+	webhookSubscriptionParam := models.WebhookSubscription{}
+	d := json.NewDecoder(r.Body)
+	d.DisallowUnknownFields()
+	if err := d.Decode(&webhookSubscriptionParam); err != nil {
+		c.errorHandler(w, r, &common.ParsingError{Err: err}, nil)
+		return
+	}
+	if err := models.AssertWebhookSubscriptionRequired(webhookSubscriptionParam); err != nil {
+		c.errorHandler(w, r, err, nil)
+		return
+	}
+	if err := models.AssertWebhookSubscriptionConstraints(webhookSubscriptionParam); err != nil {
+		c.errorHandler(w, r, err, nil)
+		return
+	}
+	result, err := c.service.AdminWebhooksIdPut(r.Context(), idParam, webhookSubscriptionParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	_ = common.EncodeJSONResponse(result.Body, &result.Code, w)
+}
+
+// AdminWebhooksPost - Add a new webhook subscription
+func (c *DefaultAPIController) AdminWebhooksPost(w http.ResponseWriter, r *http.Request) {
+	webhookSubscriptionParam := models.WebhookSubscription{}
+	d := json.NewDecoder(r.Body)
+	d.DisallowUnknownFields()
+	if err := d.Decode(&webhookSubscriptionParam); err != nil {
+		c.errorHandler(w, r, &common.ParsingError{Err: err}, nil)
+		return
+	}
+	if err := models.AssertWebhookSubscriptionRequired(webhookSubscriptionParam); err != nil {
+		c.errorHandler(w, r, err, nil)
+		return
+	}
+	if err := models.AssertWebhookSubscriptionConstraints(webhookSubscriptionParam); err != nil {
+		c.errorHandler(w, r, err, nil)
+		return
+	}
+	result, err := c.service.AdminWebhooksPost(r.Context(), webhookSubscriptionParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	_ = common.EncodeJSONResponse(result.Body, &result.Code, w)
+}
@@ -0,0 +1,235 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/mayureshucsb2019/bookstore/service/common"
+	"github.com/mayureshucsb2019/bookstore/service/webhook/db"
+	"github.com/mayureshucsb2019/bookstore/service/webhook/models"
+)
+
+// deliveryLogLimit caps the number of deliveries listed per request.
+const deliveryLogLimit = 100
+
+// DefaultAPIService is a service that implements the logic for the DefaultAPIServicer
+// This service manages webhook subscriptions and their delivery logs.
+type DefaultAPIService struct {
+	Repo *db.WebhookRepository
+}
+
+// NewDefaultAPIService creates a default API service with the given repository.
+func NewDefaultAPIService(repo *db.WebhookRepository) *DefaultAPIService {
+	return &DefaultAPIService{
+		Repo: repo,
+	}
+}
+
+// AdminWebhooksGet - Get a list of all webhook subscriptions
+func (s *DefaultAPIService) AdminWebhooksGet(ctx context.Context) (common.ImplResponse, error) {
+	subscriptions, err := s.Repo.GetAllSubscriptions(false)
+	if err != nil {
+		return common.Response(http.StatusInternalServerError, nil), err
+	}
+	subscriptionsResp := []models.WebhookSubscription{}
+	for _, subscription := range subscriptions {
+		subscriptionsResp = append(subscriptionsResp, convertDBToAPIResponse(subscription, false))
+	}
+
+	return common.Response(http.StatusOK, subscriptionsResp), nil
+}
+
+// AdminWebhooksPost - Add a new webhook subscription
+func (s *DefaultAPIService) AdminWebhooksPost(ctx context.Context, subscription models.WebhookSubscription) (common.ImplResponse, error) {
+	dbSubscription := convertApiToDBSubscription(subscription)
+	if dbSubscription.Secret == "" {
+		secret, err := generateSecret()
+		if err != nil {
+			return common.Response(http.StatusInternalServerError, nil), err
+		}
+		dbSubscription.Secret = secret
+	}
+	if err := s.Repo.CreateSubscription(&dbSubscription); err != nil {
+		return common.Response(http.StatusInternalServerError, nil), err
+	}
+
+	created, err := s.Repo.GetSubscriptionByID(dbSubscription.ID)
+	if err != nil {
+		return common.Response(http.StatusInternalServerError, nil), err
+	}
+	// The secret is shown once so it can be handed to the subscriber
+	return common.Response(http.StatusCreated, convertDBToAPIResponse(*created, true)), nil
+}
+
+// AdminWebhooksIdGet - Get a specific webhook subscription by ID
+func (s *DefaultAPIService) AdminWebhooksIdGet(ctx context.Context, id int64) (common.ImplResponse, error) {
+	subscription, err := s.Repo.GetSubscriptionByID(id)
+	if err != nil {
+		return webhookErrorResponse(err)
+	}
+
+	return common.Response(http.StatusOK, convertDBToAPIResponse(*subscription, false)), nil
+}
+
+// AdminWebhooksIdPut - Replace a webhook subscription by ID. The secret is kept unless a new one is given.
+func (s *DefaultAPIService) AdminWebhooksIdPut(ctx context.Context, id int64, subscription models.WebhookSubscription) (common.ImplResponse, error) {
+	if subscription.Id != 0 && subscription.Id != id {
+		return common.Response(http.StatusBadRequest, nil), errors.New("id in the path does not match id in the body")
+	}
+	existing, err := s.Repo.GetSubscriptionByID(id)
+	if err != nil {
+		return webhookErrorResponse(err)
+	}
+
+	dbSubscription := convertApiToDBSubscription(subscription)
+	dbSubscription.ID = id
+	if dbSubscription.Secret == "" {
+		dbSubscription.Secret = existing.Secret
+	}
+	if err := s.Repo.UpdateSubscription(&dbSubscription); err != nil {
+		return webhookErrorResponse(err)
+	}
+
+	updated, err := s.Repo.GetSubscriptionByID(id)
+	if err != nil {
+		return webhookErrorResponse(err)
+	}
+	return common.Response(http.StatusOK, convertDBToAPIResponse(*updated, subscription.Secret != "")), nil
+}
+
+// AdminWebhooksIdDelete - Delete a webhook subscription by ID
+func (s *DefaultAPIService) AdminWebhooksIdDelete(ctx context.Context, id int64) (common.ImplResponse, error) {
+	if err := s.Repo.DeleteSubscription(id); err != nil {
+		return webhookErrorResponse(err)
+	}
+
+	return common.Response(http.StatusNoContent, nil), nil
+}
+
+// AdminWebhooksIdDeliveriesGet - Get the latest deliveries of a webhook subscription
+func (s *DefaultAPIService) AdminWebhooksIdDeliveriesGet(ctx context.Context, id int64, status string) (common.ImplResponse, error) {
+	switch status {
+	case "", db.DeliveryPending, db.DeliverySucceeded, db.DeliveryDead:
+	default:
+		return common.Response(http.StatusBadRequest, nil), &common.ParsingError{Param: "status", Err: fmt.Errorf("unknown delivery status %q", status)}
+	}
+
+	deliveries, err := s.Repo.GetDeliveries(id, status, deliveryLogLimit)
+	if err != nil {
+		return webhookErrorResponse(err)
+	}
+	deliveriesResp := []models.WebhookDelivery{}
+	for _, delivery := range deliveries {
+		deliveriesResp = append(deliveriesResp, convertDBToAPIDelivery(delivery))
+	}
+
+	return common.Response(http.StatusOK, deliveriesResp), nil
+}
+
+// AdminWebhooksIdDeliveriesDeliveryIdGet - Get a delivery with its attempt log
+func (s *DefaultAPIService) AdminWebhooksIdDeliveriesDeliveryIdGet(ctx context.Context, id int64, deliveryId int64) (common.ImplResponse, error) {
+	delivery, err := s.Repo.GetDelivery(id, deliveryId)
+	if err != nil {
+		return webhookErrorResponse(err)
+	}
+	attempts, err := s.Repo.GetAttempts(deliveryId)
+	if err != nil {
+		return common.Response(http.StatusInternalServerError, nil), err
+	}
+
+	deliveryResp := convertDBToAPIDelivery(*delivery)
+	deliveryResp.Payload = delivery.Payload
+	deliveryResp.AttemptLog = []models.WebhookDeliveryAttempt{}
+	for _, attempt := range attempts {
+		deliveryResp.AttemptLog = append(deliveryResp.AttemptLog, models.WebhookDeliveryAttempt{
+			Attempt:     int32(attempt.Attempt),
+			StatusCode:  int32(attempt.StatusCode),
+			Error:       attempt.Error,
+			DurationMs:  attempt.DurationMs,
+			AttemptedAt: attempt.AttemptedAt,
+		})
+	}
+
+	return common.Response(http.StatusOK, deliveryResp), nil
+}
+
+// AdminWebhooksIdDeliveriesDeliveryIdRedeliverPost - Queue a delivery to be sent again
+func (s *DefaultAPIService) AdminWebhooksIdDeliveriesDeliveryIdRedeliverPost(ctx context.Context, id int64, deliveryId int64) (common.ImplResponse, error) {
+	if err := s.Repo.ResetDelivery(id, deliveryId); err != nil {
+		return webhookErrorResponse(err)
+	}
+	delivery, err := s.Repo.GetDelivery(id, deliveryId)
+	if err != nil {
+		return webhookErrorResponse(err)
+	}
+
+	return common.Response(http.StatusAccepted, convertDBToAPIDelivery(*delivery)), nil
+}
+
+// webhookErrorResponse maps repository errors to a not found or internal error response.
+func webhookErrorResponse(err error) (common.ImplResponse, error) {
+	if errors.Is(err, db.ErrSubscriptionNotFound) || errors.Is(err, db.ErrDeliveryNotFound) {
+		return common.Response(http.StatusNotFound, nil), err
+	}
+	return common.Response(http.StatusInternalServerError, nil), err
+}
+
+// generateSecret returns a random signing secret.
+func generateSecret() (string, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return "", fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+	return "whsec_" + hex.EncodeToString(key), nil
+}
+
+// convertApiToDBSubscription converts an API model WebhookSubscription to a database model Subscription.
+func convertApiToDBSubscription(subscription models.WebhookSubscription) db.Subscription {
+	return db.Subscription{
+		ID:         subscription.Id,
+		URL:        subscription.Url,
+		EventTypes: subscription.EventTypes,
+		Secret:     subscription.Secret,
+		Active:     subscription.Active == nil || *subscription.Active,
+	}
+}
+
+// convertDBToAPIResponse converts the DB model to the API model, leaving the secret out unless withSecret is set.
+func convertDBToAPIResponse(subscription db.Subscription, withSecret bool) models.WebhookSubscription {
+	active := subscription.Active
+	resp := models.WebhookSubscription{
+		Id:         subscription.ID,
+		Url:        subscription.URL,
+		EventTypes: subscription.EventTypes,
+		Active:     &active,
+		CreatedAt:  subscription.CreatedAt,
+	}
+	if withSecret {
+		resp.Secret = subscription.Secret
+	}
+	return resp
+}
+
+// convertDBToAPIDelivery converts the DB model of a delivery to the API model without its payload and attempts.
+func convertDBToAPIDelivery(delivery db.Delivery) models.WebhookDelivery {
+	resp := models.WebhookDelivery{
+		Id:             delivery.ID,
+		SubscriptionId: delivery.SubscriptionID,
+		EventId:        delivery.EventID,
+		EventType:      delivery.EventType,
+		Status:         delivery.Status,
+		Attempts:       int32(delivery.Attempts),
+		LastStatusCode: int32(delivery.LastStatusCode),
+		LastError:      delivery.LastError,
+		CreatedAt:      delivery.CreatedAt,
+		DeliveredAt:    delivery.DeliveredAt,
+	}
+	if delivery.Status == db.DeliveryPending {
+		resp.NextAttemptAt = delivery.NextAttemptAt
+	}
+	return resp
+}
@@ -0,0 +1,224 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	event_db "github.com/mayureshucsb2019/bookstore/service/event/db"
+	"github.com/mayureshucsb2019/bookstore/service/webhook/db"
+)
+
+// Headers sent with every webhook request
+const (
+	SignatureHeader  = "X-Bookstore-Signature"
+	EventTypeHeader  = "X-Bookstore-Event"
+	EventIDHeader    = "X-Bookstore-Event-Id"
+	DeliveryIDHeader = "X-Bookstore-Delivery"
+)
+
+// Sign returns the signature header value for a body sent at timestamp, in the form
+// "t=<unix seconds>,v1=<hex HMAC-SHA256>". The MAC covers "<unix seconds>.<body>" so receivers
+// can reject replayed requests by checking the timestamp.
+func Sign(secret string, timestamp time.Time, body []byte) string {
+	t := strconv.FormatInt(timestamp.Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(t + "."))
+	mac.Write(body)
+	return "t=" + t + ",v1=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Sink queues outbox events for every active subscription that wants them. It is registered
+// with the event dispatcher; the Sender makes the HTTP requests.
+type Sink struct {
+	Repo *db.WebhookRepository
+}
+
+// Name identifies the sink in the outbox.
+func (Sink) Name() string {
+	return "webhooks"
+}
+
+// Deliver queues the event for the matching subscriptions.
+func (s Sink) Deliver(ctx context.Context, event event_db.Event) error {
+	subscriptions, err := s.Repo.GetAllSubscriptions(true)
+	if err != nil {
+		return err
+	}
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook payload: %w", err)
+	}
+	for _, subscription := range subscriptions {
+		if !subscription.Matches(event.Type) {
+			continue
+		}
+		if err := s.Repo.EnqueueDelivery(subscription.ID, event.ID, event.Type, body); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Sender posts queued deliveries to the subscribers in the background. Any response other than
+// 2xx is retried with exponential backoff; after MaxAttempts the delivery is dead-lettered.
+type Sender struct {
+	Repo   *db.WebhookRepository
+	Client *http.Client
+
+	MaxAttempts int
+	MinBackoff  time.Duration // Delay before the second attempt, doubled for every further failure
+	MaxBackoff  time.Duration
+	BatchSize   int           // Deliveries claimed per poll
+	Lease       time.Duration // How long a claimed delivery is hidden from other senders
+}
+
+// NewSender creates a sender with a 10 second request timeout and the default retry policy of
+// 8 attempts spread over roughly a day.
+func NewSender(repo *db.WebhookRepository) *Sender {
+	return &Sender{
+		Repo:        repo,
+		Client:      &http.Client{Timeout: 10 * time.Second},
+		MaxAttempts: 8,
+		MinBackoff:  time.Minute,
+		MaxBackoff:  12 * time.Hour,
+		BatchSize:   50,
+		Lease:       time.Minute,
+	}
+}
+
+// Start sends due deliveries every interval until the returned function is called.
+func (s *Sender) Start(interval time.Duration) func() {
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if _, err := s.SendDue(ctx); err != nil {
+					log.Printf("Failed to send webhooks: %v", err)
+				}
+			}
+		}
+	}()
+	return cancel
+}
+
+// SendDue makes one attempt for each due delivery and returns how many succeeded.
+func (s *Sender) SendDue(ctx context.Context) (int, error) {
+	deliveries, err := s.Repo.ClaimDueDeliveries(s.BatchSize, s.Lease)
+	if err != nil {
+		return 0, err
+	}
+
+	subscriptions := map[int64]*db.Subscription{}
+	succeeded := 0
+	for i := range deliveries {
+		delivery := &deliveries[i]
+		subscription, ok := subscriptions[delivery.SubscriptionID]
+		if !ok {
+			if subscription, err = s.Repo.GetSubscriptionByID(delivery.SubscriptionID); err != nil {
+				return succeeded, err
+			}
+			subscriptions[delivery.SubscriptionID] = subscription
+		}
+
+		if err := s.attempt(ctx, delivery, subscription); err != nil {
+			return succeeded, err
+		}
+		if delivery.Status == db.DeliverySucceeded {
+			succeeded++
+		}
+	}
+	return succeeded, nil
+}
+
+// attempt sends a delivery once and records the outcome.
+func (s *Sender) attempt(ctx context.Context, delivery *db.Delivery, subscription *db.Subscription) error {
+	attempt, nextAttempt := s.send(ctx, delivery, subscription)
+	return s.Repo.RecordAttempt(delivery, attempt, nextAttempt)
+}
+
+// send sends a delivery once and updates its status and attempt count, returning the attempt
+// made and when the delivery is due again.
+func (s *Sender) send(ctx context.Context, delivery *db.Delivery, subscription *db.Subscription) (db.Attempt, time.Time) {
+	start := time.Now()
+	delivery.Attempts++
+	attempt := db.Attempt{
+		DeliveryID:  delivery.ID,
+		Attempt:     delivery.Attempts,
+		AttemptedAt: start.UTC().Format(db.TimeLayout),
+	}
+
+	if subscription.Active {
+		attempt.StatusCode, attempt.Error = s.post(ctx, delivery, subscription, start)
+	} else {
+		attempt.Error = "subscription is inactive"
+	}
+	attempt.DurationMs = time.Since(start).Milliseconds()
+
+	nextAttempt := start
+	switch {
+	case attempt.Error == "":
+		delivery.Status = db.DeliverySucceeded
+	case !subscription.Active || delivery.Attempts >= s.MaxAttempts:
+		delivery.Status = db.DeliveryDead
+		log.Printf("Webhook delivery %d to %s is dead after %d attempts: %s", delivery.ID, subscription.URL, delivery.Attempts, attempt.Error)
+	default:
+		delivery.Status = db.DeliveryPending
+		nextAttempt = start.Add(s.backoff(delivery.Attempts))
+	}
+	return attempt, nextAttempt
+}
+
+// post makes the HTTP request of a delivery and returns the response status and, unless the
+// subscriber accepted it with a 2xx, what went wrong.
+func (s *Sender) post(ctx context.Context, delivery *db.Delivery, subscription *db.Subscription, now time.Time) (int, string) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, subscription.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err.Error()
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("User-Agent", "bookstore-webhooks/1.0")
+	request.Header.Set(EventTypeHeader, delivery.EventType)
+	request.Header.Set(EventIDHeader, strconv.FormatInt(delivery.EventID, 10))
+	request.Header.Set(DeliveryIDHeader, strconv.FormatInt(delivery.ID, 10))
+	request.Header.Set(SignatureHeader, Sign(subscription.Secret, now, delivery.Payload))
+
+	response, err := s.Client.Do(request)
+	if err != nil {
+		return 0, err.Error()
+	}
+	defer response.Body.Close()
+	// Drain a little of the body so the connection can be reused
+	_, _ = io.Copy(io.Discard, io.LimitReader(response.Body, 64<<10))
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return response.StatusCode, "unexpected response status " + response.Status
+	}
+	return response.StatusCode, ""
+}
+
+// backoff returns the delay before retrying a delivery that has failed attempts times.
+func (s *Sender) backoff(attempts int) time.Duration {
+	delay := s.MinBackoff
+	for i := 1; i < attempts && delay < s.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > s.MaxBackoff {
+		delay = s.MaxBackoff
+	}
+	return delay
+}
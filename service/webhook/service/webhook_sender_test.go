@@ -0,0 +1,125 @@
+package service
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mayureshucsb2019/bookstore/service/webhook/db"
+)
+
+func TestSign(t *testing.T) {
+	got := Sign("whsec_test", time.Unix(1700000000, 0), []byte(`{"id":1}`))
+	want := "t=1700000000,v1=2f441ba4b3b2d50d28a9ab9d9fd8880376ecd1eb5d0435401553f5d8d0a5dcf8"
+	if got != want {
+		t.Errorf("Sign() = %q, want %q", got, want)
+	}
+}
+
+// received holds the headers of the requests a receiver got.
+type received struct {
+	mu      sync.Mutex
+	headers []http.Header
+}
+
+func (r *received) get() []http.Header {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.headers
+}
+
+// receiver starts a subscriber answering every request with the next of statuses, repeating
+// the last one, and returns the subscription pointing at it.
+func receiver(t *testing.T, statuses ...int) (*db.Subscription, *received) {
+	t.Helper()
+	requests := &received{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if Sign("secret", time.Now(), body) != r.Header.Get(SignatureHeader) &&
+			Sign("secret", time.Now().Add(-time.Second), body) != r.Header.Get(SignatureHeader) {
+			t.Errorf("request has signature %q", r.Header.Get(SignatureHeader))
+		}
+		requests.mu.Lock()
+		status := statuses[len(statuses)-1]
+		if len(requests.headers) < len(statuses) {
+			status = statuses[len(requests.headers)]
+		}
+		requests.headers = append(requests.headers, r.Header)
+		requests.mu.Unlock()
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+	return &db.Subscription{ID: 1, URL: server.URL, Secret: "secret", Active: true}, requests
+}
+
+func testSender() *Sender {
+	sender := NewSender(nil)
+	sender.MaxAttempts = 4
+	return sender
+}
+
+func TestSendSucceeds(t *testing.T) {
+	subscription, requests := receiver(t, http.StatusNoContent)
+	delivery := &db.Delivery{ID: 7, SubscriptionID: 1, EventID: 3, EventType: "BookCreated", Payload: []byte(`{"id":3}`)}
+
+	attempt, _ := testSender().send(context.Background(), delivery, subscription)
+	if delivery.Status != db.DeliverySucceeded || delivery.Attempts != 1 {
+		t.Errorf("delivery is %s after %d attempts, want succeeded after 1", delivery.Status, delivery.Attempts)
+	}
+	if attempt.StatusCode != http.StatusNoContent || attempt.Error != "" {
+		t.Errorf("attempt got %d %q, want 204 without error", attempt.StatusCode, attempt.Error)
+	}
+	headers := requests.get()
+	if len(headers) != 1 {
+		t.Fatalf("receiver got %d requests, want 1", len(headers))
+	}
+	if headers[0].Get(EventTypeHeader) != "BookCreated" || headers[0].Get(EventIDHeader) != "3" ||
+		headers[0].Get(DeliveryIDHeader) != "7" {
+		t.Errorf("request has headers %v", headers[0])
+	}
+}
+
+func TestSendRetriesWithBackoff(t *testing.T) {
+	subscription, _ := receiver(t, http.StatusInternalServerError, http.StatusBadRequest, http.StatusServiceUnavailable)
+	sender := testSender()
+	sender.MinBackoff = time.Minute
+	sender.MaxBackoff = 3 * time.Minute
+	delivery := &db.Delivery{ID: 7, SubscriptionID: 1, Payload: []byte(`{}`)}
+
+	for i, want := range []time.Duration{time.Minute, 2 * time.Minute, 3 * time.Minute} {
+		before := time.Now()
+		attempt, next := sender.send(context.Background(), delivery, subscription)
+		if delivery.Status != db.DeliveryPending || delivery.Attempts != i+1 {
+			t.Fatalf("delivery is %s after %d attempts, want pending after %d", delivery.Status, delivery.Attempts, i+1)
+		}
+		if attempt.StatusCode < 400 || attempt.Error == "" {
+			t.Errorf("attempt %d got %d %q, want a failed status", i+1, attempt.StatusCode, attempt.Error)
+		}
+		if delay := next.Sub(before); delay < want || delay > want+time.Second {
+			t.Errorf("attempt %d is retried after %s, want %s", i+1, delay, want)
+		}
+	}
+}
+
+func TestSendDeadAfterMaxAttempts(t *testing.T) {
+	subscription, requests := receiver(t, http.StatusBadGateway)
+	sender := testSender()
+	delivery := &db.Delivery{ID: 7, SubscriptionID: 1, Payload: []byte(`{}`)}
+
+	for i := 0; i < sender.MaxAttempts; i++ {
+		if delivery.Status == db.DeliveryDead {
+			t.Fatalf("delivery is dead after %d attempts, want %d", delivery.Attempts, sender.MaxAttempts)
+		}
+		sender.send(context.Background(), delivery, subscription)
+	}
+	if delivery.Status != db.DeliveryDead || delivery.Attempts != sender.MaxAttempts {
+		t.Errorf("delivery is %s after %d attempts, want dead after %d", delivery.Status, delivery.Attempts, sender.MaxAttempts)
+	}
+	if got := len(requests.get()); got != sender.MaxAttempts {
+		t.Errorf("receiver got %d requests, want %d", got, sender.MaxAttempts)
+	}
+}
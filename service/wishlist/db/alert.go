@@ -0,0 +1,362 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mayureshucsb2019/bookstore/service/common"
+)
+
+// Alert types
+const (
+	AlertBackInStock = "back_in_stock" // Fires when the stock rises to MinStock copies
+	AlertPriceDrop   = "price_drop"    // Fires when the price drops to TargetPrice
+)
+
+// Notification statuses
+const (
+	NotificationPending = "pending"
+	NotificationSent    = "sent"
+	NotificationFailed  = "failed" // Every attempt to send it failed
+)
+
+// ErrAlertNotFound is returned when the customer has no alert with the requested id
+var ErrAlertNotFound = errors.New("alert not found")
+
+// Alert is a customer waiting for a book to be back in stock or to drop to a price, as stored in
+// the BookAlerts table.
+type Alert struct {
+	ID            int64
+	CustomerEmail string
+	ISBN          string
+	BookName      string
+	Type          string
+	MinStock      int          // Copies in stock that fire a back_in_stock alert
+	TargetPrice   common.Money // Price that fires a price_drop alert, in the currency of the book
+	Active        bool
+	CreatedAt     string
+	NotifiedAt    sql.NullString
+}
+
+// Notification is the mail of a fired alert, as queued in the BookNotifications table.
+type Notification struct {
+	ID            int64
+	AlertID       int64
+	AlertType     string
+	ISBN          string
+	BookName      string
+	CustomerEmail string
+	CustomerName  string // First name, to greet the customer
+	Stock         sql.NullInt64
+	Price         common.Money // Zero for back_in_stock alerts
+	Status        string
+	Attempts      int
+	LastError     sql.NullString
+	CreatedAt     string
+	SentAt        sql.NullString
+}
+
+// SetAlert creates the alert of a customer on a book, or replaces the threshold of the one of the
+// same type they already have. Either way the alert is active until it fires. A price must be in
+// the currency of the book.
+func (r *WishlistRepository) SetAlert(alert *Alert) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	customerID, err := lockCustomer(tx, alert.CustomerEmail)
+	if err != nil {
+		return err
+	}
+	var currency string
+	err = tx.QueryRow(`SELECT currency FROM Books WHERE isbn = ?`, alert.ISBN).Scan(&currency)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("%w: %s", ErrBookNotFound, alert.ISBN)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to look up book: %w", err)
+	}
+
+	var minStock sql.NullInt64
+	var targetPrice, targetCurrency sql.NullString
+	switch alert.Type {
+	case AlertBackInStock:
+		minStock = sql.NullInt64{Int64: int64(alert.MinStock), Valid: true}
+	case AlertPriceDrop:
+		if alert.TargetPrice.Currency != currency {
+			return fmt.Errorf("%w: book %s is priced in %s", common.ErrCurrencyMismatch, alert.ISBN, currency)
+		}
+		targetPrice = sql.NullString{String: alert.TargetPrice.Decimal(), Valid: true}
+		targetCurrency = sql.NullString{String: currency, Valid: true}
+	default:
+		return fmt.Errorf("unknown alert type %q", alert.Type)
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO BookAlerts (customer_id, isbn, alert_type, min_stock, target_price, currency) VALUES (?, ?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE min_stock = VALUES(min_stock), target_price = VALUES(target_price),
+			currency = VALUES(currency), active = TRUE, notified_at = NULL`,
+		customerID, alert.ISBN, alert.Type, minStock, targetPrice, targetCurrency)
+	if err != nil {
+		return fmt.Errorf("failed to save alert: %w", err)
+	}
+	err = tx.QueryRow(`SELECT id FROM BookAlerts WHERE customer_id = ? AND isbn = ? AND alert_type = ?`,
+		customerID, alert.ISBN, alert.Type).Scan(&alert.ID)
+	if err != nil {
+		return fmt.Errorf("failed to read alert id: %w", err)
+	}
+	return tx.Commit()
+}
+
+// GetAlerts retrieves the alerts of a customer, newest first.
+func (r *WishlistRepository) GetAlerts(email string) ([]Alert, error) {
+	if _, err := customerID(r.DB, email); err != nil {
+		return nil, err
+	}
+	return r.queryAlerts(`WHERE c.email = ? ORDER BY a.created_at DESC, a.id DESC`, email)
+}
+
+// GetAlert retrieves an alert of a customer.
+func (r *WishlistRepository) GetAlert(email string, id int64) (*Alert, error) {
+	alerts, err := r.queryAlerts(`WHERE c.email = ? AND a.id = ?`, email, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(alerts) == 0 {
+		return nil, fmt.Errorf("%w: %d of %s", ErrAlertNotFound, id, email)
+	}
+	return &alerts[0], nil
+}
+
+// DeleteAlert removes an alert of a customer with its notifications.
+func (r *WishlistRepository) DeleteAlert(email string, id int64) error {
+	result, err := r.DB.Exec(`
+		DELETE a FROM BookAlerts a JOIN Customer c ON c.id = a.customer_id
+		WHERE c.email = ? AND a.id = ?`, email, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete alert: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("%w: %d of %s", ErrAlertNotFound, id, email)
+	}
+	return nil
+}
+
+func (r *WishlistRepository) queryAlerts(where string, args ...interface{}) ([]Alert, error) {
+	rows, err := r.DB.Query(`
+		SELECT a.id, c.email, a.isbn, b.name, a.alert_type, a.min_stock, a.target_price, a.currency, a.active,
+			a.created_at, a.notified_at
+		FROM BookAlerts a JOIN Customer c ON c.id = a.customer_id JOIN Books b ON b.isbn = a.isbn `+where, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query alerts: %w", err)
+	}
+	defer rows.Close()
+
+	alerts := []Alert{}
+	for rows.Next() {
+		var alert Alert
+		var minStock sql.NullInt64
+		var targetPrice, currency sql.NullString
+		err := rows.Scan(&alert.ID, &alert.CustomerEmail, &alert.ISBN, &alert.BookName, &alert.Type, &minStock, &targetPrice,
+			&currency, &alert.Active, &alert.CreatedAt, &alert.NotifiedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan alert: %w", err)
+		}
+		alert.MinStock = int(minStock.Int64)
+		if targetPrice.Valid {
+			if alert.TargetPrice, err = common.ParseMoney(targetPrice.String, currency.String); err != nil {
+				return nil, err
+			}
+		}
+		alerts = append(alerts, alert)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+	return alerts, nil
+}
+
+// StockChanged fires the back_in_stock alerts on a book whose threshold the stock crossed, rising
+// from previous to stock copies, and queues their notifications. eventID identifies the change, so
+// handling it twice queues nothing more. It returns the number of alerts fired.
+func (r *WishlistRepository) StockChanged(eventID int64, isbn string, previous int, stock int) (int, error) {
+	return r.fireAlerts(eventID, sql.NullInt64{Int64: int64(stock), Valid: true}, common.Money{},
+		`a.isbn = ? AND a.alert_type = ? AND a.min_stock > ? AND a.min_stock <= ?`, isbn, AlertBackInStock, previous, stock)
+}
+
+// PriceChanged fires the price_drop alerts on a book whose target the price crossed, dropping
+// from previous to price, and queues their notifications. A change of currency fires the alerts
+// in the new currency the price is at or below. eventID identifies the change, so handling it
+// twice queues nothing more. It returns the number of alerts fired.
+func (r *WishlistRepository) PriceChanged(eventID int64, isbn string, previous common.Money, price common.Money) (int, error) {
+	where := `a.isbn = ? AND a.alert_type = ? AND a.currency = ? AND a.target_price >= ?`
+	args := []interface{}{isbn, AlertPriceDrop, price.Currency, price.Decimal()}
+	if previous.Currency == price.Currency {
+		where += ` AND a.target_price < ?`
+		args = append(args, previous.Decimal())
+	}
+	return r.fireAlerts(eventID, sql.NullInt64{}, price, where, args...)
+}
+
+// fireAlerts queues a notification for every active alert matching where and deactivates them.
+// Alerts of suspended and closed customers are left for when they are reactivated.
+func (r *WishlistRepository) fireAlerts(eventID int64, stock sql.NullInt64, price common.Money, where string, args ...interface{}) (int, error) {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query(`
+		SELECT a.id FROM BookAlerts a JOIN Customer c ON c.id = a.customer_id
+		WHERE a.active AND (c.status IS NULL OR c.status NOT IN ('Suspended', 'Closed')) AND `+where+`
+		FOR UPDATE`, args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query alerts: %w", err)
+	}
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan alert: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("error iterating over rows: %w", err)
+	}
+
+	var notifiedPrice, currency sql.NullString
+	if price.Currency != "" {
+		notifiedPrice = sql.NullString{String: price.Decimal(), Valid: true}
+		currency = sql.NullString{String: price.Currency, Valid: true}
+	}
+	for _, id := range ids {
+		_, err := tx.Exec(`INSERT IGNORE INTO BookNotifications (alert_id, event_id, stock, price, currency) VALUES (?, ?, ?, ?, ?)`,
+			id, eventID, stock, notifiedPrice, currency)
+		if err != nil {
+			return 0, fmt.Errorf("failed to queue notification: %w", err)
+		}
+		if _, err := tx.Exec(`UPDATE BookAlerts SET active = FALSE, notified_at = CURRENT_TIMESTAMP WHERE id = ?`, id); err != nil {
+			return 0, fmt.Errorf("failed to deactivate alert: %w", err)
+		}
+	}
+	return len(ids), tx.Commit()
+}
+
+// notificationColumns are the columns of notificationTables read by queryNotifications, in scan
+// order.
+const notificationColumns = `n.id, a.id, a.alert_type, a.isbn, b.name, c.email, c.first_name, n.stock, n.price, n.currency,
+	n.status, n.attempts, n.last_error, n.created_at, n.sent_at`
+
+// notificationTables joins notifications with their alert, book and customer.
+const notificationTables = `BookNotifications n JOIN BookAlerts a ON a.id = n.alert_id
+	JOIN Customer c ON c.id = a.customer_id JOIN Books b ON b.isbn = a.isbn`
+
+// ClaimPendingNotifications returns up to limit notifications waiting to be sent, oldest first,
+// and hides them from other server instances for lease.
+func (r *WishlistRepository) ClaimPendingNotifications(limit int, lease time.Duration) ([]Notification, error) {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	notifications, err := queryNotifications(tx, `WHERE n.status = ? AND (n.next_attempt_at IS NULL OR n.next_attempt_at <= UTC_TIMESTAMP(3))
+		ORDER BY n.id LIMIT ? FOR UPDATE OF n SKIP LOCKED`, NotificationPending, limit)
+	if err != nil || len(notifications) == 0 {
+		return nil, err
+	}
+
+	args := []interface{}{lease.Microseconds()}
+	for _, notification := range notifications {
+		args = append(args, notification.ID)
+	}
+	query := `UPDATE BookNotifications SET next_attempt_at = UTC_TIMESTAMP(3) + INTERVAL ? MICROSECOND
+		WHERE id IN (?` + strings.Repeat(", ?", len(notifications)-1) + `)`
+	if _, err := tx.Exec(query, args...); err != nil {
+		return nil, fmt.Errorf("failed to claim notifications: %w", err)
+	}
+
+	return notifications, tx.Commit()
+}
+
+// GetCustomerNotifications retrieves the latest 100 notifications of a customer, newest first.
+func (r *WishlistRepository) GetCustomerNotifications(email string) ([]Notification, error) {
+	if _, err := customerID(r.DB, email); err != nil {
+		return nil, err
+	}
+	return queryNotifications(r.DB, `WHERE c.email = ? ORDER BY n.id DESC LIMIT 100`, email)
+}
+
+// MarkNotificationSent records that a notification was sent.
+func (r *WishlistRepository) MarkNotificationSent(id int64) error {
+	_, err := r.DB.Exec(`UPDATE BookNotifications SET status = ?, attempts = attempts + 1, last_error = NULL, sent_at = CURRENT_TIMESTAMP WHERE id = ?`,
+		NotificationSent, id)
+	if err != nil {
+		return fmt.Errorf("failed to update notification %d: %w", id, err)
+	}
+	return nil
+}
+
+// MarkNotificationFailed records a failed attempt to send a notification. It stays pending, and
+// due at once, to be tried again until maxAttempts have failed.
+func (r *WishlistRepository) MarkNotificationFailed(id int64, sendErr error, maxAttempts int) error {
+	message := sendErr.Error()
+	if len(message) > 1024 {
+		message = message[:1024]
+	}
+	_, err := r.DB.Exec(`
+		UPDATE BookNotifications SET attempts = attempts + 1, last_error = ?, next_attempt_at = NULL,
+			status = IF(attempts >= ?, ?, status)
+		WHERE id = ?`, message, maxAttempts, NotificationFailed, id)
+	if err != nil {
+		return fmt.Errorf("failed to update notification %d: %w", id, err)
+	}
+	return nil
+}
+
+// querier is implemented by both *sql.DB and *sql.Tx.
+type querier interface {
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+}
+
+func queryNotifications(q querier, where string, args ...interface{}) ([]Notification, error) {
+	rows, err := q.Query(`SELECT `+notificationColumns+` FROM `+notificationTables+` `+where, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query notifications: %w", err)
+	}
+	defer rows.Close()
+
+	notifications := []Notification{}
+	for rows.Next() {
+		var n Notification
+		var price, currency sql.NullString
+		err := rows.Scan(&n.ID, &n.AlertID, &n.AlertType, &n.ISBN, &n.BookName, &n.CustomerEmail, &n.CustomerName, &n.Stock,
+			&price, &currency, &n.Status, &n.Attempts, &n.LastError, &n.CreatedAt, &n.SentAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan notification: %w", err)
+		}
+		if price.Valid {
+			if n.Price, err = common.ParseMoney(price.String, currency.String); err != nil {
+				return nil, err
+			}
+		}
+		notifications = append(notifications, n)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+	return notifications, nil
+}
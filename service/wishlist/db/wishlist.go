@@ -0,0 +1,420 @@
+package db
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	_ "github.com/go-sql-driver/mysql"
+	"github.com/mayureshucsb2019/bookstore/service/common"
+)
+
+var (
+	// ErrWishlistNotFound is returned when the customer has no wishlist with the requested id, or
+	// no wishlist is shared with the requested token
+	ErrWishlistNotFound = errors.New("wishlist not found")
+	// ErrWishlistExists is returned when the customer already has a wishlist with the name
+	ErrWishlistExists = errors.New("customer already has a wishlist with this name")
+	// ErrItemNotFound is returned when a book is not on the wishlist
+	ErrItemNotFound = errors.New("book is not on the wishlist")
+	// ErrBookNotFound is returned when no book exists with the requested ISBN
+	ErrBookNotFound = errors.New("book not found")
+	// ErrCustomerNotFound is returned when no customer exists with the requested email
+	ErrCustomerNotFound = errors.New("customer not found")
+)
+
+// Wishlist represents the structure of a Wishlists record in the database, with its items.
+type Wishlist struct {
+	ID            int64
+	CustomerEmail string
+	OwnerName     string // First name and initial of the last name of the customer, shown on shared lists
+	Name          string
+	ShareToken    sql.NullString // Set while the list is shared
+	Items         []WishlistItem
+	CreatedAt     string
+	UpdatedAt     string
+}
+
+// WishlistItem is a book on a wishlist, with its current price and stock.
+type WishlistItem struct {
+	ISBN    string
+	Name    string
+	Cost    common.Money
+	Stock   int
+	AddedAt string
+}
+
+// WishlistRepository provides access to the Wishlists, WishlistItems, BookAlerts and
+// BookNotifications storage.
+type WishlistRepository struct {
+	DB *sql.DB
+}
+
+// wishlistColumns are the columns of wishlistTables read by scanWishlist, in scan order.
+const wishlistColumns = `w.id, c.email, c.first_name, c.last_name, w.name, w.share_token, w.created_at, w.updated_at`
+
+// wishlistTables joins wishlists with the customers who own them.
+const wishlistTables = `Wishlists w JOIN Customer c ON c.id = w.customer_id`
+
+// CreateWishlist adds an empty private wishlist for a customer.
+func (r *WishlistRepository) CreateWishlist(email string, name string) (*Wishlist, error) {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	customerID, err := lockCustomer(tx, email)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkNameAvailable(tx, customerID, name, 0); err != nil {
+		return nil, err
+	}
+	result, err := tx.Exec(`INSERT INTO Wishlists (customer_id, name) VALUES (?, ?)`, customerID, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to insert wishlist: %w", err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read wishlist id: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return r.GetWishlist(email, id)
+}
+
+// GetWishlists retrieves the wishlists of a customer with their items, oldest first.
+func (r *WishlistRepository) GetWishlists(email string) ([]Wishlist, error) {
+	if _, err := customerID(r.DB, email); err != nil {
+		return nil, err
+	}
+	return r.queryWishlists(`WHERE c.email = ? ORDER BY w.created_at, w.id`, email)
+}
+
+// GetWishlist retrieves a wishlist of a customer with its items.
+func (r *WishlistRepository) GetWishlist(email string, id int64) (*Wishlist, error) {
+	wishlists, err := r.queryWishlists(`WHERE c.email = ? AND w.id = ?`, email, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(wishlists) == 0 {
+		return nil, fmt.Errorf("%w: %d of %s", ErrWishlistNotFound, id, email)
+	}
+	return &wishlists[0], nil
+}
+
+// GetSharedWishlist retrieves the wishlist shared with a token.
+func (r *WishlistRepository) GetSharedWishlist(token string) (*Wishlist, error) {
+	wishlists, err := r.queryWishlists(`WHERE w.share_token = ?`, token)
+	if err != nil {
+		return nil, err
+	}
+	if len(wishlists) == 0 {
+		return nil, ErrWishlistNotFound
+	}
+	return &wishlists[0], nil
+}
+
+// RenameWishlist changes the name of a wishlist of a customer.
+func (r *WishlistRepository) RenameWishlist(email string, id int64, name string) (*Wishlist, error) {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	customerID, err := lockCustomer(tx, email)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkWishlist(tx, customerID, email, id); err != nil {
+		return nil, err
+	}
+	if err := checkNameAvailable(tx, customerID, name, id); err != nil {
+		return nil, err
+	}
+	if _, err := tx.Exec(`UPDATE Wishlists SET name = ? WHERE id = ?`, name, id); err != nil {
+		return nil, fmt.Errorf("failed to rename wishlist: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return r.GetWishlist(email, id)
+}
+
+// DeleteWishlist removes a wishlist of a customer with its items.
+func (r *WishlistRepository) DeleteWishlist(email string, id int64) error {
+	result, err := r.DB.Exec(`
+		DELETE w FROM Wishlists w JOIN Customer c ON c.id = w.customer_id
+		WHERE c.email = ? AND w.id = ?`, email, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete wishlist: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("%w: %d of %s", ErrWishlistNotFound, id, email)
+	}
+	return nil
+}
+
+// AddItem puts a book on a wishlist of a customer. Adding a book that is already on the list
+// keeps the date it was first added.
+func (r *WishlistRepository) AddItem(email string, id int64, isbn string) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	customerID, err := lockCustomer(tx, email)
+	if err != nil {
+		return err
+	}
+	if err := checkWishlist(tx, customerID, email, id); err != nil {
+		return err
+	}
+	if err := checkBook(tx, isbn); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`INSERT IGNORE INTO WishlistItems (wishlist_id, isbn) VALUES (?, ?)`, id, isbn); err != nil {
+		return fmt.Errorf("failed to add book to wishlist: %w", err)
+	}
+	if _, err := tx.Exec(`UPDATE Wishlists SET updated_at = CURRENT_TIMESTAMP WHERE id = ?`, id); err != nil {
+		return fmt.Errorf("failed to update wishlist: %w", err)
+	}
+	return tx.Commit()
+}
+
+// RemoveItem takes a book off a wishlist of a customer.
+func (r *WishlistRepository) RemoveItem(email string, id int64, isbn string) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	customerID, err := lockCustomer(tx, email)
+	if err != nil {
+		return err
+	}
+	if err := checkWishlist(tx, customerID, email, id); err != nil {
+		return err
+	}
+	result, err := tx.Exec(`DELETE FROM WishlistItems WHERE wishlist_id = ? AND isbn = ?`, id, isbn)
+	if err != nil {
+		return fmt.Errorf("failed to remove book from wishlist: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("%w: %s", ErrItemNotFound, isbn)
+	}
+	if _, err := tx.Exec(`UPDATE Wishlists SET updated_at = CURRENT_TIMESTAMP WHERE id = ?`, id); err != nil {
+		return fmt.Errorf("failed to update wishlist: %w", err)
+	}
+	return tx.Commit()
+}
+
+// ShareWishlist gives a wishlist of a customer a new share token, so anyone with the token can
+// read it. A token given out before stops working.
+func (r *WishlistRepository) ShareWishlist(email string, id int64) (*Wishlist, error) {
+	token, err := newShareToken()
+	if err != nil {
+		return nil, err
+	}
+	if err := r.setShareToken(email, id, sql.NullString{String: token, Valid: true}); err != nil {
+		return nil, err
+	}
+	return r.GetWishlist(email, id)
+}
+
+// UnshareWishlist makes a wishlist of a customer private again.
+func (r *WishlistRepository) UnshareWishlist(email string, id int64) error {
+	return r.setShareToken(email, id, sql.NullString{})
+}
+
+func (r *WishlistRepository) setShareToken(email string, id int64, token sql.NullString) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	customerID, err := lockCustomer(tx, email)
+	if err != nil {
+		return err
+	}
+	if err := checkWishlist(tx, customerID, email, id); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`UPDATE Wishlists SET share_token = ? WHERE id = ?`, token, id); err != nil {
+		return fmt.Errorf("failed to update share token: %w", err)
+	}
+	return tx.Commit()
+}
+
+// queryWishlists reads the wishlists matching where, then their items in a second query.
+func (r *WishlistRepository) queryWishlists(where string, args ...interface{}) ([]Wishlist, error) {
+	rows, err := r.DB.Query(`SELECT `+wishlistColumns+` FROM `+wishlistTables+` `+where, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query wishlists: %w", err)
+	}
+	defer rows.Close()
+
+	var wishlists []Wishlist
+	byID := make(map[int64]int)
+	for rows.Next() {
+		var wishlist Wishlist
+		var firstName, lastName string
+		err := rows.Scan(&wishlist.ID, &wishlist.CustomerEmail, &firstName, &lastName, &wishlist.Name, &wishlist.ShareToken,
+			&wishlist.CreatedAt, &wishlist.UpdatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan wishlist: %w", err)
+		}
+		wishlist.OwnerName = firstName
+		if initial := []rune(lastName); len(initial) > 0 {
+			wishlist.OwnerName += " " + string(initial[0]) + "."
+		}
+		wishlist.Items = []WishlistItem{}
+		byID[wishlist.ID] = len(wishlists)
+		wishlists = append(wishlists, wishlist)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+	if len(wishlists) == 0 {
+		return wishlists, nil
+	}
+
+	ids := make([]interface{}, 0, len(wishlists))
+	for _, wishlist := range wishlists {
+		ids = append(ids, wishlist.ID)
+	}
+	itemRows, err := r.DB.Query(`
+		SELECT i.wishlist_id, i.isbn, b.name, b.cost, b.currency, b.stock, i.added_at
+		FROM WishlistItems i JOIN Books b ON b.isbn = i.isbn
+		WHERE i.wishlist_id IN (?`+strings.Repeat(`, ?`, len(ids)-1)+`)
+		ORDER BY i.added_at, i.isbn`, ids...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query wishlist items: %w", err)
+	}
+	defer itemRows.Close()
+
+	for itemRows.Next() {
+		var wishlistID int64
+		var item WishlistItem
+		var cost sql.NullString
+		var currency string
+		err := itemRows.Scan(&wishlistID, &item.ISBN, &item.Name, &cost, &currency, &item.Stock, &item.AddedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan wishlist item: %w", err)
+		}
+		if item.Cost, err = parseCost(cost, currency); err != nil {
+			return nil, err
+		}
+		i := byID[wishlistID]
+		wishlists[i].Items = append(wishlists[i].Items, item)
+	}
+	if err := itemRows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+	return wishlists, nil
+}
+
+// queryRower is implemented by *sql.DB and *sql.Tx.
+type queryRower interface {
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// customerID returns the id of the customer with an email.
+func customerID(q queryRower, email string) (int64, error) {
+	var id int64
+	err := q.QueryRow(`SELECT id FROM Customer WHERE email = ?`, email).Scan(&id)
+	if err == sql.ErrNoRows {
+		return 0, fmt.Errorf("%w: %s", ErrCustomerNotFound, email)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to look up customer: %w", err)
+	}
+	return id, nil
+}
+
+// lockCustomer returns the id of the customer with an email, locking their row so their
+// wishlists and alerts are changed one at a time.
+func lockCustomer(tx *sql.Tx, email string) (int64, error) {
+	var id int64
+	err := tx.QueryRow(`SELECT id FROM Customer WHERE email = ? FOR UPDATE`, email).Scan(&id)
+	if err == sql.ErrNoRows {
+		return 0, fmt.Errorf("%w: %s", ErrCustomerNotFound, email)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to look up customer: %w", err)
+	}
+	return id, nil
+}
+
+// checkWishlist fails with ErrWishlistNotFound when the customer has no wishlist with the id.
+func checkWishlist(tx *sql.Tx, customerID int64, email string, id int64) error {
+	var exists int
+	err := tx.QueryRow(`SELECT COUNT(*) FROM Wishlists WHERE id = ? AND customer_id = ?`, id, customerID).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("failed to look up wishlist: %w", err)
+	}
+	if exists == 0 {
+		return fmt.Errorf("%w: %d of %s", ErrWishlistNotFound, id, email)
+	}
+	return nil
+}
+
+// checkNameAvailable fails with ErrWishlistExists when another wishlist of the customer than the
+// one with id has the name.
+func checkNameAvailable(tx *sql.Tx, customerID int64, name string, id int64) error {
+	var exists int
+	err := tx.QueryRow(`SELECT COUNT(*) FROM Wishlists WHERE customer_id = ? AND name = ? AND id <> ?`, customerID, name, id).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("failed to look up wishlist: %w", err)
+	}
+	if exists > 0 {
+		return fmt.Errorf("%w: %s", ErrWishlistExists, name)
+	}
+	return nil
+}
+
+// checkBook fails with ErrBookNotFound when no book exists with the ISBN.
+func checkBook(q queryRower, isbn string) error {
+	var exists int
+	if err := q.QueryRow(`SELECT COUNT(*) FROM Books WHERE isbn = ?`, isbn).Scan(&exists); err != nil {
+		return fmt.Errorf("failed to look up book: %w", err)
+	}
+	if exists == 0 {
+		return fmt.Errorf("%w: %s", ErrBookNotFound, isbn)
+	}
+	return nil
+}
+
+// parseCost reads a nullable DECIMAL price column.
+func parseCost(cost sql.NullString, currency string) (common.Money, error) {
+	if !cost.Valid {
+		return common.NewMoney(0, currency), nil
+	}
+	return common.ParseMoney(cost.String, currency)
+}
+
+// newShareToken returns a random token of 43 URL-safe characters.
+func newShareToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate share token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,19 @@
+package db
+
+import (
+	"sync"
+
+	"github.com/mayureshucsb2019/bookstore/service/common"
+)
+
+var wishlistRepoInstance *WishlistRepository
+var wishlistRepoOnce sync.Once
+
+func NewWishlistRepository(db *common.DBConnection) *WishlistRepository {
+	wishlistRepoOnce.Do(func() {
+		wishlistRepoInstance = &WishlistRepository{
+			DB: db.DB,
+		}
+	})
+	return wishlistRepoInstance
+}
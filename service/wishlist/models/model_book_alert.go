@@ -0,0 +1,74 @@
+package models
+
+import (
+	"errors"
+
+	"github.com/mayureshucsb2019/bookstore/service/common"
+)
+
+type BookAlert struct {
+	// Assigned by the server
+	Id int64 `json:"id,omitempty"`
+
+	Isbn string `json:"isbn"`
+
+	// back_in_stock or price_drop
+	Type string `json:"type"`
+
+	// Copies in stock that fire a back_in_stock alert, 1 when omitted
+	MinStock int32 `json:"min_stock,omitempty"`
+
+	// Price that fires a price_drop alert, in the currency of the book
+	TargetPrice *common.Money `json:"target_price,omitempty"`
+
+	// Set by the server
+	BookName string `json:"book_name,omitempty"`
+
+	// Set by the server; an alert is inactive once it fired, until it is set again
+	Active bool `json:"active"`
+
+	CreatedAt string `json:"created_at,omitempty"`
+
+	NotifiedAt string `json:"notified_at,omitempty"`
+}
+
+// AssertBookAlertRequired checks if the required fields are not zero-ed
+func AssertBookAlertRequired(obj BookAlert) error {
+	elements := map[string]interface{}{
+		"isbn": obj.Isbn,
+		"type": obj.Type,
+	}
+	for name, el := range elements {
+		if isZero := common.IsZeroValue(el); isZero {
+			return &common.RequiredError{Field: name}
+		}
+	}
+	if obj.Type == "price_drop" && obj.TargetPrice == nil {
+		return &common.RequiredError{Field: "target_price"}
+	}
+
+	return nil
+}
+
+// AssertBookAlertConstraints checks if the values respects the defined constraints
+func AssertBookAlertConstraints(obj BookAlert) error {
+	switch obj.Type {
+	case "back_in_stock":
+		if obj.TargetPrice != nil {
+			return &common.ParsingError{Param: "target_price", Err: errors.New("only applies to price_drop alerts")}
+		}
+	case "price_drop":
+		if obj.MinStock != 0 {
+			return &common.ParsingError{Param: "min_stock", Err: errors.New("only applies to back_in_stock alerts")}
+		}
+	default:
+		return &common.ParsingError{Param: "type", Err: errors.New("must be one of back_in_stock, price_drop")}
+	}
+	if obj.MinStock < 0 {
+		return &common.ParsingError{Param: "min_stock", Err: errors.New("cannot be negative")}
+	}
+	if obj.TargetPrice != nil && obj.TargetPrice.Amount < 0 {
+		return &common.ParsingError{Param: "target_price", Err: errors.New("cannot be negative")}
+	}
+	return nil
+}
@@ -0,0 +1,34 @@
+package models
+
+import "github.com/mayureshucsb2019/bookstore/service/common"
+
+// BookNotification is the mail sent to a customer when one of their alerts fired.
+type BookNotification struct {
+	Id int64 `json:"id"`
+
+	AlertId int64 `json:"alert_id"`
+
+	// Type of the alert, back_in_stock or price_drop
+	Type string `json:"type"`
+
+	Isbn string `json:"isbn"`
+
+	BookName string `json:"book_name"`
+
+	// Copies in stock when a back_in_stock alert fired
+	Stock *int32 `json:"stock,omitempty"`
+
+	// Price when a price_drop alert fired
+	Price *common.Money `json:"price,omitempty"`
+
+	// pending, sent or failed
+	Status string `json:"status"`
+
+	Attempts int32 `json:"attempts"`
+
+	LastError string `json:"last_error,omitempty"`
+
+	CreatedAt string `json:"created_at"`
+
+	SentAt string `json:"sent_at,omitempty"`
+}
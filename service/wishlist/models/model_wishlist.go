@@ -0,0 +1,55 @@
+package models
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/mayureshucsb2019/bookstore/service/common"
+)
+
+type Wishlist struct {
+	// Assigned by the server
+	Id int64 `json:"id,omitempty"`
+
+	// Unique among the wishlists of the customer
+	Name string `json:"name"`
+
+	// Set by the server while the wishlist is shared; anyone with it can read the list at
+	// /wishlists/shared/{token}
+	ShareToken string `json:"share_token,omitempty"`
+
+	// First name and initial of the last name of the owner; set by the server on shared wishlists
+	OwnerName string `json:"owner_name,omitempty"`
+
+	// Set by the server; books are added and removed through the items endpoints
+	Items []WishlistItem `json:"items,omitempty"`
+
+	CreatedAt string `json:"created_at,omitempty"`
+
+	UpdatedAt string `json:"updated_at,omitempty"`
+}
+
+// AssertWishlistRequired checks if the required fields are not zero-ed
+func AssertWishlistRequired(obj Wishlist) error {
+	elements := map[string]interface{}{
+		"name": obj.Name,
+	}
+	for name, el := range elements {
+		if isZero := common.IsZeroValue(el); isZero {
+			return &common.RequiredError{Field: name}
+		}
+	}
+
+	return nil
+}
+
+// AssertWishlistConstraints checks if the values respects the defined constraints
+func AssertWishlistConstraints(obj Wishlist) error {
+	if strings.TrimSpace(obj.Name) == "" {
+		return &common.ParsingError{Param: "name", Err: errors.New("cannot be blank")}
+	}
+	if len(obj.Name) > 100 {
+		return &common.ParsingError{Param: "name", Err: errors.New("must be at most 100 characters")}
+	}
+	return nil
+}
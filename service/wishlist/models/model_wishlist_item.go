@@ -0,0 +1,16 @@
+package models
+
+import "github.com/mayureshucsb2019/bookstore/service/common"
+
+// WishlistItem is a book on a wishlist, with its current price and stock.
+type WishlistItem struct {
+	Isbn string `json:"isbn"`
+
+	Name string `json:"name"`
+
+	Cost common.Money `json:"cost"`
+
+	Stock int32 `json:"stock"`
+
+	AddedAt string `json:"added_at"`
+}
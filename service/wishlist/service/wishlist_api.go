@@ -0,0 +1,50 @@
+package service
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/mayureshucsb2019/bookstore/service/common"
+	"github.com/mayureshucsb2019/bookstore/service/wishlist/models"
+)
+
+// DefaultAPIRouter defines the required methods for binding the api requests to a responses for the DefaultAPI
+// The DefaultAPIRouter implementation should parse necessary information from the http request,
+// pass the data to a DefaultAPIServicer to perform the required actions, then write the service results to the http response.
+type DefaultAPIRouter interface {
+	CustomersEmailAlertsAlertIdDelete(http.ResponseWriter, *http.Request)
+	CustomersEmailAlertsGet(http.ResponseWriter, *http.Request)
+	CustomersEmailAlertsPost(http.ResponseWriter, *http.Request)
+	CustomersEmailNotificationsGet(http.ResponseWriter, *http.Request)
+	CustomersEmailWishlistsGet(http.ResponseWriter, *http.Request)
+	CustomersEmailWishlistsIdDelete(http.ResponseWriter, *http.Request)
+	CustomersEmailWishlistsIdGet(http.ResponseWriter, *http.Request)
+	CustomersEmailWishlistsIdItemsIsbnDelete(http.ResponseWriter, *http.Request)
+	CustomersEmailWishlistsIdItemsIsbnPut(http.ResponseWriter, *http.Request)
+	CustomersEmailWishlistsIdPut(http.ResponseWriter, *http.Request)
+	CustomersEmailWishlistsIdShareDelete(http.ResponseWriter, *http.Request)
+	CustomersEmailWishlistsIdSharePost(http.ResponseWriter, *http.Request)
+	CustomersEmailWishlistsPost(http.ResponseWriter, *http.Request)
+	WishlistsSharedTokenGet(http.ResponseWriter, *http.Request)
+}
+
+// DefaultAPIServicer defines the api actions for the DefaultAPI service
+// This interface intended to stay up to date with the openapi yaml used to generate it,
+// while the service implementation can be ignored with the .openapi-generator-ignore file
+// and updated with the logic required for the API.
+type DefaultAPIServicer interface {
+	CustomersEmailAlertsAlertIdDelete(context.Context, string, int64) (common.ImplResponse, error)
+	CustomersEmailAlertsGet(context.Context, string) (common.ImplResponse, error)
+	CustomersEmailAlertsPost(context.Context, string, models.BookAlert) (common.ImplResponse, error)
+	CustomersEmailNotificationsGet(context.Context, string) (common.ImplResponse, error)
+	CustomersEmailWishlistsGet(context.Context, string) (common.ImplResponse, error)
+	CustomersEmailWishlistsIdDelete(context.Context, string, int64) (common.ImplResponse, error)
+	CustomersEmailWishlistsIdGet(context.Context, string, int64) (common.ImplResponse, error)
+	CustomersEmailWishlistsIdItemsIsbnDelete(context.Context, string, int64, string) (common.ImplResponse, error)
+	CustomersEmailWishlistsIdItemsIsbnPut(context.Context, string, int64, string) (common.ImplResponse, error)
+	CustomersEmailWishlistsIdPut(context.Context, string, int64, models.Wishlist) (common.ImplResponse, error)
+	CustomersEmailWishlistsIdShareDelete(context.Context, string, int64) (common.ImplResponse, error)
+	CustomersEmailWishlistsIdSharePost(context.Context, string, int64) (common.ImplResponse, error)
+	CustomersEmailWishlistsPost(context.Context, string, models.Wishlist) (common.ImplResponse, error)
+	WishlistsSharedTokenGet(context.Context, string) (common.ImplResponse, error)
+}
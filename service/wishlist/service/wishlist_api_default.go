@@ -0,0 +1,488 @@
+package service
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/mayureshucsb2019/bookstore/service/common"
+	"github.com/mayureshucsb2019/bookstore/service/wishlist/models"
+)
+
+// DefaultAPIController binds http requests to an api service and writes the service results to the http response
+type DefaultAPIController struct {
+	service      DefaultAPIServicer
+	errorHandler common.ErrorHandler
+}
+
+// DefaultAPIOption for how the controller is set up.
+type DefaultAPIOption func(*DefaultAPIController)
+
+// WithDefaultAPIErrorHandler inject ErrorHandler into controller
+func WithDefaultAPIErrorHandler(h common.ErrorHandler) DefaultAPIOption {
+	return func(c *DefaultAPIController) {
+		c.errorHandler = h
+	}
+}
+
+// NewDefaultAPIController creates a default api controller
+func NewDefaultAPIController(s DefaultAPIServicer, opts ...DefaultAPIOption) *DefaultAPIController {
+	controller := &DefaultAPIController{
+		service:      s,
+		errorHandler: common.DefaultErrorHandler,
+	}
+
+	for _, opt := range opts {
+		opt(controller)
+	}
+
+	return controller
+}
+
+// Routes returns all the api routes for the DefaultAPIController
+func (c *DefaultAPIController) Routes() common.Routes {
+	return common.Routes{
+		"CustomersEmailAlertsAlertIdDelete": common.Route{
+			Method:      strings.ToUpper("Delete"),
+			Pattern:     "/customers/{email}/alerts/{alertId}",
+			HandlerFunc: c.CustomersEmailAlertsAlertIdDelete,
+		},
+		"CustomersEmailAlertsGet": common.Route{
+			Method:      strings.ToUpper("Get"),
+			Pattern:     "/customers/{email}/alerts",
+			HandlerFunc: c.CustomersEmailAlertsGet,
+		},
+		"CustomersEmailAlertsPost": common.Route{
+			Method:      strings.ToUpper("Post"),
+			Pattern:     "/customers/{email}/alerts",
+			HandlerFunc: c.CustomersEmailAlertsPost,
+		},
+		"CustomersEmailNotificationsGet": common.Route{
+			Method:      strings.ToUpper("Get"),
+			Pattern:     "/customers/{email}/notifications",
+			HandlerFunc: c.CustomersEmailNotificationsGet,
+		},
+		"CustomersEmailWishlistsGet": common.Route{
+			Method:      strings.ToUpper("Get"),
+			Pattern:     "/customers/{email}/wishlists",
+			HandlerFunc: c.CustomersEmailWishlistsGet,
+		},
+		"CustomersEmailWishlistsIdDelete": common.Route{
+			Method:      strings.ToUpper("Delete"),
+			Pattern:     "/customers/{email}/wishlists/{id}",
+			HandlerFunc: c.CustomersEmailWishlistsIdDelete,
+		},
+		"CustomersEmailWishlistsIdGet": common.Route{
+			Method:      strings.ToUpper("Get"),
+			Pattern:     "/customers/{email}/wishlists/{id}",
+			HandlerFunc: c.CustomersEmailWishlistsIdGet,
+		},
+		"CustomersEmailWishlistsIdItemsIsbnDelete": common.Route{
+			Method:      strings.ToUpper("Delete"),
+			Pattern:     "/customers/{email}/wishlists/{id}/items/{isbn}",
+			HandlerFunc: c.CustomersEmailWishlistsIdItemsIsbnDelete,
+		},
+		"CustomersEmailWishlistsIdItemsIsbnPut": common.Route{
+			Method:      strings.ToUpper("Put"),
+			Pattern:     "/customers/{email}/wishlists/{id}/items/{isbn}",
+			HandlerFunc: c.CustomersEmailWishlistsIdItemsIsbnPut,
+		},
+		"CustomersEmailWishlistsIdPut": common.Route{
+			Method:      strings.ToUpper("Put"),
+			Pattern:     "/customers/{email}/wishlists/{id}",
+			HandlerFunc: c.CustomersEmailWishlistsIdPut,
+		},
+		"CustomersEmailWishlistsIdShareDelete": common.Route{
+			Method:      strings.ToUpper("Delete"),
+			Pattern:     "/customers/{email}/wishlists/{id}/share",
+			HandlerFunc: c.CustomersEmailWishlistsIdShareDelete,
+		},
+		"CustomersEmailWishlistsIdSharePost": common.Route{
+			Method:      strings.ToUpper("Post"),
+			Pattern:     "/customers/{email}/wishlists/{id}/share",
+			HandlerFunc: c.CustomersEmailWishlistsIdSharePost,
+		},
+		"CustomersEmailWishlistsPost": common.Route{
+			Method:      strings.ToUpper("Post"),
+			Pattern:     "/customers/{email}/wishlists",
+			HandlerFunc: c.CustomersEmailWishlistsPost,
+		},
+		"WishlistsSharedTokenGet": common.Route{
+			Method:      strings.ToUpper("Get"),
+			Pattern:     "/wishlists/shared/{token}",
+			HandlerFunc: c.WishlistsSharedTokenGet,
+		},
+	}
+}
+
+// CustomersEmailAlertsAlertIdDelete - Delete an alert of a customer
+func (c *DefaultAPIController) CustomersEmailAlertsAlertIdDelete(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	emailParam := params["email"]
+	if emailParam == "" {
+		c.errorHandler(w, r, &common.RequiredError{Field: "email"}, nil)
+		return
+	}
+	alertIdParam, err := common.ParseNumericParameter[int64](
+		params["alertId"],
+		common.WithRequire[int64](common.ParseInt64),
+	)
+	if err != nil {
+		c.errorHandler(w, r, &common.ParsingError{Param: "alertId", Err: err}, nil)
+		return
+	}
+	result, err := c.service.CustomersEmailAlertsAlertIdDelete(r.Context(), emailParam, alertIdParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	_ = common.EncodeJSONResponse(result.Body, &result.Code, w)
+}
+
+// CustomersEmailAlertsGet - Get the alerts of a customer
+func (c *DefaultAPIController) CustomersEmailAlertsGet(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	emailParam := params["email"]
+	if emailParam == "" {
+		c.errorHandler(w, r, &common.RequiredError{Field: "email"}, nil)
+		return
+	}
+	result, err := c.service.CustomersEmailAlertsGet(r.Context(), emailParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	_ = common.EncodeJSONResponse(result.Body, &result.Code, w)
+}
+
+// CustomersEmailAlertsPost - Set an alert on a book
+func (c *DefaultAPIController) CustomersEmailAlertsPost(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	emailParam := params["email"]
+	if emailParam == "" {
+		c.errorHandler(w, r, &common.RequiredError{Field: "email"}, nil)
+		return
+	}
+	bookAlertParam := models.BookAlert{}
+	d := json.NewDecoder(r.Body)
+	d.DisallowUnknownFields()
+	if err := d.Decode(&bookAlertParam); err != nil {
+		c.errorHandler(w, r, &common.ParsingError{Err: err}, nil)
+		return
+	}
+	if err := models.AssertBookAlertRequired(bookAlertParam); err != nil {
+		c.errorHandler(w, r, err, nil)
+		return
+	}
+	if err := models.AssertBookAlertConstraints(bookAlertParam); err != nil {
+		c.errorHandler(w, r, err, nil)
+		return
+	}
+	result, err := c.service.CustomersEmailAlertsPost(r.Context(), emailParam, bookAlertParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	_ = common.EncodeJSONResponse(result.Body, &result.Code, w)
+}
+
+// CustomersEmailNotificationsGet - Get the latest notifications of a customer
+func (c *DefaultAPIController) CustomersEmailNotificationsGet(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	emailParam := params["email"]
+	if emailParam == "" {
+		c.errorHandler(w, r, &common.RequiredError{Field: "email"}, nil)
+		return
+	}
+	result, err := c.service.CustomersEmailNotificationsGet(r.Context(), emailParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	_ = common.EncodeJSONResponse(result.Body, &result.Code, w)
+}
+
+// CustomersEmailWishlistsGet - Get the wishlists of a customer
+func (c *DefaultAPIController) CustomersEmailWishlistsGet(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	emailParam := params["email"]
+	if emailParam == "" {
+		c.errorHandler(w, r, &common.RequiredError{Field: "email"}, nil)
+		return
+	}
+	result, err := c.service.CustomersEmailWishlistsGet(r.Context(), emailParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	_ = common.EncodeJSONResponse(result.Body, &result.Code, w)
+}
+
+// CustomersEmailWishlistsIdDelete - Delete a wishlist
+func (c *DefaultAPIController) CustomersEmailWishlistsIdDelete(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	emailParam := params["email"]
+	if emailParam == "" {
+		c.errorHandler(w, r, &common.RequiredError{Field: "email"}, nil)
+		return
+	}
+	idParam, err := common.ParseNumericParameter[int64](
+		params["id"],
+		common.WithRequire[int64](common.ParseInt64),
+	)
+	if err != nil {
+		c.errorHandler(w, r, &common.ParsingError{Param: "id", Err: err}, nil)
+		return
+	}
+	result, err := c.service.CustomersEmailWishlistsIdDelete(r.Context(), emailParam, idParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	_ = common.EncodeJSONResponse(result.Body, &result.Code, w)
+}
+
+// CustomersEmailWishlistsIdGet - Get a wishlist with its books
+func (c *DefaultAPIController) CustomersEmailWishlistsIdGet(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	emailParam := params["email"]
+	if emailParam == "" {
+		c.errorHandler(w, r, &common.RequiredError{Field: "email"}, nil)
+		return
+	}
+	idParam, err := common.ParseNumericParameter[int64](
+		params["id"],
+		common.WithRequire[int64](common.ParseInt64),
+	)
+	if err != nil {
+		c.errorHandler(w, r, &common.ParsingError{Param: "id", Err: err}, nil)
+		return
+	}
+	result, err := c.service.CustomersEmailWishlistsIdGet(r.Context(), emailParam, idParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	_ = common.EncodeJSONResponse(result.Body, &result.Code, w)
+}
+
+// CustomersEmailWishlistsIdItemsIsbnDelete - Remove a book from a wishlist
+func (c *DefaultAPIController) CustomersEmailWishlistsIdItemsIsbnDelete(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	emailParam := params["email"]
+	if emailParam == "" {
+		c.errorHandler(w, r, &common.RequiredError{Field: "email"}, nil)
+		return
+	}
+	idParam, err := common.ParseNumericParameter[int64](
+		params["id"],
+		common.WithRequire[int64](common.ParseInt64),
+	)
+	if err != nil {
+		c.errorHandler(w, r, &common.ParsingError{Param: "id", Err: err}, nil)
+		return
+	}
+	isbnParam := params["isbn"]
+	if isbnParam == "" {
+		c.errorHandler(w, r, &common.RequiredError{Field: "isbn"}, nil)
+		return
+	}
+	result, err := c.service.CustomersEmailWishlistsIdItemsIsbnDelete(r.Context(), emailParam, idParam, isbnParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	_ = common.EncodeJSONResponse(result.Body, &result.Code, w)
+}
+
+// CustomersEmailWishlistsIdItemsIsbnPut - Add a book to a wishlist
+func (c *DefaultAPIController) CustomersEmailWishlistsIdItemsIsbnPut(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	emailParam := params["email"]
+	if emailParam == "" {
+		c.errorHandler(w, r, &common.RequiredError{Field: "email"}, nil)
+		return
+	}
+	idParam, err := common.ParseNumericParameter[int64](
+		params["id"],
+		common.WithRequire[int64](common.ParseInt64),
+	)
+	if err != nil {
+		c.errorHandler(w, r, &common.ParsingError{Param: "id", Err: err}, nil)
+		return
+	}
+	isbnParam := params["isbn"]
+	if isbnParam == "" {
+		c.errorHandler(w, r, &common.RequiredError{Field: "isbn"}, nil)
+		return
+	}
+	result, err := c.service.CustomersEmailWishlistsIdItemsIsbnPut(r.Context(), emailParam, idParam, isbnParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	_ = common.EncodeJSONResponse(result.Body, &result.Code, w)
+}
+
+// CustomersEmailWishlistsIdPut - Rename a wishlist
+func (c *DefaultAPIController) CustomersEmailWishlistsIdPut(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	emailParam := params["email"]
+	if emailParam == "" {
+		c.errorHandler(w, r, &common.RequiredError{Field: "email"}, nil)
+		return
+	}
+	idParam, err := common.ParseNumericParameter[int64](
+		params["id"],
+		common.WithRequire[int64](common.ParseInt64),
+	)
+	if err != nil {
+		c.errorHandler(w, r, &common.ParsingError{Param: "id", Err: err}, nil)
+		return
+	}
+	wishlistParam := models.Wishlist{}
+	d := json.NewDecoder(r.Body)
+	d.DisallowUnknownFields()
+	if err := d.Decode(&wishlistParam); err != nil {
+		c.errorHandler(w, r, &common.ParsingError{Err: err}, nil)
+		return
+	}
+	if err := models.AssertWishlistRequired(wishlistParam); err != nil {
+		c.errorHandler(w, r, err, nil)
+		return
+	}
+	if err := models.AssertWishlistConstraints(wishlistParam); err != nil {
+		c.errorHandler(w, r, err, nil)
+		return
+	}
+	result, err := c.service.CustomersEmailWishlistsIdPut(r.Context(), emailParam, idParam, wishlistParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	_ = common.EncodeJSONResponse(result.Body, &result.Code, w)
+}
+
+// CustomersEmailWishlistsIdShareDelete - Stop sharing a wishlist
+func (c *DefaultAPIController) CustomersEmailWishlistsIdShareDelete(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	emailParam := params["email"]
+	if emailParam == "" {
+		c.errorHandler(w, r, &common.RequiredError{Field: "email"}, nil)
+		return
+	}
+	idParam, err := common.ParseNumericParameter[int64](
+		params["id"],
+		common.WithRequire[int64](common.ParseInt64),
+	)
+	if err != nil {
+		c.errorHandler(w, r, &common.ParsingError{Param: "id", Err: err}, nil)
+		return
+	}
+	result, err := c.service.CustomersEmailWishlistsIdShareDelete(r.Context(), emailParam, idParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	_ = common.EncodeJSONResponse(result.Body, &result.Code, w)
+}
+
+// CustomersEmailWishlistsIdSharePost - Share a wishlist
+func (c *DefaultAPIController) CustomersEmailWishlistsIdSharePost(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	emailParam := params["email"]
+	if emailParam == "" {
+		c.errorHandler(w, r, &common.RequiredError{Field: "email"}, nil)
+		return
+	}
+	idParam, err := common.ParseNumericParameter[int64](
+		params["id"],
+		common.WithRequire[int64](common.ParseInt64),
+	)
+	if err != nil {
+		c.errorHandler(w, r, &common.ParsingError{Param: "id", Err: err}, nil)
+		return
+	}
+	result, err := c.service.CustomersEmailWishlistsIdSharePost(r.Context(), emailParam, idParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	_ = common.EncodeJSONResponse(result.Body, &result.Code, w)
+}
+
+// CustomersEmailWishlistsPost - Add a new wishlist
+func (c *DefaultAPIController) CustomersEmailWishlistsPost(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	emailParam := params["email"]
+	if emailParam == "" {
+		c.errorHandler(w, r, &common.RequiredError{Field: "email"}, nil)
+		return
+	}
+	wishlistParam := models.Wishlist{}
+	d := json.NewDecoder(r.Body)
+	d.DisallowUnknownFields()
+	if err := d.Decode(&wishlistParam); err != nil {
+		c.errorHandler(w, r, &common.ParsingError{Err: err}, nil)
+		return
+	}
+	if err := models.AssertWishlistRequired(wishlistParam); err != nil {
+		c.errorHandler(w, r, err, nil)
+		return
+	}
+	if err := models.AssertWishlistConstraints(wishlistParam); err != nil {
+		c.errorHandler(w, r, err, nil)
+		return
+	}
+	result, err := c.service.CustomersEmailWishlistsPost(r.Context(), emailParam, wishlistParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	_ = common.EncodeJSONResponse(result.Body, &result.Code, w)
+}
+
+// WishlistsSharedTokenGet - Get a shared wishlist
+func (c *DefaultAPIController) WishlistsSharedTokenGet(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	tokenParam := params["token"]
+	if tokenParam == "" {
+		c.errorHandler(w, r, &common.RequiredError{Field: "token"}, nil)
+		return
+	}
+	result, err := c.service.WishlistsSharedTokenGet(r.Context(), tokenParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	_ = common.EncodeJSONResponse(result.Body, &result.Code, w)
+}
@@ -0,0 +1,284 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/mayureshucsb2019/bookstore/service/common"
+	"github.com/mayureshucsb2019/bookstore/service/wishlist/db"
+	"github.com/mayureshucsb2019/bookstore/service/wishlist/models"
+)
+
+// DefaultAPIService is a service that implements the logic for the DefaultAPIServicer
+// This service manages the wishlists of customers and their back in stock and price drop alerts.
+type DefaultAPIService struct {
+	Repo *db.WishlistRepository
+}
+
+// NewDefaultAPIService creates a default API service with the given repository.
+func NewDefaultAPIService(repo *db.WishlistRepository) *DefaultAPIService {
+	return &DefaultAPIService{
+		Repo: repo,
+	}
+}
+
+// CustomersEmailAlertsAlertIdDelete - Delete an alert of a customer
+func (s *DefaultAPIService) CustomersEmailAlertsAlertIdDelete(ctx context.Context, email string, alertId int64) (common.ImplResponse, error) {
+	if err := s.Repo.DeleteAlert(email, alertId); err != nil {
+		return wishlistErrorResponse(err)
+	}
+
+	return common.Response(http.StatusNoContent, nil), nil
+}
+
+// CustomersEmailAlertsGet - Get the alerts of a customer
+func (s *DefaultAPIService) CustomersEmailAlertsGet(ctx context.Context, email string) (common.ImplResponse, error) {
+	alerts, err := s.Repo.GetAlerts(email)
+	if err != nil {
+		return wishlistErrorResponse(err)
+	}
+	alertsResp := []models.BookAlert{}
+	for _, alert := range alerts {
+		alertsResp = append(alertsResp, ConvertDBToAPIAlert(alert))
+	}
+
+	return common.Response(http.StatusOK, alertsResp), nil
+}
+
+// CustomersEmailAlertsPost - Set an alert on a book
+func (s *DefaultAPIService) CustomersEmailAlertsPost(ctx context.Context, email string, alert models.BookAlert) (common.ImplResponse, error) {
+	dbAlert := db.Alert{
+		CustomerEmail: email,
+		ISBN:          alert.Isbn,
+		Type:          alert.Type,
+		MinStock:      int(alert.MinStock),
+	}
+	if alert.Type == db.AlertBackInStock && dbAlert.MinStock == 0 {
+		dbAlert.MinStock = 1
+	}
+	if alert.TargetPrice != nil {
+		dbAlert.TargetPrice = *alert.TargetPrice
+	}
+	if err := s.Repo.SetAlert(&dbAlert); err != nil {
+		return wishlistErrorResponse(err)
+	}
+	saved, err := s.Repo.GetAlert(email, dbAlert.ID)
+	if err != nil {
+		return wishlistErrorResponse(err)
+	}
+
+	return common.Response(http.StatusCreated, ConvertDBToAPIAlert(*saved)), nil
+}
+
+// CustomersEmailNotificationsGet - Get the latest notifications of a customer
+func (s *DefaultAPIService) CustomersEmailNotificationsGet(ctx context.Context, email string) (common.ImplResponse, error) {
+	notifications, err := s.Repo.GetCustomerNotifications(email)
+	if err != nil {
+		return wishlistErrorResponse(err)
+	}
+	notificationsResp := []models.BookNotification{}
+	for _, notification := range notifications {
+		notificationsResp = append(notificationsResp, convertDBToAPINotification(notification))
+	}
+
+	return common.Response(http.StatusOK, notificationsResp), nil
+}
+
+// CustomersEmailWishlistsGet - Get the wishlists of a customer
+func (s *DefaultAPIService) CustomersEmailWishlistsGet(ctx context.Context, email string) (common.ImplResponse, error) {
+	wishlists, err := s.Repo.GetWishlists(email)
+	if err != nil {
+		return wishlistErrorResponse(err)
+	}
+	wishlistsResp := []models.Wishlist{}
+	for _, wishlist := range wishlists {
+		wishlistsResp = append(wishlistsResp, ConvertDBToAPIWishlist(wishlist))
+	}
+
+	return common.Response(http.StatusOK, wishlistsResp), nil
+}
+
+// CustomersEmailWishlistsIdDelete - Delete a wishlist
+func (s *DefaultAPIService) CustomersEmailWishlistsIdDelete(ctx context.Context, email string, id int64) (common.ImplResponse, error) {
+	if err := s.Repo.DeleteWishlist(email, id); err != nil {
+		return wishlistErrorResponse(err)
+	}
+
+	return common.Response(http.StatusNoContent, nil), nil
+}
+
+// CustomersEmailWishlistsIdGet - Get a wishlist with its books
+func (s *DefaultAPIService) CustomersEmailWishlistsIdGet(ctx context.Context, email string, id int64) (common.ImplResponse, error) {
+	wishlist, err := s.Repo.GetWishlist(email, id)
+	if err != nil {
+		return wishlistErrorResponse(err)
+	}
+
+	return common.Response(http.StatusOK, ConvertDBToAPIWishlist(*wishlist)), nil
+}
+
+// CustomersEmailWishlistsIdItemsIsbnDelete - Remove a book from a wishlist
+func (s *DefaultAPIService) CustomersEmailWishlistsIdItemsIsbnDelete(ctx context.Context, email string, id int64, isbn string) (common.ImplResponse, error) {
+	if err := s.Repo.RemoveItem(email, id, isbn); err != nil {
+		return wishlistErrorResponse(err)
+	}
+
+	return common.Response(http.StatusNoContent, nil), nil
+}
+
+// CustomersEmailWishlistsIdItemsIsbnPut - Add a book to a wishlist
+func (s *DefaultAPIService) CustomersEmailWishlistsIdItemsIsbnPut(ctx context.Context, email string, id int64, isbn string) (common.ImplResponse, error) {
+	if err := s.Repo.AddItem(email, id, isbn); err != nil {
+		return wishlistErrorResponse(err)
+	}
+	wishlist, err := s.Repo.GetWishlist(email, id)
+	if err != nil {
+		return wishlistErrorResponse(err)
+	}
+
+	return common.Response(http.StatusOK, ConvertDBToAPIWishlist(*wishlist)), nil
+}
+
+// CustomersEmailWishlistsIdPut - Rename a wishlist
+func (s *DefaultAPIService) CustomersEmailWishlistsIdPut(ctx context.Context, email string, id int64, wishlist models.Wishlist) (common.ImplResponse, error) {
+	if wishlist.Id != 0 && wishlist.Id != id {
+		return common.Response(http.StatusBadRequest, nil), errors.New("id in the path does not match id in the body")
+	}
+	renamed, err := s.Repo.RenameWishlist(email, id, strings.TrimSpace(wishlist.Name))
+	if err != nil {
+		return wishlistErrorResponse(err)
+	}
+
+	return common.Response(http.StatusOK, ConvertDBToAPIWishlist(*renamed)), nil
+}
+
+// CustomersEmailWishlistsIdShareDelete - Stop sharing a wishlist
+func (s *DefaultAPIService) CustomersEmailWishlistsIdShareDelete(ctx context.Context, email string, id int64) (common.ImplResponse, error) {
+	if err := s.Repo.UnshareWishlist(email, id); err != nil {
+		return wishlistErrorResponse(err)
+	}
+
+	return common.Response(http.StatusNoContent, nil), nil
+}
+
+// CustomersEmailWishlistsIdSharePost - Share a wishlist
+func (s *DefaultAPIService) CustomersEmailWishlistsIdSharePost(ctx context.Context, email string, id int64) (common.ImplResponse, error) {
+	wishlist, err := s.Repo.ShareWishlist(email, id)
+	if err != nil {
+		return wishlistErrorResponse(err)
+	}
+
+	return common.Response(http.StatusOK, ConvertDBToAPIWishlist(*wishlist)), nil
+}
+
+// CustomersEmailWishlistsPost - Add a new wishlist
+func (s *DefaultAPIService) CustomersEmailWishlistsPost(ctx context.Context, email string, wishlist models.Wishlist) (common.ImplResponse, error) {
+	created, err := s.Repo.CreateWishlist(email, strings.TrimSpace(wishlist.Name))
+	if err != nil {
+		return wishlistErrorResponse(err)
+	}
+
+	return common.Response(http.StatusCreated, ConvertDBToAPIWishlist(*created)), nil
+}
+
+// WishlistsSharedTokenGet - Get a shared wishlist
+func (s *DefaultAPIService) WishlistsSharedTokenGet(ctx context.Context, token string) (common.ImplResponse, error) {
+	wishlist, err := s.Repo.GetSharedWishlist(token)
+	if err != nil {
+		return wishlistErrorResponse(err)
+	}
+
+	// Whoever has the link sees the books and the owner's name, not how to change the list
+	resp := ConvertDBToAPIWishlist(*wishlist)
+	resp.Id = 0
+	resp.ShareToken = ""
+	return common.Response(http.StatusOK, resp), nil
+}
+
+// wishlistErrorResponse maps repository errors to their response status, or an internal error.
+func wishlistErrorResponse(err error) (common.ImplResponse, error) {
+	switch {
+	case errors.Is(err, db.ErrWishlistNotFound), errors.Is(err, db.ErrItemNotFound), errors.Is(err, db.ErrAlertNotFound),
+		errors.Is(err, db.ErrBookNotFound), errors.Is(err, db.ErrCustomerNotFound):
+		return common.Response(http.StatusNotFound, nil), err
+	case errors.Is(err, db.ErrWishlistExists):
+		return common.Response(http.StatusConflict, nil), err
+	case errors.Is(err, common.ErrCurrencyMismatch):
+		return common.Response(http.StatusUnprocessableEntity, nil), err
+	}
+	return common.Response(http.StatusInternalServerError, nil), err
+}
+
+// ConvertDBToAPIWishlist converts the DB model to the API model. The owner name is only shown on
+// shared wishlists.
+func ConvertDBToAPIWishlist(wishlist db.Wishlist) models.Wishlist {
+	resp := models.Wishlist{
+		Id:         wishlist.ID,
+		Name:       wishlist.Name,
+		ShareToken: common.StringOrEmpty(wishlist.ShareToken),
+		Items:      []models.WishlistItem{},
+		CreatedAt:  wishlist.CreatedAt,
+		UpdatedAt:  wishlist.UpdatedAt,
+	}
+	if wishlist.ShareToken.Valid {
+		resp.OwnerName = wishlist.OwnerName
+	}
+	for _, item := range wishlist.Items {
+		resp.Items = append(resp.Items, models.WishlistItem{
+			Isbn:    item.ISBN,
+			Name:    item.Name,
+			Cost:    item.Cost,
+			Stock:   int32(item.Stock),
+			AddedAt: item.AddedAt,
+		})
+	}
+	return resp
+}
+
+// ConvertDBToAPIAlert converts the DB model to the API model
+func ConvertDBToAPIAlert(alert db.Alert) models.BookAlert {
+	resp := models.BookAlert{
+		Id:         alert.ID,
+		Isbn:       alert.ISBN,
+		Type:       alert.Type,
+		BookName:   alert.BookName,
+		Active:     alert.Active,
+		CreatedAt:  alert.CreatedAt,
+		NotifiedAt: common.StringOrEmpty(alert.NotifiedAt),
+	}
+	switch alert.Type {
+	case db.AlertBackInStock:
+		resp.MinStock = int32(alert.MinStock)
+	case db.AlertPriceDrop:
+		targetPrice := alert.TargetPrice
+		resp.TargetPrice = &targetPrice
+	}
+	return resp
+}
+
+// convertDBToAPINotification converts the DB model to the API model
+func convertDBToAPINotification(notification db.Notification) models.BookNotification {
+	resp := models.BookNotification{
+		Id:        notification.ID,
+		AlertId:   notification.AlertID,
+		Type:      notification.AlertType,
+		Isbn:      notification.ISBN,
+		BookName:  notification.BookName,
+		Status:    notification.Status,
+		Attempts:  int32(notification.Attempts),
+		LastError: common.StringOrEmpty(notification.LastError),
+		CreatedAt: notification.CreatedAt,
+		SentAt:    common.StringOrEmpty(notification.SentAt),
+	}
+	if notification.Stock.Valid {
+		stock := int32(notification.Stock.Int64)
+		resp.Stock = &stock
+	}
+	if notification.Price.Currency != "" {
+		price := notification.Price
+		resp.Price = &price
+	}
+	return resp
+}
@@ -0,0 +1,136 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	event_db "github.com/mayureshucsb2019/bookstore/service/event/db"
+	"github.com/mayureshucsb2019/bookstore/service/mail"
+	"github.com/mayureshucsb2019/bookstore/service/wishlist/db"
+)
+
+// AlertSink fires the book alerts whose threshold a stock or price change crossed, queuing their
+// notifications. It is registered with the event dispatcher; the Notifier sends the mails.
+type AlertSink struct {
+	Repo *db.WishlistRepository
+}
+
+// Name identifies the sink in the outbox.
+func (AlertSink) Name() string {
+	return "book-alerts"
+}
+
+// Deliver fires the alerts matching BookStockChanged and BookPriceChanged events and ignores
+// other events.
+func (s AlertSink) Deliver(ctx context.Context, event event_db.Event) error {
+	switch event.Type {
+	case event_db.BookStockChanged:
+		var change event_db.StockChanged
+		if err := json.Unmarshal(event.Payload, &change); err != nil {
+			return fmt.Errorf("failed to decode %s payload: %w", event.Type, err)
+		}
+		_, err := s.Repo.StockChanged(event.ID, change.ISBN, change.Stock-change.Change, change.Stock)
+		return err
+	case event_db.BookPriceChanged:
+		var change event_db.PriceChanged
+		if err := json.Unmarshal(event.Payload, &change); err != nil {
+			return fmt.Errorf("failed to decode %s payload: %w", event.Type, err)
+		}
+		_, err := s.Repo.PriceChanged(event.ID, change.ISBN, change.OldCost, change.NewCost)
+		return err
+	}
+	return nil
+}
+
+// Notifier mails queued notifications in the background. A notification that cannot be sent is
+// tried again on the next rounds until MaxAttempts have failed. Every server instance can run
+// one: notifications are claimed for Lease before they are sent, so each is mailed once unless an
+// instance stops while sending it.
+type Notifier struct {
+	Repo   *db.WishlistRepository
+	Mailer mail.Mailer
+
+	MaxAttempts int
+	BatchSize   int           // Notifications sent per round
+	Lease       time.Duration // How long a round may take before others take over its notifications
+}
+
+// NewNotifier creates a notifier that tries each notification 5 times.
+func NewNotifier(repo *db.WishlistRepository, mailer mail.Mailer) *Notifier {
+	return &Notifier{
+		Repo:        repo,
+		Mailer:      mailer,
+		MaxAttempts: 5,
+		BatchSize:   50,
+		Lease:       5 * time.Minute,
+	}
+}
+
+// Start sends pending notifications every interval until the returned function is called.
+func (n *Notifier) Start(interval time.Duration) func() {
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if _, err := n.SendPending(ctx); err != nil {
+					log.Printf("Failed to send book alert notifications: %v", err)
+				}
+			}
+		}
+	}()
+	return cancel
+}
+
+// SendPending claims pending notifications, makes one attempt for each and returns how many were
+// sent.
+func (n *Notifier) SendPending(ctx context.Context) (int, error) {
+	notifications, err := n.Repo.ClaimPendingNotifications(n.BatchSize, n.Lease)
+	if err != nil {
+		return 0, err
+	}
+	sent := 0
+	for _, notification := range notifications {
+		if ctx.Err() != nil {
+			break
+		}
+		if err := n.send(ctx, notification); err != nil {
+			log.Printf("Failed to send notification %d to %s: %v", notification.ID, notification.CustomerEmail, err)
+			if err := n.Repo.MarkNotificationFailed(notification.ID, err, n.MaxAttempts); err != nil {
+				return sent, err
+			}
+			continue
+		}
+		if err := n.Repo.MarkNotificationSent(notification.ID); err != nil {
+			return sent, err
+		}
+		sent++
+	}
+	return sent, nil
+}
+
+func (n *Notifier) send(ctx context.Context, notification db.Notification) error {
+	data := mail.BookAlertData{
+		Name:  notification.CustomerName,
+		Title: notification.BookName,
+		ISBN:  notification.ISBN,
+		Stock: notification.Stock.Int64,
+	}
+	template := mail.TemplateBackInStock
+	if notification.AlertType == db.AlertPriceDrop {
+		template = mail.TemplatePriceDrop
+		data.Price = notification.Price.String()
+	}
+	message, err := mail.Render(template, notification.CustomerEmail, data)
+	if err != nil {
+		return err
+	}
+	return n.Mailer.Send(ctx, message)
+}